@@ -1,9 +1,11 @@
 package azcli
 
 import (
+	"path/filepath"
 	"strings"
 	"testing"
 
+	"github.com/Azure/aks-mcp/internal/command"
 	"github.com/Azure/aks-mcp/internal/config"
 	"github.com/Azure/aks-mcp/internal/security"
 )
@@ -623,3 +625,48 @@ func TestFleetExecutor_DeleteClusterResourcePlacement(t *testing.T) {
 		})
 	}
 }
+
+func TestFleetExecutor_Execute_Replay(t *testing.T) {
+	stop, err := command.UseCassette(filepath.Join("testdata", "cassettes", "fleet_list.json"))
+	if err != nil {
+		t.Fatalf("Failed to load cassette: %v", err)
+	}
+	defer func() {
+		if err := stop(); err != nil {
+			t.Errorf("Failed to save cassette: %v", err)
+		}
+	}()
+
+	executor := NewFleetExecutor()
+	cfg := &config.ConfigData{
+		AccessLevel: "readonly",
+		Timeout:     60,
+		SecurityConfig: &security.SecurityConfig{
+			AccessLevel: "readonly",
+		},
+	}
+
+	output, err := executor.Execute(map[string]any{
+		"operation": "list",
+		"resource":  "fleet",
+		"args":      "--resource-group test-rg --output json",
+	}, cfg)
+	if err != nil {
+		t.Fatalf("Execute() error = %v", err)
+	}
+	if !strings.Contains(output, "\"name\": \"test-fleet\"") {
+		t.Errorf("Execute() output = %q, want test-fleet", output)
+	}
+
+	output, err = executor.Execute(map[string]any{
+		"operation": "list",
+		"resource":  "member",
+		"args":      "--fleet-name test-fleet --resource-group test-rg --output json",
+	}, cfg)
+	if err != nil {
+		t.Fatalf("Execute() error = %v", err)
+	}
+	if strings.TrimSpace(output) != "[]" {
+		t.Errorf("Execute() output = %q, want []", output)
+	}
+}
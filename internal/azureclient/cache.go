@@ -1,6 +1,7 @@
 package azureclient
 
 import (
+	"log"
 	"sync"
 	"time"
 )
@@ -10,6 +11,9 @@ type AzureCache struct {
 	data           map[string]cacheItem
 	mu             sync.RWMutex
 	defaultTimeout time.Duration
+	// Optional disk-backed layer for read-only metadata, nil when disabled
+	persistent        *DiskCache
+	persistentTimeout time.Duration
 }
 
 // cacheItem represents a cached resource with expiration time.
@@ -62,18 +66,87 @@ func (c *AzureCache) SetWithExpiration(key string, value interface{}, duration t
 	}
 }
 
-// Delete removes a value from the cache.
+// Delete removes a value from the cache, including any persisted copy.
 func (c *AzureCache) Delete(key string) {
 	c.mu.Lock()
 	defer c.mu.Unlock()
 
 	delete(c.data, key)
+	if c.persistent != nil {
+		c.persistent.Delete(key)
+	}
 }
 
-// Clear removes all values from the cache.
+// Clear removes all values from the cache, including any persisted copies.
 func (c *AzureCache) Clear() {
 	c.mu.Lock()
 	defer c.mu.Unlock()
 
 	c.data = make(map[string]cacheItem)
+	if c.persistent != nil {
+		c.persistent.Clear()
+	}
+}
+
+// EnablePersistence attaches a disk-backed layer used by GetPersisted and SetPersisted.
+// Persisted entries expire after the given timeout.
+func (c *AzureCache) EnablePersistence(disk *DiskCache, timeout time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.persistent = disk
+	c.persistentTimeout = timeout
+}
+
+// IsPersistent returns true if a disk-backed layer is attached.
+func (c *AzureCache) IsPersistent() bool {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	return c.persistent != nil
+}
+
+// GetPersisted looks up a value in the disk-backed layer and decodes it into target,
+// which must be a pointer. On a hit the pointer is also stored in memory for the
+// remaining lifetime of the persisted entry, so later Get calls return it directly.
+// Returns false if persistence is disabled or the entry is missing or expired.
+func (c *AzureCache) GetPersisted(key string, target interface{}) bool {
+	c.mu.RLock()
+	disk := c.persistent
+	c.mu.RUnlock()
+
+	if disk == nil {
+		return false
+	}
+
+	remaining, found := disk.Get(key, target)
+	if !found {
+		return false
+	}
+
+	// Never keep the value in memory longer than the in-memory timeout
+	if remaining > c.defaultTimeout {
+		remaining = c.defaultTimeout
+	}
+	c.SetWithExpiration(key, target, remaining)
+
+	return true
+}
+
+// SetPersisted adds or updates a value in memory and, when persistence is enabled,
+// on disk. It must only be used for read-only metadata.
+func (c *AzureCache) SetPersisted(key string, value interface{}) {
+	c.Set(key, value)
+
+	c.mu.RLock()
+	disk, timeout := c.persistent, c.persistentTimeout
+	c.mu.RUnlock()
+
+	if disk == nil {
+		return
+	}
+
+	if err := disk.Set(key, value, timeout); err != nil {
+		log.Printf("Warning: failed to persist cache entry %s: %v", key, err)
+	}
 }
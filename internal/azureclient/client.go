@@ -4,64 +4,236 @@ package azureclient
 import (
 	"context"
 	"fmt"
+	"log"
+	"net/http"
+	"strings"
 	"sync"
 
 	"github.com/Azure/aks-mcp/internal/config"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
 	"github.com/Azure/azure-sdk-for-go/sdk/azcore/arm"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/cloud"
 	"github.com/Azure/azure-sdk-for-go/sdk/azidentity"
+	"github.com/Azure/azure-sdk-for-go/sdk/monitor/azquery"
 	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/compute/armcompute/v4"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/containerregistry/armcontainerregistry"
 	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/containerservice/armcontainerservice/v2"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/keyvault/armkeyvault"
 	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/monitor/armmonitor"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/msi/armmsi"
 	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/network/armnetwork/v2"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/privatedns/armprivatedns"
 )
 
 // SubscriptionClients contains Azure clients for a specific subscription.
 type SubscriptionClients struct {
-	SubscriptionID           string
-	ContainerServiceClient   *armcontainerservice.ManagedClustersClient
-	VNetClient               *armnetwork.VirtualNetworksClient
-	SubnetsClient            *armnetwork.SubnetsClient
-	RouteTableClient         *armnetwork.RouteTablesClient
-	NSGClient                *armnetwork.SecurityGroupsClient
-	LoadBalancerClient       *armnetwork.LoadBalancersClient
-	PrivateEndpointsClient   *armnetwork.PrivateEndpointsClient
-	VMSSClient               *armcompute.VirtualMachineScaleSetsClient
-	VMSSVMsClient            *armcompute.VirtualMachineScaleSetVMsClient
-	DiagnosticSettingsClient *armmonitor.DiagnosticSettingsClient
-}
-
-// AzureClient represents an Azure API client that can handle multiple subscriptions.
+	SubscriptionID             string
+	ContainerServiceClient     *armcontainerservice.ManagedClustersClient
+	AgentPoolsClient           *armcontainerservice.AgentPoolsClient
+	VNetClient                 *armnetwork.VirtualNetworksClient
+	SubnetsClient              *armnetwork.SubnetsClient
+	RouteTableClient           *armnetwork.RouteTablesClient
+	NSGClient                  *armnetwork.SecurityGroupsClient
+	LoadBalancerClient         *armnetwork.LoadBalancersClient
+	PrivateEndpointsClient     *armnetwork.PrivateEndpointsClient
+	PublicIPAddressesClient    *armnetwork.PublicIPAddressesClient
+	NatGatewaysClient          *armnetwork.NatGatewaysClient
+	ApplicationGatewaysClient  *armnetwork.ApplicationGatewaysClient
+	PrivateDNSZonesClient      *armprivatedns.PrivateZonesClient
+	VirtualNetworkLinksClient  *armprivatedns.VirtualNetworkLinksClient
+	VMSSClient                 *armcompute.VirtualMachineScaleSetsClient
+	VMSSVMsClient              *armcompute.VirtualMachineScaleSetVMsClient
+	DisksClient                *armcompute.DisksClient
+	UserAssignedIdentityClient *armmsi.UserAssignedIdentitiesClient
+	KeyVaultClient             *armkeyvault.VaultsClient
+	ContainerRegistryClient    *armcontainerregistry.RegistriesClient
+	DiagnosticSettingsClient   *armmonitor.DiagnosticSettingsClient
+	MetricsClient              *azquery.MetricsClient
+	ActivityLogsClient         *armmonitor.ActivityLogsClient
+	MetricAlertsClient         *armmonitor.MetricAlertsClient
+	ScheduledQueryRulesClient  *armmonitor.ScheduledQueryRulesClient
+	DCRAssociationsClient      *armmonitor.DataCollectionRuleAssociationsClient
+	DataCollectionRulesClient  *armmonitor.DataCollectionRulesClient
+	MonitorWorkspacesClient    *armmonitor.AzureMonitorWorkspacesClient
+}
+
+// AzureClient represents an Azure API client that can handle multiple subscriptions and tenants.
 type AzureClient struct {
-	// Map of subscription ID to clients for that subscription
-	clientsMap map[string]*SubscriptionClients
-	// Mutex to ensure thread safety when accessing the map
+	// Map of (tenant, subscription) to clients for that subscription
+	clientsMap map[clientKey]*SubscriptionClients
+	// Mutex to ensure thread safety when accessing the maps
 	mu sync.RWMutex
-	// Shared credential for all clients
-	credential *azidentity.DefaultAzureCredential
+	// Default credential, used when the tenant of a subscription is unknown
+	credential azcore.TokenCredential
+	// Credentials for specific tenants, created on first use
+	tenantCredentials map[string]azcore.TokenCredential
+	// Factory for tenant-specific credentials
+	newTenantCredential func(tenantID string) (azcore.TokenCredential, error)
+	// Map of lowercase subscription ID to tenant ID
+	subscriptionTenants map[string]string
+	// Runs subscription-to-tenant discovery once; concurrent callers wait for it to finish
+	tenantsOnce sync.Once
+	// Whether subscription-to-tenant discovery has filled the map
+	tenantsDiscovered bool
+	// Discovers subscription-to-tenant mappings, nil disables discovery
+	tenantLookup func() (map[string]string, error)
+	// Tenants that credentials may be used with (empty allows any)
+	allowedTenants []string
 	// Cache for Azure resources
 	cache *AzureCache
+	// Azure Resource Manager endpoint used for REST calls
+	endpoint string
+	// Options passed to every SDK client, nil uses the SDK defaults
+	clientOptions *arm.ClientOptions
+	// HTTP client used for REST calls made outside the SDK clients
+	httpClient *http.Client
+}
+
+// DefaultResourceManagerEndpoint is the Azure Resource Manager endpoint of the public cloud.
+const DefaultResourceManagerEndpoint = "https://management.azure.com"
+
+// ClientOptions overrides how AzureClient reaches Azure Resource Manager.
+// It is primarily intended for tests that run against a local ARM stand-in.
+type ClientOptions struct {
+	// Credential replaces DefaultAzureCredential for every tenant.
+	// Setting it also disables tenant discovery through the Azure CLI.
+	Credential azcore.TokenCredential
+	// Endpoint replaces the Azure Resource Manager endpoint, e.g. "https://127.0.0.1:8443".
+	Endpoint string
+	// HTTPClient is used for all requests to Azure Resource Manager.
+	HTTPClient *http.Client
 }
 
 // NewAzureClient creates a new Azure client using default credentials and the provided configuration.
 func NewAzureClient(cfg *config.ConfigData) (*AzureClient, error) {
-	// Create a credential using DefaultAzureCredential
-	cred, err := azidentity.NewDefaultAzureCredential(nil)
-	if err != nil {
-		return nil, fmt.Errorf("failed to create credential: %v", err)
+	return NewAzureClientWithOptions(cfg, nil)
+}
+
+// NewAzureClientWithOptions creates a new Azure client with the provided configuration,
+// optionally overriding the credential and Azure Resource Manager endpoint.
+func NewAzureClientWithOptions(cfg *config.ConfigData, opts *ClientOptions) (*AzureClient, error) {
+	if opts == nil {
+		opts = &ClientOptions{}
+	}
+
+	cred := opts.Credential
+	newTenantCredential := newDefaultTenantCredential
+	tenantLookup := listSubscriptionTenantsViaCLI
+	if cred == nil {
+		// Create a credential using DefaultAzureCredential
+		defaultCred, err := azidentity.NewDefaultAzureCredential(nil)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create credential: %v", err)
+		}
+		cred = defaultCred
+	} else {
+		// The provided credential is used for every tenant
+		newTenantCredential = func(string) (azcore.TokenCredential, error) {
+			return opts.Credential, nil
+		}
+		tenantLookup = nil
+	}
+
+	endpoint := DefaultResourceManagerEndpoint
+	var clientOptions *arm.ClientOptions
+	if opts.Endpoint != "" || opts.HTTPClient != nil {
+		clientOptions = &arm.ClientOptions{}
+		if opts.Endpoint != "" {
+			endpoint = strings.TrimSuffix(opts.Endpoint, "/")
+			clientOptions.Cloud = cloud.Configuration{
+				ActiveDirectoryAuthorityHost: cloud.AzurePublic.ActiveDirectoryAuthorityHost,
+				Services: map[cloud.ServiceName]cloud.ServiceConfiguration{
+					cloud.ResourceManager: {
+						Endpoint: endpoint,
+						Audience: endpoint,
+					},
+				},
+			}
+		}
+		if opts.HTTPClient != nil {
+			clientOptions.Transport = opts.HTTPClient
+		}
+	}
+
+	httpClient := opts.HTTPClient
+	if httpClient == nil {
+		httpClient = &http.Client{}
+	}
+
+	cache := NewAzureCache(cfg.CacheTimeout)
+
+	// Attach the optional disk-backed cache for read-only metadata
+	if cfg.PersistentCache {
+		diskCache, err := NewDiskCache(cfg.PersistentCacheDir, cfg.PersistentCacheKeyFile)
+		if err != nil {
+			log.Printf("Warning: persistent cache disabled: %v", err)
+		} else {
+			cache.EnablePersistence(diskCache, cfg.PersistentCacheTimeout)
+		}
+	}
+
+	var allowedTenants []string
+	for _, tenantID := range strings.Split(cfg.AllowedTenants, ",") {
+		if tenantID = strings.TrimSpace(tenantID); tenantID != "" {
+			allowedTenants = append(allowedTenants, tenantID)
+		}
 	}
 
 	return &AzureClient{
-		clientsMap: make(map[string]*SubscriptionClients),
-		credential: cred,
-		cache:      NewAzureCache(cfg.CacheTimeout),
+		clientsMap:          make(map[clientKey]*SubscriptionClients),
+		credential:          cred,
+		tenantCredentials:   make(map[string]azcore.TokenCredential),
+		newTenantCredential: newTenantCredential,
+		subscriptionTenants: make(map[string]string),
+		tenantLookup:        tenantLookup,
+		allowedTenants:      allowedTenants,
+		cache:               cache,
+		endpoint:            endpoint,
+		clientOptions:       clientOptions,
+		httpClient:          httpClient,
 	}, nil
 }
 
+// ResourceManagerEndpoint returns the Azure Resource Manager endpoint used by the client.
+func (c *AzureClient) ResourceManagerEndpoint() string {
+	if c.endpoint == "" {
+		return DefaultResourceManagerEndpoint
+	}
+	return c.endpoint
+}
+
 // GetOrCreateClientsForSubscription gets existing clients for a subscription or creates new ones.
+// The tenant is resolved from the subscription-to-tenant map, falling back to the default credential.
 func (c *AzureClient) GetOrCreateClientsForSubscription(subscriptionID string) (*SubscriptionClients, error) {
+	tenantID, err := c.GetTenantForSubscription(subscriptionID)
+	if err != nil {
+		return nil, err
+	}
+	return c.GetOrCreateClientsForTenantSubscription(tenantID, subscriptionID)
+}
+
+// GetOrCreateClientsForContext gets existing clients for a subscription in the tenant of the call or creates new ones.
+// The tenant is the one set on ctx with WithTenant, or else the tenant the subscription belongs to.
+func (c *AzureClient) GetOrCreateClientsForContext(ctx context.Context, subscriptionID string) (*SubscriptionClients, error) {
+	tenantID, err := c.tenantFor(ctx, subscriptionID)
+	if err != nil {
+		return nil, err
+	}
+	return c.GetOrCreateClientsForTenantSubscription(tenantID, subscriptionID)
+}
+
+// GetOrCreateClientsForTenantSubscription gets existing clients for a subscription in a tenant or creates new ones.
+// An empty tenant ID uses the default credential.
+func (c *AzureClient) GetOrCreateClientsForTenantSubscription(tenantID, subscriptionID string) (*SubscriptionClients, error) {
+	if !c.isTenantAllowed(tenantID) {
+		return nil, fmt.Errorf("tenant %s is not in the list of allowed tenants", tenantID)
+	}
+
+	key := clientKey{tenantID: strings.ToLower(tenantID), subscriptionID: subscriptionID}
+
 	// First try to get existing clients with a read lock
 	c.mu.RLock()
-	clients, exists := c.clientsMap[subscriptionID]
+	clients, exists := c.clientsMap[key]
 	c.mu.RUnlock()
 
 	if exists {
@@ -73,84 +245,193 @@ func (c *AzureClient) GetOrCreateClientsForSubscription(subscriptionID string) (
 	defer c.mu.Unlock()
 
 	// Check again in case another goroutine created the clients while we were waiting for the lock
-	if clients, exists = c.clientsMap[subscriptionID]; exists {
+	if clients, exists = c.clientsMap[key]; exists {
 		return clients, nil
 	}
 
+	credential, err := c.getCredentialLocked(tenantID)
+	if err != nil {
+		return nil, err
+	}
+
 	// Create new clients for this subscription
-	containerServiceClient, err := armcontainerservice.NewManagedClustersClient(subscriptionID, c.credential, nil)
+	containerServiceClient, err := armcontainerservice.NewManagedClustersClient(subscriptionID, credential, c.clientOptions)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create container service client for subscription %s: %v", subscriptionID, err)
 	}
 
-	vnetClient, err := armnetwork.NewVirtualNetworksClient(subscriptionID, c.credential, nil)
+	agentPoolsClient, err := armcontainerservice.NewAgentPoolsClient(subscriptionID, credential, c.clientOptions)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create agent pools client for subscription %s: %v", subscriptionID, err)
+	}
+
+	vnetClient, err := armnetwork.NewVirtualNetworksClient(subscriptionID, credential, c.clientOptions)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create virtual network client for subscription %s: %v", subscriptionID, err)
 	}
 
-	routeTableClient, err := armnetwork.NewRouteTablesClient(subscriptionID, c.credential, nil)
+	routeTableClient, err := armnetwork.NewRouteTablesClient(subscriptionID, credential, c.clientOptions)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create route table client for subscription %s: %v", subscriptionID, err)
 	}
 
-	nsgClient, err := armnetwork.NewSecurityGroupsClient(subscriptionID, c.credential, nil)
+	nsgClient, err := armnetwork.NewSecurityGroupsClient(subscriptionID, credential, c.clientOptions)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create network security group client for subscription %s: %v", subscriptionID, err)
 	}
 
-	subnetsClient, err := armnetwork.NewSubnetsClient(subscriptionID, c.credential, nil)
+	subnetsClient, err := armnetwork.NewSubnetsClient(subscriptionID, credential, c.clientOptions)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create subnets client for subscription %s: %v", subscriptionID, err)
 	}
 
-	loadBalancerClient, err := armnetwork.NewLoadBalancersClient(subscriptionID, c.credential, nil)
+	loadBalancerClient, err := armnetwork.NewLoadBalancersClient(subscriptionID, credential, c.clientOptions)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create load balancer client for subscription %s: %v", subscriptionID, err)
 	}
 
-	privateEndpointsClient, err := armnetwork.NewPrivateEndpointsClient(subscriptionID, c.credential, nil)
+	privateEndpointsClient, err := armnetwork.NewPrivateEndpointsClient(subscriptionID, credential, c.clientOptions)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create private endpoints client for subscription %s: %v", subscriptionID, err)
 	}
 
-	vmssClient, err := armcompute.NewVirtualMachineScaleSetsClient(subscriptionID, c.credential, nil)
+	publicIPAddressesClient, err := armnetwork.NewPublicIPAddressesClient(subscriptionID, credential, c.clientOptions)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create public IP addresses client for subscription %s: %v", subscriptionID, err)
+	}
+
+	natGatewaysClient, err := armnetwork.NewNatGatewaysClient(subscriptionID, credential, c.clientOptions)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create NAT gateways client for subscription %s: %v", subscriptionID, err)
+	}
+
+	applicationGatewaysClient, err := armnetwork.NewApplicationGatewaysClient(subscriptionID, credential, c.clientOptions)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create application gateways client for subscription %s: %v", subscriptionID, err)
+	}
+
+	privateDNSZonesClient, err := armprivatedns.NewPrivateZonesClient(subscriptionID, credential, c.clientOptions)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create private DNS zones client for subscription %s: %v", subscriptionID, err)
+	}
+
+	virtualNetworkLinksClient, err := armprivatedns.NewVirtualNetworkLinksClient(subscriptionID, credential, c.clientOptions)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create private DNS virtual network links client for subscription %s: %v", subscriptionID, err)
+	}
+
+	vmssClient, err := armcompute.NewVirtualMachineScaleSetsClient(subscriptionID, credential, c.clientOptions)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create VMSS client for subscription %s: %v", subscriptionID, err)
 	}
 
-	vmssVMsClient, err := armcompute.NewVirtualMachineScaleSetVMsClient(subscriptionID, c.credential, nil)
+	vmssVMsClient, err := armcompute.NewVirtualMachineScaleSetVMsClient(subscriptionID, credential, c.clientOptions)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create VMSS VMs client for subscription %s: %v", subscriptionID, err)
 	}
 
-	diagnosticSettingsClient, err := armmonitor.NewDiagnosticSettingsClient(c.credential, nil)
+	disksClient, err := armcompute.NewDisksClient(subscriptionID, credential, c.clientOptions)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create disks client for subscription %s: %v", subscriptionID, err)
+	}
+
+	userAssignedIdentityClient, err := armmsi.NewUserAssignedIdentitiesClient(subscriptionID, credential, c.clientOptions)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create user-assigned identities client for subscription %s: %v", subscriptionID, err)
+	}
+
+	keyVaultClient, err := armkeyvault.NewVaultsClient(subscriptionID, credential, c.clientOptions)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create key vault client for subscription %s: %v", subscriptionID, err)
+	}
+
+	containerRegistryClient, err := armcontainerregistry.NewRegistriesClient(subscriptionID, credential, c.clientOptions)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create container registry client for subscription %s: %v", subscriptionID, err)
+	}
+
+	diagnosticSettingsClient, err := armmonitor.NewDiagnosticSettingsClient(credential, c.clientOptions)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create diagnostic settings client for subscription %s: %v", subscriptionID, err)
 	}
 
+	metricsClient, err := azquery.NewMetricsClient(credential, c.metricsClientOptions())
+	if err != nil {
+		return nil, fmt.Errorf("failed to create metrics client for subscription %s: %v", subscriptionID, err)
+	}
+
+	activityLogsClient, err := armmonitor.NewActivityLogsClient(subscriptionID, credential, c.clientOptions)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create activity logs client for subscription %s: %v", subscriptionID, err)
+	}
+
+	metricAlertsClient, err := armmonitor.NewMetricAlertsClient(subscriptionID, credential, c.clientOptions)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create metric alerts client for subscription %s: %v", subscriptionID, err)
+	}
+
+	scheduledQueryRulesClient, err := armmonitor.NewScheduledQueryRulesClient(subscriptionID, credential, c.clientOptions)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create scheduled query rules client for subscription %s: %v", subscriptionID, err)
+	}
+
+	dcrAssociationsClient, err := armmonitor.NewDataCollectionRuleAssociationsClient(subscriptionID, credential, c.clientOptions)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create data collection rule associations client for subscription %s: %v", subscriptionID, err)
+	}
+
+	dataCollectionRulesClient, err := armmonitor.NewDataCollectionRulesClient(subscriptionID, credential, c.clientOptions)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create data collection rules client for subscription %s: %v", subscriptionID, err)
+	}
+
+	monitorWorkspacesClient, err := armmonitor.NewAzureMonitorWorkspacesClient(subscriptionID, credential, c.clientOptions)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create Azure Monitor workspaces client for subscription %s: %v", subscriptionID, err)
+	}
+
 	// Create and store the clients
 	clients = &SubscriptionClients{
-		SubscriptionID:           subscriptionID,
-		ContainerServiceClient:   containerServiceClient,
-		VNetClient:               vnetClient,
-		SubnetsClient:            subnetsClient,
-		RouteTableClient:         routeTableClient,
-		NSGClient:                nsgClient,
-		LoadBalancerClient:       loadBalancerClient,
-		PrivateEndpointsClient:   privateEndpointsClient,
-		VMSSClient:               vmssClient,
-		VMSSVMsClient:            vmssVMsClient,
-		DiagnosticSettingsClient: diagnosticSettingsClient,
-	}
-
-	c.clientsMap[subscriptionID] = clients
+		SubscriptionID:             subscriptionID,
+		ContainerServiceClient:     containerServiceClient,
+		AgentPoolsClient:           agentPoolsClient,
+		VNetClient:                 vnetClient,
+		SubnetsClient:              subnetsClient,
+		RouteTableClient:           routeTableClient,
+		NSGClient:                  nsgClient,
+		LoadBalancerClient:         loadBalancerClient,
+		PrivateEndpointsClient:     privateEndpointsClient,
+		PublicIPAddressesClient:    publicIPAddressesClient,
+		NatGatewaysClient:          natGatewaysClient,
+		ApplicationGatewaysClient:  applicationGatewaysClient,
+		PrivateDNSZonesClient:      privateDNSZonesClient,
+		VirtualNetworkLinksClient:  virtualNetworkLinksClient,
+		VMSSClient:                 vmssClient,
+		VMSSVMsClient:              vmssVMsClient,
+		DisksClient:                disksClient,
+		UserAssignedIdentityClient: userAssignedIdentityClient,
+		KeyVaultClient:             keyVaultClient,
+		ContainerRegistryClient:    containerRegistryClient,
+		DiagnosticSettingsClient:   diagnosticSettingsClient,
+		MetricsClient:              metricsClient,
+		ActivityLogsClient:         activityLogsClient,
+		MetricAlertsClient:         metricAlertsClient,
+		ScheduledQueryRulesClient:  scheduledQueryRulesClient,
+		DCRAssociationsClient:      dcrAssociationsClient,
+		DataCollectionRulesClient:  dataCollectionRulesClient,
+		MonitorWorkspacesClient:    monitorWorkspacesClient,
+	}
+
+	c.clientsMap[key] = clients
 	return clients, nil
 }
 
 // GetAKSCluster retrieves information about the specified AKS cluster.
+// The cluster is cached in memory only: it changes with every operation on the cluster, so it is not persisted.
+// Use GetAKSClusterStatus to act on the current state of the cluster.
 func (c *AzureClient) GetAKSCluster(ctx context.Context, subscriptionID, resourceGroup, clusterName string) (*armcontainerservice.ManagedCluster, error) {
 	// Create cache key
-	cacheKey := fmt.Sprintf("resource:cluster:%s:%s:%s", subscriptionID, resourceGroup, clusterName)
+	cacheKey := c.CacheKey(ctx, "resource:cluster", subscriptionID, resourceGroup, clusterName)
 
 	// Check cache first
 	if cached, found := c.cache.Get(cacheKey); found {
@@ -159,7 +440,7 @@ func (c *AzureClient) GetAKSCluster(ctx context.Context, subscriptionID, resourc
 		}
 	}
 
-	clients, err := c.GetOrCreateClientsForSubscription(subscriptionID)
+	clients, err := c.GetOrCreateClientsForContext(ctx, subscriptionID)
 	if err != nil {
 		return nil, err
 	}
@@ -179,7 +460,7 @@ func (c *AzureClient) GetAKSCluster(ctx context.Context, subscriptionID, resourc
 // GetVirtualNetwork retrieves information about the specified virtual network.
 func (c *AzureClient) GetVirtualNetwork(ctx context.Context, subscriptionID, resourceGroup, vnetName string) (*armnetwork.VirtualNetwork, error) {
 	// Create cache key
-	cacheKey := fmt.Sprintf("resource:vnet:%s:%s:%s", subscriptionID, resourceGroup, vnetName)
+	cacheKey := c.CacheKey(ctx, "resource:vnet", subscriptionID, resourceGroup, vnetName)
 
 	// Check cache first
 	if cached, found := c.cache.Get(cacheKey); found {
@@ -188,7 +469,13 @@ func (c *AzureClient) GetVirtualNetwork(ctx context.Context, subscriptionID, res
 		}
 	}
 
-	clients, err := c.GetOrCreateClientsForSubscription(subscriptionID)
+	// Check persistent cache next
+	var persisted armnetwork.VirtualNetwork
+	if c.cache.GetPersisted(cacheKey, &persisted) {
+		return &persisted, nil
+	}
+
+	clients, err := c.GetOrCreateClientsForContext(ctx, subscriptionID)
 	if err != nil {
 		return nil, err
 	}
@@ -200,7 +487,7 @@ func (c *AzureClient) GetVirtualNetwork(ctx context.Context, subscriptionID, res
 
 	vnet := &resp.VirtualNetwork
 	// Store in cache
-	c.cache.Set(cacheKey, vnet)
+	c.cache.SetPersisted(cacheKey, vnet)
 
 	return vnet, nil
 }
@@ -208,7 +495,7 @@ func (c *AzureClient) GetVirtualNetwork(ctx context.Context, subscriptionID, res
 // GetRouteTable retrieves information about the specified route table.
 func (c *AzureClient) GetRouteTable(ctx context.Context, subscriptionID, resourceGroup, routeTableName string) (*armnetwork.RouteTable, error) {
 	// Create cache key
-	cacheKey := fmt.Sprintf("resource:routetable:%s:%s:%s", subscriptionID, resourceGroup, routeTableName)
+	cacheKey := c.CacheKey(ctx, "resource:routetable", subscriptionID, resourceGroup, routeTableName)
 
 	// Check cache first
 	if cached, found := c.cache.Get(cacheKey); found {
@@ -217,7 +504,13 @@ func (c *AzureClient) GetRouteTable(ctx context.Context, subscriptionID, resourc
 		}
 	}
 
-	clients, err := c.GetOrCreateClientsForSubscription(subscriptionID)
+	// Check persistent cache next
+	var persisted armnetwork.RouteTable
+	if c.cache.GetPersisted(cacheKey, &persisted) {
+		return &persisted, nil
+	}
+
+	clients, err := c.GetOrCreateClientsForContext(ctx, subscriptionID)
 	if err != nil {
 		return nil, err
 	}
@@ -229,7 +522,7 @@ func (c *AzureClient) GetRouteTable(ctx context.Context, subscriptionID, resourc
 
 	routeTable := &resp.RouteTable
 	// Store in cache
-	c.cache.Set(cacheKey, routeTable)
+	c.cache.SetPersisted(cacheKey, routeTable)
 
 	return routeTable, nil
 }
@@ -237,7 +530,7 @@ func (c *AzureClient) GetRouteTable(ctx context.Context, subscriptionID, resourc
 // GetNetworkSecurityGroup retrieves information about the specified network security group.
 func (c *AzureClient) GetNetworkSecurityGroup(ctx context.Context, subscriptionID, resourceGroup, nsgName string) (*armnetwork.SecurityGroup, error) {
 	// Create cache key
-	cacheKey := fmt.Sprintf("resource:nsg:%s:%s:%s", subscriptionID, resourceGroup, nsgName)
+	cacheKey := c.CacheKey(ctx, "resource:nsg", subscriptionID, resourceGroup, nsgName)
 
 	// Check cache first
 	if cached, found := c.cache.Get(cacheKey); found {
@@ -246,7 +539,13 @@ func (c *AzureClient) GetNetworkSecurityGroup(ctx context.Context, subscriptionI
 		}
 	}
 
-	clients, err := c.GetOrCreateClientsForSubscription(subscriptionID)
+	// Check persistent cache next
+	var persisted armnetwork.SecurityGroup
+	if c.cache.GetPersisted(cacheKey, &persisted) {
+		return &persisted, nil
+	}
+
+	clients, err := c.GetOrCreateClientsForContext(ctx, subscriptionID)
 	if err != nil {
 		return nil, err
 	}
@@ -258,7 +557,7 @@ func (c *AzureClient) GetNetworkSecurityGroup(ctx context.Context, subscriptionI
 
 	nsg := &resp.SecurityGroup
 	// Store in cache
-	c.cache.Set(cacheKey, nsg)
+	c.cache.SetPersisted(cacheKey, nsg)
 
 	return nsg, nil
 }
@@ -266,7 +565,7 @@ func (c *AzureClient) GetNetworkSecurityGroup(ctx context.Context, subscriptionI
 // GetSubnet retrieves information about the specified subnet in a virtual network.
 func (c *AzureClient) GetSubnet(ctx context.Context, subscriptionID, resourceGroup, vnetName, subnetName string) (*armnetwork.Subnet, error) {
 	// Create cache key
-	cacheKey := fmt.Sprintf("resource:subnet:%s:%s:%s:%s", subscriptionID, resourceGroup, vnetName, subnetName)
+	cacheKey := c.CacheKey(ctx, "resource:subnet", subscriptionID, resourceGroup, vnetName, subnetName)
 
 	// Check cache first
 	if cached, found := c.cache.Get(cacheKey); found {
@@ -275,7 +574,13 @@ func (c *AzureClient) GetSubnet(ctx context.Context, subscriptionID, resourceGro
 		}
 	}
 
-	clients, err := c.GetOrCreateClientsForSubscription(subscriptionID)
+	// Check persistent cache next
+	var persisted armnetwork.Subnet
+	if c.cache.GetPersisted(cacheKey, &persisted) {
+		return &persisted, nil
+	}
+
+	clients, err := c.GetOrCreateClientsForContext(ctx, subscriptionID)
 	if err != nil {
 		return nil, err
 	}
@@ -287,7 +592,7 @@ func (c *AzureClient) GetSubnet(ctx context.Context, subscriptionID, resourceGro
 
 	subnet := &resp.Subnet
 	// Store in cache
-	c.cache.Set(cacheKey, subnet)
+	c.cache.SetPersisted(cacheKey, subnet)
 
 	return subnet, nil
 }
@@ -295,7 +600,7 @@ func (c *AzureClient) GetSubnet(ctx context.Context, subscriptionID, resourceGro
 // GetLoadBalancer retrieves information about the specified load balancer.
 func (c *AzureClient) GetLoadBalancer(ctx context.Context, subscriptionID, resourceGroup, lbName string) (*armnetwork.LoadBalancer, error) {
 	// Create cache key
-	cacheKey := fmt.Sprintf("resource:loadbalancer:%s:%s:%s", subscriptionID, resourceGroup, lbName)
+	cacheKey := c.CacheKey(ctx, "resource:loadbalancer", subscriptionID, resourceGroup, lbName)
 
 	// Check cache first
 	if cached, found := c.cache.Get(cacheKey); found {
@@ -304,7 +609,13 @@ func (c *AzureClient) GetLoadBalancer(ctx context.Context, subscriptionID, resou
 		}
 	}
 
-	clients, err := c.GetOrCreateClientsForSubscription(subscriptionID)
+	// Check persistent cache next
+	var persisted armnetwork.LoadBalancer
+	if c.cache.GetPersisted(cacheKey, &persisted) {
+		return &persisted, nil
+	}
+
+	clients, err := c.GetOrCreateClientsForContext(ctx, subscriptionID)
 	if err != nil {
 		return nil, err
 	}
@@ -316,7 +627,7 @@ func (c *AzureClient) GetLoadBalancer(ctx context.Context, subscriptionID, resou
 
 	lb := &resp.LoadBalancer
 	// Store in cache
-	c.cache.Set(cacheKey, lb)
+	c.cache.SetPersisted(cacheKey, lb)
 
 	return lb, nil
 }
@@ -324,7 +635,7 @@ func (c *AzureClient) GetLoadBalancer(ctx context.Context, subscriptionID, resou
 // GetPrivateEndpoint retrieves information about the specified private endpoint.
 func (c *AzureClient) GetPrivateEndpoint(ctx context.Context, subscriptionID, resourceGroup, peName string) (*armnetwork.PrivateEndpoint, error) {
 	// Create cache key
-	cacheKey := fmt.Sprintf("resource:privateendpoint:%s:%s:%s", subscriptionID, resourceGroup, peName)
+	cacheKey := c.CacheKey(ctx, "resource:privateendpoint", subscriptionID, resourceGroup, peName)
 
 	// Check cache first
 	if cached, found := c.cache.Get(cacheKey); found {
@@ -333,7 +644,13 @@ func (c *AzureClient) GetPrivateEndpoint(ctx context.Context, subscriptionID, re
 		}
 	}
 
-	clients, err := c.GetOrCreateClientsForSubscription(subscriptionID)
+	// Check persistent cache next
+	var persisted armnetwork.PrivateEndpoint
+	if c.cache.GetPersisted(cacheKey, &persisted) {
+		return &persisted, nil
+	}
+
+	clients, err := c.GetOrCreateClientsForContext(ctx, subscriptionID)
 	if err != nil {
 		return nil, err
 	}
@@ -345,7 +662,7 @@ func (c *AzureClient) GetPrivateEndpoint(ctx context.Context, subscriptionID, re
 
 	pe := &resp.PrivateEndpoint
 	// Store in cache
-	c.cache.Set(cacheKey, pe)
+	c.cache.SetPersisted(cacheKey, pe)
 
 	return pe, nil
 }
@@ -368,7 +685,7 @@ func (c *AzureClient) GetPrivateEndpointByID(ctx context.Context, privateEndpoin
 // GetVMSS retrieves information about the specified VMSS.
 func (c *AzureClient) GetVMSS(ctx context.Context, subscriptionID, resourceGroup, vmssName string) (*armcompute.VirtualMachineScaleSet, error) {
 	// Create cache key
-	cacheKey := fmt.Sprintf("resource:vmss:%s:%s:%s", subscriptionID, resourceGroup, vmssName)
+	cacheKey := c.CacheKey(ctx, "resource:vmss", subscriptionID, resourceGroup, vmssName)
 
 	// Check cache first
 	if cached, found := c.cache.Get(cacheKey); found {
@@ -377,7 +694,13 @@ func (c *AzureClient) GetVMSS(ctx context.Context, subscriptionID, resourceGroup
 		}
 	}
 
-	clients, err := c.GetOrCreateClientsForSubscription(subscriptionID)
+	// Check persistent cache next
+	var persisted armcompute.VirtualMachineScaleSet
+	if c.cache.GetPersisted(cacheKey, &persisted) {
+		return &persisted, nil
+	}
+
+	clients, err := c.GetOrCreateClientsForContext(ctx, subscriptionID)
 	if err != nil {
 		return nil, err
 	}
@@ -389,11 +712,326 @@ func (c *AzureClient) GetVMSS(ctx context.Context, subscriptionID, resourceGroup
 
 	vmss := &resp.VirtualMachineScaleSet
 	// Store in cache
-	c.cache.Set(cacheKey, vmss)
+	c.cache.SetPersisted(cacheKey, vmss)
 
 	return vmss, nil
 }
 
+// GetPublicIPAddress retrieves information about the specified public IP address.
+func (c *AzureClient) GetPublicIPAddress(ctx context.Context, subscriptionID, resourceGroup, pipName string) (*armnetwork.PublicIPAddress, error) {
+	// Create cache key
+	cacheKey := c.CacheKey(ctx, "resource:publicip", subscriptionID, resourceGroup, pipName)
+
+	// Check cache first
+	if cached, found := c.cache.Get(cacheKey); found {
+		if pip, ok := cached.(*armnetwork.PublicIPAddress); ok {
+			return pip, nil
+		}
+	}
+
+	// Check persistent cache next
+	var persisted armnetwork.PublicIPAddress
+	if c.cache.GetPersisted(cacheKey, &persisted) {
+		return &persisted, nil
+	}
+
+	clients, err := c.GetOrCreateClientsForContext(ctx, subscriptionID)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := clients.PublicIPAddressesClient.Get(ctx, resourceGroup, pipName, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get public IP address: %v", err)
+	}
+
+	pip := &resp.PublicIPAddress
+	// Store in cache
+	c.cache.SetPersisted(cacheKey, pip)
+
+	return pip, nil
+}
+
+// GetNatGateway retrieves information about the specified NAT gateway.
+func (c *AzureClient) GetNatGateway(ctx context.Context, subscriptionID, resourceGroup, natGatewayName string) (*armnetwork.NatGateway, error) {
+	// Create cache key
+	cacheKey := c.CacheKey(ctx, "resource:natgateway", subscriptionID, resourceGroup, natGatewayName)
+
+	// Check cache first
+	if cached, found := c.cache.Get(cacheKey); found {
+		if natGateway, ok := cached.(*armnetwork.NatGateway); ok {
+			return natGateway, nil
+		}
+	}
+
+	// Check persistent cache next
+	var persisted armnetwork.NatGateway
+	if c.cache.GetPersisted(cacheKey, &persisted) {
+		return &persisted, nil
+	}
+
+	clients, err := c.GetOrCreateClientsForContext(ctx, subscriptionID)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := clients.NatGatewaysClient.Get(ctx, resourceGroup, natGatewayName, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get NAT gateway: %v", err)
+	}
+
+	natGateway := &resp.NatGateway
+	// Store in cache
+	c.cache.SetPersisted(cacheKey, natGateway)
+
+	return natGateway, nil
+}
+
+// GetApplicationGateway retrieves information about the specified application gateway.
+func (c *AzureClient) GetApplicationGateway(ctx context.Context, subscriptionID, resourceGroup, appGatewayName string) (*armnetwork.ApplicationGateway, error) {
+	// Create cache key
+	cacheKey := c.CacheKey(ctx, "resource:appgateway", subscriptionID, resourceGroup, appGatewayName)
+
+	// Check cache first
+	if cached, found := c.cache.Get(cacheKey); found {
+		if appGateway, ok := cached.(*armnetwork.ApplicationGateway); ok {
+			return appGateway, nil
+		}
+	}
+
+	// Check persistent cache next
+	var persisted armnetwork.ApplicationGateway
+	if c.cache.GetPersisted(cacheKey, &persisted) {
+		return &persisted, nil
+	}
+
+	clients, err := c.GetOrCreateClientsForContext(ctx, subscriptionID)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := clients.ApplicationGatewaysClient.Get(ctx, resourceGroup, appGatewayName, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get application gateway: %v", err)
+	}
+
+	appGateway := &resp.ApplicationGateway
+	// Store in cache
+	c.cache.SetPersisted(cacheKey, appGateway)
+
+	return appGateway, nil
+}
+
+// GetPrivateDNSZone retrieves information about the specified private DNS zone.
+func (c *AzureClient) GetPrivateDNSZone(ctx context.Context, subscriptionID, resourceGroup, zoneName string) (*armprivatedns.PrivateZone, error) {
+	// Create cache key
+	cacheKey := c.CacheKey(ctx, "resource:privatednszone", subscriptionID, resourceGroup, zoneName)
+
+	// Check cache first
+	if cached, found := c.cache.Get(cacheKey); found {
+		if zone, ok := cached.(*armprivatedns.PrivateZone); ok {
+			return zone, nil
+		}
+	}
+
+	// Check persistent cache next
+	var persisted armprivatedns.PrivateZone
+	if c.cache.GetPersisted(cacheKey, &persisted) {
+		return &persisted, nil
+	}
+
+	clients, err := c.GetOrCreateClientsForContext(ctx, subscriptionID)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := clients.PrivateDNSZonesClient.Get(ctx, resourceGroup, zoneName, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get private DNS zone: %v", err)
+	}
+
+	zone := &resp.PrivateZone
+	// Store in cache
+	c.cache.SetPersisted(cacheKey, zone)
+
+	return zone, nil
+}
+
+// GetPrivateDNSZoneVirtualNetworkLink retrieves information about the specified virtual network link of a private DNS zone.
+func (c *AzureClient) GetPrivateDNSZoneVirtualNetworkLink(ctx context.Context, subscriptionID, resourceGroup, zoneName, linkName string) (*armprivatedns.VirtualNetworkLink, error) {
+	// Create cache key
+	cacheKey := c.CacheKey(ctx, "resource:privatednszonelink", subscriptionID, resourceGroup, zoneName, linkName)
+
+	// Check cache first
+	if cached, found := c.cache.Get(cacheKey); found {
+		if link, ok := cached.(*armprivatedns.VirtualNetworkLink); ok {
+			return link, nil
+		}
+	}
+
+	// Check persistent cache next
+	var persisted armprivatedns.VirtualNetworkLink
+	if c.cache.GetPersisted(cacheKey, &persisted) {
+		return &persisted, nil
+	}
+
+	clients, err := c.GetOrCreateClientsForContext(ctx, subscriptionID)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := clients.VirtualNetworkLinksClient.Get(ctx, resourceGroup, zoneName, linkName, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get private DNS zone virtual network link: %v", err)
+	}
+
+	link := &resp.VirtualNetworkLink
+	// Store in cache
+	c.cache.SetPersisted(cacheKey, link)
+
+	return link, nil
+}
+
+// GetDisk retrieves information about the specified managed disk.
+func (c *AzureClient) GetDisk(ctx context.Context, subscriptionID, resourceGroup, diskName string) (*armcompute.Disk, error) {
+	// Create cache key
+	cacheKey := c.CacheKey(ctx, "resource:disk", subscriptionID, resourceGroup, diskName)
+
+	// Check cache first
+	if cached, found := c.cache.Get(cacheKey); found {
+		if disk, ok := cached.(*armcompute.Disk); ok {
+			return disk, nil
+		}
+	}
+
+	// Check persistent cache next
+	var persisted armcompute.Disk
+	if c.cache.GetPersisted(cacheKey, &persisted) {
+		return &persisted, nil
+	}
+
+	clients, err := c.GetOrCreateClientsForContext(ctx, subscriptionID)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := clients.DisksClient.Get(ctx, resourceGroup, diskName, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get managed disk: %v", err)
+	}
+
+	disk := &resp.Disk
+	// Store in cache
+	c.cache.SetPersisted(cacheKey, disk)
+
+	return disk, nil
+}
+
+// GetUserAssignedIdentity retrieves information about the specified user-assigned managed identity.
+func (c *AzureClient) GetUserAssignedIdentity(ctx context.Context, subscriptionID, resourceGroup, identityName string) (*armmsi.Identity, error) {
+	// Create cache key
+	cacheKey := c.CacheKey(ctx, "resource:identity", subscriptionID, resourceGroup, identityName)
+
+	// Check cache first
+	if cached, found := c.cache.Get(cacheKey); found {
+		if identity, ok := cached.(*armmsi.Identity); ok {
+			return identity, nil
+		}
+	}
+
+	// Check persistent cache next
+	var persisted armmsi.Identity
+	if c.cache.GetPersisted(cacheKey, &persisted) {
+		return &persisted, nil
+	}
+
+	clients, err := c.GetOrCreateClientsForContext(ctx, subscriptionID)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := clients.UserAssignedIdentityClient.Get(ctx, resourceGroup, identityName, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get user-assigned managed identity: %v", err)
+	}
+
+	identity := &resp.Identity
+	// Store in cache
+	c.cache.SetPersisted(cacheKey, identity)
+
+	return identity, nil
+}
+
+// GetKeyVault retrieves information about the specified Key Vault.
+func (c *AzureClient) GetKeyVault(ctx context.Context, subscriptionID, resourceGroup, vaultName string) (*armkeyvault.Vault, error) {
+	// Create cache key
+	cacheKey := c.CacheKey(ctx, "resource:keyvault", subscriptionID, resourceGroup, vaultName)
+
+	// Check cache first
+	if cached, found := c.cache.Get(cacheKey); found {
+		if vault, ok := cached.(*armkeyvault.Vault); ok {
+			return vault, nil
+		}
+	}
+
+	// Check persistent cache next
+	var persisted armkeyvault.Vault
+	if c.cache.GetPersisted(cacheKey, &persisted) {
+		return &persisted, nil
+	}
+
+	clients, err := c.GetOrCreateClientsForContext(ctx, subscriptionID)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := clients.KeyVaultClient.Get(ctx, resourceGroup, vaultName, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get Key Vault: %v", err)
+	}
+
+	vault := &resp.Vault
+	// Store in cache
+	c.cache.SetPersisted(cacheKey, vault)
+
+	return vault, nil
+}
+
+// GetContainerRegistry retrieves information about the specified container registry.
+func (c *AzureClient) GetContainerRegistry(ctx context.Context, subscriptionID, resourceGroup, registryName string) (*armcontainerregistry.Registry, error) {
+	// Create cache key
+	cacheKey := c.CacheKey(ctx, "resource:containerregistry", subscriptionID, resourceGroup, registryName)
+
+	// Check cache first
+	if cached, found := c.cache.Get(cacheKey); found {
+		if registry, ok := cached.(*armcontainerregistry.Registry); ok {
+			return registry, nil
+		}
+	}
+
+	// Check persistent cache next
+	var persisted armcontainerregistry.Registry
+	if c.cache.GetPersisted(cacheKey, &persisted) {
+		return &persisted, nil
+	}
+
+	clients, err := c.GetOrCreateClientsForContext(ctx, subscriptionID)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := clients.ContainerRegistryClient.Get(ctx, resourceGroup, registryName, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get container registry: %v", err)
+	}
+
+	registry := &resp.Registry
+	// Store in cache
+	c.cache.SetPersisted(cacheKey, registry)
+
+	return registry, nil
+}
+
 // Helper methods for working with resource IDs
 
 // GetResourceByID retrieves a resource by its full Azure resource ID.
@@ -423,8 +1061,36 @@ func (c *AzureClient) GetResourceByID(ctx context.Context, resourceID string) (i
 			return c.GetSubnet(ctx, parsed.SubscriptionID, parsed.ResourceGroupName, parsed.Parent.Name, parsed.Name)
 		}
 		return nil, fmt.Errorf("invalid subnet resource ID format: %s", resourceID)
+	case "Microsoft.Network/privateEndpoints":
+		return c.GetPrivateEndpoint(ctx, parsed.SubscriptionID, parsed.ResourceGroupName, parsed.Name)
+	case "Microsoft.Network/publicIPAddresses":
+		return c.GetPublicIPAddress(ctx, parsed.SubscriptionID, parsed.ResourceGroupName, parsed.Name)
+	case "Microsoft.Network/natGateways":
+		return c.GetNatGateway(ctx, parsed.SubscriptionID, parsed.ResourceGroupName, parsed.Name)
+	case "Microsoft.Network/applicationGateways":
+		return c.GetApplicationGateway(ctx, parsed.SubscriptionID, parsed.ResourceGroupName, parsed.Name)
+	case "Microsoft.Network/privateDnsZones":
+		return c.GetPrivateDNSZone(ctx, parsed.SubscriptionID, parsed.ResourceGroupName, parsed.Name)
+	case "Microsoft.Network/privateDnsZones/virtualNetworkLinks":
+		// For VNet links, we need the zone name from parent and link name
+		if parsed.Parent != nil {
+			return c.GetPrivateDNSZoneVirtualNetworkLink(ctx, parsed.SubscriptionID, parsed.ResourceGroupName, parsed.Parent.Name, parsed.Name)
+		}
+		return nil, fmt.Errorf("invalid private DNS zone virtual network link resource ID format: %s", resourceID)
 	case "Microsoft.Compute/virtualMachineScaleSets":
 		return c.GetVMSS(ctx, parsed.SubscriptionID, parsed.ResourceGroupName, parsed.Name)
+	case "Microsoft.Compute/disks":
+		return c.GetDisk(ctx, parsed.SubscriptionID, parsed.ResourceGroupName, parsed.Name)
+	case "Microsoft.ManagedIdentity/userAssignedIdentities":
+		return c.GetUserAssignedIdentity(ctx, parsed.SubscriptionID, parsed.ResourceGroupName, parsed.Name)
+	case "Microsoft.KeyVault/vaults":
+		return c.GetKeyVault(ctx, parsed.SubscriptionID, parsed.ResourceGroupName, parsed.Name)
+	case "Microsoft.ContainerRegistry/registries":
+		return c.GetContainerRegistry(ctx, parsed.SubscriptionID, parsed.ResourceGroupName, parsed.Name)
+	case "Microsoft.Insights/dataCollectionRules":
+		return c.GetDataCollectionRule(ctx, parsed.SubscriptionID, parsed.ResourceGroupName, parsed.Name)
+	case "Microsoft.Monitor/accounts":
+		return c.GetAzureMonitorWorkspace(ctx, parsed.SubscriptionID, parsed.ResourceGroupName, parsed.Name)
 	default:
 		return nil, fmt.Errorf("unsupported resource type: %s", parsed.ResourceType)
 	}
@@ -438,7 +1104,7 @@ func (c *AzureClient) GetCache() *AzureCache {
 // GetDiagnosticSettings retrieves diagnostic settings for the specified resource.
 func (c *AzureClient) GetDiagnosticSettings(ctx context.Context, subscriptionID, resourceURI string) ([]*armmonitor.DiagnosticSettingsResource, error) {
 	// Create cache key
-	cacheKey := fmt.Sprintf("resource:diagnosticsettings:%s:%s", subscriptionID, resourceURI)
+	cacheKey := c.CacheKey(ctx, "resource:diagnosticsettings", subscriptionID, resourceURI)
 
 	// Check cache first
 	if cached, found := c.cache.Get(cacheKey); found {
@@ -447,7 +1113,21 @@ func (c *AzureClient) GetDiagnosticSettings(ctx context.Context, subscriptionID,
 		}
 	}
 
-	clients, err := c.GetOrCreateClientsForSubscription(subscriptionID)
+	diagnosticSettings, err := c.ListDiagnosticSettings(ctx, subscriptionID, resourceURI)
+	if err != nil {
+		return nil, err
+	}
+
+	// Store in cache
+	c.cache.Set(cacheKey, diagnosticSettings)
+
+	return diagnosticSettings, nil
+}
+
+// ListDiagnosticSettings retrieves diagnostic settings for the specified resource, bypassing the cache.
+// It is intended for reads that decide how to change the settings.
+func (c *AzureClient) ListDiagnosticSettings(ctx context.Context, subscriptionID, resourceURI string) ([]*armmonitor.DiagnosticSettingsResource, error) {
+	clients, err := c.GetOrCreateClientsForContext(ctx, subscriptionID)
 	if err != nil {
 		return nil, err
 	}
@@ -463,8 +1143,224 @@ func (c *AzureClient) GetDiagnosticSettings(ctx context.Context, subscriptionID,
 		diagnosticSettings = append(diagnosticSettings, page.Value...)
 	}
 
+	return diagnosticSettings, nil
+}
+
+// CreateOrUpdateDiagnosticSetting creates or updates a diagnostic setting on the specified resource and
+// invalidates the cached diagnostic settings of the resource.
+func (c *AzureClient) CreateOrUpdateDiagnosticSetting(ctx context.Context, subscriptionID, resourceURI, name string, setting armmonitor.DiagnosticSettingsResource) (*armmonitor.DiagnosticSettingsResource, error) {
+	clients, err := c.GetOrCreateClientsForContext(ctx, subscriptionID)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := clients.DiagnosticSettingsClient.CreateOrUpdate(ctx, resourceURI, name, setting, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create or update diagnostic setting %s: %v", name, err)
+	}
+
+	c.cache.Delete(c.CacheKey(ctx, "resource:diagnosticsettings", subscriptionID, resourceURI))
+
+	return &resp.DiagnosticSettingsResource, nil
+}
+
+// GetMetrics queries metric values of a resource. Metric values change constantly, so they are not cached.
+func (c *AzureClient) GetMetrics(ctx context.Context, subscriptionID, resourceURI string, options *azquery.MetricsClientQueryResourceOptions) (*azquery.Response, error) {
+	clients, err := c.GetOrCreateClientsForContext(ctx, subscriptionID)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := clients.MetricsClient.QueryResource(ctx, strings.TrimPrefix(resourceURI, "/"), options)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get metrics: %v", err)
+	}
+
+	return &resp.Response, nil
+}
+
+// metricsClientOptions returns the options of the metrics client. Azure Monitor serves metrics from the
+// Resource Manager endpoint, so the client uses the cloud, endpoint and transport of the other clients.
+func (c *AzureClient) metricsClientOptions() *azquery.MetricsClientOptions {
+	options := &azquery.MetricsClientOptions{}
+	if c.clientOptions != nil {
+		options.ClientOptions = c.clientOptions.ClientOptions
+	}
+	if options.Cloud.ActiveDirectoryAuthorityHost == "" && len(options.Cloud.Services) == 0 {
+		options.Cloud = cloud.AzurePublic
+	}
+
+	// Copy the services so the metrics entry is not added to the configuration shared with the other clients
+	services := make(map[cloud.ServiceName]cloud.ServiceConfiguration, len(options.Cloud.Services)+1)
+	for name, service := range options.Cloud.Services {
+		services[name] = service
+	}
+	resourceManager, ok := services[cloud.ResourceManager]
+	if !ok {
+		resourceManager = cloud.ServiceConfiguration{Endpoint: c.ResourceManagerEndpoint(), Audience: c.ResourceManagerEndpoint()}
+	}
+	services[azquery.ServiceNameMetrics] = resourceManager
+	options.Cloud.Services = services
+	return options
+}
+
+// ListActivityLogs lists the activity log events of a subscription that match an OData filter, newest first,
+// stopping once maxEvents events have been read. Activity logs grow constantly, so they are not cached.
+func (c *AzureClient) ListActivityLogs(ctx context.Context, subscriptionID, filter string, maxEvents int) ([]*armmonitor.EventData, error) {
+	clients, err := c.GetOrCreateClientsForContext(ctx, subscriptionID)
+	if err != nil {
+		return nil, err
+	}
+
+	pager := clients.ActivityLogsClient.NewListPager(filter, nil)
+	var events []*armmonitor.EventData
+
+	for pager.More() && len(events) < maxEvents {
+		page, err := pager.NextPage(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list activity logs: %v", err)
+		}
+		events = append(events, page.Value...)
+	}
+	if len(events) > maxEvents {
+		events = events[:maxEvents]
+	}
+
+	return events, nil
+}
+
+// ListMetricAlerts lists the metric alert rules of a subscription. Rules are read on every call so that
+// changes to them show up right away.
+func (c *AzureClient) ListMetricAlerts(ctx context.Context, subscriptionID string) ([]*armmonitor.MetricAlertResource, error) {
+	clients, err := c.GetOrCreateClientsForContext(ctx, subscriptionID)
+	if err != nil {
+		return nil, err
+	}
+
+	pager := clients.MetricAlertsClient.NewListBySubscriptionPager(nil)
+	var rules []*armmonitor.MetricAlertResource
+
+	for pager.More() {
+		page, err := pager.NextPage(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list metric alert rules: %v", err)
+		}
+		rules = append(rules, page.Value...)
+	}
+
+	return rules, nil
+}
+
+// ListScheduledQueryRules lists the log search alert rules of a subscription. Rules are read on every call so that
+// changes to them show up right away.
+func (c *AzureClient) ListScheduledQueryRules(ctx context.Context, subscriptionID string) ([]*armmonitor.ScheduledQueryRuleResource, error) {
+	clients, err := c.GetOrCreateClientsForContext(ctx, subscriptionID)
+	if err != nil {
+		return nil, err
+	}
+
+	pager := clients.ScheduledQueryRulesClient.NewListBySubscriptionPager(nil)
+	var rules []*armmonitor.ScheduledQueryRuleResource
+
+	for pager.More() {
+		page, err := pager.NextPage(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list log search alert rules: %v", err)
+		}
+		rules = append(rules, page.Value...)
+	}
+
+	return rules, nil
+}
+
+// GetDataCollectionRuleAssociations retrieves the data collection rule associations of the specified resource.
+func (c *AzureClient) GetDataCollectionRuleAssociations(ctx context.Context, subscriptionID, resourceURI string) ([]*armmonitor.DataCollectionRuleAssociationProxyOnlyResource, error) {
+	// Create cache key
+	cacheKey := c.CacheKey(ctx, "resource:dcrassociations", subscriptionID, resourceURI)
+
+	// Check cache first
+	if cached, found := c.cache.Get(cacheKey); found {
+		if associations, ok := cached.([]*armmonitor.DataCollectionRuleAssociationProxyOnlyResource); ok {
+			return associations, nil
+		}
+	}
+
+	clients, err := c.GetOrCreateClientsForContext(ctx, subscriptionID)
+	if err != nil {
+		return nil, err
+	}
+
+	pager := clients.DCRAssociationsClient.NewListByResourcePager(strings.TrimPrefix(resourceURI, "/"), nil)
+	var associations []*armmonitor.DataCollectionRuleAssociationProxyOnlyResource
+
+	for pager.More() {
+		page, err := pager.NextPage(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get data collection rule associations: %v", err)
+		}
+		associations = append(associations, page.Value...)
+	}
+
 	// Store in cache
-	c.cache.Set(cacheKey, diagnosticSettings)
+	c.cache.Set(cacheKey, associations)
 
-	return diagnosticSettings, nil
+	return associations, nil
+}
+
+// GetDataCollectionRule retrieves information about the specified data collection rule.
+func (c *AzureClient) GetDataCollectionRule(ctx context.Context, subscriptionID, resourceGroup, ruleName string) (*armmonitor.DataCollectionRuleResource, error) {
+	// Create cache key
+	cacheKey := c.CacheKey(ctx, "resource:datacollectionrule", subscriptionID, resourceGroup, ruleName)
+
+	// Check cache first
+	if cached, found := c.cache.Get(cacheKey); found {
+		if rule, ok := cached.(*armmonitor.DataCollectionRuleResource); ok {
+			return rule, nil
+		}
+	}
+
+	clients, err := c.GetOrCreateClientsForContext(ctx, subscriptionID)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := clients.DataCollectionRulesClient.Get(ctx, resourceGroup, ruleName, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get data collection rule: %v", err)
+	}
+
+	rule := &resp.DataCollectionRuleResource
+	// Store in cache
+	c.cache.Set(cacheKey, rule)
+
+	return rule, nil
+}
+
+// GetAzureMonitorWorkspace retrieves information about the specified Azure Monitor workspace.
+func (c *AzureClient) GetAzureMonitorWorkspace(ctx context.Context, subscriptionID, resourceGroup, workspaceName string) (*armmonitor.AzureMonitorWorkspaceResource, error) {
+	// Create cache key
+	cacheKey := c.CacheKey(ctx, "resource:monitorworkspace", subscriptionID, resourceGroup, workspaceName)
+
+	// Check cache first
+	if cached, found := c.cache.Get(cacheKey); found {
+		if workspace, ok := cached.(*armmonitor.AzureMonitorWorkspaceResource); ok {
+			return workspace, nil
+		}
+	}
+
+	clients, err := c.GetOrCreateClientsForContext(ctx, subscriptionID)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := clients.MonitorWorkspacesClient.Get(ctx, resourceGroup, workspaceName, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get Azure Monitor workspace: %v", err)
+	}
+
+	workspace := &resp.AzureMonitorWorkspaceResource
+	// Store in cache
+	c.cache.Set(cacheKey, workspace)
+
+	return workspace, nil
 }
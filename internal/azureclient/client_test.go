@@ -1,10 +1,20 @@
 package azureclient
 
 import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"strings"
 	"testing"
 	"time"
 
+	"github.com/Azure/aks-mcp/internal/azureclient/fakearm"
 	"github.com/Azure/aks-mcp/internal/config"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/arm"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/cloud"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/policy"
+	"github.com/Azure/azure-sdk-for-go/sdk/monitor/azquery"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/msi/armmsi"
 )
 
 func TestNewAzureClientWithConfigurableTimeout(t *testing.T) {
@@ -40,3 +50,332 @@ func TestNewAzureClientWithConfigurableTimeout(t *testing.T) {
 		t.Errorf("Expected custom cache timeout to be 5 minutes, got %v", customClient.cache.defaultTimeout)
 	}
 }
+
+func TestGetOrCreateClientsForSubscriptionCreatesDependencyClients(t *testing.T) {
+	// A fake credential disables tenant discovery through az account list
+	client, err := NewAzureClientWithOptions(config.NewConfig(), &ClientOptions{Credential: fakearm.Credential{}})
+	if err != nil {
+		t.Fatalf("Failed to create Azure client: %v", err)
+	}
+
+	clients, err := client.GetOrCreateClientsForSubscription("12345678-1234-1234-1234-123456789012")
+	if err != nil {
+		t.Fatalf("Failed to create subscription clients: %v", err)
+	}
+
+	if clients.PublicIPAddressesClient == nil || clients.NatGatewaysClient == nil || clients.ApplicationGatewaysClient == nil {
+		t.Error("Expected network dependency clients to be created")
+	}
+	if clients.PrivateDNSZonesClient == nil || clients.VirtualNetworkLinksClient == nil {
+		t.Error("Expected private DNS clients to be created")
+	}
+	if clients.DisksClient == nil || clients.UserAssignedIdentityClient == nil || clients.KeyVaultClient == nil || clients.ContainerRegistryClient == nil {
+		t.Error("Expected disk, identity, key vault and container registry clients to be created")
+	}
+
+	// A second call should return the same cached clients
+	again, err := client.GetOrCreateClientsForSubscription("12345678-1234-1234-1234-123456789012")
+	if err != nil {
+		t.Fatalf("Failed to get subscription clients: %v", err)
+	}
+	if again != clients {
+		t.Error("Expected subscription clients to be reused")
+	}
+}
+
+func TestGetResourceByIDUnsupportedType(t *testing.T) {
+	client, err := NewAzureClient(config.NewConfig())
+	if err != nil {
+		t.Fatalf("Failed to create Azure client: %v", err)
+	}
+
+	resourceID := "/subscriptions/12345678-1234-1234-1234-123456789012/resourceGroups/myRG/providers/Microsoft.Storage/storageAccounts/mystorage"
+	_, err = client.GetResourceByID(context.Background(), resourceID)
+	if err == nil {
+		t.Fatal("Expected error for unsupported resource type")
+	}
+	if !strings.Contains(err.Error(), "unsupported resource type") {
+		t.Errorf("Expected unsupported resource type error, got: %v", err)
+	}
+}
+
+func TestGetResourceByIDUsesCache(t *testing.T) {
+	// A fake credential disables tenant discovery through az account list
+	client, err := NewAzureClientWithOptions(config.NewConfig(), &ClientOptions{Credential: fakearm.Credential{}})
+	if err != nil {
+		t.Fatalf("Failed to create Azure client: %v", err)
+	}
+
+	// Pre-populate the cache so no API call is needed
+	name := "myIdentity"
+	cached := &armmsi.Identity{Name: &name}
+	client.cache.Set(client.CacheKey(context.Background(), "resource:identity", "sub", "myRG", "myIdentity"), cached)
+
+	resourceID := "/subscriptions/sub/resourceGroups/myRG/providers/Microsoft.ManagedIdentity/userAssignedIdentities/myIdentity"
+	resource, err := client.GetResourceByID(context.Background(), resourceID)
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+	if resource.(*armmsi.Identity) != cached {
+		t.Errorf("Expected cached identity to be returned")
+	}
+}
+
+// newFakeARMClient creates an AzureClient that talks to a local fake ARM server
+func newFakeARMClient(t *testing.T) (*AzureClient, *fakearm.Server) {
+	t.Helper()
+
+	srv, err := fakearm.NewServer()
+	if err != nil {
+		t.Fatalf("Failed to start fake ARM server: %v", err)
+	}
+	t.Cleanup(srv.Close)
+
+	client, err := NewAzureClientWithOptions(config.NewConfig(), &ClientOptions{
+		Credential: fakearm.Credential{},
+		Endpoint:   srv.URL,
+		HTTPClient: srv.Client(),
+	})
+	if err != nil {
+		t.Fatalf("Failed to create Azure client: %v", err)
+	}
+	return client, srv
+}
+
+func TestFakeARM_GetAKSCluster(t *testing.T) {
+	client, srv := newFakeARMClient(t)
+	ctx := context.Background()
+
+	cluster, err := client.GetAKSCluster(ctx, fakearm.SubscriptionID, fakearm.ResourceGroup, fakearm.ClusterName)
+	if err != nil {
+		t.Fatalf("Failed to get cluster: %v", err)
+	}
+	if cluster.Properties == nil || cluster.Properties.NodeResourceGroup == nil || *cluster.Properties.NodeResourceGroup != fakearm.NodeResourceGroup {
+		t.Errorf("Expected node resource group %s, got %+v", fakearm.NodeResourceGroup, cluster.Properties)
+	}
+
+	// The second call is served from the cache
+	if _, err := client.GetAKSCluster(ctx, fakearm.SubscriptionID, fakearm.ResourceGroup, fakearm.ClusterName); err != nil {
+		t.Fatalf("Failed to get cached cluster: %v", err)
+	}
+	if count := srv.RequestCount(fakearm.ClusterResourceID); count != 1 {
+		t.Errorf("Expected one request to ARM, got %d", count)
+	}
+}
+
+func TestFakeARM_GetAKSClusterNotPersisted(t *testing.T) {
+	srv, err := fakearm.NewServer()
+	if err != nil {
+		t.Fatalf("Failed to start fake ARM server: %v", err)
+	}
+	t.Cleanup(srv.Close)
+
+	cfg := config.NewConfig()
+	cfg.PersistentCache = true
+	cfg.PersistentCacheDir = t.TempDir()
+	cfg.PersistentCacheKeyFile = filepath.Join(t.TempDir(), "cache.key")
+
+	// Each client stands for a new process sharing the on-disk cache
+	for i := 1; i <= 2; i++ {
+		client, err := NewAzureClientWithOptions(cfg, &ClientOptions{
+			Credential: fakearm.Credential{},
+			Endpoint:   srv.URL,
+			HTTPClient: srv.Client(),
+		})
+		if err != nil {
+			t.Fatalf("Failed to create Azure client: %v", err)
+		}
+		if !client.GetCache().IsPersistent() {
+			t.Fatal("Expected the persistent cache to be enabled")
+		}
+		if _, err := client.GetAKSCluster(context.Background(), fakearm.SubscriptionID, fakearm.ResourceGroup, fakearm.ClusterName); err != nil {
+			t.Fatalf("Failed to get cluster: %v", err)
+		}
+		if count := srv.RequestCount(fakearm.ClusterResourceID); count != i {
+			t.Errorf("Expected the cluster to be read from ARM by every process, got %d requests after %d clients", count, i)
+		}
+	}
+}
+
+func TestFakeARM_GetResourceByID(t *testing.T) {
+	client, _ := newFakeARMClient(t)
+	ctx := context.Background()
+
+	prefix := "/subscriptions/" + fakearm.SubscriptionID + "/resourceGroups/" + fakearm.NodeResourceGroup + "/providers/"
+	tests := []struct {
+		resourceID string
+		wantType   string
+	}{
+		{prefix + "Microsoft.Network/virtualNetworks/aks-vnet-12345678", "*armnetwork.VirtualNetwork"},
+		{prefix + "Microsoft.Network/networkSecurityGroups/aks-agentpool-12345678-nsg", "*armnetwork.SecurityGroup"},
+		{prefix + "Microsoft.Network/virtualNetworks/aks-vnet-12345678/subnets/aks-subnet", "*armnetwork.Subnet"},
+		{prefix + "Microsoft.Compute/virtualMachineScaleSets/aks-nodepool1-12345678-vmss", "*armcompute.VirtualMachineScaleSet"},
+	}
+
+	for _, tt := range tests {
+		resource, err := client.GetResourceByID(ctx, tt.resourceID)
+		if err != nil {
+			t.Errorf("Failed to get %s: %v", tt.resourceID, err)
+			continue
+		}
+		if got := fmt.Sprintf("%T", resource); got != tt.wantType {
+			t.Errorf("Expected %s for %s, got %s", tt.wantType, tt.resourceID, got)
+		}
+	}
+
+	// Resources missing from the fixtures surface the ARM error
+	if _, err := client.GetResourceByID(ctx, prefix+"Microsoft.Network/networkSecurityGroups/missing"); err == nil {
+		t.Error("Expected error for a resource that does not exist")
+	}
+}
+
+func TestFakeARM_GetDiagnosticSettings(t *testing.T) {
+	client, _ := newFakeARMClient(t)
+
+	settings, err := client.GetDiagnosticSettings(context.Background(), fakearm.SubscriptionID, fakearm.ClusterResourceID)
+	if err != nil {
+		t.Fatalf("Failed to get diagnostic settings: %v", err)
+	}
+	if len(settings) != 1 || settings[0].Properties == nil || settings[0].Properties.WorkspaceID == nil {
+		t.Fatalf("Expected one diagnostic setting with a workspace, got %+v", settings)
+	}
+}
+
+func TestFakeARM_MakeDetectorAPICall(t *testing.T) {
+	client, _ := newFakeARMClient(t)
+
+	resp, err := client.MakeDetectorAPICall(context.Background(),
+		client.ResourceManagerEndpoint()+fakearm.ClusterResourceID+"/detectors?api-version=2024-08-01", fakearm.SubscriptionID)
+	if err != nil {
+		t.Fatalf("Failed to call detector API: %v", err)
+	}
+
+	body, err := HandleDetectorAPIResponse(resp)
+	if err != nil {
+		t.Fatalf("Unexpected detector API error: %v", err)
+	}
+	if !strings.Contains(string(body), "node-health") {
+		t.Errorf("Expected detector list to contain node-health, got %s", body)
+	}
+}
+
+func TestFakeARM_OperationStatus(t *testing.T) {
+	client, srv := newFakeARMClient(t)
+	ctx := context.Background()
+
+	// Status reads bypass the cache so that polling sees every change
+	for i := 0; i < 2; i++ {
+		if _, err := client.GetAKSClusterStatus(ctx, fakearm.SubscriptionID, fakearm.ResourceGroup, fakearm.ClusterName); err != nil {
+			t.Fatalf("Failed to get cluster status: %v", err)
+		}
+	}
+	if count := srv.RequestCount(fakearm.ClusterResourceID); count != 2 {
+		t.Errorf("Expected two requests to ARM, got %d", count)
+	}
+
+	pool, err := client.GetAgentPool(ctx, fakearm.SubscriptionID, fakearm.ResourceGroup, fakearm.ClusterName, "nodepool1")
+	if err != nil {
+		t.Fatalf("Failed to get agent pool: %v", err)
+	}
+	if pool.Properties == nil || pool.Properties.ProvisioningState == nil || *pool.Properties.ProvisioningState != "Succeeded" {
+		t.Errorf("Expected agent pool provisioning state Succeeded, got %+v", pool.Properties)
+	}
+
+	operation, err := client.GetLatestAKSOperation(ctx, fakearm.SubscriptionID, fakearm.ResourceGroup, fakearm.ClusterName, "")
+	if err != nil {
+		t.Fatalf("Failed to get latest operation: %v", err)
+	}
+	if operation.Status != "Succeeded" || operation.PercentComplete == nil || *operation.PercentComplete != 100 {
+		t.Errorf("Unexpected operation status: %+v", operation)
+	}
+
+	// A missing resource is reported as not found
+	_, err = client.GetLatestAKSOperation(ctx, fakearm.SubscriptionID, fakearm.ResourceGroup, fakearm.ClusterName, "missing")
+	if !IsNotFound(err) {
+		t.Errorf("Expected a not found error, got %v", err)
+	}
+}
+
+func TestFakeARM_ListARMResources(t *testing.T) {
+	client, srv := newFakeARMClient(t)
+	ctx := context.Background()
+
+	listPath := fakearm.ClusterResourceID + "/items"
+	pages := map[string]interface{}{
+		listPath:            map[string]interface{}{"value": []interface{}{"a", "b"}, "nextLink": srv.URL + listPath + "/page2?api-version=1"},
+		listPath + "/page2": map[string]interface{}{"value": []interface{}{"c"}},
+	}
+	for path, page := range pages {
+		if err := srv.SetResponse(path, page); err != nil {
+			t.Fatalf("Failed to set response: %v", err)
+		}
+	}
+
+	items, err := client.ListARMResources(ctx, fakearm.SubscriptionID, listPath, "1", nil, 10)
+	if err != nil {
+		t.Fatalf("Failed to list resources: %v", err)
+	}
+	if len(items) != 3 {
+		t.Errorf("Expected the items of both pages, got %d", len(items))
+	}
+
+	// A next link to another host is not sent the ARM token
+	if err := srv.SetResponse(listPath, map[string]interface{}{"value": []interface{}{"a"}, "nextLink": "https://example.com" + listPath + "/page2"}); err != nil {
+		t.Fatalf("Failed to set response: %v", err)
+	}
+	before := srv.RequestCount(listPath + "/page2")
+	if _, err := client.ListARMResources(ctx, fakearm.SubscriptionID, listPath, "1", nil, 10); err == nil || !strings.Contains(err.Error(), "refusing to follow next link") {
+		t.Errorf("Expected the next link to be refused, got %v", err)
+	}
+	if count := srv.RequestCount(listPath + "/page2"); count != before {
+		t.Errorf("Expected no request for the refused next link, got %d", count-before)
+	}
+}
+
+func TestMetricsClientOptions_KeepsCloud(t *testing.T) {
+	sovereign := cloud.Configuration{
+		ActiveDirectoryAuthorityHost: "https://login.sovereign.example/",
+		Services: map[cloud.ServiceName]cloud.ServiceConfiguration{
+			cloud.ResourceManager: {Endpoint: "https://management.sovereign.example", Audience: "https://management.core.sovereign.example"},
+		},
+	}
+	client := &AzureClient{clientOptions: &arm.ClientOptions{ClientOptions: policy.ClientOptions{Cloud: sovereign}}}
+
+	options := client.metricsClientOptions()
+	if options.Cloud.ActiveDirectoryAuthorityHost != sovereign.ActiveDirectoryAuthorityHost {
+		t.Errorf("Expected the configured cloud's authority, got %s", options.Cloud.ActiveDirectoryAuthorityHost)
+	}
+	if metrics := options.Cloud.Services[azquery.ServiceNameMetrics]; metrics != sovereign.Services[cloud.ResourceManager] {
+		t.Errorf("Expected metrics on the configured cloud's Resource Manager endpoint, got %+v", metrics)
+	}
+	if _, ok := sovereign.Services[azquery.ServiceNameMetrics]; ok {
+		t.Error("Expected the shared cloud configuration to be left unchanged")
+	}
+
+	defaults := (&AzureClient{}).metricsClientOptions()
+	if metrics := defaults.Cloud.Services[azquery.ServiceNameMetrics]; metrics != cloud.AzurePublic.Services[cloud.ResourceManager] {
+		t.Errorf("Expected metrics on the public cloud by default, got %+v", metrics)
+	}
+}
+
+func TestFakeARM_QueryPrometheusTooLarge(t *testing.T) {
+	client, srv := newFakeARMClient(t)
+	err := srv.SetResponse("/prometheus/api/v1/query", map[string]interface{}{
+		"status": "success",
+		"data":   map[string]interface{}{"resultType": "vector", "result": []interface{}{}},
+	})
+	if err != nil {
+		t.Fatalf("Failed to set response: %v", err)
+	}
+
+	if _, err := client.QueryPrometheus(context.Background(), fakearm.SubscriptionID, srv.URL+"/prometheus", "/api/v1/query", nil); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	defer func(original int64) { maxPrometheusResponseBytes = original }(maxPrometheusResponseBytes)
+	maxPrometheusResponseBytes = 16
+	_, err = client.QueryPrometheus(context.Background(), fakearm.SubscriptionID, srv.URL+"/prometheus", "/api/v1/query", nil)
+	if err == nil || !strings.Contains(err.Error(), "narrow the query") {
+		t.Errorf("Expected an error for a response over the limit, got %v", err)
+	}
+}
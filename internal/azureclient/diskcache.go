@@ -0,0 +1,318 @@
+package azureclient
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// diskCacheKeyFile is the name of the file holding the local encryption key
+	diskCacheKeyFile = "cache.key"
+	// diskCacheEntrySuffix is the file extension used for encrypted cache entries
+	diskCacheEntrySuffix = ".bin"
+	// diskCacheKeySize is the AES-256 key size in bytes
+	diskCacheKeySize = 32
+)
+
+// DiskCache is a persistent, encrypted cache for read-only Azure metadata.
+// Entries are stored as one file per key, encrypted with AES-256-GCM using a
+// key generated on first use and kept outside the cache directory with owner-only permissions.
+// The encryption protects entries when the cache directory alone is copied, backed up or shared;
+// it does not protect them from processes that can read the user's files, which can read the key too.
+type DiskCache struct {
+	dir  string
+	aead cipher.AEAD
+	mu   sync.Mutex
+}
+
+// diskCacheEntry is the plaintext representation of a persisted cache entry.
+type diskCacheEntry struct {
+	Key        string          `json:"key"`
+	Expiration time.Time       `json:"expiration"`
+	Value      json.RawMessage `json:"value"`
+}
+
+// DefaultDiskCacheDir returns the default directory for the persistent cache.
+func DefaultDiskCacheDir() string {
+	if dir, err := os.UserCacheDir(); err == nil {
+		return filepath.Join(dir, "aks-mcp")
+	}
+	return filepath.Join(os.TempDir(), "aks-mcp-cache")
+}
+
+// DefaultDiskCacheKeyFile returns the default path of the encryption key of the persistent cache,
+// in the user config directory so it is not stored with the entries.
+func DefaultDiskCacheKeyFile() string {
+	if dir, err := os.UserConfigDir(); err == nil {
+		return filepath.Join(dir, "aks-mcp", diskCacheKeyFile)
+	}
+	if dir, err := os.UserHomeDir(); err == nil {
+		return filepath.Join(dir, ".aks-mcp", diskCacheKeyFile)
+	}
+	return filepath.Join(os.TempDir(), "aks-mcp-key", diskCacheKeyFile)
+}
+
+// NewDiskCache opens or creates a persistent cache in the given directory, encrypted with the key in keyFile.
+// The key file must be outside the cache directory. Expired entries found in the directory are removed.
+func NewDiskCache(dir, keyFile string) (*DiskCache, error) {
+	if dir == "" {
+		dir = DefaultDiskCacheDir()
+	}
+	if keyFile == "" {
+		keyFile = DefaultDiskCacheKeyFile()
+	}
+
+	inside, err := isInsideDir(keyFile, dir)
+	if err != nil {
+		return nil, err
+	}
+	if inside {
+		return nil, fmt.Errorf("cache key file %s must be outside the cache directory %s", keyFile, dir)
+	}
+
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return nil, fmt.Errorf("failed to create cache directory %s: %v", dir, err)
+	}
+	if err := os.MkdirAll(filepath.Dir(keyFile), 0o700); err != nil {
+		return nil, fmt.Errorf("failed to create cache key directory %s: %v", filepath.Dir(keyFile), err)
+	}
+
+	key, err := loadOrCreateDiskCacheKey(keyFile)
+	if err != nil {
+		return nil, err
+	}
+
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create cache cipher: %v", err)
+	}
+
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create cache cipher: %v", err)
+	}
+
+	cache := &DiskCache{
+		dir:  dir,
+		aead: aead,
+	}
+	cache.Prune()
+
+	return cache, nil
+}
+
+// isInsideDir reports whether path is dir or inside it.
+func isInsideDir(path, dir string) (bool, error) {
+	absPath, err := filepath.Abs(path)
+	if err != nil {
+		return false, fmt.Errorf("invalid cache key file %s: %v", path, err)
+	}
+	absDir, err := filepath.Abs(dir)
+	if err != nil {
+		return false, fmt.Errorf("invalid cache directory %s: %v", dir, err)
+	}
+	rel, err := filepath.Rel(absDir, absPath)
+	if err != nil {
+		return false, nil
+	}
+	return rel == "." || (rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator))), nil
+}
+
+// loadOrCreateDiskCacheKey reads the local encryption key, generating it if it does not exist.
+func loadOrCreateDiskCacheKey(path string) ([]byte, error) {
+	key, err := os.ReadFile(path) // #nosec G304 -- path is the configured cache key file
+	if err == nil {
+		if len(key) != diskCacheKeySize {
+			return nil, fmt.Errorf("invalid cache key in %s: expected %d bytes, got %d", path, diskCacheKeySize, len(key))
+		}
+		return key, nil
+	}
+	if !errors.Is(err, os.ErrNotExist) {
+		return nil, fmt.Errorf("failed to read cache key: %v", err)
+	}
+
+	key = make([]byte, diskCacheKeySize)
+	if _, err := io.ReadFull(rand.Reader, key); err != nil {
+		return nil, fmt.Errorf("failed to generate cache key: %v", err)
+	}
+
+	if err := os.WriteFile(path, key, 0o600); err != nil {
+		return nil, fmt.Errorf("failed to write cache key: %v", err)
+	}
+
+	return key, nil
+}
+
+// entryPath returns the file path for a cache key. Keys are hashed so that
+// resource names are not exposed in file names.
+func (d *DiskCache) entryPath(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return filepath.Join(d.dir, hex.EncodeToString(sum[:])+diskCacheEntrySuffix)
+}
+
+// Get decodes the persisted value for key into target.
+// Returns the remaining time to live and true if the entry exists and hasn't expired.
+func (d *DiskCache) Get(key string, target interface{}) (time.Duration, bool) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	path := d.entryPath(key)
+	entry, err := d.readEntry(path, key)
+	if err != nil {
+		return 0, false
+	}
+
+	remaining := time.Until(entry.Expiration)
+	if remaining <= 0 {
+		_ = os.Remove(path)
+		return 0, false
+	}
+
+	if err := json.Unmarshal(entry.Value, target); err != nil {
+		return 0, false
+	}
+
+	return remaining, true
+}
+
+// Set persists a value for key with the given time to live.
+func (d *DiskCache) Set(key string, value interface{}, duration time.Duration) error {
+	raw, err := json.Marshal(value)
+	if err != nil {
+		return fmt.Errorf("failed to marshal cache value: %v", err)
+	}
+
+	plaintext, err := json.Marshal(diskCacheEntry{
+		Key:        key,
+		Expiration: time.Now().Add(duration),
+		Value:      raw,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to marshal cache entry: %v", err)
+	}
+
+	nonce := make([]byte, d.aead.NonceSize())
+	if _, err := io.ReadFull(rand.Reader, nonce); err != nil {
+		return fmt.Errorf("failed to generate nonce: %v", err)
+	}
+
+	// The entry file name is bound as additional data so an entry cannot be moved to another key
+	path := d.entryPath(key)
+	ciphertext := d.aead.Seal(nonce, nonce, plaintext, []byte(filepath.Base(path)))
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	tmp, err := os.CreateTemp(d.dir, "entry-*.tmp")
+	if err != nil {
+		return fmt.Errorf("failed to create cache entry: %v", err)
+	}
+	tmpName := tmp.Name()
+
+	if _, err := tmp.Write(ciphertext); err != nil {
+		_ = tmp.Close()
+		_ = os.Remove(tmpName)
+		return fmt.Errorf("failed to write cache entry: %v", err)
+	}
+	if err := tmp.Close(); err != nil {
+		_ = os.Remove(tmpName)
+		return fmt.Errorf("failed to write cache entry: %v", err)
+	}
+
+	if err := os.Rename(tmpName, path); err != nil {
+		_ = os.Remove(tmpName)
+		return fmt.Errorf("failed to store cache entry: %v", err)
+	}
+
+	return nil
+}
+
+// Delete removes the persisted value for key.
+func (d *DiskCache) Delete(key string) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	_ = os.Remove(d.entryPath(key))
+}
+
+// Clear removes all persisted entries but keeps the encryption key.
+func (d *DiskCache) Clear() {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	for _, path := range d.entryFiles() {
+		_ = os.Remove(path)
+	}
+}
+
+// Prune removes expired and unreadable entries.
+func (d *DiskCache) Prune() {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	now := time.Now()
+	for _, path := range d.entryFiles() {
+		entry, err := d.readEntry(path, "")
+		if err != nil || now.After(entry.Expiration) {
+			_ = os.Remove(path)
+		}
+	}
+}
+
+// entryFiles lists the entry files in the cache directory.
+func (d *DiskCache) entryFiles() []string {
+	entries, err := os.ReadDir(d.dir)
+	if err != nil {
+		return nil
+	}
+
+	var files []string
+	for _, entry := range entries {
+		if !entry.IsDir() && strings.HasSuffix(entry.Name(), diskCacheEntrySuffix) {
+			files = append(files, filepath.Join(d.dir, entry.Name()))
+		}
+	}
+	return files
+}
+
+// readEntry reads and decrypts an entry file. When key is not empty the
+// entry must have been stored for that key.
+func (d *DiskCache) readEntry(path, key string) (*diskCacheEntry, error) {
+	data, err := os.ReadFile(path) // #nosec G304 -- path is derived from the cache directory
+	if err != nil {
+		return nil, err
+	}
+
+	nonceSize := d.aead.NonceSize()
+	if len(data) < nonceSize {
+		return nil, fmt.Errorf("cache entry too short")
+	}
+	nonce, ciphertext := data[:nonceSize], data[nonceSize:]
+
+	plaintext, err := d.aead.Open(nil, nonce, ciphertext, []byte(filepath.Base(path)))
+	if err != nil {
+		return nil, fmt.Errorf("failed to decrypt cache entry: %v", err)
+	}
+
+	var entry diskCacheEntry
+	if err := json.Unmarshal(plaintext, &entry); err != nil {
+		return nil, fmt.Errorf("failed to parse cache entry: %v", err)
+	}
+	if key != "" && entry.Key != key {
+		return nil, fmt.Errorf("cache entry key mismatch")
+	}
+
+	return &entry, nil
+}
@@ -0,0 +1,257 @@
+package azureclient
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/containerservice/armcontainerservice/v2"
+)
+
+// newTestDiskCache opens a disk cache in dir with a key file in a separate temporary directory
+func newTestDiskCache(t *testing.T, dir string) (*DiskCache, error) {
+	t.Helper()
+
+	return NewDiskCache(dir, filepath.Join(t.TempDir(), diskCacheKeyFile))
+}
+
+func TestDiskCache_SetAndGet(t *testing.T) {
+	cache, err := newTestDiskCache(t, t.TempDir())
+	if err != nil {
+		t.Fatalf("Failed to create disk cache: %v", err)
+	}
+
+	name := "test-cluster"
+	key := "resource:cluster:sub:rg:test-cluster"
+	if err := cache.Set(key, &armcontainerservice.ManagedCluster{Name: &name}, 5*time.Minute); err != nil {
+		t.Fatalf("Failed to set value: %v", err)
+	}
+
+	var retrieved armcontainerservice.ManagedCluster
+	remaining, found := cache.Get(key, &retrieved)
+	if !found {
+		t.Fatal("Expected to find persisted value")
+	}
+	if retrieved.Name == nil || *retrieved.Name != name {
+		t.Errorf("Expected cluster name %s, got %v", name, retrieved.Name)
+	}
+	if remaining <= 0 || remaining > 5*time.Minute {
+		t.Errorf("Expected remaining TTL within (0, 5m], got %v", remaining)
+	}
+}
+
+func TestDiskCache_SurvivesReopen(t *testing.T) {
+	dir := t.TempDir()
+	keyFile := filepath.Join(t.TempDir(), diskCacheKeyFile)
+
+	first, err := NewDiskCache(dir, keyFile)
+	if err != nil {
+		t.Fatalf("Failed to create disk cache: %v", err)
+	}
+	if err := first.Set("detectors:list:sub:rg:cluster", map[string]string{"a": "b"}, time.Minute); err != nil {
+		t.Fatalf("Failed to set value: %v", err)
+	}
+
+	second, err := NewDiskCache(dir, keyFile)
+	if err != nil {
+		t.Fatalf("Failed to reopen disk cache: %v", err)
+	}
+
+	var retrieved map[string]string
+	if _, found := second.Get("detectors:list:sub:rg:cluster", &retrieved); !found {
+		t.Fatal("Expected persisted value to survive reopening the cache")
+	}
+	if retrieved["a"] != "b" {
+		t.Errorf("Expected value b, got %v", retrieved["a"])
+	}
+}
+
+func TestDiskCache_Expiration(t *testing.T) {
+	cache, err := newTestDiskCache(t, t.TempDir())
+	if err != nil {
+		t.Fatalf("Failed to create disk cache: %v", err)
+	}
+
+	if err := cache.Set("key", "value", 50*time.Millisecond); err != nil {
+		t.Fatalf("Failed to set value: %v", err)
+	}
+
+	time.Sleep(100 * time.Millisecond)
+
+	var retrieved string
+	if _, found := cache.Get("key", &retrieved); found {
+		t.Error("Expected persisted value to be expired")
+	}
+	if _, err := os.Stat(cache.entryPath("key")); !os.IsNotExist(err) {
+		t.Error("Expected expired entry file to be removed")
+	}
+}
+
+func TestDiskCache_EncryptedAtRest(t *testing.T) {
+	dir := t.TempDir()
+	keyFile := filepath.Join(t.TempDir(), diskCacheKeyFile)
+	cache, err := NewDiskCache(dir, keyFile)
+	if err != nil {
+		t.Fatalf("Failed to create disk cache: %v", err)
+	}
+
+	secret := "very-identifiable-cluster-name"
+	if err := cache.Set("resource:cluster:sub:rg:"+secret, secret, time.Minute); err != nil {
+		t.Fatalf("Failed to set value: %v", err)
+	}
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatalf("Failed to read cache directory: %v", err)
+	}
+	for _, entry := range entries {
+		if bytes.Contains([]byte(entry.Name()), []byte(secret)) {
+			t.Errorf("Expected file names not to contain cache keys, got %s", entry.Name())
+		}
+		data, err := os.ReadFile(filepath.Join(dir, entry.Name()))
+		if err != nil {
+			t.Fatalf("Failed to read %s: %v", entry.Name(), err)
+		}
+		if bytes.Contains(data, []byte(secret)) {
+			t.Errorf("Expected %s to be encrypted, found plaintext", entry.Name())
+		}
+	}
+
+	if _, err := os.Stat(filepath.Join(dir, diskCacheKeyFile)); !os.IsNotExist(err) {
+		t.Error("Expected no key file in the cache directory")
+	}
+	info, err := os.Stat(keyFile)
+	if err != nil {
+		t.Fatalf("Expected key file to exist: %v", err)
+	}
+	if info.Mode().Perm()&0o077 != 0 {
+		t.Errorf("Expected key file to be owner-only, got %v", info.Mode().Perm())
+	}
+}
+
+func TestDiskCache_WrongKeyCannotRead(t *testing.T) {
+	dir := t.TempDir()
+	keyFile := filepath.Join(t.TempDir(), diskCacheKeyFile)
+	cache, err := NewDiskCache(dir, keyFile)
+	if err != nil {
+		t.Fatalf("Failed to create disk cache: %v", err)
+	}
+	if err := cache.Set("key", "value", time.Minute); err != nil {
+		t.Fatalf("Failed to set value: %v", err)
+	}
+
+	// Replace the local key, as if the cache directory was copied to another machine
+	if err := os.WriteFile(keyFile, bytes.Repeat([]byte{1}, diskCacheKeySize), 0o600); err != nil {
+		t.Fatalf("Failed to overwrite key: %v", err)
+	}
+
+	reopened, err := NewDiskCache(dir, keyFile)
+	if err != nil {
+		t.Fatalf("Failed to reopen disk cache: %v", err)
+	}
+
+	var retrieved string
+	if _, found := reopened.Get("key", &retrieved); found {
+		t.Error("Expected entry encrypted with another key to be unreadable")
+	}
+}
+
+func TestDiskCache_KeyOutsideCacheDir(t *testing.T) {
+	dir := t.TempDir()
+
+	for _, keyFile := range []string{filepath.Join(dir, diskCacheKeyFile), filepath.Join(dir, "keys", diskCacheKeyFile), dir} {
+		if _, err := NewDiskCache(dir, keyFile); err == nil {
+			t.Errorf("Expected key file %s inside the cache directory to be rejected", keyFile)
+		}
+	}
+}
+
+func TestDiskCache_DeleteAndClear(t *testing.T) {
+	cache, err := newTestDiskCache(t, t.TempDir())
+	if err != nil {
+		t.Fatalf("Failed to create disk cache: %v", err)
+	}
+
+	_ = cache.Set("key1", "value1", time.Minute)
+	_ = cache.Set("key2", "value2", time.Minute)
+
+	cache.Delete("key1")
+
+	var retrieved string
+	if _, found := cache.Get("key1", &retrieved); found {
+		t.Error("Expected key1 to be deleted")
+	}
+	if _, found := cache.Get("key2", &retrieved); !found {
+		t.Error("Expected key2 to still exist")
+	}
+
+	cache.Clear()
+	if _, found := cache.Get("key2", &retrieved); found {
+		t.Error("Expected key2 to be cleared")
+	}
+}
+
+func TestAzureCache_PersistedLayer(t *testing.T) {
+	disk, err := newTestDiskCache(t, t.TempDir())
+	if err != nil {
+		t.Fatalf("Failed to create disk cache: %v", err)
+	}
+
+	// Simulate a previous process that stored a cluster
+	previous := NewAzureCache(time.Minute)
+	previous.EnablePersistence(disk, 10*time.Minute)
+
+	name := "test-cluster"
+	key := "resource:cluster:sub:rg:test-cluster"
+	previous.SetPersisted(key, &armcontainerservice.ManagedCluster{Name: &name})
+
+	// A fresh in-memory cache misses, but the persisted layer hits
+	cache := NewAzureCache(time.Minute)
+	cache.EnablePersistence(disk, 10*time.Minute)
+
+	if _, found := cache.Get(key); found {
+		t.Fatal("Expected in-memory cache to start empty")
+	}
+
+	var cluster armcontainerservice.ManagedCluster
+	if !cache.GetPersisted(key, &cluster) {
+		t.Fatal("Expected persisted cluster to be found")
+	}
+	if *cluster.Name != name {
+		t.Errorf("Expected cluster name %s, got %s", name, *cluster.Name)
+	}
+
+	// The persisted hit is promoted into memory with the requested pointer type
+	cached, found := cache.Get(key)
+	if !found {
+		t.Fatal("Expected persisted value to be promoted into memory")
+	}
+	if _, ok := cached.(*armcontainerservice.ManagedCluster); !ok {
+		t.Errorf("Expected *ManagedCluster in memory, got %T", cached)
+	}
+
+	// Delete removes both layers
+	cache.Delete(key)
+	if cache.GetPersisted(key, &cluster) {
+		t.Error("Expected persisted value to be deleted")
+	}
+}
+
+func TestAzureCache_PersistenceDisabled(t *testing.T) {
+	cache := NewAzureCache(time.Minute)
+	if cache.IsPersistent() {
+		t.Error("Expected persistence to be disabled by default")
+	}
+
+	cache.SetPersisted("key", "value")
+
+	var retrieved string
+	if cache.GetPersisted("key", &retrieved) {
+		t.Error("Expected GetPersisted to miss when persistence is disabled")
+	}
+	if value, found := cache.Get("key"); !found || value != "value" {
+		t.Error("Expected SetPersisted to still populate the in-memory cache")
+	}
+}
@@ -0,0 +1,41 @@
+// Package armtest creates Azure clients backed by a fake ARM server for tests.
+// It is separate from fakearm so the azureclient package can use fakearm in its own tests.
+package armtest
+
+import (
+	"testing"
+
+	"github.com/Azure/aks-mcp/internal/azureclient"
+	"github.com/Azure/aks-mcp/internal/azureclient/fakearm"
+	"github.com/Azure/aks-mcp/internal/config"
+)
+
+// NewClient starts a fake ARM server with the default fixtures and the given fixture files, closed when the test ends,
+// and returns it with a new configuration and an Azure client that talks to it.
+func NewClient(t testing.TB, fixtureFiles ...string) (*fakearm.Server, *azureclient.AzureClient, *config.ConfigData) {
+	t.Helper()
+
+	srv, err := fakearm.NewServer(fixtureFiles...)
+	if err != nil {
+		t.Fatalf("Failed to start fake ARM server: %v", err)
+	}
+	t.Cleanup(srv.Close)
+
+	cfg := config.NewConfig()
+	return srv, NewClientFor(t, srv, cfg), cfg
+}
+
+// NewClientFor returns an Azure client with an empty cache that talks to an existing fake ARM server.
+func NewClientFor(t testing.TB, srv *fakearm.Server, cfg *config.ConfigData) *azureclient.AzureClient {
+	t.Helper()
+
+	client, err := azureclient.NewAzureClientWithOptions(cfg, &azureclient.ClientOptions{
+		Credential: fakearm.Credential{},
+		Endpoint:   srv.URL,
+		HTTPClient: srv.Client(),
+	})
+	if err != nil {
+		t.Fatalf("Failed to create Azure client: %v", err)
+	}
+	return client
+}
@@ -0,0 +1,248 @@
+// Package fakearm provides a local Azure Resource Manager stand-in for hermetic tests.
+//
+// The server answers GET requests from fixture JSON keyed by ARM resource path, so
+// AzureClient and whole tool handlers can run without network access. PUT requests
+// store their body as the fixture of the path and echo it, like a synchronous create or update:
+//
+//	srv, err := fakearm.NewServer()
+//	...
+//	defer srv.Close()
+//	client, err := azureclient.NewAzureClientWithOptions(cfg, &azureclient.ClientOptions{
+//		Credential: fakearm.Credential{},
+//		Endpoint:   srv.URL,
+//		HTTPClient: srv.Client(),
+//	})
+package fakearm
+
+import (
+	"context"
+	"embed"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/policy"
+)
+
+// Identifiers of the resources in the default fixtures
+const (
+	SubscriptionID    = "00000000-0000-0000-0000-000000000000"
+	ResourceGroup     = "test-rg"
+	ClusterName       = "test-cluster"
+	NodeResourceGroup = "MC_test-rg_test-cluster_eastus"
+	ClusterResourceID = "/subscriptions/" + SubscriptionID + "/resourceGroups/" + ResourceGroup +
+		"/providers/Microsoft.ContainerService/managedClusters/" + ClusterName
+)
+
+//go:embed fixtures/*.json
+var defaultFixtures embed.FS
+
+// Server is a fake Azure Resource Manager endpoint backed by fixtures.
+// It is served over TLS because the SDK refuses to send bearer tokens over plain HTTP;
+// use Client() for an HTTP client that trusts the server certificate.
+type Server struct {
+	*httptest.Server
+
+	mu       sync.Mutex
+	fixtures map[string]json.RawMessage
+	requests []string
+	queries  []url.Values
+}
+
+// NewServer starts a fake ARM server with the default AKS fixtures, followed by
+// fixtures loaded from the given files. Later fixtures override earlier ones.
+func NewServer(fixtureFiles ...string) (*Server, error) {
+	s := &Server{
+		fixtures: make(map[string]json.RawMessage),
+	}
+
+	entries, err := defaultFixtures.ReadDir("fixtures")
+	if err != nil {
+		return nil, fmt.Errorf("failed to read default fixtures: %v", err)
+	}
+	for _, entry := range entries {
+		data, err := defaultFixtures.ReadFile(path.Join("fixtures", entry.Name()))
+		if err != nil {
+			return nil, fmt.Errorf("failed to read fixture %s: %v", entry.Name(), err)
+		}
+		if err := s.LoadFixtures(data); err != nil {
+			return nil, fmt.Errorf("failed to load fixture %s: %v", entry.Name(), err)
+		}
+	}
+
+	for _, file := range fixtureFiles {
+		data, err := os.ReadFile(file) // #nosec G304 -- fixture paths are provided by tests
+		if err != nil {
+			return nil, fmt.Errorf("failed to read fixture %s: %v", file, err)
+		}
+		if err := s.LoadFixtures(data); err != nil {
+			return nil, fmt.Errorf("failed to load fixture %s: %v", file, err)
+		}
+	}
+
+	s.Server = httptest.NewTLSServer(http.HandlerFunc(s.handle))
+	return s, nil
+}
+
+// LoadFixtures adds fixtures from a JSON object mapping ARM resource paths to response bodies.
+// List endpoints are regular paths whose body is an ARM list, e.g. {"value": [...]}.
+func (s *Server) LoadFixtures(data []byte) error {
+	var fixtures map[string]json.RawMessage
+	if err := json.Unmarshal(data, &fixtures); err != nil {
+		return fmt.Errorf("failed to parse fixtures: %v", err)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for resourcePath, body := range fixtures {
+		s.fixtures[normalizePath(resourcePath)] = body
+	}
+	return nil
+}
+
+// SetResponse sets the response body for an ARM resource path.
+func (s *Server) SetResponse(resourcePath string, body interface{}) error {
+	data, err := json.Marshal(body)
+	if err != nil {
+		return fmt.Errorf("failed to marshal response: %v", err)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.fixtures[normalizePath(resourcePath)] = data
+	return nil
+}
+
+// RemoveResponse removes the fixture for an ARM resource path, so requests to it return 404.
+func (s *Server) RemoveResponse(resourcePath string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.fixtures, normalizePath(resourcePath))
+}
+
+// Requests returns the paths of the requests served so far, in order.
+func (s *Server) Requests() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return append([]string(nil), s.requests...)
+}
+
+// RequestCount returns how many requests were made for an ARM resource path.
+func (s *Server) RequestCount(resourcePath string) int {
+	key := normalizePath(resourcePath)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	count := 0
+	for _, request := range s.requests {
+		if normalizePath(request) == key {
+			count++
+		}
+	}
+	return count
+}
+
+// Queries returns the query parameters of the requests made for an ARM resource path, in order.
+func (s *Server) Queries(resourcePath string) []url.Values {
+	key := normalizePath(resourcePath)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var queries []url.Values
+	for i, request := range s.requests {
+		if normalizePath(request) == key {
+			queries = append(queries, s.queries[i])
+		}
+	}
+	return queries
+}
+
+// handle serves fixtures for GET requests and stores the body of PUT requests, with an ARM error body for everything else.
+func (s *Server) handle(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	s.requests = append(s.requests, r.URL.Path)
+	s.queries = append(s.queries, r.URL.Query())
+	body, found := s.fixtures[normalizePath(r.URL.Path)]
+	s.mu.Unlock()
+
+	if !strings.HasPrefix(r.Header.Get("Authorization"), "Bearer ") {
+		writeError(w, http.StatusUnauthorized, "AuthenticationFailed", "Authentication failed. The 'Authorization' header is missing.")
+		return
+	}
+
+	if r.Method == http.MethodPut {
+		data, err := io.ReadAll(r.Body)
+		if err != nil || !json.Valid(data) {
+			writeError(w, http.StatusBadRequest, "InvalidRequestContent", "The request content was invalid and could not be deserialized.")
+			return
+		}
+		s.mu.Lock()
+		s.fixtures[normalizePath(r.URL.Path)] = data
+		s.mu.Unlock()
+		body, found = data, true
+	} else if r.Method != http.MethodGet {
+		writeError(w, http.StatusMethodNotAllowed, "MethodNotAllowed", fmt.Sprintf("The fake ARM server does not support %s requests.", r.Method))
+		return
+	}
+
+	if !found {
+		writeError(w, http.StatusNotFound, "ResourceNotFound", fmt.Sprintf("The resource '%s' was not found.", r.URL.Path))
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	_, _ = w.Write(body)
+}
+
+// writeError writes an error in the ARM error response format.
+func writeError(w http.ResponseWriter, status int, code, message string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("x-ms-error-code", code)
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(map[string]interface{}{
+		"error": map[string]string{
+			"code":    code,
+			"message": message,
+		},
+	})
+}
+
+// normalizePath makes resource paths comparable: ARM paths are case-insensitive and
+// some SDK clients produce duplicate slashes when joining resource URIs.
+func normalizePath(resourcePath string) string {
+	for strings.Contains(resourcePath, "//") {
+		resourcePath = strings.ReplaceAll(resourcePath, "//", "/")
+	}
+	resourcePath = strings.TrimSuffix(resourcePath, "/")
+	if !strings.HasPrefix(resourcePath, "/") {
+		resourcePath = "/" + resourcePath
+	}
+	return strings.ToLower(resourcePath)
+}
+
+// Credential is a token credential that returns a static token, for use with Server.
+type Credential struct{}
+
+// GetToken returns a static bearer token that never needs refreshing during a test.
+func (Credential) GetToken(ctx context.Context, options policy.TokenRequestOptions) (azcore.AccessToken, error) {
+	return azcore.AccessToken{
+		Token:     "fake-arm-token",
+		ExpiresOn: time.Now().Add(time.Hour),
+	}, nil
+}
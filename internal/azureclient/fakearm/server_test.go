@@ -0,0 +1,119 @@
+package fakearm
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func newTestServer(t *testing.T) *Server {
+	t.Helper()
+	srv, err := NewServer()
+	if err != nil {
+		t.Fatalf("Failed to start fake ARM server: %v", err)
+	}
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func get(t *testing.T, srv *Server, resourcePath string, authorized bool) (*http.Response, map[string]interface{}) {
+	t.Helper()
+	req, err := http.NewRequest(http.MethodGet, srv.URL+resourcePath+"?api-version=2024-01-01", nil)
+	if err != nil {
+		t.Fatalf("Failed to create request: %v", err)
+	}
+	if authorized {
+		req.Header.Set("Authorization", "Bearer token")
+	}
+	resp, err := srv.Client().Do(req)
+	if err != nil {
+		t.Fatalf("Request failed: %v", err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	var body map[string]interface{}
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		t.Fatalf("Failed to decode response: %v", err)
+	}
+	return resp, body
+}
+
+func TestServer_ServesDefaultFixtures(t *testing.T) {
+	srv := newTestServer(t)
+
+	// Paths are matched case-insensitively, as in ARM
+	resp, body := get(t, srv, strings.ToLower(ClusterResourceID), true)
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("Expected 200, got %d", resp.StatusCode)
+	}
+	if body["name"] != ClusterName {
+		t.Errorf("Expected cluster %s, got %v", ClusterName, body["name"])
+	}
+	if srv.RequestCount(ClusterResourceID) != 1 {
+		t.Errorf("Expected one recorded request, got %v", srv.Requests())
+	}
+}
+
+func TestServer_Errors(t *testing.T) {
+	srv := newTestServer(t)
+
+	resp, body := get(t, srv, ClusterResourceID, false)
+	if resp.StatusCode != http.StatusUnauthorized {
+		t.Errorf("Expected 401 without a bearer token, got %d", resp.StatusCode)
+	}
+	if _, ok := body["error"].(map[string]interface{}); !ok {
+		t.Errorf("Expected ARM error body, got %v", body)
+	}
+
+	resp, body = get(t, srv, "/subscriptions/"+SubscriptionID+"/resourceGroups/missing", true)
+	if resp.StatusCode != http.StatusNotFound {
+		t.Errorf("Expected 404 for unknown resource, got %d", resp.StatusCode)
+	}
+	if errBody, ok := body["error"].(map[string]interface{}); !ok || errBody["code"] != "ResourceNotFound" {
+		t.Errorf("Expected ResourceNotFound error, got %v", body)
+	}
+}
+
+func TestServer_SetAndRemoveResponse(t *testing.T) {
+	srv := newTestServer(t)
+
+	resourcePath := "/subscriptions/" + SubscriptionID + "/resourceGroups/other-rg"
+	if err := srv.SetResponse(resourcePath, map[string]string{"name": "other-rg"}); err != nil {
+		t.Fatalf("Failed to set response: %v", err)
+	}
+
+	resp, body := get(t, srv, resourcePath, true)
+	if resp.StatusCode != http.StatusOK || body["name"] != "other-rg" {
+		t.Errorf("Expected custom fixture, got %d %v", resp.StatusCode, body)
+	}
+
+	srv.RemoveResponse(ClusterResourceID)
+	if resp, _ := get(t, srv, ClusterResourceID, true); resp.StatusCode != http.StatusNotFound {
+		t.Errorf("Expected removed fixture to return 404, got %d", resp.StatusCode)
+	}
+}
+
+func TestServer_Put(t *testing.T) {
+	srv := newTestServer(t)
+
+	resourcePath := ClusterResourceID + "/providers/Microsoft.Insights/diagnosticSettings/new-setting"
+	req, err := http.NewRequest(http.MethodPut, srv.URL+resourcePath+"?api-version=2021-05-01-preview", strings.NewReader(`{"name": "new-setting"}`))
+	if err != nil {
+		t.Fatalf("Failed to create request: %v", err)
+	}
+	req.Header.Set("Authorization", "Bearer token")
+	resp, err := srv.Client().Do(req)
+	if err != nil {
+		t.Fatalf("Request failed: %v", err)
+	}
+	_ = resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Errorf("Expected 200 for PUT, got %d", resp.StatusCode)
+	}
+
+	// The body of the PUT is served afterwards
+	if resp, body := get(t, srv, resourcePath, true); resp.StatusCode != http.StatusOK || body["name"] != "new-setting" {
+		t.Errorf("Expected the stored body, got %d %v", resp.StatusCode, body)
+	}
+}
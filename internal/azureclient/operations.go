@@ -0,0 +1,239 @@
+package azureclient
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/policy"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/runtime"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/containerservice/armcontainerservice/v2"
+)
+
+// aksOperationsAPIVersion is the AKS API version that exposes the latest operation of a cluster or agent pool
+const aksOperationsAPIVersion = "2024-05-02-preview"
+
+// AKSOperationStatus is the status of the latest asynchronous ARM operation on an AKS cluster or agent pool
+type AKSOperationStatus struct {
+	ID              string               `json:"id,omitempty"`
+	Name            string               `json:"name,omitempty"`
+	Status          string               `json:"status"`
+	StartTime       *time.Time           `json:"startTime,omitempty"`
+	EndTime         *time.Time           `json:"endTime,omitempty"`
+	PercentComplete *float64             `json:"percentComplete,omitempty"`
+	Error           *AKSOperationErrInfo `json:"error,omitempty"`
+}
+
+// AKSOperationErrInfo describes why an AKS operation failed
+type AKSOperationErrInfo struct {
+	Code    string `json:"code,omitempty"`
+	Message string `json:"message,omitempty"`
+}
+
+// GetAKSClusterStatus retrieves the current state of an AKS cluster, bypassing the cache.
+// It is intended for polling operations that change the cluster.
+func (c *AzureClient) GetAKSClusterStatus(ctx context.Context, subscriptionID, resourceGroup, clusterName string) (*armcontainerservice.ManagedCluster, error) {
+	clients, err := c.GetOrCreateClientsForContext(ctx, subscriptionID)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := clients.ContainerServiceClient.Get(ctx, resourceGroup, clusterName, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get AKS cluster: %w", err)
+	}
+
+	return &resp.ManagedCluster, nil
+}
+
+// GetAgentPool retrieves the current state of an agent pool of an AKS cluster, bypassing the cache.
+func (c *AzureClient) GetAgentPool(ctx context.Context, subscriptionID, resourceGroup, clusterName, agentPoolName string) (*armcontainerservice.AgentPool, error) {
+	clients, err := c.GetOrCreateClientsForContext(ctx, subscriptionID)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := clients.AgentPoolsClient.Get(ctx, resourceGroup, clusterName, agentPoolName, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get agent pool: %w", err)
+	}
+
+	return &resp.AgentPool, nil
+}
+
+// GetLatestAKSOperation retrieves the latest asynchronous operation on an AKS cluster,
+// or on one of its agent pools when agentPoolName is not empty.
+func (c *AzureClient) GetLatestAKSOperation(ctx context.Context, subscriptionID, resourceGroup, clusterName, agentPoolName string) (*AKSOperationStatus, error) {
+	resourcePath := fmt.Sprintf("/subscriptions/%s/resourceGroups/%s/providers/Microsoft.ContainerService/managedClusters/%s",
+		url.PathEscape(subscriptionID), url.PathEscape(resourceGroup), url.PathEscape(clusterName))
+	if agentPoolName != "" {
+		resourcePath += "/agentPools/" + url.PathEscape(agentPoolName)
+	}
+
+	body, err := c.GetARMResource(ctx, subscriptionID, resourcePath+"/operations/latest", aksOperationsAPIVersion)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get latest AKS operation: %w", err)
+	}
+
+	var status AKSOperationStatus
+	if err := json.Unmarshal(body, &status); err != nil {
+		return nil, fmt.Errorf("failed to parse AKS operation status: %v", err)
+	}
+	return &status, nil
+}
+
+// GetARMResource makes a GET request for an ARM resource path that has no SDK client and returns the response body.
+// Error responses are returned as *azcore.ResponseError.
+func (c *AzureClient) GetARMResource(ctx context.Context, subscriptionID, resourcePath, apiVersion string) ([]byte, error) {
+	url := fmt.Sprintf("%s/%s?api-version=%s", c.ResourceManagerEndpoint(), strings.TrimPrefix(resourcePath, "/"), apiVersion)
+
+	resp, err := c.armGet(ctx, url, subscriptionID)
+	if err != nil {
+		return nil, err
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		defer func() {
+			_ = resp.Body.Close()
+		}()
+		return nil, runtime.NewResponseError(resp)
+	}
+
+	return readARMResponse(resp)
+}
+
+// ListARMResources lists a collection of ARM resources that has no SDK client, following next links until every page
+// or at least maxItems items have been read, and returns the raw items. Query parameters other than the API version
+// are passed in query. Next links must point at the Resource Manager endpoint, since the request carries the ARM token.
+// Error responses are returned as *azcore.ResponseError.
+func (c *AzureClient) ListARMResources(ctx context.Context, subscriptionID, resourcePath, apiVersion string, query url.Values, maxItems int) ([]json.RawMessage, error) {
+	endpoint, err := url.Parse(c.ResourceManagerEndpoint())
+	if err != nil {
+		return nil, fmt.Errorf("invalid Resource Manager endpoint: %v", err)
+	}
+
+	values := url.Values{}
+	for key, value := range query {
+		values[key] = value
+	}
+	values.Set("api-version", apiVersion)
+	next := fmt.Sprintf("%s/%s?%s", c.ResourceManagerEndpoint(), strings.TrimPrefix(resourcePath, "/"), values.Encode())
+
+	var items []json.RawMessage
+	for next != "" && len(items) < maxItems {
+		nextURL, err := url.Parse(next)
+		if err != nil || nextURL.Scheme != endpoint.Scheme || !strings.EqualFold(nextURL.Host, endpoint.Host) {
+			return nil, fmt.Errorf("refusing to follow next link %s of %s outside of %s", next, resourcePath, c.ResourceManagerEndpoint())
+		}
+
+		resp, err := c.armGet(ctx, next, subscriptionID)
+		if err != nil {
+			return nil, err
+		}
+		if resp.StatusCode != http.StatusOK {
+			respErr := runtime.NewResponseError(resp)
+			_ = resp.Body.Close()
+			return nil, respErr
+		}
+
+		body, err := readARMResponse(resp)
+		if err != nil {
+			return nil, err
+		}
+		var page struct {
+			Value    []json.RawMessage `json:"value"`
+			NextLink string            `json:"nextLink"`
+		}
+		if err := json.Unmarshal(body, &page); err != nil {
+			return nil, fmt.Errorf("failed to parse %s: %v", resourcePath, err)
+		}
+		items = append(items, page.Value...)
+		next = page.NextLink
+	}
+	if len(items) > maxItems {
+		items = items[:maxItems]
+	}
+
+	return items, nil
+}
+
+// armGet makes a GET request to an Azure Resource Manager URL with the credential for the subscription's tenant
+func (c *AzureClient) armGet(ctx context.Context, url string, subscriptionID string) (*http.Response, error) {
+	// Create request
+	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %v", err)
+	}
+
+	// Get access token for the request using the credential for the subscription's tenant
+	credential, err := c.credentialForSubscription(ctx, subscriptionID)
+	if err != nil {
+		return nil, err
+	}
+
+	token, err := credential.GetToken(ctx, policy.TokenRequestOptions{
+		Scopes: []string{c.ResourceManagerEndpoint() + "/.default"},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get access token: %v", err)
+	}
+
+	// Set headers
+	req.Header.Set("Authorization", "Bearer "+token.Token)
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "AKS-MCP")
+
+	// Make the request
+	client := c.httpClient
+	if client == nil {
+		client = &http.Client{}
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to make request: %v", err)
+	}
+
+	return resp, nil
+}
+
+// readARMResponse reads and closes the body of an Azure Resource Manager response, returning an error unless the status is 200 OK
+func readARMResponse(resp *http.Response) ([]byte, error) {
+	defer func() {
+		if err := resp.Body.Close(); err != nil {
+			log.Printf("Warning: failed to close response body: %v", err)
+		}
+	}()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response body: %v", err)
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		var errorMsg map[string]interface{}
+		if err := json.Unmarshal(body, &errorMsg); err == nil {
+			if msg, ok := errorMsg["error"].(map[string]interface{}); ok {
+				if message, ok := msg["message"].(string); ok {
+					return nil, fmt.Errorf("API error (%d): %s", resp.StatusCode, message)
+				}
+			}
+		}
+		return nil, fmt.Errorf("API error (%d): %s", resp.StatusCode, string(body))
+	}
+
+	return body, nil
+}
+
+// IsNotFound reports whether err is an Azure Resource Manager "not found" response
+func IsNotFound(err error) bool {
+	var respErr *azcore.ResponseError
+	return errors.As(err, &respErr) && respErr.StatusCode == http.StatusNotFound
+}
@@ -0,0 +1,93 @@
+package azureclient
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/policy"
+)
+
+// PrometheusQueryScope is the token scope of Azure Monitor workspace query endpoints
+const PrometheusQueryScope = "https://prometheus.monitor.azure.com/.default"
+
+// maxPrometheusResponseBytes is the largest Prometheus query response that is read; tests replace it.
+// Results are trimmed to the requested series only after they are decoded, so the whole response is buffered.
+var maxPrometheusResponseBytes int64 = 32 << 20
+
+// QueryPrometheus calls the Prometheus HTTP API of an Azure Monitor workspace, e.g. path "/api/v1/query",
+// and returns the data field of a successful response. Query results are not cached.
+func (c *AzureClient) QueryPrometheus(ctx context.Context, subscriptionID, queryEndpoint, path string, values url.Values) (json.RawMessage, error) {
+	endpoint, err := url.Parse(strings.TrimSuffix(queryEndpoint, "/") + path)
+	if err != nil || endpoint.Scheme != "https" || endpoint.Host == "" {
+		return nil, fmt.Errorf("invalid Prometheus query endpoint: %s", queryEndpoint)
+	}
+	endpoint.RawQuery = values.Encode()
+
+	req, err := http.NewRequestWithContext(ctx, "GET", endpoint.String(), nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %v", err)
+	}
+
+	// Get access token for the request using the credential for the subscription's tenant
+	credential, err := c.credentialForSubscription(ctx, subscriptionID)
+	if err != nil {
+		return nil, err
+	}
+
+	token, err := credential.GetToken(ctx, policy.TokenRequestOptions{
+		Scopes: []string{PrometheusQueryScope},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get access token: %v", err)
+	}
+
+	req.Header.Set("Authorization", "Bearer "+token.Token)
+	req.Header.Set("User-Agent", "AKS-MCP")
+
+	client := c.httpClient
+	if client == nil {
+		client = &http.Client{}
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to make request: %v", err)
+	}
+	defer func() {
+		if err := resp.Body.Close(); err != nil {
+			log.Printf("Warning: failed to close response body: %v", err)
+		}
+	}()
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, maxPrometheusResponseBytes+1))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response body: %v", err)
+	}
+	if int64(len(body)) > maxPrometheusResponseBytes {
+		return nil, fmt.Errorf("prometheus query result is larger than %d bytes, narrow the query with label matchers or aggregation, or use a shorter time range or larger step", maxPrometheusResponseBytes)
+	}
+
+	// Prometheus reports errors as {"status": "error", "errorType": ..., "error": ...}
+	var result struct {
+		Status    string          `json:"status"`
+		Data      json.RawMessage `json:"data"`
+		ErrorType string          `json:"errorType"`
+		Error     string          `json:"error"`
+	}
+	if err := json.Unmarshal(body, &result); err != nil {
+		return nil, fmt.Errorf("prometheus query failed (%d): %s", resp.StatusCode, string(body))
+	}
+	if resp.StatusCode != http.StatusOK || result.Status != "success" {
+		if result.Error != "" {
+			return nil, fmt.Errorf("prometheus query failed (%d): %s: %s", resp.StatusCode, result.ErrorType, result.Error)
+		}
+		return nil, fmt.Errorf("prometheus query failed (%d): %s", resp.StatusCode, string(body))
+	}
+
+	return result.Data, nil
+}
@@ -0,0 +1,219 @@
+package azureclient
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"regexp"
+	"strings"
+
+	"github.com/Azure/aks-mcp/internal/command"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
+	"github.com/Azure/azure-sdk-for-go/sdk/azidentity"
+)
+
+// clientKey identifies the set of clients for a subscription in a specific tenant.
+// An empty tenant ID means the tenant of the default credential.
+type clientKey struct {
+	tenantID       string
+	subscriptionID string
+}
+
+// tenantIDPattern matches tenant GUIDs and verified domain names
+var tenantIDPattern = regexp.MustCompile(`^[a-zA-Z0-9][a-zA-Z0-9.-]*$`)
+
+// accountListTimeout is the timeout in seconds for subscription-to-tenant discovery
+const accountListTimeout = 60
+
+// azAccount is the subset of `az account list` output used for tenant discovery
+type azAccount struct {
+	ID       string `json:"id"`
+	TenantID string `json:"tenantId"`
+}
+
+// ValidateTenantID checks that a tenant ID is well-formed.
+func ValidateTenantID(tenantID string) error {
+	if !tenantIDPattern.MatchString(tenantID) {
+		return fmt.Errorf("invalid tenant ID: %s", tenantID)
+	}
+	return nil
+}
+
+// isTenantAllowed checks a tenant against the configured allow-list.
+// An empty allow-list or "*" allows any tenant.
+func (c *AzureClient) isTenantAllowed(tenantID string) bool {
+	if tenantID == "" || len(c.allowedTenants) == 0 {
+		return true
+	}
+	for _, allowed := range c.allowedTenants {
+		if allowed == "*" || strings.EqualFold(allowed, tenantID) {
+			return true
+		}
+	}
+	return false
+}
+
+// CheckTenant checks that a tenant ID is well-formed and in the list of allowed tenants.
+func (c *AzureClient) CheckTenant(tenantID string) error {
+	if err := ValidateTenantID(tenantID); err != nil {
+		return err
+	}
+	if !c.isTenantAllowed(tenantID) {
+		return fmt.Errorf("tenant %s is not in the list of allowed tenants", tenantID)
+	}
+	return nil
+}
+
+// tenantContextKey is the context key of the tenant of a call
+type tenantContextKey struct{}
+
+// WithTenant returns a context whose Azure calls use clients and credentials for tenantID,
+// rather than the tenant discovered for the subscription. An empty tenant ID returns ctx unchanged.
+func WithTenant(ctx context.Context, tenantID string) context.Context {
+	if tenantID == "" {
+		return ctx
+	}
+	return context.WithValue(ctx, tenantContextKey{}, tenantID)
+}
+
+// tenantFor returns the tenant of a call for a subscription: the tenant set with WithTenant,
+// or else the tenant the subscription belongs to. The tenant is returned along with the error
+// when the subscription belongs to a tenant that is not allowed.
+func (c *AzureClient) tenantFor(ctx context.Context, subscriptionID string) (string, error) {
+	if tenantID, ok := ctx.Value(tenantContextKey{}).(string); ok && tenantID != "" {
+		return tenantID, nil
+	}
+	return c.GetTenantForSubscription(subscriptionID)
+}
+
+// CacheKey returns the cache key of a resource in a subscription, read in the tenant of the call.
+// Keys include the tenant so data fetched with one tenant's credential is not served to a call in another.
+// Nothing is cached for a tenant that is not allowed, so its key is built from the tenant all the same.
+func (c *AzureClient) CacheKey(ctx context.Context, prefix, subscriptionID string, names ...string) string {
+	tenantID, _ := c.tenantFor(ctx, subscriptionID)
+	parts := append([]string{prefix, strings.ToLower(tenantID), subscriptionID}, names...)
+	return strings.Join(parts, ":")
+}
+
+// GetTenantForSubscription returns the tenant a subscription belongs to.
+// Tenants are discovered via `az account list`.
+// Returns an empty string when the tenant is unknown, meaning the default credential's tenant,
+// and an error when the subscription belongs to a tenant that is not in the list of allowed tenants.
+func (c *AzureClient) GetTenantForSubscription(subscriptionID string) (string, error) {
+	key := strings.ToLower(subscriptionID)
+
+	c.mu.RLock()
+	tenantID, found := c.subscriptionTenants[key]
+	discovered := c.tenantsDiscovered
+	c.mu.RUnlock()
+
+	if !found && !discovered {
+		c.discoverSubscriptionTenants()
+
+		c.mu.RLock()
+		tenantID = c.subscriptionTenants[key]
+		c.mu.RUnlock()
+	}
+
+	if !c.isTenantAllowed(tenantID) {
+		return tenantID, fmt.Errorf("tenant %s of subscription %s is not in the list of allowed tenants", tenantID, subscriptionID)
+	}
+	return tenantID, nil
+}
+
+// discoverSubscriptionTenants populates the subscription-to-tenant map once.
+// Concurrent callers block until the first lookup has filled the map.
+func (c *AzureClient) discoverSubscriptionTenants() {
+	c.tenantsOnce.Do(func() {
+		c.mu.RLock()
+		lookup := c.tenantLookup
+		c.mu.RUnlock()
+
+		var tenants map[string]string
+		if lookup != nil {
+			var err error
+			if tenants, err = lookup(); err != nil {
+				log.Printf("Warning: failed to discover subscription tenants: %v", err)
+			}
+		}
+
+		c.mu.Lock()
+		defer c.mu.Unlock()
+
+		// Subscriptions of tenants that are not allowed are recorded too, so they are rejected
+		// rather than read with the default credential
+		for subscriptionID, tenantID := range tenants {
+			if ValidateTenantID(tenantID) != nil {
+				continue
+			}
+			c.subscriptionTenants[strings.ToLower(subscriptionID)] = tenantID
+		}
+		c.tenantsDiscovered = true
+	})
+}
+
+// listSubscriptionTenantsViaCLI maps subscriptions to tenants using `az account list`.
+func listSubscriptionTenantsViaCLI() (map[string]string, error) {
+	process := command.NewShellProcess("az", accountListTimeout)
+	process.ReturnErrOutput = false
+
+	output, err := process.Run("account list --all --output json")
+	if err != nil {
+		return nil, fmt.Errorf("failed to list accounts: %v", err)
+	}
+
+	var accounts []azAccount
+	if err := json.Unmarshal([]byte(output), &accounts); err != nil {
+		return nil, fmt.Errorf("failed to parse account list: %v", err)
+	}
+
+	tenants := make(map[string]string, len(accounts))
+	for _, account := range accounts {
+		if account.ID != "" && account.TenantID != "" {
+			tenants[account.ID] = account.TenantID
+		}
+	}
+	return tenants, nil
+}
+
+// getCredentialLocked returns the credential for a tenant, creating it on first use.
+// Must be called with c.mu held for writing.
+func (c *AzureClient) getCredentialLocked(tenantID string) (azcore.TokenCredential, error) {
+	if tenantID == "" {
+		return c.credential, nil
+	}
+
+	key := strings.ToLower(tenantID)
+	if cred, exists := c.tenantCredentials[key]; exists {
+		return cred, nil
+	}
+
+	cred, err := c.newTenantCredential(tenantID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create credential for tenant %s: %v", tenantID, err)
+	}
+
+	c.tenantCredentials[key] = cred
+	return cred, nil
+}
+
+// credentialForSubscription returns the credential to use for a subscription in the tenant of the call.
+func (c *AzureClient) credentialForSubscription(ctx context.Context, subscriptionID string) (azcore.TokenCredential, error) {
+	tenantID, err := c.tenantFor(ctx, subscriptionID)
+	if err != nil {
+		return nil, err
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.getCredentialLocked(tenantID)
+}
+
+// newDefaultTenantCredential creates a DefaultAzureCredential scoped to a single tenant.
+func newDefaultTenantCredential(tenantID string) (azcore.TokenCredential, error) {
+	return azidentity.NewDefaultAzureCredential(&azidentity.DefaultAzureCredentialOptions{
+		TenantID: tenantID,
+	})
+}
@@ -0,0 +1,264 @@
+package azureclient
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/Azure/aks-mcp/internal/config"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/policy"
+)
+
+// fakeTenantCredential is a token credential that records the tenant it was created for
+type fakeTenantCredential struct {
+	tenantID string
+}
+
+func (f *fakeTenantCredential) GetToken(ctx context.Context, options policy.TokenRequestOptions) (azcore.AccessToken, error) {
+	return azcore.AccessToken{Token: "fake-token-" + f.tenantID}, nil
+}
+
+// newTestTenantClient creates an AzureClient with fake tenant discovery and credentials
+func newTestTenantClient(t *testing.T, allowedTenants string, discovered map[string]string) (*AzureClient, map[string]int) {
+	t.Helper()
+
+	client, err := NewAzureClient(&config.ConfigData{AllowedTenants: allowedTenants})
+	if err != nil {
+		t.Fatalf("Failed to create Azure client: %v", err)
+	}
+
+	created := make(map[string]int)
+	client.newTenantCredential = func(tenantID string) (azcore.TokenCredential, error) {
+		created[tenantID]++
+		return &fakeTenantCredential{tenantID: tenantID}, nil
+	}
+	client.tenantLookup = func() (map[string]string, error) {
+		return discovered, nil
+	}
+
+	return client, created
+}
+
+func TestValidateTenantID(t *testing.T) {
+	valid := []string{"72f988bf-86f1-41af-91ab-2d7cd011db47", "contoso.onmicrosoft.com"}
+	for _, tenantID := range valid {
+		if err := ValidateTenantID(tenantID); err != nil {
+			t.Errorf("Expected %q to be valid, got %v", tenantID, err)
+		}
+	}
+
+	invalid := []string{"", "-tenant", "tenant; rm -rf /", "tenant id"}
+	for _, tenantID := range invalid {
+		if err := ValidateTenantID(tenantID); err == nil {
+			t.Errorf("Expected %q to be invalid", tenantID)
+		}
+	}
+}
+
+func TestGetTenantForSubscription_DiscoveredAndPerCall(t *testing.T) {
+	client, _ := newTestTenantClient(t, "", map[string]string{
+		"sub-a": "tenant-a",
+		"SUB-B": "tenant-b",
+	})
+
+	if got, _ := client.GetTenantForSubscription("sub-a"); got != "tenant-a" {
+		t.Errorf("Expected discovered tenant-a, got %q", got)
+	}
+	if got, _ := client.GetTenantForSubscription("sub-b"); got != "tenant-b" {
+		t.Errorf("Expected case-insensitive lookup to return tenant-b, got %q", got)
+	}
+	if got, _ := client.GetTenantForSubscription("sub-unknown"); got != "" {
+		t.Errorf("Expected unknown subscription to use the default tenant, got %q", got)
+	}
+
+	// The tenant of a call overrides the discovered one for that call only
+	ctx := WithTenant(context.Background(), "tenant-override")
+	if got, _ := client.tenantFor(ctx, "sub-a"); got != "tenant-override" {
+		t.Errorf("Expected the tenant of the call, got %q", got)
+	}
+	if got, _ := client.tenantFor(context.Background(), "sub-a"); got != "tenant-a" {
+		t.Errorf("Expected other calls to keep the discovered tenant-a, got %q", got)
+	}
+	if got, _ := client.GetTenantForSubscription("sub-a"); got != "tenant-a" {
+		t.Errorf("Expected the subscription tenant to be unchanged, got %q", got)
+	}
+
+	clients, err := client.GetOrCreateClientsForContext(ctx, "sub-a")
+	if err != nil {
+		t.Fatalf("Failed to create clients for the tenant of the call: %v", err)
+	}
+	if expected, _ := client.GetOrCreateClientsForTenantSubscription("tenant-override", "sub-a"); clients != expected {
+		t.Error("Expected the clients of the tenant of the call")
+	}
+}
+
+func TestCacheKey_PerTenant(t *testing.T) {
+	client, _ := newTestTenantClient(t, "", map[string]string{"sub-a": "tenant-a"})
+
+	discovered := client.CacheKey(context.Background(), "resource:cluster", "sub-a", "rg", "aks")
+	if discovered != "resource:cluster:tenant-a:sub-a:rg:aks" {
+		t.Errorf("Expected the key to include the discovered tenant, got %q", discovered)
+	}
+
+	// A call in another tenant must not be served data read with the discovered tenant's credential
+	override := client.CacheKey(WithTenant(context.Background(), "Tenant-Override"), "resource:cluster", "sub-a", "rg", "aks")
+	if override != "resource:cluster:tenant-override:sub-a:rg:aks" {
+		t.Errorf("Expected the key to include the tenant of the call, got %q", override)
+	}
+}
+
+func TestGetOrCreateClients_PerTenant(t *testing.T) {
+	client, created := newTestTenantClient(t, "", map[string]string{
+		"sub-a": "tenant-a",
+		"sub-b": "tenant-b",
+		"sub-c": "tenant-a",
+	})
+
+	clientsA, err := client.GetOrCreateClientsForSubscription("sub-a")
+	if err != nil {
+		t.Fatalf("Failed to create clients for sub-a: %v", err)
+	}
+	clientsB, err := client.GetOrCreateClientsForSubscription("sub-b")
+	if err != nil {
+		t.Fatalf("Failed to create clients for sub-b: %v", err)
+	}
+	if _, err := client.GetOrCreateClientsForSubscription("sub-c"); err != nil {
+		t.Fatalf("Failed to create clients for sub-c: %v", err)
+	}
+
+	if clientsA == clientsB {
+		t.Error("Expected distinct clients for subscriptions in different tenants")
+	}
+
+	again, err := client.GetOrCreateClientsForSubscription("sub-a")
+	if err != nil {
+		t.Fatalf("Failed to get clients for sub-a: %v", err)
+	}
+	if again != clientsA {
+		t.Error("Expected clients to be reused for the same tenant and subscription")
+	}
+
+	// One credential per tenant, shared across subscriptions in that tenant
+	if created["tenant-a"] != 1 || created["tenant-b"] != 1 {
+		t.Errorf("Expected one credential per tenant, got %v", created)
+	}
+
+	// The same subscription in another tenant gets its own clients
+	otherTenant, err := client.GetOrCreateClientsForTenantSubscription("tenant-b", "sub-a")
+	if err != nil {
+		t.Fatalf("Failed to create clients for sub-a in tenant-b: %v", err)
+	}
+	if otherTenant == clientsA {
+		t.Error("Expected clients to be keyed by tenant as well as subscription")
+	}
+}
+
+func TestCredentialForSubscription(t *testing.T) {
+	client, _ := newTestTenantClient(t, "", map[string]string{"sub-a": "tenant-a"})
+
+	cred, err := client.credentialForSubscription(context.Background(), "sub-a")
+	if err != nil {
+		t.Fatalf("Failed to get credential: %v", err)
+	}
+	fake, ok := cred.(*fakeTenantCredential)
+	if !ok || fake.tenantID != "tenant-a" {
+		t.Errorf("Expected credential for tenant-a, got %#v", cred)
+	}
+
+	cred, err = client.credentialForSubscription(context.Background(), "sub-unknown")
+	if err != nil {
+		t.Fatalf("Failed to get default credential: %v", err)
+	}
+	if cred != client.credential {
+		t.Error("Expected default credential for subscriptions with unknown tenant")
+	}
+}
+
+func TestAllowedTenants(t *testing.T) {
+	client, created := newTestTenantClient(t, "tenant-a, tenant-b", map[string]string{
+		"sub-a": "tenant-a",
+		"sub-x": "tenant-x",
+	})
+
+	if err := client.CheckTenant("tenant-y"); err == nil {
+		t.Error("Expected error for a tenant that is not allowed")
+	}
+	if err := client.CheckTenant("TENANT-B"); err != nil {
+		t.Errorf("Expected allowed tenant to match case-insensitively, got %v", err)
+	}
+
+	// Subscriptions discovered in tenants outside the allow-list are rejected
+	if _, err := client.GetTenantForSubscription("sub-x"); err == nil {
+		t.Error("Expected error for a subscription discovered in a tenant that is not allowed")
+	}
+	if _, err := client.credentialForSubscription(context.Background(), "sub-x"); err == nil {
+		t.Error("Expected no credential for a subscription discovered in a tenant that is not allowed")
+	}
+
+	if _, err := client.GetOrCreateClientsForTenantSubscription("tenant-x", "sub-x"); err == nil {
+		t.Error("Expected error when creating clients for a tenant that is not allowed")
+	}
+	if created["tenant-x"] != 0 {
+		t.Error("Expected no credential to be created for a tenant that is not allowed")
+	}
+}
+
+func TestAllowedTenants_OnlyDiscoveredTenantNotAllowed(t *testing.T) {
+	client, created := newTestTenantClient(t, "tenant-a", map[string]string{"sub-home": "tenant-home"})
+
+	// The subscription must not fall back to the default credential, which can reach its tenant
+	if _, err := client.GetTenantForSubscription("sub-home"); err == nil || !strings.Contains(err.Error(), "tenant tenant-home of subscription sub-home is not in the list of allowed tenants") {
+		t.Errorf("Expected the subscription's tenant to be rejected, got %v", err)
+	}
+	if _, err := client.credentialForSubscription(context.Background(), "sub-home"); err == nil {
+		t.Error("Expected no credential for the subscription")
+	}
+	if _, err := client.GetOrCreateClientsForSubscription("sub-home"); err == nil {
+		t.Error("Expected no clients for the subscription")
+	}
+	if _, err := client.GetOrCreateClientsForContext(context.Background(), "sub-home"); err == nil {
+		t.Error("Expected no clients for the subscription in the tenant of the call")
+	}
+	if len(created) != 0 {
+		t.Errorf("Expected no credentials to be created, got %v", created)
+	}
+}
+
+func TestGetTenantForSubscription_ConcurrentDiscovery(t *testing.T) {
+	client, _ := newTestTenantClient(t, "", nil)
+	release := make(chan struct{})
+	lookups := 0
+	client.tenantLookup = func() (map[string]string, error) {
+		lookups++
+		<-release
+		return map[string]string{"sub-a": "tenant-a"}, nil
+	}
+
+	// Callers that arrive while discovery runs wait for it instead of using the default tenant
+	results := make(chan string, 5)
+	var wg sync.WaitGroup
+	for range 5 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			tenantID, _ := client.GetTenantForSubscription("sub-a")
+			results <- tenantID
+		}()
+	}
+	time.Sleep(20 * time.Millisecond)
+	close(release)
+	wg.Wait()
+	close(results)
+
+	for tenantID := range results {
+		if tenantID != "tenant-a" {
+			t.Errorf("Expected tenant-a, got %q", tenantID)
+		}
+	}
+	if lookups != 1 {
+		t.Errorf("Expected one lookup, got %d", lookups)
+	}
+}
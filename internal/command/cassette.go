@@ -0,0 +1,187 @@
+package command
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+)
+
+// CassetteModeEnv selects how UseCassette behaves: "record" runs real processes and
+// saves them to the cassette, anything else replays the cassette.
+const CassetteModeEnv = "AKS_MCP_CASSETTE_MODE"
+
+// Interaction is a single recorded process run
+type Interaction struct {
+	Args     []string `json:"args"`
+	Stdout   string   `json:"stdout"`
+	Stderr   string   `json:"stderr"`
+	ExitCode int      `json:"exit_code"`
+}
+
+// Cassette is a set of recorded process runs
+type Cassette struct {
+	Interactions []Interaction `json:"interactions"`
+}
+
+// LoadCassette reads a cassette file
+func LoadCassette(path string) (*Cassette, error) {
+	data, err := os.ReadFile(path) // #nosec G304 -- cassette paths are provided by tests
+	if err != nil {
+		return nil, fmt.Errorf("failed to read cassette %s: %v", path, err)
+	}
+
+	var cassette Cassette
+	if err := json.Unmarshal(data, &cassette); err != nil {
+		return nil, fmt.Errorf("failed to parse cassette %s: %v", path, err)
+	}
+	return &cassette, nil
+}
+
+// Save writes the cassette to a file, creating parent directories as needed
+func (c *Cassette) Save(path string) error {
+	data, err := json.MarshalIndent(c, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal cassette: %v", err)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0o750); err != nil {
+		return fmt.Errorf("failed to create cassette directory: %v", err)
+	}
+	if err := os.WriteFile(path, append(data, '\n'), 0o600); err != nil {
+		return fmt.Errorf("failed to write cassette %s: %v", path, err)
+	}
+	return nil
+}
+
+// RecordingRunner runs processes with another runner and records every run
+type RecordingRunner struct {
+	runner   Runner
+	mu       sync.Mutex
+	cassette Cassette
+}
+
+// NewRecordingRunner creates a runner that records the runs made through runner
+func NewRecordingRunner(runner Runner) *RecordingRunner {
+	return &RecordingRunner{runner: runner}
+}
+
+// Run runs argv and records its output and exit code
+func (r *RecordingRunner) Run(ctx context.Context, argv []string) (*Result, error) {
+	result, err := r.runner.Run(ctx, argv)
+	if err != nil {
+		return result, err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.cassette.Interactions = append(r.cassette.Interactions, Interaction{
+		Args:     append([]string(nil), argv...),
+		Stdout:   result.Stdout,
+		Stderr:   result.Stderr,
+		ExitCode: result.ExitCode,
+	})
+	return result, nil
+}
+
+// LookPath delegates to the wrapped runner
+func (r *RecordingRunner) LookPath(file string) (string, error) {
+	return r.runner.LookPath(file)
+}
+
+// Cassette returns a copy of the runs recorded so far
+func (r *RecordingRunner) Cassette() *Cassette {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return &Cassette{Interactions: append([]Interaction(nil), r.cassette.Interactions...)}
+}
+
+// ReplayRunner serves recorded runs instead of running processes.
+// Runs are matched by exact argv; repeated runs of the same argv are served in
+// recorded order, and the last matching run is reused once they are exhausted.
+type ReplayRunner struct {
+	mu           sync.Mutex
+	interactions []Interaction
+	served       map[string]int
+}
+
+// NewReplayRunner creates a runner that replays the given cassette
+func NewReplayRunner(cassette *Cassette) *ReplayRunner {
+	return &ReplayRunner{
+		interactions: cassette.Interactions,
+		served:       make(map[string]int),
+	}
+}
+
+// Run returns the recorded result for argv
+func (r *ReplayRunner) Run(ctx context.Context, argv []string) (*Result, error) {
+	key := argvKey(argv)
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var matches []Interaction
+	for _, interaction := range r.interactions {
+		if argvKey(interaction.Args) == key {
+			matches = append(matches, interaction)
+		}
+	}
+	if len(matches) == 0 {
+		return nil, fmt.Errorf("no recorded interaction for: %s", strings.Join(argv, " "))
+	}
+
+	index := r.served[key]
+	if index >= len(matches) {
+		index = len(matches) - 1
+	}
+	r.served[key]++
+
+	interaction := matches[index]
+	return &Result{
+		Stdout:   interaction.Stdout,
+		Stderr:   interaction.Stderr,
+		ExitCode: interaction.ExitCode,
+	}, nil
+}
+
+// LookPath reports every executable as available, since no process is run
+func (r *ReplayRunner) LookPath(file string) (string, error) {
+	return file, nil
+}
+
+// argvKey builds a comparable key for an argv
+func argvKey(argv []string) string {
+	data, _ := json.Marshal(argv)
+	return string(data)
+}
+
+// UseCassette installs a cassette-backed default runner for the cassette file at path.
+// When CassetteModeEnv is "record", real processes are run and the cassette is written
+// when stop is called; otherwise the cassette is replayed. Review recorded cassettes for
+// secrets before committing them.
+func UseCassette(path string) (stop func() error, err error) {
+	if os.Getenv(CassetteModeEnv) == "record" {
+		recorder := NewRecordingRunner(ExecRunner{})
+		restore := SetDefaultRunner(recorder)
+		return func() error {
+			restore()
+			return recorder.Cassette().Save(path)
+		}, nil
+	}
+
+	cassette, err := LoadCassette(path)
+	if err != nil {
+		return nil, err
+	}
+
+	restore := SetDefaultRunner(NewReplayRunner(cassette))
+	return func() error {
+		restore()
+		return nil
+	}, nil
+}
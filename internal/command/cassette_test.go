@@ -0,0 +1,175 @@
+package command
+
+import (
+	"context"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// stubRunner returns fixed results without running processes
+type stubRunner struct {
+	results map[string]*Result
+	calls   int
+}
+
+func (s *stubRunner) Run(ctx context.Context, argv []string) (*Result, error) {
+	s.calls++
+	if result, ok := s.results[strings.Join(argv, " ")]; ok {
+		return result, nil
+	}
+	return &Result{Stderr: "unknown command", ExitCode: 1}, nil
+}
+
+func (s *stubRunner) LookPath(file string) (string, error) {
+	return "/usr/bin/" + file, nil
+}
+
+func TestShellProcess_UsesRunner(t *testing.T) {
+	runner := &stubRunner{results: map[string]*Result{
+		"az aks show --name my cluster": {Stdout: "{\"name\": \"my cluster\"}\n"},
+	}}
+
+	process := NewShellProcess("az", 10)
+	process.Runner = runner
+
+	// Quoted arguments are split like a shell would
+	output, err := process.Run(`aks show --name "my cluster"`)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if output != "{\"name\": \"my cluster\"}\n" {
+		t.Errorf("Unexpected output: %q", output)
+	}
+
+	process.StripNewlines = true
+	output, _ = process.Run(`aks show --name "my cluster"`)
+	if output != "{\"name\": \"my cluster\"}" {
+		t.Errorf("Expected trailing newline to be stripped, got %q", output)
+	}
+}
+
+func TestShellProcess_NonZeroExitCode(t *testing.T) {
+	runner := &stubRunner{}
+
+	process := NewShellProcess("az", 10)
+	process.Runner = runner
+
+	// stderr is returned as output by default
+	output, err := process.Run("aks missing")
+	if err != nil {
+		t.Fatalf("Expected stderr to be returned as output, got error %v", err)
+	}
+	if output != "unknown command" {
+		t.Errorf("Expected stderr output, got %q", output)
+	}
+
+	process.ReturnErrOutput = false
+	if _, err := process.Run("aks missing"); err == nil || err.Error() != "exit status 1" {
+		t.Errorf("Expected exit status error, got %v", err)
+	}
+}
+
+func TestRecordAndReplay(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "cassettes", "aks.json")
+
+	stub := &stubRunner{results: map[string]*Result{
+		"az aks list":               {Stdout: "[]"},
+		"az aks show --name absent": {Stderr: "ResourceNotFound", ExitCode: 3},
+	}}
+	recorder := NewRecordingRunner(stub)
+
+	process := NewShellProcess("az", 10)
+	process.Runner = recorder
+	process.ReturnErrOutput = false
+	_, _ = process.Run("aks list")
+	_, _ = process.Run("aks show --name absent")
+
+	if err := recorder.Cassette().Save(path); err != nil {
+		t.Fatalf("Failed to save cassette: %v", err)
+	}
+
+	cassette, err := LoadCassette(path)
+	if err != nil {
+		t.Fatalf("Failed to load cassette: %v", err)
+	}
+	if len(cassette.Interactions) != 2 {
+		t.Fatalf("Expected 2 recorded interactions, got %d", len(cassette.Interactions))
+	}
+	recorded := cassette.Interactions[1]
+	if strings.Join(recorded.Args, " ") != "az aks show --name absent" || recorded.Stderr != "ResourceNotFound" || recorded.ExitCode != 3 {
+		t.Errorf("Unexpected recorded interaction: %+v", recorded)
+	}
+
+	// Replay serves the recorded results without running anything
+	process.Runner = NewReplayRunner(cassette)
+	output, err := process.Run("aks list")
+	if err != nil || output != "[]" {
+		t.Errorf("Expected replayed output [], got %q, %v", output, err)
+	}
+	if _, err := process.Run("aks show --name absent"); err == nil || err.Error() != "exit status 3" {
+		t.Errorf("Expected replayed exit code, got %v", err)
+	}
+	if _, err := process.Run("aks show --name other"); err == nil || !strings.Contains(err.Error(), "no recorded interaction") {
+		t.Errorf("Expected error for unrecorded command, got %v", err)
+	}
+	if stub.calls != 2 {
+		t.Errorf("Expected replay not to run processes, got %d runs", stub.calls)
+	}
+}
+
+func TestReplayRunner_RepeatedCommands(t *testing.T) {
+	runner := NewReplayRunner(&Cassette{Interactions: []Interaction{
+		{Args: []string{"az", "aks", "show"}, Stdout: "Creating"},
+		{Args: []string{"az", "aks", "show"}, Stdout: "Succeeded"},
+	}})
+
+	var outputs []string
+	for i := 0; i < 3; i++ {
+		result, err := runner.Run(context.Background(), []string{"az", "aks", "show"})
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		outputs = append(outputs, result.Stdout)
+	}
+
+	if strings.Join(outputs, ",") != "Creating,Succeeded,Succeeded" {
+		t.Errorf("Expected runs in recorded order with the last one reused, got %v", outputs)
+	}
+}
+
+func TestUseCassette(t *testing.T) {
+	t.Setenv(CassetteModeEnv, "")
+
+	path := filepath.Join(t.TempDir(), "version.json")
+	cassette := &Cassette{Interactions: []Interaction{
+		{Args: []string{"az", "version"}, Stdout: "2.60.0"},
+	}}
+	if err := cassette.Save(path); err != nil {
+		t.Fatalf("Failed to save cassette: %v", err)
+	}
+
+	stop, err := UseCassette(path)
+	if err != nil {
+		t.Fatalf("Failed to use cassette: %v", err)
+	}
+
+	output, err := NewShellProcess("az", 10).Run("version")
+	if err != nil || output != "2.60.0" {
+		t.Errorf("Expected replayed output, got %q, %v", output, err)
+	}
+	if _, err := LookPath("az"); err != nil {
+		t.Errorf("Expected replay to report az as installed, got %v", err)
+	}
+
+	if err := stop(); err != nil {
+		t.Fatalf("Failed to stop cassette: %v", err)
+	}
+	if _, ok := DefaultRunner().(ExecRunner); !ok {
+		t.Errorf("Expected default runner to be restored, got %T", DefaultRunner())
+	}
+
+	if _, err := UseCassette(filepath.Join(t.TempDir(), "missing.json")); err == nil {
+		t.Error("Expected error for a missing cassette in replay mode")
+	}
+}
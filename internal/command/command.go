@@ -1,9 +1,8 @@
 package command
 
 import (
-	"bytes"
 	"context"
-	"os/exec"
+	"fmt"
 	"strings"
 	"time"
 
@@ -15,7 +14,8 @@ type ShellProcess struct {
 	Command         string
 	StripNewlines   bool
 	ReturnErrOutput bool
-	Timeout         int // in seconds
+	Timeout         int    // in seconds
+	Runner          Runner // nil uses DefaultRunner()
 }
 
 // NewShellProcess creates a new ShellProcess
@@ -40,39 +40,41 @@ func (s *ShellProcess) Run(args string) (string, error) {
 	return s.Exec(commands)
 }
 
+// RunArgs executes the command with arguments that are already split,
+// without shell-style parsing of quotes
+func (s *ShellProcess) RunArgs(args []string) (string, error) {
+	return s.execArgv(append([]string{s.Command}, args...))
+}
+
 // Exec runs the commands and returns the output
 func (s *ShellProcess) Exec(commands string) (string, error) {
-	// Create a context with timeout
-	ctx, cancel := context.WithTimeout(context.Background(), time.Duration(s.Timeout)*time.Second)
-	defer cancel()
-
-	var cmd *exec.Cmd
-
 	// Parse the command string with proper handling of quotes
 	parts, err := shlex.Split(commands)
 	if err != nil {
 		return "", err
 	}
 
-	if len(parts) > 1 {
-		// Command with arguments
-		// #nosec G204: Subprocess launched with a potential tainted input or cmd arguments
-		cmd = exec.CommandContext(ctx, parts[0], parts[1:]...)
-	} else if len(parts) == 1 {
-		// Single command without arguments
-		// #nosec G204: Subprocess launched with a potential tainted input or cmd arguments
-		cmd = exec.CommandContext(ctx, parts[0])
-	} else {
+	return s.execArgv(parts)
+}
+
+// execArgv runs argv with the configured runner and returns the output
+func (s *ShellProcess) execArgv(parts []string) (string, error) {
+	if len(parts) == 0 {
 		// Empty command
 		return "", nil
 	}
 
-	var stdout, stderr bytes.Buffer
-	cmd.Stdout = &stdout
-	cmd.Stderr = &stderr
+	// Create a context with timeout
+	ctx, cancel := context.WithTimeout(context.Background(), time.Duration(s.Timeout)*time.Second)
+	defer cancel()
+
+	runner := s.Runner
+	if runner == nil {
+		runner = DefaultRunner()
+	}
 
 	// Execute the command
-	err = cmd.Run()
+	result, err := runner.Run(ctx, parts)
 
 	// Check for timeout
 	if ctx.Err() == context.DeadlineExceeded {
@@ -80,15 +82,18 @@ func (s *ShellProcess) Exec(commands string) (string, error) {
 	}
 
 	// Handle errors
+	if err == nil && result.ExitCode != 0 {
+		err = fmt.Errorf("exit status %d", result.ExitCode)
+	}
 	if err != nil {
-		if s.ReturnErrOutput && stderr.Len() > 0 {
-			return stderr.String(), nil
+		if s.ReturnErrOutput && result != nil && result.Stderr != "" {
+			return result.Stderr, nil
 		}
 		return "", err
 	}
 
 	// Process output
-	output := stdout.String()
+	output := result.Stdout
 	if s.StripNewlines {
 		output = strings.TrimSpace(output)
 	}
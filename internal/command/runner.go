@@ -0,0 +1,92 @@
+package command
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"os/exec"
+	"sync"
+)
+
+// Result is the outcome of running a process to completion
+type Result struct {
+	Stdout   string
+	Stderr   string
+	ExitCode int
+}
+
+// Runner runs processes for ShellProcess.
+// Run returns an error only when the process could not be run; a process that ran
+// and exited with a non-zero code is reported through Result.ExitCode.
+type Runner interface {
+	Run(ctx context.Context, argv []string) (*Result, error)
+	LookPath(file string) (string, error)
+}
+
+// ExecRunner runs processes on the local machine
+type ExecRunner struct{}
+
+// Run executes argv with exec.CommandContext
+func (ExecRunner) Run(ctx context.Context, argv []string) (*Result, error) {
+	// #nosec G204: Subprocess launched with a potential tainted input or cmd arguments
+	cmd := exec.CommandContext(ctx, argv[0], argv[1:]...)
+
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+
+	err := cmd.Run()
+
+	result := &Result{
+		Stdout: stdout.String(),
+		Stderr: stderr.String(),
+	}
+
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) && exitErr.ExitCode() >= 0 {
+		result.ExitCode = exitErr.ExitCode()
+		return result, nil
+	}
+	if err != nil {
+		return result, err
+	}
+
+	return result, nil
+}
+
+// LookPath searches for an executable in the system PATH
+func (ExecRunner) LookPath(file string) (string, error) {
+	return exec.LookPath(file)
+}
+
+var (
+	defaultRunnerMu sync.RWMutex
+	defaultRunner   Runner = ExecRunner{}
+)
+
+// DefaultRunner returns the runner used by ShellProcess instances without their own Runner
+func DefaultRunner() Runner {
+	defaultRunnerMu.RLock()
+	defer defaultRunnerMu.RUnlock()
+	return defaultRunner
+}
+
+// SetDefaultRunner replaces the default runner and returns a function that restores the previous one.
+// It is intended for tests that exercise code which creates its own ShellProcess.
+func SetDefaultRunner(runner Runner) func() {
+	defaultRunnerMu.Lock()
+	defer defaultRunnerMu.Unlock()
+
+	previous := defaultRunner
+	defaultRunner = runner
+	return func() {
+		defaultRunnerMu.Lock()
+		defer defaultRunnerMu.Unlock()
+		defaultRunner = previous
+	}
+}
+
+// LookPath checks whether an executable is available using the default runner
+func LookPath(file string) (string, error) {
+	return DefaultRunner().LookPath(file)
+}
@@ -1,8 +1,11 @@
 package advisor
 
 import (
+	"encoding/json"
+	"path/filepath"
 	"testing"
 
+	"github.com/Azure/aks-mcp/internal/command"
 	"github.com/Azure/aks-mcp/internal/config"
 )
 
@@ -280,3 +283,40 @@ func containsInMiddle(s, substr string) bool {
 	}
 	return false
 }
+
+func TestHandleAdvisorRecommendationListReplay(t *testing.T) {
+	stop, err := command.UseCassette(filepath.Join("testdata", "cassettes", "advisor_list.json"))
+	if err != nil {
+		t.Fatalf("Failed to load cassette: %v", err)
+	}
+	defer func() {
+		if err := stop(); err != nil {
+			t.Errorf("Failed to save cassette: %v", err)
+		}
+	}()
+
+	cfg := config.NewConfig()
+	params := map[string]interface{}{
+		"operation":       "list",
+		"subscription_id": "00000000-0000-0000-0000-000000000000",
+		"cluster_names":   "test-cluster",
+	}
+
+	result, err := HandleAdvisorRecommendation(params, cfg)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	var summaries []AKSRecommendationSummary
+	if err := json.Unmarshal([]byte(result), &summaries); err != nil {
+		t.Fatalf("Failed to parse result: %v", err)
+	}
+
+	// The storage recommendation and the other cluster are filtered out
+	if len(summaries) != 1 {
+		t.Fatalf("Expected 1 recommendation, got %d", len(summaries))
+	}
+	if summaries[0].ClusterName != "test-cluster" || summaries[0].Category != "Cost" {
+		t.Errorf("Unexpected recommendation: %+v", summaries[0])
+	}
+}
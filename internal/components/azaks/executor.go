@@ -4,17 +4,26 @@ import (
 	"fmt"
 	"strings"
 
+	"github.com/Azure/aks-mcp/internal/azureclient"
 	"github.com/Azure/aks-mcp/internal/command"
 	"github.com/Azure/aks-mcp/internal/config"
 	"github.com/Azure/aks-mcp/internal/security"
+	"github.com/google/shlex"
 )
 
 // AksOperationsExecutor handles execution of AKS operations
-type AksOperationsExecutor struct{}
+type AksOperationsExecutor struct {
+	azClient   *azureclient.AzureClient
+	operations *OperationTracker
+}
 
-// NewAksOperationsExecutor creates a new AksOperationsExecutor
-func NewAksOperationsExecutor() *AksOperationsExecutor {
-	return &AksOperationsExecutor{}
+// NewAksOperationsExecutor creates a new AksOperationsExecutor.
+// The Azure client is used to check on operations started with --no-wait.
+func NewAksOperationsExecutor(azClient *azureclient.AzureClient) *AksOperationsExecutor {
+	return &AksOperationsExecutor{
+		azClient:   azClient,
+		operations: NewOperationTracker(),
+	}
 }
 
 // Execute handles the AKS operations
@@ -25,7 +34,7 @@ func (e *AksOperationsExecutor) Execute(params map[string]interface{}, cfg *conf
 		return "", fmt.Errorf("missing or invalid 'operation' parameter")
 	}
 
-	// Parse args parameter
+	// Parse args parameter, the raw escape hatch
 	args, ok := params["args"].(string)
 	if !ok {
 		args = ""
@@ -36,48 +45,61 @@ func (e *AksOperationsExecutor) Execute(params map[string]interface{}, cfg *conf
 		return "", err
 	}
 
+	// operation_status is answered by the server instead of an az command
+	if operation == string(OpOperationStatus) {
+		if strings.TrimSpace(args) != "" {
+			return "", fmt.Errorf("the 'args' parameter is not supported by operation '%s'", operation)
+		}
+		return e.operationStatus(params, cfg)
+	}
+
+	// Raw args bypass typed validation, so they are gated by access level
+	var extraArgs []string
+	if strings.TrimSpace(args) != "" {
+		if err := ValidateArgsAccess(cfg); err != nil {
+			return "", err
+		}
+		parts, err := shlex.Split(args)
+		if err != nil {
+			return "", fmt.Errorf("failed to parse args: %v", err)
+		}
+		extraArgs = parts
+	}
+
 	// Map operation to Azure CLI command
 	baseCommand, err := MapOperationToCommand(operation)
 	if err != nil {
 		return "", err
 	}
 
-	// Build full command
-	fullCommand := baseCommand
-	if args != "" {
-		fullCommand += " " + args
+	// Validate typed parameters and build the argument list
+	opArgs, err := BuildOperationArgs(operation, params, extraArgs)
+	if err != nil {
+		return "", err
 	}
 
+	argv := append(strings.Fields(baseCommand), opArgs...)
+
 	// Validate the command against security settings
 	validator := security.NewValidator(cfg.SecurityConfig)
-	err = validator.ValidateCommand(fullCommand, security.CommandTypeAz)
+	err = validator.ValidateCommand(strings.Join(argv, " "), security.CommandTypeAz)
 	if err != nil {
 		return "", err
 	}
 
-	// Extract binary name and arguments from command
-	cmdParts := strings.Fields(fullCommand)
-	if len(cmdParts) == 0 {
-		return "", fmt.Errorf("empty command")
-	}
-
-	// Use the first part as the binary name
-	binaryName := cmdParts[0]
-
-	// The rest of the command becomes the arguments
-	cmdArgs := ""
-	if len(cmdParts) > 1 {
-		cmdArgs = strings.Join(cmdParts[1:], " ")
-	}
-
 	// If the command is not an az command, return an error
-	if binaryName != "az" {
+	if argv[0] != "az" {
 		return "", fmt.Errorf("command must start with 'az'")
 	}
 
-	// Execute the command
-	process := command.NewShellProcess(binaryName, cfg.Timeout)
-	return process.Run(cmdArgs)
+	// Operations started with --no-wait are tracked so their status can be checked later
+	if _, tracked := trackedOperations[operation]; tracked && hasFlag(argv, "--no-wait") {
+		return e.startTrackedOperation(operation, argv, cfg)
+	}
+
+	// Execute the command with the already split arguments
+	process := command.NewShellProcess(argv[0], cfg.Timeout)
+	return process.RunArgs(argv[1:])
 }
 
 // ExecuteSpecificCommand executes a specific operation with the given arguments (for backward compatibility)
@@ -0,0 +1,294 @@
+package azaks
+
+import (
+	"context"
+	"encoding/json"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Azure/aks-mcp/internal/azureclient"
+	"github.com/Azure/aks-mcp/internal/azureclient/fakearm"
+	"github.com/Azure/aks-mcp/internal/azureclient/fakearm/armtest"
+	"github.com/Azure/aks-mcp/internal/command"
+	"github.com/Azure/aks-mcp/internal/config"
+)
+
+// useCassette replays the named cassette from testdata for the duration of the test.
+// Set AKS_MCP_CASSETTE_MODE=record to re-record it against a real az CLI.
+func useCassette(t *testing.T, name string) {
+	t.Helper()
+	stop, err := command.UseCassette(filepath.Join("testdata", "cassettes", name+".json"))
+	if err != nil {
+		t.Fatalf("Failed to load cassette: %v", err)
+	}
+	t.Cleanup(func() {
+		if err := stop(); err != nil {
+			t.Errorf("Failed to save cassette: %v", err)
+		}
+	})
+}
+
+func TestAksOperationsExecutor_Execute_Replay(t *testing.T) {
+	useCassette(t, "aks_show")
+
+	cfg := config.NewConfig()
+	executor := NewAksOperationsExecutor(nil)
+
+	output, err := executor.Execute(map[string]interface{}{
+		"operation":      "show",
+		"cluster_name":   "test-cluster",
+		"resource_group": "test-rg",
+	}, cfg)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	var cluster map[string]interface{}
+	if err := json.Unmarshal([]byte(output), &cluster); err != nil {
+		t.Fatalf("Expected JSON output, got %q: %v", output, err)
+	}
+	if cluster["name"] != "test-cluster" {
+		t.Errorf("Expected test-cluster, got %v", cluster["name"])
+	}
+
+	// A failing az command returns its stderr
+	output, err = executor.Execute(map[string]interface{}{
+		"operation":      "show",
+		"cluster_name":   "missing-cluster",
+		"resource_group": "test-rg",
+	}, cfg)
+	if err != nil {
+		t.Fatalf("Expected stderr as output, got error %v", err)
+	}
+	if !strings.Contains(output, "ResourceNotFound") {
+		t.Errorf("Expected ResourceNotFound in output, got %q", output)
+	}
+
+	// Write operations are rejected before anything runs in readonly mode
+	if _, err := executor.Execute(map[string]interface{}{
+		"operation":      "scale",
+		"cluster_name":   "test-cluster",
+		"resource_group": "test-rg",
+		"node_count":     float64(5),
+	}, cfg); err == nil {
+		t.Error("Expected readonly access level to reject scale")
+	}
+
+	// The raw args escape hatch is not available in readonly mode
+	if _, err := executor.Execute(map[string]interface{}{
+		"operation": "show",
+		"args":      "--name test-cluster --resource-group test-rg",
+	}, cfg); err == nil || !strings.Contains(err.Error(), "'args' parameter requires") {
+		t.Errorf("Expected args to be rejected in readonly mode, got %v", err)
+	}
+}
+
+func TestAksOperationsExecutor_Execute_ArgsEscapeHatch(t *testing.T) {
+	useCassette(t, "aks_nodepool_add")
+
+	cfg := config.NewConfig()
+	cfg.AccessLevel = "readwrite"
+	cfg.SecurityConfig.AccessLevel = "readwrite"
+	executor := NewAksOperationsExecutor(nil)
+
+	// Typed parameters come first, raw args are appended verbatim, including quoted values
+	output, err := executor.Execute(map[string]interface{}{
+		"operation":      "nodepool-add",
+		"cluster_name":   "test-cluster",
+		"resource_group": "test-rg",
+		"nodepool_name":  "userpool",
+		"node_count":     float64(2),
+		"mode":           "user",
+		"args":           `--labels "team=payments tier=backend"`,
+	}, cfg)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if !strings.Contains(output, "\"name\": \"userpool\"") {
+		t.Errorf("Expected new node pool in output, got %q", output)
+	}
+
+	// A typed parameter cannot be repeated in args
+	_, err = executor.Execute(map[string]interface{}{
+		"operation":      "nodepool-scale",
+		"cluster_name":   "test-cluster",
+		"resource_group": "test-rg",
+		"nodepool_name":  "userpool",
+		"node_count":     float64(3),
+		"args":           "-c 4",
+	}, cfg)
+	if err == nil || !strings.Contains(err.Error(), "conflicts") {
+		t.Errorf("Expected conflict error, got %v", err)
+	}
+}
+
+func TestAksOperationsExecutor_NoWaitAndOperationStatus(t *testing.T) {
+	useCassette(t, "aks_nodepool_upgrade_no_wait")
+
+	srv, azClient, _ := armtest.NewClient(t)
+
+	cfg := config.NewConfig()
+	cfg.AccessLevel = "readwrite"
+	cfg.SecurityConfig.AccessLevel = "readwrite"
+	executor := NewAksOperationsExecutor(azClient)
+
+	// Starting with no_wait returns a handle instead of blocking until the upgrade finishes
+	output, err := executor.Execute(map[string]interface{}{
+		"operation":          "nodepool-upgrade",
+		"cluster_name":       "test-cluster",
+		"resource_group":     "test-rg",
+		"nodepool_name":      "nodepool1",
+		"kubernetes_version": "1.31.1",
+		"yes":                true,
+		"no_wait":            true,
+	}, cfg)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	var handle OperationHandle
+	if err := json.Unmarshal([]byte(output), &handle); err != nil {
+		t.Fatalf("Expected an operation handle, got %q: %v", output, err)
+	}
+	if !strings.HasPrefix(handle.ID, "op-") || handle.SubscriptionID != fakearm.SubscriptionID || handle.NodepoolName != "nodepool1" {
+		t.Fatalf("Unexpected operation handle: %+v", handle)
+	}
+
+	poolPath := fakearm.ClusterResourceID + "/agentPools/nodepool1"
+	if err := srv.SetResponse(poolPath, map[string]interface{}{
+		"name":       "nodepool1",
+		"properties": map[string]interface{}{"provisioningState": "Upgrading", "orchestratorVersion": "1.31.1", "currentOrchestratorVersion": "1.30.4"},
+	}); err != nil {
+		t.Fatalf("Failed to set response: %v", err)
+	}
+	if err := srv.SetResponse(poolPath+"/operations/latest", map[string]interface{}{
+		"status":          "InProgress",
+		"startTime":       handle.StartedAt,
+		"percentComplete": 40,
+	}); err != nil {
+		t.Fatalf("Failed to set response: %v", err)
+	}
+
+	status := checkOperationStatus(t, executor, map[string]interface{}{"operation_id": handle.ID}, cfg)
+	if status.State != "InProgress" || status.Done || status.Nodepool == nil || status.Nodepool.ProvisioningState != "Upgrading" {
+		t.Errorf("Expected the upgrade to be in progress, got %+v", status)
+	}
+
+	// Once ARM reports the operation finished, the status is final
+	if err := srv.SetResponse(poolPath+"/operations/latest", map[string]interface{}{
+		"status":    "Succeeded",
+		"startTime": handle.StartedAt,
+	}); err != nil {
+		t.Fatalf("Failed to set response: %v", err)
+	}
+	status = checkOperationStatus(t, executor, map[string]interface{}{"operation_id": handle.ID}, cfg)
+	if status.State != "Succeeded" || !status.Done {
+		t.Errorf("Expected the upgrade to have succeeded, got %+v", status)
+	}
+
+	// Status can also be checked by cluster without a handle, here with a subscription ID so no lookup is needed
+	status = checkOperationStatus(t, executor, map[string]interface{}{
+		"cluster_name":   "test-cluster",
+		"resource_group": "test-rg",
+		"subscription":   fakearm.SubscriptionID,
+	}, cfg)
+	if status.Cluster == nil || status.Cluster.ProvisioningState != "Succeeded" || status.AsyncOperation == nil {
+		t.Errorf("Expected cluster status and latest operation, got %+v", status)
+	}
+
+	if _, err := executor.Execute(map[string]interface{}{
+		"operation":    "operation_status",
+		"operation_id": "op-0000000000000000",
+	}, cfg); err == nil || !strings.Contains(err.Error(), "unknown operation_id") {
+		t.Errorf("Expected error for an unknown operation_id, got %v", err)
+	}
+}
+
+// noSubscriptionRunner fails az account show, as when the az CLI is not logged in, and records every run
+type noSubscriptionRunner struct {
+	runs []string
+}
+
+func (r *noSubscriptionRunner) Run(_ context.Context, argv []string) (*command.Result, error) {
+	r.runs = append(r.runs, strings.Join(argv, " "))
+	if len(argv) > 2 && argv[1] == "account" && argv[2] == "show" {
+		return &command.Result{Stderr: "ERROR: Please run 'az login' to setup account.", ExitCode: 1}, nil
+	}
+	return &command.Result{Stdout: "{}"}, nil
+}
+
+func (r *noSubscriptionRunner) LookPath(file string) (string, error) {
+	return file, nil
+}
+
+func TestAksOperationsExecutor_NoWaitUnresolvedSubscription(t *testing.T) {
+	runner := &noSubscriptionRunner{}
+	defer command.SetDefaultRunner(runner)()
+
+	cfg := config.NewConfig()
+	cfg.AccessLevel = "readwrite"
+	cfg.SecurityConfig.AccessLevel = "readwrite"
+
+	_, err := NewAksOperationsExecutor(nil).Execute(map[string]interface{}{
+		"operation":      "nodepool-scale",
+		"cluster_name":   "test-cluster",
+		"resource_group": "test-rg",
+		"nodepool_name":  "nodepool1",
+		"node_count":     float64(3),
+		"no_wait":        true,
+	}, cfg)
+	if err == nil || !strings.Contains(err.Error(), "it was not started") {
+		t.Errorf("Expected the operation not to start without a subscription, got %v", err)
+	}
+	for _, run := range runner.runs {
+		if strings.HasPrefix(run, "az aks") {
+			t.Errorf("Expected no az aks command to run, got %q", run)
+		}
+	}
+}
+
+func TestOperationState(t *testing.T) {
+	startedAt := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	before := startedAt.Add(-time.Hour)
+	after := startedAt.Add(10 * time.Second)
+	pool := &ResourceStatus{ProvisioningState: "Upgrading"}
+
+	tests := []struct {
+		name      string
+		startedAt time.Time
+		async     *azureclient.AKSOperationStatus
+		want      string
+	}{
+		{"operation of the handle", startedAt, &azureclient.AKSOperationStatus{Status: "Succeeded", StartTime: &after}, "Succeeded"},
+		{"earlier operation", startedAt, &azureclient.AKSOperationStatus{Status: "Succeeded", StartTime: &before}, "InProgress"},
+		{"operation without a start time", startedAt, &azureclient.AKSOperationStatus{Status: "Failed"}, "InProgress"},
+		{"latest operation without a handle", time.Time{}, &azureclient.AKSOperationStatus{Status: "Failed"}, "Failed"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handle := OperationHandle{Operation: "nodepool-upgrade", StartedAt: tt.startedAt}
+			if got := operationState(handle, pool, tt.async); got != tt.want {
+				t.Errorf("operationState() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+// checkOperationStatus runs operation_status and decodes its result
+func checkOperationStatus(t *testing.T, executor *AksOperationsExecutor, params map[string]interface{}, cfg *config.ConfigData) *OperationStatus {
+	t.Helper()
+
+	params["operation"] = "operation_status"
+	output, err := executor.Execute(params, cfg)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	var status OperationStatus
+	if err := json.Unmarshal([]byte(output), &status); err != nil {
+		t.Fatalf("Expected an operation status, got %q: %v", output, err)
+	}
+	return &status
+}
@@ -0,0 +1,379 @@
+package azaks
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Azure/aks-mcp/internal/azureclient"
+	"github.com/Azure/aks-mcp/internal/command"
+	"github.com/Azure/aks-mcp/internal/config"
+)
+
+// maxTrackedOperations bounds how many operation handles are kept in memory
+const maxTrackedOperations = 100
+
+// trackedOperations lists the write operations that can run with --no-wait,
+// mapped to whether they act on a node pool rather than the whole cluster
+var trackedOperations = map[string]bool{
+	string(OpClusterCreate):   false,
+	string(OpClusterDelete):   false,
+	string(OpClusterScale):    false,
+	string(OpClusterUpdate):   false,
+	string(OpClusterUpgrade):  false,
+	string(OpClusterStart):    false,
+	string(OpClusterStop):     false,
+	string(OpNodepoolAdd):     true,
+	string(OpNodepoolDelete):  true,
+	string(OpNodepoolScale):   true,
+	string(OpNodepoolUpgrade): true,
+	string(OpNodepoolStart):   true,
+	string(OpNodepoolStop):    true,
+}
+
+// subscriptionIDPattern matches subscription IDs, as opposed to subscription names
+var subscriptionIDPattern = regexp.MustCompile(`^[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}$`)
+
+// OperationHandle identifies a write operation that was started without waiting for it to finish
+type OperationHandle struct {
+	ID             string    `json:"operation_id"`
+	Operation      string    `json:"operation"`
+	SubscriptionID string    `json:"subscription_id"`
+	ResourceGroup  string    `json:"resource_group"`
+	ClusterName    string    `json:"cluster_name"`
+	NodepoolName   string    `json:"nodepool_name,omitempty"`
+	StartedAt      time.Time `json:"started_at"`
+}
+
+// OperationTracker keeps the handles of operations started with --no-wait so their
+// status can be checked in later calls
+type OperationTracker struct {
+	mu      sync.Mutex
+	handles map[string]*OperationHandle
+}
+
+// NewOperationTracker creates an empty OperationTracker
+func NewOperationTracker() *OperationTracker {
+	return &OperationTracker{
+		handles: make(map[string]*OperationHandle),
+	}
+}
+
+// Record assigns an ID to the handle and stores it, evicting the oldest handle when full
+func (t *OperationTracker) Record(handle OperationHandle) (*OperationHandle, error) {
+	id := make([]byte, 8)
+	if _, err := rand.Read(id); err != nil {
+		return nil, fmt.Errorf("failed to generate operation ID: %v", err)
+	}
+	handle.ID = "op-" + hex.EncodeToString(id)
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if len(t.handles) >= maxTrackedOperations {
+		var oldest *OperationHandle
+		for _, h := range t.handles {
+			if oldest == nil || h.StartedAt.Before(oldest.StartedAt) {
+				oldest = h
+			}
+		}
+		delete(t.handles, oldest.ID)
+	}
+
+	t.handles[handle.ID] = &handle
+	return &handle, nil
+}
+
+// Get returns the handle with the given ID
+func (t *OperationTracker) Get(id string) (*OperationHandle, bool) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	handle, exists := t.handles[id]
+	if !exists {
+		return nil, false
+	}
+	copied := *handle
+	return &copied, true
+}
+
+// ResourceStatus is the provisioning state of a cluster or node pool
+type ResourceStatus struct {
+	ProvisioningState string `json:"provisioning_state,omitempty"`
+	PowerState        string `json:"power_state,omitempty"`
+	KubernetesVersion string `json:"kubernetes_version,omitempty"`
+	CurrentVersion    string `json:"current_kubernetes_version,omitempty"`
+	NodeCount         *int32 `json:"node_count,omitempty"`
+	NotFound          bool   `json:"not_found,omitempty"`
+}
+
+// OperationStatus is the result of the operation_status operation
+type OperationStatus struct {
+	OperationHandle
+	// State is Succeeded, Failed, Canceled or Deleted once the operation finished, otherwise InProgress
+	State               string                          `json:"state"`
+	Done                bool                            `json:"done"`
+	Cluster             *ResourceStatus                 `json:"cluster,omitempty"`
+	Nodepool            *ResourceStatus                 `json:"nodepool,omitempty"`
+	AsyncOperation      *azureclient.AKSOperationStatus `json:"async_operation,omitempty"`
+	AsyncOperationError string                          `json:"async_operation_error,omitempty"`
+}
+
+// startTrackedOperation runs a write operation with --no-wait and records a handle for it.
+// The subscription is resolved first, so an operation is never started without a handle to track it.
+func (e *AksOperationsExecutor) startTrackedOperation(operation string, argv []string, cfg *config.ConfigData) (string, error) {
+	args := argv[1:]
+	handle := OperationHandle{
+		Operation:     operation,
+		ResourceGroup: flagValue(args, "--resource-group"),
+		ClusterName:   flagValue(args, "--name"),
+	}
+	if trackedOperations[operation] {
+		handle.ClusterName = flagValue(args, "--cluster-name")
+		handle.NodepoolName = flagValue(args, "--name")
+	}
+
+	subscriptionID, err := resolveSubscriptionID(flagValue(args, "--subscription"), cfg)
+	if err != nil {
+		return "", fmt.Errorf("failed to resolve the subscription to track the operation, it was not started: %v", err)
+	}
+	handle.SubscriptionID = subscriptionID
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Duration(cfg.Timeout)*time.Second)
+	defer cancel()
+
+	// The ARM operation starts while az runs, so the handle records the time before az is started
+	handle.StartedAt = time.Now().UTC()
+	result, err := command.DefaultRunner().Run(ctx, argv)
+	if err != nil {
+		return "", fmt.Errorf("failed to start operation: %v", err)
+	}
+	if result.ExitCode != 0 {
+		// Match the output of operations that wait, which return the az CLI error
+		return result.Stderr, nil
+	}
+
+	recorded, err := e.operations.Record(handle)
+	if err != nil {
+		return "", err
+	}
+
+	response := map[string]interface{}{
+		"operation_id":    recorded.ID,
+		"operation":       recorded.Operation,
+		"subscription_id": recorded.SubscriptionID,
+		"resource_group":  recorded.ResourceGroup,
+		"cluster_name":    recorded.ClusterName,
+		"started_at":      recorded.StartedAt,
+		"state":           "InProgress",
+		"message": fmt.Sprintf("The operation was started without waiting for it to finish. "+
+			"Check its progress with operation=\"%s\", operation_id=\"%s\".", OpOperationStatus, recorded.ID),
+	}
+	if recorded.NodepoolName != "" {
+		response["nodepool_name"] = recorded.NodepoolName
+	}
+
+	output, err := json.MarshalIndent(response, "", "  ")
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal operation handle: %v", err)
+	}
+	return string(output), nil
+}
+
+// operationStatus reports the progress of a tracked operation, or of the latest operation
+// on a cluster or node pool when no operation ID is given
+func (e *AksOperationsExecutor) operationStatus(params map[string]interface{}, cfg *config.ConfigData) (string, error) {
+	// Validate the typed parameters; operation_status does not run an az command
+	if _, err := BuildOperationArgs(string(OpOperationStatus), params, nil); err != nil {
+		return "", err
+	}
+
+	if e.azClient == nil {
+		return "", fmt.Errorf("operation '%s' requires an Azure client", OpOperationStatus)
+	}
+
+	var handle OperationHandle
+	if id, _ := params["operation_id"].(string); id != "" {
+		tracked, exists := e.operations.Get(id)
+		if !exists {
+			return "", fmt.Errorf("unknown operation_id '%s'; operation handles are kept in memory by the server that started the operation", id)
+		}
+		handle = *tracked
+	} else {
+		clusterName, _ := params["cluster_name"].(string)
+		resourceGroup, _ := params["resource_group"].(string)
+		if clusterName == "" || resourceGroup == "" {
+			return "", fmt.Errorf("operation '%s' requires operation_id, or cluster_name and resource_group", OpOperationStatus)
+		}
+		subscription, _ := params["subscription"].(string)
+		subscriptionID, err := resolveSubscriptionID(subscription, cfg)
+		if err != nil {
+			return "", fmt.Errorf("failed to resolve subscription: %v", err)
+		}
+		nodepoolName, _ := params["nodepool_name"].(string)
+		handle = OperationHandle{
+			SubscriptionID: subscriptionID,
+			ResourceGroup:  resourceGroup,
+			ClusterName:    clusterName,
+			NodepoolName:   nodepoolName,
+		}
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Duration(cfg.Timeout)*time.Second)
+	defer cancel()
+
+	status, err := e.pollOperation(ctx, handle)
+	if err != nil {
+		return "", err
+	}
+
+	output, err := json.MarshalIndent(status, "", "  ")
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal operation status: %v", err)
+	}
+	return string(output), nil
+}
+
+// pollOperation reads the cluster, node pool and latest ARM operation for a handle
+func (e *AksOperationsExecutor) pollOperation(ctx context.Context, handle OperationHandle) (*OperationStatus, error) {
+	status := &OperationStatus{OperationHandle: handle}
+
+	cluster, err := e.azClient.GetAKSClusterStatus(ctx, handle.SubscriptionID, handle.ResourceGroup, handle.ClusterName)
+	switch {
+	case azureclient.IsNotFound(err):
+		status.Cluster = &ResourceStatus{NotFound: true}
+	case err != nil:
+		return nil, err
+	case cluster.Properties != nil:
+		props := cluster.Properties
+		status.Cluster = &ResourceStatus{
+			ProvisioningState: stringValue(props.ProvisioningState),
+			KubernetesVersion: stringValue(props.KubernetesVersion),
+			CurrentVersion:    stringValue(props.CurrentKubernetesVersion),
+		}
+		if props.PowerState != nil && props.PowerState.Code != nil {
+			status.Cluster.PowerState = string(*props.PowerState.Code)
+		}
+	}
+
+	if handle.NodepoolName != "" && (status.Cluster == nil || !status.Cluster.NotFound) {
+		pool, err := e.azClient.GetAgentPool(ctx, handle.SubscriptionID, handle.ResourceGroup, handle.ClusterName, handle.NodepoolName)
+		switch {
+		case azureclient.IsNotFound(err):
+			status.Nodepool = &ResourceStatus{NotFound: true}
+		case err != nil:
+			return nil, err
+		case pool.Properties != nil:
+			props := pool.Properties
+			status.Nodepool = &ResourceStatus{
+				ProvisioningState: stringValue(props.ProvisioningState),
+				KubernetesVersion: stringValue(props.OrchestratorVersion),
+				CurrentVersion:    stringValue(props.CurrentOrchestratorVersion),
+				NodeCount:         props.Count,
+			}
+			if props.PowerState != nil && props.PowerState.Code != nil {
+				status.Nodepool.PowerState = string(*props.PowerState.Code)
+			}
+		}
+	}
+
+	// The latest ARM operation is only available while the resource exists
+	target := status.Cluster
+	if handle.NodepoolName != "" {
+		target = status.Nodepool
+	}
+	if target != nil && !target.NotFound {
+		asyncOperation, err := e.azClient.GetLatestAKSOperation(ctx, handle.SubscriptionID, handle.ResourceGroup, handle.ClusterName, handle.NodepoolName)
+		if err != nil {
+			status.AsyncOperationError = err.Error()
+		} else {
+			status.AsyncOperation = asyncOperation
+		}
+	}
+
+	status.State = operationState(handle, target, status.AsyncOperation)
+	status.Done = status.State != "InProgress"
+	return status, nil
+}
+
+// operationState summarizes the progress of an operation from the resource and its latest ARM operation
+func operationState(handle OperationHandle, target *ResourceStatus, asyncOperation *azureclient.AKSOperationStatus) string {
+	if target == nil || target.NotFound {
+		if handle.Operation == string(OpClusterDelete) || handle.Operation == string(OpNodepoolDelete) {
+			return "Deleted"
+		}
+		return "Failed"
+	}
+
+	// Prefer the ARM operation when it started after the tracked operation, allowing for clock skew.
+	// An ARM operation without a start time may be an earlier one, so it is only used without a tracked operation.
+	state := target.ProvisioningState
+	if asyncOperation != nil && asyncOperation.Status != "" &&
+		(handle.StartedAt.IsZero() || asyncOperation.StartTime != nil && asyncOperation.StartTime.After(handle.StartedAt.Add(-time.Minute))) {
+		state = asyncOperation.Status
+	}
+
+	switch strings.ToLower(state) {
+	case "succeeded":
+		return "Succeeded"
+	case "failed":
+		return "Failed"
+	case "canceled", "cancelled":
+		return "Canceled"
+	default:
+		return "InProgress"
+	}
+}
+
+// resolveSubscriptionID returns the ID of a subscription given by name or ID,
+// or of the current az CLI subscription when subscription is empty
+func resolveSubscriptionID(subscription string, cfg *config.ConfigData) (string, error) {
+	if subscriptionIDPattern.MatchString(subscription) {
+		return subscription, nil
+	}
+
+	args := []string{"account", "show", "--query", "id", "--output", "tsv"}
+	if subscription != "" {
+		args = append(args, "--subscription", subscription)
+	}
+
+	process := command.NewShellProcess("az", cfg.Timeout)
+	process.StripNewlines = true
+	process.ReturnErrOutput = false
+	output, err := process.RunArgs(args)
+	if err != nil {
+		return "", err
+	}
+	if !subscriptionIDPattern.MatchString(output) {
+		return "", fmt.Errorf("unexpected subscription ID %q", output)
+	}
+	return output, nil
+}
+
+// flagValue returns the value of a flag in an argument list, in long, short or --flag=value form
+func flagValue(args []string, flag string) string {
+	alias := flagAliases[flag]
+	for i, arg := range args {
+		if strings.HasPrefix(arg, flag+"=") {
+			return strings.TrimPrefix(arg, flag+"=")
+		}
+		if (arg == flag || (alias != "" && arg == alias)) && i+1 < len(args) {
+			return args[i+1]
+		}
+	}
+	return ""
+}
+
+// stringValue dereferences an optional string from the Azure SDK
+func stringValue[T ~string](value *T) string {
+	if value == nil {
+		return ""
+	}
+	return string(*value)
+}
@@ -0,0 +1,749 @@
+package azaks
+
+import (
+	"fmt"
+	"math"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// paramKind is the JSON type of a typed operation parameter
+type paramKind string
+
+const (
+	paramString  paramKind = "string"
+	paramInteger paramKind = "integer"
+	paramBoolean paramKind = "boolean"
+)
+
+// paramDef describes a typed parameter shared by AKS operations
+type paramDef struct {
+	// Flag is the az CLI flag the parameter maps to
+	Flag        string
+	Kind        paramKind
+	Description string
+	// Enum restricts string values, compared case-insensitively
+	Enum []string
+	// Pattern restricts string values
+	Pattern *regexp.Regexp
+	// Min and Max bound integer values
+	Min int
+	Max int
+}
+
+// opParam is a parameter accepted by a specific operation
+type opParam struct {
+	Name     string
+	Required bool
+	// Flag overrides the parameter's default az CLI flag for this operation
+	Flag string
+}
+
+// weekdays are the day names accepted by maintenance configuration parameters
+var weekdays = []string{"Monday", "Tuesday", "Wednesday", "Thursday", "Friday", "Saturday", "Sunday"}
+
+// addonNames are the addons managed by az aks addon
+var addonNames = []string{
+	"azure-keyvault-secrets-provider", "azure-policy", "confcom", "http_application_routing", "ingress-appgw",
+	"monitoring", "open-service-mesh", "virtual-node", "web_application_routing",
+}
+
+// paramDefs contains all typed parameters accepted by az_aks_operations
+var paramDefs = map[string]paramDef{
+	"cluster_name": {
+		Flag:        "--name",
+		Kind:        paramString,
+		Description: "AKS cluster name",
+		Pattern:     regexp.MustCompile(`^[a-zA-Z0-9][a-zA-Z0-9_-]{0,62}$`),
+	},
+	"resource_group": {
+		Flag:        "--resource-group",
+		Kind:        paramString,
+		Description: "Resource group name",
+		Pattern:     regexp.MustCompile(`^[-\w.()]{1,90}$`),
+	},
+	"subscription": {
+		Flag:        "--subscription",
+		Kind:        paramString,
+		Description: "Subscription name or ID",
+		Pattern:     regexp.MustCompile(`^[\w][\w .()-]{0,63}$`),
+	},
+	"location": {
+		Flag:        "--location",
+		Kind:        paramString,
+		Description: "Azure region, e.g. eastus",
+		Pattern:     regexp.MustCompile(`^[a-z0-9]{1,40}$`),
+	},
+	"nodepool_name": {
+		Flag:        "--nodepool-name",
+		Kind:        paramString,
+		Description: "Node pool name (lowercase letters and numbers, up to 12 characters)",
+		Pattern:     regexp.MustCompile(`^[a-z][a-z0-9]{0,11}$`),
+	},
+	"node_count": {
+		Flag:        "--node-count",
+		Kind:        paramInteger,
+		Description: "Number of nodes",
+		Min:         0,
+		Max:         1000,
+	},
+	"min_count": {
+		Flag:        "--min-count",
+		Kind:        paramInteger,
+		Description: "Minimum node count for the cluster autoscaler",
+		Min:         0,
+		Max:         1000,
+	},
+	"max_count": {
+		Flag:        "--max-count",
+		Kind:        paramInteger,
+		Description: "Maximum node count for the cluster autoscaler",
+		Min:         0,
+		Max:         1000,
+	},
+	"kubernetes_version": {
+		Flag:        "--kubernetes-version",
+		Kind:        paramString,
+		Description: "Kubernetes version, e.g. 1.30 or 1.30.4",
+		Pattern:     regexp.MustCompile(`^\d+\.\d+(\.\d+)?$`),
+	},
+	"vm_size": {
+		Flag:        "--node-vm-size",
+		Kind:        paramString,
+		Description: "VM size for nodes, e.g. Standard_D4s_v5",
+		Pattern:     regexp.MustCompile(`^[A-Za-z0-9_]{1,64}$`),
+	},
+	"mode": {
+		Flag:        "--mode",
+		Kind:        paramString,
+		Description: "Node pool mode",
+		Enum:        []string{"System", "User"},
+	},
+	"os_type": {
+		Flag:        "--os-type",
+		Kind:        paramString,
+		Description: "Node OS type",
+		Enum:        []string{"Linux", "Windows"},
+	},
+	"max_surge": {
+		Flag:        "--max-surge",
+		Kind:        paramString,
+		Description: "Maximum extra nodes during upgrade, as a count or percentage, e.g. 1 or 33%",
+		Pattern:     regexp.MustCompile(`^\d{1,3}%?$`),
+	},
+	"network_plugin": {
+		Flag:        "--network-plugin",
+		Kind:        paramString,
+		Description: "Network plugin",
+		Enum:        []string{"azure", "kubenet", "none"},
+	},
+	"tier": {
+		Flag:        "--tier",
+		Kind:        paramString,
+		Description: "Cluster pricing tier",
+		Enum:        []string{"free", "standard", "premium"},
+	},
+	"enable_cluster_autoscaler": {
+		Flag:        "--enable-cluster-autoscaler",
+		Kind:        paramBoolean,
+		Description: "Enable the cluster autoscaler (requires min_count and max_count)",
+	},
+	"disable_cluster_autoscaler": {
+		Flag:        "--disable-cluster-autoscaler",
+		Kind:        paramBoolean,
+		Description: "Disable the cluster autoscaler",
+	},
+	"update_cluster_autoscaler": {
+		Flag:        "--update-cluster-autoscaler",
+		Kind:        paramBoolean,
+		Description: "Update the cluster autoscaler min_count and max_count",
+	},
+	"generate_ssh_keys": {
+		Flag:        "--generate-ssh-keys",
+		Kind:        paramBoolean,
+		Description: "Generate SSH keys if missing",
+	},
+	"control_plane_only": {
+		Flag:        "--control-plane-only",
+		Kind:        paramBoolean,
+		Description: "Upgrade only the control plane",
+	},
+	"node_image_only": {
+		Flag:        "--node-image-only",
+		Kind:        paramBoolean,
+		Description: "Upgrade only the node image",
+	},
+	"admin": {
+		Flag:        "--admin",
+		Kind:        paramBoolean,
+		Description: "Get cluster administrator credentials",
+	},
+	"overwrite_existing": {
+		Flag:        "--overwrite-existing",
+		Kind:        paramBoolean,
+		Description: "Overwrite an existing kubeconfig entry with the same name",
+	},
+	"all": {
+		Flag:        "--all",
+		Kind:        paramBoolean,
+		Description: "List all subscriptions, including disabled ones",
+	},
+	"tenant": {
+		Flag:        "--tenant",
+		Kind:        paramString,
+		Description: "Entra tenant ID or domain",
+		Pattern:     regexp.MustCompile(`^[a-zA-Z0-9][a-zA-Z0-9.-]*$`),
+	},
+	"yes": {
+		Flag:        "--yes",
+		Kind:        paramBoolean,
+		Description: "Do not prompt for confirmation",
+	},
+	"no_wait": {
+		Flag:        "--no-wait",
+		Kind:        paramBoolean,
+		Description: "Start the operation without waiting for it to finish and return an operation_id for operation_status",
+	},
+	"config_name": {
+		Flag:        "--name",
+		Kind:        paramString,
+		Description: "Maintenance configuration name",
+		Enum:        []string{"default", "aksManagedAutoUpgradeSchedule", "aksManagedNodeOSUpgradeSchedule"},
+	},
+	"weekday": {
+		Flag:        "--weekday",
+		Kind:        paramString,
+		Description: "Day of the week of a default maintenance window",
+		Enum:        weekdays,
+	},
+	"start_hour": {
+		Flag:        "--start-hour",
+		Kind:        paramInteger,
+		Description: "Hour of the day a default maintenance window starts, in UTC",
+		Min:         0,
+		Max:         23,
+	},
+	"schedule_type": {
+		Flag:        "--schedule-type",
+		Kind:        paramString,
+		Description: "Schedule type of an auto-upgrade or node OS maintenance window",
+		Enum:        []string{"Daily", "Weekly", "AbsoluteMonthly", "RelativeMonthly"},
+	},
+	"interval_days": {
+		Flag:        "--interval-days",
+		Kind:        paramInteger,
+		Description: "Days between maintenance windows of a Daily schedule",
+		Min:         1,
+		Max:         7,
+	},
+	"interval_weeks": {
+		Flag:        "--interval-weeks",
+		Kind:        paramInteger,
+		Description: "Weeks between maintenance windows of a Weekly schedule",
+		Min:         1,
+		Max:         4,
+	},
+	"interval_months": {
+		Flag:        "--interval-months",
+		Kind:        paramInteger,
+		Description: "Months between maintenance windows of a monthly schedule",
+		Min:         1,
+		Max:         6,
+	},
+	"day_of_week": {
+		Flag:        "--day-of-week",
+		Kind:        paramString,
+		Description: "Day of the week of a Weekly or RelativeMonthly schedule",
+		Enum:        weekdays,
+	},
+	"day_of_month": {
+		Flag:        "--day-of-month",
+		Kind:        paramInteger,
+		Description: "Day of the month of an AbsoluteMonthly schedule",
+		Min:         1,
+		Max:         31,
+	},
+	"week_index": {
+		Flag:        "--week-index",
+		Kind:        paramString,
+		Description: "Week of the month of a RelativeMonthly schedule",
+		Enum:        []string{"First", "Second", "Third", "Fourth", "Last"},
+	},
+	"start_time": {
+		Flag:        "--start-time",
+		Kind:        paramString,
+		Description: "Time a maintenance window starts, as HH:mm",
+		Pattern:     regexp.MustCompile(`^([01]\d|2[0-3]):[0-5]\d$`),
+	},
+	"start_date": {
+		Flag:        "--start-date",
+		Kind:        paramString,
+		Description: "Date the maintenance schedule takes effect, as yyyy-mm-dd",
+		Pattern:     regexp.MustCompile(`^\d{4}-\d{2}-\d{2}$`),
+	},
+	"duration": {
+		Flag:        "--duration",
+		Kind:        paramInteger,
+		Description: "Length of a maintenance window in hours",
+		Min:         4,
+		Max:         24,
+	},
+	"utc_offset": {
+		Flag:        "--utc-offset",
+		Kind:        paramString,
+		Description: "UTC offset of the maintenance window start time, e.g. +05:30",
+		Pattern:     regexp.MustCompile(`^[+-](0\d|1[0-4]):[0-5]\d$`),
+	},
+	"addon": {
+		Flag:        "--addon",
+		Kind:        paramString,
+		Description: "Addon name",
+		Enum:        addonNames,
+	},
+	"addons": {
+		Flag:        "--addons",
+		Kind:        paramString,
+		Description: "Comma-separated addon names, e.g. monitoring,azure-policy",
+		Pattern:     regexp.MustCompile(`^[a-z][a-z_-]*(,[a-z][a-z_-]*)*$`),
+	},
+	"workspace_resource_id": {
+		Flag:        "--workspace-resource-id",
+		Kind:        paramString,
+		Description: "Resource ID of the Log Analytics workspace used by the monitoring addon",
+		Pattern:     regexp.MustCompile(`(?i)^/subscriptions/[0-9a-f-]{36}/resourcegroups/[-\w.()]{1,90}/providers/microsoft\.operationalinsights/workspaces/[\w-]{1,63}$`),
+	},
+	"subnet_name": {
+		Flag:        "--subnet-name",
+		Kind:        paramString,
+		Description: "Subnet used by the virtual-node addon",
+		Pattern:     regexp.MustCompile(`^[\w.-]{1,80}$`),
+	},
+	"snapshot_name": {
+		Flag:        "--name",
+		Kind:        paramString,
+		Description: "Node pool snapshot name",
+		Pattern:     regexp.MustCompile(`^[a-zA-Z0-9][\w.-]{0,79}$`),
+	},
+	"nodepool_id": {
+		Flag:        "--nodepool-id",
+		Kind:        paramString,
+		Description: "Resource ID of the node pool to snapshot",
+		Pattern:     regexp.MustCompile(`(?i)^/subscriptions/[0-9a-f-]{36}/resourcegroups/[-\w.()]{1,90}/providers/microsoft\.containerservice/managedclusters/[\w-]{1,63}/agentpools/[a-z0-9]{1,12}$`),
+	},
+	"operation_id": {
+		// Handled by the server, never passed to az
+		Kind:        paramString,
+		Description: "Handle of an operation started with no_wait",
+		Pattern:     regexp.MustCompile(`^op-[0-9a-f]{16}$`),
+	},
+}
+
+// Parameter sets shared by several operations
+var (
+	clusterParams = []opParam{
+		{Name: "cluster_name", Required: true},
+		{Name: "resource_group", Required: true},
+		{Name: "subscription"},
+	}
+	nodepoolParams = []opParam{
+		{Name: "cluster_name", Required: true, Flag: "--cluster-name"},
+		{Name: "resource_group", Required: true},
+		{Name: "nodepool_name", Required: true, Flag: "--name"},
+		{Name: "subscription"},
+	}
+	autoscalerParams = []opParam{
+		{Name: "enable_cluster_autoscaler"},
+		{Name: "min_count"},
+		{Name: "max_count"},
+	}
+	maintenanceParams = []opParam{
+		{Name: "cluster_name", Required: true, Flag: "--cluster-name"},
+		{Name: "resource_group", Required: true},
+		{Name: "subscription"},
+	}
+	maintenanceWindowParams = []opParam{
+		{Name: "config_name", Required: true},
+		{Name: "weekday"},
+		{Name: "start_hour"},
+		{Name: "schedule_type"},
+		{Name: "interval_days"},
+		{Name: "interval_weeks"},
+		{Name: "interval_months"},
+		{Name: "day_of_week"},
+		{Name: "day_of_month"},
+		{Name: "week_index"},
+		{Name: "start_time"},
+		{Name: "start_date"},
+		{Name: "duration"},
+		{Name: "utc_offset"},
+	}
+	addonConfigParams = []opParam{
+		{Name: "workspace_resource_id"},
+		{Name: "subnet_name"},
+	}
+)
+
+// withParams combines parameter sets into a single list
+func withParams(sets ...[]opParam) []opParam {
+	var params []opParam
+	for _, set := range sets {
+		params = append(params, set...)
+	}
+	return params
+}
+
+// operationParams defines the typed parameters accepted by each operation
+var operationParams = map[string][]opParam{
+	// Cluster operations
+	string(OpClusterShow): clusterParams,
+	string(OpClusterList): {
+		{Name: "resource_group"},
+		{Name: "subscription"},
+	},
+	string(OpClusterCreate): withParams(clusterParams, autoscalerParams, []opParam{
+		{Name: "location"},
+		{Name: "node_count"},
+		{Name: "kubernetes_version"},
+		{Name: "vm_size"},
+		{Name: "network_plugin"},
+		{Name: "tier"},
+		{Name: "generate_ssh_keys"},
+		{Name: "no_wait"},
+	}),
+	string(OpClusterDelete): withParams(clusterParams, []opParam{
+		{Name: "yes"},
+		{Name: "no_wait"},
+	}),
+	string(OpClusterScale): withParams(clusterParams, []opParam{
+		{Name: "node_count", Required: true},
+		{Name: "nodepool_name"},
+		{Name: "no_wait"},
+	}),
+	string(OpClusterUpdate): withParams(clusterParams, autoscalerParams, []opParam{
+		{Name: "disable_cluster_autoscaler"},
+		{Name: "update_cluster_autoscaler"},
+		{Name: "tier"},
+		{Name: "no_wait"},
+	}),
+	string(OpClusterUpgrade): withParams(clusterParams, []opParam{
+		{Name: "kubernetes_version"},
+		{Name: "control_plane_only"},
+		{Name: "node_image_only"},
+		{Name: "yes"},
+		{Name: "no_wait"},
+	}),
+	string(OpClusterGetVersions): {
+		{Name: "location", Required: true},
+		{Name: "subscription"},
+	},
+	string(OpClusterCheckNetwork): clusterParams,
+	string(OpClusterGetCredentials): withParams(clusterParams, []opParam{
+		{Name: "admin"},
+		{Name: "overwrite_existing"},
+	}),
+	string(OpClusterGetUpgrades): clusterParams,
+	string(OpClusterStart):       withParams(clusterParams, []opParam{{Name: "no_wait"}}),
+	string(OpClusterStop):        withParams(clusterParams, []opParam{{Name: "no_wait"}}),
+
+	// Nodepool operations
+	string(OpNodepoolList): {
+		{Name: "cluster_name", Required: true, Flag: "--cluster-name"},
+		{Name: "resource_group", Required: true},
+		{Name: "subscription"},
+	},
+	string(OpNodepoolShow): nodepoolParams,
+	string(OpNodepoolAdd): withParams(nodepoolParams, autoscalerParams, []opParam{
+		{Name: "node_count"},
+		{Name: "vm_size"},
+		{Name: "mode"},
+		{Name: "os_type"},
+		{Name: "kubernetes_version"},
+		{Name: "max_surge"},
+		{Name: "no_wait"},
+	}),
+	string(OpNodepoolDelete): withParams(nodepoolParams, []opParam{{Name: "no_wait"}}),
+	string(OpNodepoolScale): withParams(nodepoolParams, []opParam{
+		{Name: "node_count", Required: true},
+		{Name: "no_wait"},
+	}),
+	string(OpNodepoolUpgrade): withParams(nodepoolParams, []opParam{
+		{Name: "kubernetes_version"},
+		{Name: "node_image_only"},
+		{Name: "max_surge"},
+		{Name: "yes"},
+		{Name: "no_wait"},
+	}),
+	string(OpNodepoolStart): withParams(nodepoolParams, []opParam{{Name: "no_wait"}}),
+	string(OpNodepoolStop):  withParams(nodepoolParams, []opParam{{Name: "no_wait"}}),
+
+	// Nodepool snapshot operations
+	string(OpNodepoolSnapshotCreate): {
+		{Name: "snapshot_name", Required: true},
+		{Name: "resource_group", Required: true},
+		{Name: "nodepool_id", Required: true},
+		{Name: "location"},
+		{Name: "subscription"},
+	},
+	string(OpNodepoolSnapshotList): {
+		{Name: "resource_group"},
+		{Name: "subscription"},
+	},
+
+	// Maintenance configuration operations
+	string(OpMaintenanceConfigList):   maintenanceParams,
+	string(OpMaintenanceConfigShow):   withParams(maintenanceParams, []opParam{{Name: "config_name", Required: true}}),
+	string(OpMaintenanceConfigAdd):    withParams(maintenanceParams, maintenanceWindowParams),
+	string(OpMaintenanceConfigUpdate): withParams(maintenanceParams, maintenanceWindowParams),
+
+	// Addon operations
+	string(OpAddonList):     clusterParams,
+	string(OpAddonShow):     withParams(clusterParams, []opParam{{Name: "addon", Required: true}}),
+	string(OpAddonEnable):   withParams(clusterParams, []opParam{{Name: "addon", Required: true}}, addonConfigParams),
+	string(OpAddonDisable):  withParams(clusterParams, []opParam{{Name: "addon", Required: true}}),
+	string(OpAddonUpdate):   withParams(clusterParams, []opParam{{Name: "addon", Required: true}}, addonConfigParams),
+	string(OpEnableAddons):  withParams(clusterParams, []opParam{{Name: "addons", Required: true}}, addonConfigParams),
+	string(OpDisableAddons): withParams(clusterParams, []opParam{{Name: "addons", Required: true}}),
+
+	// Account operations
+	string(OpAccountList): {
+		{Name: "all"},
+	},
+	string(OpAccountSet): {
+		{Name: "subscription", Required: true},
+	},
+	string(OpLogin): {
+		{Name: "tenant"},
+	},
+
+	// Long-running operation tracking, either by handle or by cluster and node pool
+	string(OpOperationStatus): {
+		{Name: "operation_id"},
+		{Name: "cluster_name"},
+		{Name: "resource_group"},
+		{Name: "nodepool_name"},
+		{Name: "subscription"},
+	},
+	string(OpOperationShowLatest): withParams(clusterParams, []opParam{{Name: "nodepool_name"}}),
+}
+
+// operationSchema returns the JSON schema of the typed parameters for an operation
+func operationSchema(operation string) map[string]interface{} {
+	properties := make(map[string]interface{})
+	var required []string
+
+	for _, p := range operationParams[operation] {
+		properties[p.Name] = paramSchema(paramDefs[p.Name])
+		if p.Required {
+			required = append(required, p.Name)
+		}
+	}
+
+	schema := map[string]interface{}{
+		"type":       "object",
+		"properties": properties,
+	}
+	if len(required) > 0 {
+		schema["required"] = required
+	}
+	return schema
+}
+
+// paramSchema returns the JSON schema of a single parameter
+func paramSchema(def paramDef) map[string]interface{} {
+	schema := map[string]interface{}{
+		"type":        string(def.Kind),
+		"description": def.Description,
+	}
+	if len(def.Enum) > 0 {
+		schema["enum"] = def.Enum
+	}
+	if def.Pattern != nil {
+		schema["pattern"] = def.Pattern.String()
+	}
+	if def.Kind == paramInteger {
+		schema["minimum"] = def.Min
+		schema["maximum"] = def.Max
+	}
+	return schema
+}
+
+// operationsUsingParam returns the sorted operations that accept a parameter
+func operationsUsingParam(name string, operations []string) []string {
+	var result []string
+	for _, operation := range operations {
+		for _, p := range operationParams[operation] {
+			if p.Name == name {
+				result = append(result, operation)
+				break
+			}
+		}
+	}
+	sort.Strings(result)
+	return result
+}
+
+// flagAliases maps az CLI flags to their short forms
+var flagAliases = map[string]string{
+	"--name":               "-n",
+	"--resource-group":     "-g",
+	"--location":           "-l",
+	"--kubernetes-version": "-k",
+	"--node-count":         "-c",
+	"--node-vm-size":       "-s",
+	"--tenant":             "-t",
+}
+
+// hasFlag reports whether raw arguments contain a flag, in long, short or --flag=value form
+func hasFlag(args []string, flag string) bool {
+	alias := flagAliases[flag]
+	for _, arg := range args {
+		if arg == flag || strings.HasPrefix(arg, flag+"=") || (alias != "" && arg == alias) {
+			return true
+		}
+	}
+	return false
+}
+
+// BuildOperationArgs validates the typed parameters for an operation and converts them to az CLI arguments,
+// followed by extraArgs from the raw args escape hatch. Typed parameters that the operation does not accept
+// are rejected, and a required parameter may instead be supplied as a flag in extraArgs.
+func BuildOperationArgs(operation string, params map[string]interface{}, extraArgs []string) ([]string, error) {
+	accepted, exists := operationParams[operation]
+	if !exists {
+		return nil, fmt.Errorf("no parameter schema for operation: %s", operation)
+	}
+
+	// Reject known parameters that this operation does not accept
+	acceptedNames := make(map[string]bool, len(accepted))
+	for _, p := range accepted {
+		acceptedNames[p.Name] = true
+	}
+	var unsupported []string
+	for name := range params {
+		if _, known := paramDefs[name]; known && !acceptedNames[name] && params[name] != nil {
+			unsupported = append(unsupported, name)
+		}
+	}
+	if len(unsupported) > 0 {
+		sort.Strings(unsupported)
+		return nil, fmt.Errorf("parameters not supported by operation '%s': %s", operation, strings.Join(unsupported, ", "))
+	}
+
+	var args []string
+	for _, p := range accepted {
+		def := paramDefs[p.Name]
+		flag := def.Flag
+		if p.Flag != "" {
+			flag = p.Flag
+		}
+
+		value, present := params[p.Name]
+		if !present || value == nil || value == "" {
+			if p.Required && !hasFlag(extraArgs, flag) {
+				return nil, fmt.Errorf("missing required parameter '%s' for operation '%s'", p.Name, operation)
+			}
+			continue
+		}
+
+		if hasFlag(extraArgs, flag) {
+			return nil, fmt.Errorf("parameter '%s' conflicts with %s in args", p.Name, flag)
+		}
+
+		switch def.Kind {
+		case paramString:
+			s, err := stringParam(p.Name, def, value)
+			if err != nil {
+				return nil, err
+			}
+			args = append(args, flag, s)
+		case paramInteger:
+			n, err := integerParam(p.Name, def, value)
+			if err != nil {
+				return nil, err
+			}
+			args = append(args, flag, strconv.Itoa(n))
+		case paramBoolean:
+			b, err := booleanParam(p.Name, value)
+			if err != nil {
+				return nil, err
+			}
+			if b {
+				args = append(args, flag)
+			}
+		}
+	}
+
+	return append(args, extraArgs...), nil
+}
+
+// stringParam validates a string parameter against its enum or pattern
+func stringParam(name string, def paramDef, value interface{}) (string, error) {
+	s, ok := value.(string)
+	if !ok {
+		return "", fmt.Errorf("parameter '%s' must be a string", name)
+	}
+
+	if len(def.Enum) > 0 {
+		for _, allowed := range def.Enum {
+			if strings.EqualFold(s, allowed) {
+				return allowed, nil
+			}
+		}
+		return "", fmt.Errorf("invalid value '%s' for parameter '%s', must be one of: %s", s, name, strings.Join(def.Enum, ", "))
+	}
+
+	if def.Pattern != nil && !def.Pattern.MatchString(s) {
+		return "", fmt.Errorf("invalid value '%s' for parameter '%s'", s, name)
+	}
+	return s, nil
+}
+
+// integerParam validates an integer parameter, accepting JSON numbers and numeric strings
+func integerParam(name string, def paramDef, value interface{}) (int, error) {
+	var n int
+	switch v := value.(type) {
+	case float64:
+		// Check the number before converting it, since NaN, infinities and values outside the int range
+		// have no defined int conversion
+		if math.IsNaN(v) || math.IsInf(v, 0) || v != math.Trunc(v) {
+			return 0, fmt.Errorf("parameter '%s' must be an integer", name)
+		}
+		if v < float64(def.Min) || v > float64(def.Max) {
+			return 0, fmt.Errorf("parameter '%s' must be between %d and %d", name, def.Min, def.Max)
+		}
+		n = int(v)
+	case int:
+		n = v
+	case string:
+		parsed, err := strconv.Atoi(v)
+		if err != nil {
+			return 0, fmt.Errorf("parameter '%s' must be an integer", name)
+		}
+		n = parsed
+	default:
+		return 0, fmt.Errorf("parameter '%s' must be an integer", name)
+	}
+
+	if n < def.Min || n > def.Max {
+		return 0, fmt.Errorf("parameter '%s' must be between %d and %d", name, def.Min, def.Max)
+	}
+	return n, nil
+}
+
+// booleanParam validates a boolean parameter, accepting JSON booleans and "true"/"false"
+func booleanParam(name string, value interface{}) (bool, error) {
+	switch v := value.(type) {
+	case bool:
+		return v, nil
+	case string:
+		b, err := strconv.ParseBool(v)
+		if err != nil {
+			return false, fmt.Errorf("parameter '%s' must be a boolean", name)
+		}
+		return b, nil
+	default:
+		return false, fmt.Errorf("parameter '%s' must be a boolean", name)
+	}
+}
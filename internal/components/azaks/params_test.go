@@ -0,0 +1,232 @@
+package azaks
+
+import (
+	"math"
+	"strings"
+	"testing"
+
+	"github.com/Azure/aks-mcp/internal/config"
+)
+
+func TestBuildOperationArgs(t *testing.T) {
+	tests := []struct {
+		name      string
+		operation string
+		params    map[string]interface{}
+		extraArgs []string
+		want      string
+		wantErr   string
+	}{
+		{
+			name:      "cluster show",
+			operation: "show",
+			params:    map[string]interface{}{"cluster_name": "myCluster", "resource_group": "myRG"},
+			want:      "--name myCluster --resource-group myRG",
+		},
+		{
+			name:      "nodepool flags",
+			operation: "nodepool-scale",
+			params: map[string]interface{}{
+				"cluster_name": "myCluster", "resource_group": "myRG", "nodepool_name": "pool1", "node_count": float64(3),
+			},
+			want: "--cluster-name myCluster --resource-group myRG --name pool1 --node-count 3",
+		},
+		{
+			name:      "booleans and enums",
+			operation: "nodepool-add",
+			params: map[string]interface{}{
+				"cluster_name": "myCluster", "resource_group": "myRG", "nodepool_name": "pool1",
+				"enable_cluster_autoscaler": true, "min_count": "1", "max_count": float64(5), "mode": "user", "os_type": "linux",
+			},
+			want: "--cluster-name myCluster --resource-group myRG --name pool1 --enable-cluster-autoscaler --min-count 1 --max-count 5 --mode User --os-type Linux",
+		},
+		{
+			name:      "false boolean omitted",
+			operation: "upgrade",
+			params: map[string]interface{}{
+				"cluster_name": "myCluster", "resource_group": "myRG", "kubernetes_version": "1.30.4", "control_plane_only": false,
+			},
+			want: "--name myCluster --resource-group myRG --kubernetes-version 1.30.4",
+		},
+		{
+			name:      "no wait",
+			operation: "nodepool-delete",
+			params:    map[string]interface{}{"cluster_name": "myCluster", "resource_group": "myRG", "nodepool_name": "pool1", "no_wait": true},
+			want:      "--cluster-name myCluster --resource-group myRG --name pool1 --no-wait",
+		},
+		{
+			name:      "maintenance window",
+			operation: "maintenanceconfiguration-add",
+			params: map[string]interface{}{
+				"cluster_name": "myCluster", "resource_group": "myRG", "config_name": "aksmanagedautoupgradeschedule",
+				"schedule_type": "weekly", "interval_weeks": float64(1), "day_of_week": "saturday", "start_time": "02:00",
+				"duration": float64(4), "utc_offset": "+05:30",
+			},
+			want: "--cluster-name myCluster --resource-group myRG --name aksManagedAutoUpgradeSchedule --schedule-type Weekly " +
+				"--interval-weeks 1 --day-of-week Saturday --start-time 02:00 --duration 4 --utc-offset +05:30",
+		},
+		{
+			name:      "enable addons",
+			operation: "enable-addons",
+			params:    map[string]interface{}{"cluster_name": "myCluster", "resource_group": "myRG", "addons": "monitoring,azure-policy"},
+			want:      "--name myCluster --resource-group myRG --addons monitoring,azure-policy",
+		},
+		{
+			name:      "nodepool snapshot",
+			operation: "nodepool-snapshot-create",
+			params: map[string]interface{}{
+				"snapshot_name": "pool1-snap", "resource_group": "myRG",
+				"nodepool_id": "/subscriptions/00000000-0000-0000-0000-000000000000/resourceGroups/myRG/providers/Microsoft.ContainerService/managedClusters/myCluster/agentPools/pool1",
+			},
+			want: "--name pool1-snap --resource-group myRG " +
+				"--nodepool-id /subscriptions/00000000-0000-0000-0000-000000000000/resourceGroups/myRG/providers/Microsoft.ContainerService/managedClusters/myCluster/agentPools/pool1",
+		},
+		{
+			name:      "invalid maintenance start time",
+			operation: "maintenanceconfiguration-update",
+			params:    map[string]interface{}{"cluster_name": "c", "resource_group": "rg", "config_name": "default", "start_time": "25:00"},
+			wantErr:   "invalid value '25:00' for parameter 'start_time'",
+		},
+		{
+			name:      "unknown addon",
+			operation: "addon-show",
+			params:    map[string]interface{}{"cluster_name": "c", "resource_group": "rg", "addon": "kube-dashboard"},
+			wantErr:   "invalid value 'kube-dashboard' for parameter 'addon'",
+		},
+		{
+			name:      "required parameter supplied through args",
+			operation: "show",
+			params:    map[string]interface{}{"resource_group": "myRG"},
+			extraArgs: []string{"-n", "myCluster"},
+			want:      "--resource-group myRG -n myCluster",
+		},
+		{
+			name:      "missing required parameter",
+			operation: "scale",
+			params:    map[string]interface{}{"cluster_name": "myCluster", "resource_group": "myRG"},
+			wantErr:   "missing required parameter 'node_count'",
+		},
+		{
+			name:      "parameter not supported by operation",
+			operation: "show",
+			params:    map[string]interface{}{"cluster_name": "myCluster", "resource_group": "myRG", "node_count": float64(3)},
+			wantErr:   "not supported by operation 'show': node_count",
+		},
+		{
+			name:      "invalid pattern",
+			operation: "show",
+			params:    map[string]interface{}{"cluster_name": "my cluster; rm -rf /", "resource_group": "myRG"},
+			wantErr:   "invalid value",
+		},
+		{
+			name:      "invalid enum",
+			operation: "nodepool-add",
+			params:    map[string]interface{}{"cluster_name": "c", "resource_group": "rg", "nodepool_name": "pool1", "mode": "Gateway"},
+			wantErr:   "must be one of: System, User",
+		},
+		{
+			name:      "integer out of range",
+			operation: "scale",
+			params:    map[string]interface{}{"cluster_name": "c", "resource_group": "rg", "node_count": float64(5000)},
+			wantErr:   "must be between 0 and 1000",
+		},
+		{
+			name:      "non-integer number",
+			operation: "scale",
+			params:    map[string]interface{}{"cluster_name": "c", "resource_group": "rg", "node_count": 2.5},
+			wantErr:   "must be an integer",
+		},
+		{
+			name:      "infinite number",
+			operation: "scale",
+			params:    map[string]interface{}{"cluster_name": "c", "resource_group": "rg", "node_count": math.Inf(1)},
+			wantErr:   "must be an integer",
+		},
+		{
+			name:      "NaN",
+			operation: "scale",
+			params:    map[string]interface{}{"cluster_name": "c", "resource_group": "rg", "node_count": math.NaN()},
+			wantErr:   "must be an integer",
+		},
+		{
+			name:      "number beyond the int range",
+			operation: "scale",
+			params:    map[string]interface{}{"cluster_name": "c", "resource_group": "rg", "node_count": 1e300},
+			wantErr:   "must be between 0 and 1000",
+		},
+		{
+			name:      "typed parameter repeated in args",
+			operation: "show",
+			params:    map[string]interface{}{"cluster_name": "myCluster", "resource_group": "myRG"},
+			extraArgs: []string{"--resource-group=otherRG"},
+			wantErr:   "conflicts with --resource-group",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			args, err := BuildOperationArgs(tt.operation, tt.params, tt.extraArgs)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Errorf("Expected error containing %q, got %v", tt.wantErr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if got := strings.Join(args, " "); got != tt.want {
+				t.Errorf("Expected %q, got %q", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestOperationSchemasCoverSupportedOperations(t *testing.T) {
+	for _, operation := range GetSupportedOperations() {
+		params, exists := operationParams[operation]
+		if !exists {
+			t.Errorf("Operation '%s' has no parameter schema", operation)
+			continue
+		}
+		for _, p := range params {
+			if _, known := paramDefs[p.Name]; !known {
+				t.Errorf("Operation '%s' uses undefined parameter '%s'", operation, p.Name)
+			}
+		}
+	}
+}
+
+func TestRegisterAzAksOperations_TypedSchema(t *testing.T) {
+	readonly := RegisterAzAksOperations(&config.ConfigData{AccessLevel: "readonly"})
+
+	if _, exists := readonly.InputSchema.Properties["cluster_name"]; !exists {
+		t.Error("Expected cluster_name in the input schema")
+	}
+	if _, exists := readonly.InputSchema.Properties["args"]; exists {
+		t.Error("Expected args not to be offered in readonly mode")
+	}
+	if _, exists := readonly.InputSchema.Properties["node_count"]; exists {
+		t.Error("Expected write-only parameters not to be offered in readonly mode")
+	}
+	if _, exists := readonly.InputSchema.Defs["create"]; exists {
+		t.Error("Expected no schema for operations that are not allowed")
+	}
+
+	showSchema, ok := readonly.InputSchema.Defs["show"].(map[string]interface{})
+	if !ok {
+		t.Fatal("Expected a schema for the show operation")
+	}
+	required, _ := showSchema["required"].([]string)
+	if strings.Join(required, ",") != "cluster_name,resource_group" {
+		t.Errorf("Expected show to require cluster_name and resource_group, got %v", required)
+	}
+
+	readwrite := RegisterAzAksOperations(&config.ConfigData{AccessLevel: "readwrite"})
+	if _, exists := readwrite.InputSchema.Properties["args"]; !exists {
+		t.Error("Expected args to be offered in readwrite mode")
+	}
+	if _, exists := readwrite.InputSchema.Properties["node_count"]; !exists {
+		t.Error("Expected node_count to be offered in readwrite mode")
+	}
+}
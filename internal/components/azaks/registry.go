@@ -3,6 +3,7 @@ package azaks
 import (
 	"fmt"
 	"slices"
+	"sort"
 
 	"github.com/Azure/aks-mcp/internal/config"
 	"github.com/mark3labs/mcp-go/mcp"
@@ -23,6 +24,9 @@ const (
 	OpClusterGetVersions    AksOperationType = "get-versions"
 	OpClusterCheckNetwork   AksOperationType = "check-network"
 	OpClusterGetCredentials AksOperationType = "get-credentials"
+	OpClusterGetUpgrades    AksOperationType = "get-upgrades"
+	OpClusterStart          AksOperationType = "start"
+	OpClusterStop           AksOperationType = "stop"
 
 	// Nodepool operations
 	OpNodepoolList    AksOperationType = "nodepool-list"
@@ -31,28 +35,63 @@ const (
 	OpNodepoolDelete  AksOperationType = "nodepool-delete"
 	OpNodepoolScale   AksOperationType = "nodepool-scale"
 	OpNodepoolUpgrade AksOperationType = "nodepool-upgrade"
+	OpNodepoolStart   AksOperationType = "nodepool-start"
+	OpNodepoolStop    AksOperationType = "nodepool-stop"
+
+	// Nodepool snapshot operations
+	OpNodepoolSnapshotCreate AksOperationType = "nodepool-snapshot-create"
+	OpNodepoolSnapshotList   AksOperationType = "nodepool-snapshot-list"
+
+	// Maintenance configuration operations
+	OpMaintenanceConfigList   AksOperationType = "maintenanceconfiguration-list"
+	OpMaintenanceConfigShow   AksOperationType = "maintenanceconfiguration-show"
+	OpMaintenanceConfigAdd    AksOperationType = "maintenanceconfiguration-add"
+	OpMaintenanceConfigUpdate AksOperationType = "maintenanceconfiguration-update"
+
+	// Addon operations
+	OpAddonList     AksOperationType = "addon-list"
+	OpAddonShow     AksOperationType = "addon-show"
+	OpAddonEnable   AksOperationType = "addon-enable"
+	OpAddonDisable  AksOperationType = "addon-disable"
+	OpAddonUpdate   AksOperationType = "addon-update"
+	OpEnableAddons  AksOperationType = "enable-addons"
+	OpDisableAddons AksOperationType = "disable-addons"
 
 	// Account operations
 	OpAccountList AksOperationType = "account-list"
 	OpAccountSet  AksOperationType = "account-set"
 	OpLogin       AksOperationType = "login"
+
+	// Long-running operation tracking, answered by the server
+	OpOperationStatus AksOperationType = "operation_status"
+
+	// Latest ARM operation on a cluster or node pool, reported by az
+	OpOperationShowLatest AksOperationType = "operation-show-latest"
 )
 
 // generateToolDescription creates a tool description based on access level
 func generateToolDescription(accessLevel string) string {
 	baseDesc := "Unified tool for managing Azure Kubernetes Service (AKS) clusters and related operations.\n\nSupported operations:\n"
 
-	var clusterOps, nodepoolOps, accountOps []string
+	var clusterOps, nodepoolOps, snapshotOps, maintenanceOps, addonOps, accountOps, trackingOps []string
 
 	// Add read-only operations for all access levels
-	clusterOps = append(clusterOps, "show", "list", "get-versions", "check-network")
+	clusterOps = append(clusterOps, "show", "list", "get-versions", "get-upgrades", "check-network")
 	nodepoolOps = append(nodepoolOps, "nodepool-list", "nodepool-show")
+	snapshotOps = append(snapshotOps, "nodepool-snapshot-list")
+	maintenanceOps = append(maintenanceOps, "maintenanceconfiguration-list", "maintenanceconfiguration-show")
+	addonOps = append(addonOps, "addon-list", "addon-show")
 	accountOps = append(accountOps, "account-list")
+	trackingOps = append(trackingOps, "operation_status", "operation-show-latest")
 
 	// Add read-write operations for readwrite and admin
 	if accessLevel == "readwrite" || accessLevel == "admin" {
-		clusterOps = append(clusterOps, "create", "delete", "scale", "update", "upgrade")
-		nodepoolOps = append(nodepoolOps, "nodepool-add", "nodepool-delete", "nodepool-scale", "nodepool-upgrade")
+		clusterOps = append(clusterOps, "create", "delete", "scale", "update", "upgrade", "start", "stop")
+		nodepoolOps = append(nodepoolOps, "nodepool-add", "nodepool-delete", "nodepool-scale", "nodepool-upgrade",
+			"nodepool-start", "nodepool-stop")
+		snapshotOps = append(snapshotOps, "nodepool-snapshot-create")
+		maintenanceOps = append(maintenanceOps, "maintenanceconfiguration-add", "maintenanceconfiguration-update")
+		addonOps = append(addonOps, "addon-enable", "addon-disable", "addon-update", "enable-addons", "disable-addons")
 		accountOps = append(accountOps, "account-set", "login")
 	}
 
@@ -65,16 +104,29 @@ func generateToolDescription(accessLevel string) string {
 	desc := baseDesc
 	desc += fmt.Sprintf("- Cluster: %s\n", joinOps(clusterOps))
 	desc += fmt.Sprintf("- Nodepool: %s\n", joinOps(nodepoolOps))
+	desc += fmt.Sprintf("- Snapshot: %s\n", joinOps(snapshotOps))
+	desc += fmt.Sprintf("- Maintenance: %s\n", joinOps(maintenanceOps))
+	desc += fmt.Sprintf("- Addon: %s\n", joinOps(addonOps))
 	desc += fmt.Sprintf("- Account: %s\n", joinOps(accountOps))
+	desc += fmt.Sprintf("- Tracking: %s\n", joinOps(trackingOps))
+
+	desc += "\nParameters are typed: pass cluster_name, resource_group, node_count, kubernetes_version, nodepool_name and the other " +
+		"properties of the input schema instead of CLI flags. The schema of each operation is published under $defs.\n"
 
 	// Add examples based on access level
 	desc += "\nExamples:\n"
-	desc += "- Show cluster: operation=\"show\", args=\"--name myCluster --resource-group myRG\"\n"
-	desc += "- List nodepools: operation=\"nodepool-list\", args=\"--cluster-name myCluster --resource-group myRG\"\n"
+	desc += "- Show cluster: operation=\"show\", cluster_name=\"myCluster\", resource_group=\"myRG\"\n"
+	desc += "- List nodepools: operation=\"nodepool-list\", cluster_name=\"myCluster\", resource_group=\"myRG\"\n"
+	desc += "- Show planned maintenance: operation=\"maintenanceconfiguration-show\", cluster_name=\"myCluster\", resource_group=\"myRG\", " +
+		"config_name=\"aksManagedAutoUpgradeSchedule\"\n"
 
 	// Only show write operation examples if access level allows it
 	if accessLevel == "readwrite" || accessLevel == "admin" {
-		desc += "- Scale cluster: operation=\"scale\", args=\"--name myCluster --resource-group myRG --node-count 5\"\n"
+		desc += "- Scale cluster: operation=\"scale\", cluster_name=\"myCluster\", resource_group=\"myRG\", node_count=5\n"
+		desc += "- Enable monitoring: operation=\"enable-addons\", cluster_name=\"myCluster\", resource_group=\"myRG\", addons=\"monitoring\"\n"
+		desc += "- Start an upgrade without waiting: operation=\"upgrade\", cluster_name=\"myCluster\", resource_group=\"myRG\", " +
+			"kubernetes_version=\"1.30.4\", yes=true, no_wait=true, then poll operation=\"operation_status\" with the returned operation_id\n"
+		desc += "\nAdvanced: args appends raw az CLI flags not covered by the typed parameters, e.g. args=\"--tags env=dev\".\n"
 	}
 
 	return desc
@@ -95,35 +147,111 @@ func joinOps(ops []string) string {
 // RegisterAzAksOperations registers the AKS operations tool
 func RegisterAzAksOperations(cfg *config.ConfigData) mcp.Tool {
 	description := generateToolDescription(cfg.AccessLevel)
+	operations := operationsForAccessLevel(cfg.AccessLevel)
 
-	return mcp.NewTool("az_aks_operations",
+	options := []mcp.ToolOption{
 		mcp.WithDescription(description),
 		mcp.WithString("operation",
 			mcp.Required(),
 			mcp.Description("The operation to perform"),
+			mcp.Enum(operations...),
 		),
 		mcp.WithString("resource_type",
 			mcp.Description("The resource type (cluster, nodepool, account). Can be inferred from operation."),
 		),
-		mcp.WithString("args",
-			mcp.Required(),
-			mcp.Description("Arguments for the operation"),
-		),
-	)
+	}
+
+	// Publish every typed parameter used by an operation available at this access level
+	names := make([]string, 0, len(paramDefs))
+	for name := range paramDefs {
+		if len(operationsUsingParam(name, operations)) > 0 {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		def := paramDefs[name]
+		desc := fmt.Sprintf("%s. Used by: %s", def.Description, joinOps(operationsUsingParam(name, operations)))
+
+		switch def.Kind {
+		case paramInteger:
+			options = append(options, mcp.WithNumber(name,
+				mcp.Description(desc),
+				mcp.Min(float64(def.Min)),
+				mcp.Max(float64(def.Max)),
+			))
+		case paramBoolean:
+			options = append(options, mcp.WithBoolean(name, mcp.Description(desc)))
+		default:
+			propertyOptions := []mcp.PropertyOption{mcp.Description(desc)}
+			if len(def.Enum) > 0 {
+				propertyOptions = append(propertyOptions, mcp.Enum(def.Enum...))
+			} else if def.Pattern != nil {
+				propertyOptions = append(propertyOptions, mcp.Pattern(def.Pattern.String()))
+			}
+			options = append(options, mcp.WithString(name, propertyOptions...))
+		}
+	}
+
+	// The raw escape hatch is only offered when the access level permits it
+	if ValidateArgsAccess(cfg) == nil {
+		options = append(options, mcp.WithString("args",
+			mcp.Description("Advanced: additional raw az CLI arguments appended after the typed parameters"),
+		))
+	}
+
+	tool := mcp.NewTool("az_aks_operations", options...)
+
+	// Per-operation schemas describe which typed parameters each operation accepts and requires
+	tool.InputSchema.Defs = make(map[string]any, len(operations))
+	for _, operation := range operations {
+		tool.InputSchema.Defs[operation] = operationSchema(operation)
+	}
+
+	return tool
+}
+
+// operationsForAccessLevel returns the supported operations allowed at an access level
+func operationsForAccessLevel(accessLevel string) []string {
+	cfg := &config.ConfigData{AccessLevel: accessLevel}
+
+	var operations []string
+	for _, operation := range GetSupportedOperations() {
+		if ValidateOperationAccess(operation, cfg) == nil {
+			operations = append(operations, operation)
+		}
+	}
+	return operations
+}
+
+// ValidateArgsAccess checks if the raw args escape hatch is allowed for the access level.
+// Raw arguments bypass typed validation, so they require readwrite or admin access.
+func ValidateArgsAccess(cfg *config.ConfigData) error {
+	if cfg.AccessLevel != "readwrite" && cfg.AccessLevel != "admin" {
+		return fmt.Errorf("the 'args' parameter requires readwrite or admin access level; use the typed parameters instead")
+	}
+	return nil
 }
 
 // GetOperationAccessLevel returns the required access level for an operation
 func GetOperationAccessLevel(operation string) string {
 	readOnlyOps := []string{
 		string(OpClusterShow), string(OpClusterList), string(OpClusterGetVersions),
-		string(OpClusterCheckNetwork), string(OpNodepoolList), string(OpNodepoolShow),
-		string(OpAccountList),
+		string(OpClusterGetUpgrades), string(OpClusterCheckNetwork), string(OpNodepoolList),
+		string(OpNodepoolShow), string(OpNodepoolSnapshotList), string(OpMaintenanceConfigList),
+		string(OpMaintenanceConfigShow), string(OpAddonList), string(OpAddonShow),
+		string(OpAccountList), string(OpOperationStatus), string(OpOperationShowLatest),
 	}
 
 	readWriteOps := []string{
 		string(OpClusterCreate), string(OpClusterDelete), string(OpClusterScale),
-		string(OpClusterUpdate), string(OpClusterUpgrade), string(OpNodepoolAdd),
-		string(OpNodepoolDelete), string(OpNodepoolScale), string(OpNodepoolUpgrade),
+		string(OpClusterUpdate), string(OpClusterUpgrade), string(OpClusterStart),
+		string(OpClusterStop), string(OpNodepoolAdd), string(OpNodepoolDelete),
+		string(OpNodepoolScale), string(OpNodepoolUpgrade), string(OpNodepoolStart),
+		string(OpNodepoolStop), string(OpNodepoolSnapshotCreate), string(OpMaintenanceConfigAdd),
+		string(OpMaintenanceConfigUpdate), string(OpAddonEnable), string(OpAddonDisable),
+		string(OpAddonUpdate), string(OpEnableAddons), string(OpDisableAddons),
 		string(OpAccountSet), string(OpLogin),
 	}
 
@@ -182,6 +310,9 @@ func MapOperationToCommand(operation string) (string, error) {
 		string(OpClusterGetVersions):    "az aks get-versions",
 		string(OpClusterCheckNetwork):   "az aks check-network outbound",
 		string(OpClusterGetCredentials): "az aks get-credentials",
+		string(OpClusterGetUpgrades):    "az aks get-upgrades",
+		string(OpClusterStart):          "az aks start",
+		string(OpClusterStop):           "az aks stop",
 
 		// Nodepool operations
 		string(OpNodepoolList):    "az aks nodepool list",
@@ -190,6 +321,30 @@ func MapOperationToCommand(operation string) (string, error) {
 		string(OpNodepoolDelete):  "az aks nodepool delete",
 		string(OpNodepoolScale):   "az aks nodepool scale",
 		string(OpNodepoolUpgrade): "az aks nodepool upgrade",
+		string(OpNodepoolStart):   "az aks nodepool start",
+		string(OpNodepoolStop):    "az aks nodepool stop",
+
+		// Nodepool snapshot operations
+		string(OpNodepoolSnapshotCreate): "az aks nodepool snapshot create",
+		string(OpNodepoolSnapshotList):   "az aks nodepool snapshot list",
+
+		// Maintenance configuration operations
+		string(OpMaintenanceConfigList):   "az aks maintenanceconfiguration list",
+		string(OpMaintenanceConfigShow):   "az aks maintenanceconfiguration show",
+		string(OpMaintenanceConfigAdd):    "az aks maintenanceconfiguration add",
+		string(OpMaintenanceConfigUpdate): "az aks maintenanceconfiguration update",
+
+		// Addon operations
+		string(OpAddonList):     "az aks addon list",
+		string(OpAddonShow):     "az aks addon show",
+		string(OpAddonEnable):   "az aks addon enable",
+		string(OpAddonDisable):  "az aks addon disable",
+		string(OpAddonUpdate):   "az aks addon update",
+		string(OpEnableAddons):  "az aks enable-addons",
+		string(OpDisableAddons): "az aks disable-addons",
+
+		// Operation history
+		string(OpOperationShowLatest): "az aks operation show-latest",
 
 		// Account operations
 		string(OpAccountList): "az account list",
@@ -212,11 +367,23 @@ func GetSupportedOperations() []string {
 		string(OpClusterShow), string(OpClusterList), string(OpClusterCreate),
 		string(OpClusterDelete), string(OpClusterScale), string(OpClusterUpdate),
 		string(OpClusterUpgrade), string(OpClusterGetVersions), string(OpClusterCheckNetwork),
-		string(OpClusterGetCredentials),
+		string(OpClusterGetCredentials), string(OpClusterGetUpgrades), string(OpClusterStart),
+		string(OpClusterStop),
 		// Nodepool operations
 		string(OpNodepoolList), string(OpNodepoolShow), string(OpNodepoolAdd),
 		string(OpNodepoolDelete), string(OpNodepoolScale), string(OpNodepoolUpgrade),
+		string(OpNodepoolStart), string(OpNodepoolStop),
+		// Nodepool snapshot operations
+		string(OpNodepoolSnapshotCreate), string(OpNodepoolSnapshotList),
+		// Maintenance configuration operations
+		string(OpMaintenanceConfigList), string(OpMaintenanceConfigShow),
+		string(OpMaintenanceConfigAdd), string(OpMaintenanceConfigUpdate),
+		// Addon operations
+		string(OpAddonList), string(OpAddonShow), string(OpAddonEnable), string(OpAddonDisable),
+		string(OpAddonUpdate), string(OpEnableAddons), string(OpDisableAddons),
 		// Account operations
 		string(OpAccountList), string(OpAccountSet), string(OpLogin),
+		// Long-running operation tracking
+		string(OpOperationStatus), string(OpOperationShowLatest),
 	}
 }
@@ -1,9 +1,11 @@
 package azaks
 
 import (
+	"strings"
 	"testing"
 
 	"github.com/Azure/aks-mcp/internal/config"
+	"github.com/Azure/aks-mcp/internal/security"
 )
 
 func TestRegisterAzAksOperations_Tool(t *testing.T) {
@@ -72,3 +74,59 @@ func TestValidateOperationAccess_ChecksAccessLevels(t *testing.T) {
 		}
 	}
 }
+
+func TestReadOnlyOperationsMatchReadPolicy(t *testing.T) {
+	// Every readonly operation must run under the readonly command policy, and the write
+	// operations sharing a command group with a read operation must not
+	validator := security.NewValidator(&security.SecurityConfig{AccessLevel: "readonly"})
+
+	for _, operation := range GetSupportedOperations() {
+		if GetOperationAccessLevel(operation) != "readonly" || operation == string(OpOperationStatus) {
+			continue
+		}
+		cmd, err := MapOperationToCommand(operation)
+		if err != nil {
+			t.Fatalf("Unexpected error for %s: %v", operation, err)
+		}
+		if err := validator.ValidateCommand(cmd, security.CommandTypeAz); err != nil {
+			t.Errorf("Expected readonly operation '%s' (%s) to be allowed by the read policy, got %v", operation, cmd, err)
+		}
+	}
+
+	writeOps := []AksOperationType{
+		OpClusterStart, OpClusterStop, OpNodepoolStart, OpNodepoolStop, OpNodepoolSnapshotCreate,
+		OpMaintenanceConfigAdd, OpMaintenanceConfigUpdate, OpAddonEnable, OpAddonDisable, OpAddonUpdate,
+		OpEnableAddons, OpDisableAddons,
+	}
+	for _, operation := range writeOps {
+		if level := GetOperationAccessLevel(string(operation)); level != "readwrite" {
+			t.Errorf("Expected operation '%s' to require readwrite, got %s", operation, level)
+		}
+		cmd, err := MapOperationToCommand(string(operation))
+		if err != nil {
+			t.Fatalf("Unexpected error for %s: %v", operation, err)
+		}
+		if err := validator.ValidateCommand(cmd, security.CommandTypeAz); err == nil {
+			t.Errorf("Expected write operation '%s' (%s) to be rejected by the read policy", operation, cmd)
+		}
+	}
+}
+
+func TestGenerateToolDescription_ListsOperationsByAccessLevel(t *testing.T) {
+	readonly := generateToolDescription("readonly")
+	readwrite := generateToolDescription("readwrite")
+
+	for _, op := range []string{"get-upgrades", "maintenanceconfiguration-show", "addon-list", "nodepool-snapshot-list", "operation-show-latest"} {
+		if !strings.Contains(readonly, op) {
+			t.Errorf("Expected readonly description to list %s", op)
+		}
+	}
+	for _, op := range []string{"nodepool-stop", "maintenanceconfiguration-add", "enable-addons", "nodepool-snapshot-create"} {
+		if strings.Contains(readonly, op) {
+			t.Errorf("Expected readonly description not to list %s", op)
+		}
+		if !strings.Contains(readwrite, op) {
+			t.Errorf("Expected readwrite description to list %s", op)
+		}
+	}
+}
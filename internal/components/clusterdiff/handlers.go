@@ -0,0 +1,287 @@
+// Package clusterdiff compares the configuration of two AKS clusters to find drift between them.
+package clusterdiff
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/Azure/aks-mcp/internal/azureclient"
+	"github.com/Azure/aks-mcp/internal/components/common"
+	"github.com/Azure/aks-mcp/internal/components/compute"
+	"github.com/Azure/aks-mcp/internal/components/network/resourcehelpers"
+	"github.com/Azure/aks-mcp/internal/config"
+	"github.com/Azure/aks-mcp/internal/tools"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/arm"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/compute/armcompute/v4"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/containerservice/armcontainerservice/v2"
+)
+
+// ClusterRef identifies one of the compared clusters
+type ClusterRef struct {
+	SubscriptionID string `json:"subscription_id"`
+	ResourceGroup  string `json:"resource_group"`
+	ClusterName    string `json:"cluster_name"`
+}
+
+// Difference is a setting whose value differs between the clusters
+type Difference struct {
+	Setting string      `json:"setting"`
+	Base    interface{} `json:"base"`
+	Compare interface{} `json:"compare"`
+}
+
+// Setting is a setting that only one of the clusters has
+type Setting struct {
+	Setting string      `json:"setting"`
+	Value   interface{} `json:"value"`
+}
+
+// Result is the response of the aks_cluster_diff tool
+type Result struct {
+	Base          ClusterRef   `json:"base"`
+	Compare       ClusterRef   `json:"compare"`
+	Identical     bool         `json:"identical"`
+	Differences   []Difference `json:"differences"`
+	OnlyInBase    []Setting    `json:"only_in_base"`
+	OnlyInCompare []Setting    `json:"only_in_compare"`
+	Warnings      []string     `json:"warnings,omitempty"`
+}
+
+// GetClusterDiffHandler returns a handler for the aks_cluster_diff tool
+func GetClusterDiffHandler(client *azureclient.AzureClient, cfg *config.ConfigData) tools.ResourceHandler {
+	return tools.ResourceHandlerFunc(func(params map[string]interface{}, _ *config.ConfigData) (string, error) {
+		subID, rg, clusterName, err := common.ExtractAKSParameters(params)
+		if err != nil {
+			return "", err
+		}
+		base := ClusterRef{SubscriptionID: subID, ResourceGroup: rg, ClusterName: clusterName}
+
+		compare, err := compareCluster(base, params)
+		if err != nil {
+			return "", err
+		}
+
+		baseCtx, err := common.TenantContext(context.Background(), client, params)
+		if err != nil {
+			return "", err
+		}
+		// The compare cluster shares the base tenant when it is in the same subscription
+		compareCtx := baseCtx
+		if !strings.EqualFold(compare.SubscriptionID, base.SubscriptionID) {
+			compareCtx = context.Background()
+		}
+		if tenantID, ok := params["compare_tenant_id"].(string); ok && tenantID != "" {
+			if compareCtx, err = common.TenantContext(context.Background(), client, map[string]interface{}{"tenant_id": tenantID}); err != nil {
+				return "", err
+			}
+		}
+
+		baseSettings, baseWarnings, err := collectSettings(baseCtx, client, base)
+		if err != nil {
+			return "", err
+		}
+		compareSettings, compareWarnings, err := collectSettings(compareCtx, client, compare)
+		if err != nil {
+			return "", err
+		}
+
+		result := diff(baseSettings, compareSettings)
+		result.Base = base
+		result.Compare = compare
+		result.Warnings = append(baseWarnings, compareWarnings...)
+
+		resultJSON, err := json.MarshalIndent(result, "", "  ")
+		if err != nil {
+			return "", fmt.Errorf("failed to marshal cluster diff to JSON: %v", err)
+		}
+		return string(resultJSON), nil
+	})
+}
+
+// compareCluster returns the cluster to compare with; its subscription and resource group default to the base cluster's
+func compareCluster(base ClusterRef, params map[string]interface{}) (ClusterRef, error) {
+	compare := base
+	name, ok := params["compare_cluster_name"].(string)
+	if !ok || name == "" {
+		return compare, fmt.Errorf("missing or invalid compare_cluster_name parameter")
+	}
+	compare.ClusterName = name
+	if subID, ok := params["compare_subscription_id"].(string); ok && subID != "" {
+		compare.SubscriptionID = subID
+	}
+	if rg, ok := params["compare_resource_group"].(string); ok && rg != "" {
+		compare.ResourceGroup = rg
+	}
+	return compare, nil
+}
+
+// collectSettings fetches the current state of a cluster with its node pools, VMSS models and network resources and returns their normalized settings.
+// Only failing to get the cluster is an error; the other lookups are reported as warnings so the rest can still be compared.
+func collectSettings(ctx context.Context, client *azureclient.AzureClient, ref ClusterRef) (map[string]interface{}, []string, error) {
+	cluster, err := client.GetAKSClusterStatus(ctx, ref.SubscriptionID, ref.ResourceGroup, ref.ClusterName)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to get cluster %s/%s: %v", ref.ResourceGroup, ref.ClusterName, err)
+	}
+
+	settings := make(map[string]interface{})
+	var warnings []string
+	warn := func(format string, args ...interface{}) {
+		warnings = append(warnings, fmt.Sprintf("%s: ", ref.ClusterName)+fmt.Sprintf(format, args...))
+	}
+
+	clusterMap, err := toMap(cluster)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to convert cluster %s: %v", ref.ClusterName, err)
+	}
+	normalizeCluster(clusterMap, settings)
+
+	pools, err := compute.GetNodePoolsFromAKS(ctx, cluster, client)
+	if err != nil {
+		warn("failed to get node pools: %v", err)
+	}
+	for _, pool := range pools {
+		if pool.Name == nil {
+			continue
+		}
+		poolMap, err := toMap(pool)
+		if err != nil {
+			warn("failed to convert node pool %s: %v", *pool.Name, err)
+			continue
+		}
+		normalizeAgentPool(*pool.Name, poolMap, settings)
+
+		if pool.Type != nil && *pool.Type != armcontainerservice.AgentPoolTypeVirtualMachineScaleSets {
+			continue
+		}
+		if err := collectVMSS(ctx, client, cluster, *pool.Name, settings); err != nil {
+			warn("node pool %s: %v", *pool.Name, err)
+		}
+	}
+
+	for _, err := range collectNetwork(ctx, client, cluster, settings) {
+		warn("%v", err)
+	}
+	return settings, warnings, nil
+}
+
+// collectVMSS adds the model settings of the VMSS behind a node pool
+func collectVMSS(ctx context.Context, client *azureclient.AzureClient, cluster *armcontainerservice.ManagedCluster, poolName string, settings map[string]interface{}) error {
+	vmssID, err := compute.GetVMSSIDFromNodePool(ctx, cluster, poolName, client)
+	if err != nil {
+		return fmt.Errorf("failed to get VMSS ID: %v", err)
+	}
+
+	vmssInterface, err := client.GetResourceByID(ctx, vmssID)
+	if err != nil {
+		return fmt.Errorf("failed to get VMSS details: %v", err)
+	}
+	vmss, ok := vmssInterface.(*armcompute.VirtualMachineScaleSet)
+	if !ok {
+		return fmt.Errorf("unexpected resource type returned for VMSS")
+	}
+
+	vmssMap, err := toMap(vmss)
+	if err != nil {
+		return fmt.Errorf("failed to convert VMSS: %v", err)
+	}
+	normalizeVMSS(poolName, vmssMap, settings)
+	return nil
+}
+
+// collectNetwork adds the settings of the cluster's VNet, subnet and subnet NSG and returns the lookups that failed
+func collectNetwork(ctx context.Context, client *azureclient.AzureClient, cluster *armcontainerservice.ManagedCluster, settings map[string]interface{}) []error {
+	var errs []error
+
+	subnetID, err := resourcehelpers.GetSubnetIDFromAKS(ctx, cluster, client)
+	if err != nil {
+		return append(errs, fmt.Errorf("failed to find subnet: %v", err))
+	}
+	subnetResourceID, err := arm.ParseResourceID(subnetID)
+	if err != nil || subnetResourceID.Parent == nil {
+		return append(errs, fmt.Errorf("invalid subnet ID: %s", subnetID))
+	}
+	subID := subnetResourceID.SubscriptionID
+	rg := subnetResourceID.ResourceGroupName
+	vnetName := subnetResourceID.Parent.Name
+
+	if vnet, err := client.GetVirtualNetwork(ctx, subID, rg, vnetName); err != nil {
+		errs = append(errs, fmt.Errorf("failed to get VNet: %v", err))
+	} else if vnetMap, err := toMap(vnet); err == nil {
+		normalizeNetwork("network.vnet", vnetMap, settings, "subnets", "virtualNetworkPeerings")
+	}
+
+	subnet, err := client.GetSubnet(ctx, subID, rg, vnetName, subnetResourceID.Name)
+	if err != nil {
+		return append(errs, fmt.Errorf("failed to get subnet: %v", err))
+	}
+	if subnetMap, err := toMap(subnet); err == nil {
+		normalizeNetwork("network.subnet", subnetMap, settings, "ipConfigurations")
+	}
+
+	// Clusters without an NSG on the subnet simply have no NSG rules to compare
+	if subnet.Properties == nil || subnet.Properties.NetworkSecurityGroup == nil || subnet.Properties.NetworkSecurityGroup.ID == nil {
+		return errs
+	}
+	nsgResourceID, err := arm.ParseResourceID(*subnet.Properties.NetworkSecurityGroup.ID)
+	if err != nil {
+		return append(errs, fmt.Errorf("invalid NSG ID: %s", *subnet.Properties.NetworkSecurityGroup.ID))
+	}
+	nsg, err := client.GetNetworkSecurityGroup(ctx, nsgResourceID.SubscriptionID, nsgResourceID.ResourceGroupName, nsgResourceID.Name)
+	if err != nil {
+		return append(errs, fmt.Errorf("failed to get NSG: %v", err))
+	}
+	if nsgMap, err := toMap(nsg); err == nil {
+		normalizeNetwork("network.nsg", nsgMap, settings, "defaultSecurityRules", "subnets", "networkInterfaces", "flowLogs")
+	}
+	return errs
+}
+
+// diff compares two sets of normalized settings
+func diff(base, compare map[string]interface{}) Result {
+	result := Result{
+		Differences:   []Difference{},
+		OnlyInBase:    []Setting{},
+		OnlyInCompare: []Setting{},
+	}
+
+	keys := make([]string, 0, len(base)+len(compare))
+	for key := range base {
+		keys = append(keys, key)
+	}
+	for key := range compare {
+		if _, ok := base[key]; !ok {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		baseValue, inBase := base[key]
+		compareValue, inCompare := compare[key]
+		switch {
+		case !inCompare:
+			result.OnlyInBase = append(result.OnlyInBase, Setting{Setting: key, Value: baseValue})
+		case !inBase:
+			result.OnlyInCompare = append(result.OnlyInCompare, Setting{Setting: key, Value: compareValue})
+		case !equal(baseValue, compareValue):
+			result.Differences = append(result.Differences, Difference{Setting: key, Base: baseValue, Compare: compareValue})
+		}
+	}
+
+	result.Identical = len(result.Differences) == 0 && len(result.OnlyInBase) == 0 && len(result.OnlyInCompare) == 0
+	return result
+}
+
+// equal compares two setting values; strings compare case-insensitively because ARM does not preserve the case of enum values
+func equal(a, b interface{}) bool {
+	if as, ok := a.(string); ok {
+		if bs, ok := b.(string); ok {
+			return strings.EqualFold(as, bs)
+		}
+	}
+	return reflect.DeepEqual(a, b)
+}
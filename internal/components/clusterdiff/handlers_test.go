@@ -0,0 +1,249 @@
+package clusterdiff
+
+import (
+	"encoding/json"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/Azure/aks-mcp/internal/azureclient"
+	"github.com/Azure/aks-mcp/internal/azureclient/fakearm"
+	"github.com/Azure/aks-mcp/internal/azureclient/fakearm/armtest"
+	"github.com/Azure/aks-mcp/internal/config"
+)
+
+func newTestClient(t *testing.T) (*azureclient.AzureClient, *config.ConfigData) {
+	t.Helper()
+
+	_, client, cfg := armtest.NewClient(t, filepath.Join("testdata", "fixtures.json"))
+	return client, cfg
+}
+
+func TestGetClusterDiffHandler(t *testing.T) {
+	client, cfg := newTestClient(t)
+
+	output, err := GetClusterDiffHandler(client, cfg).Handle(map[string]interface{}{
+		"subscription_id":      fakearm.SubscriptionID,
+		"resource_group":       fakearm.ResourceGroup,
+		"cluster_name":         fakearm.ClusterName,
+		"compare_cluster_name": "test-cluster-prod",
+	}, cfg)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	var result Result
+	if err := json.Unmarshal([]byte(output), &result); err != nil {
+		t.Fatalf("Failed to parse result: %v\n%s", err, output)
+	}
+	if result.Compare.ClusterName != "test-cluster-prod" || result.Compare.ResourceGroup != fakearm.ResourceGroup {
+		t.Errorf("Unexpected compare cluster: %+v", result.Compare)
+	}
+	if result.Identical {
+		t.Error("Expected the clusters to differ")
+	}
+	if len(result.Warnings) != 0 {
+		t.Errorf("Unexpected warnings: %v", result.Warnings)
+	}
+
+	differences := make(map[string]Difference)
+	for _, difference := range result.Differences {
+		differences[difference.Setting] = difference
+	}
+	expected := map[string][2]interface{}{
+		"cluster.kubernetesVersion":                                  {"1.30.4", "1.31.1"},
+		"cluster.currentKubernetesVersion":                           {"1.30.4", "1.31.1"},
+		"cluster.networkProfile.networkPlugin":                       {"kubenet", "azure"},
+		"agentPools.nodepool1.vmSize":                                {"Standard_DS2_v2", "Standard_D4s_v5"},
+		"agentPools.nodepool1.orchestratorVersion":                   {"1.30.4", "1.31.1"},
+		"vmss.nodepool1.sku.name":                                    {"Standard_DS2_v2", "Standard_D4s_v5"},
+		"network.nsg.securityRules[allow-https].properties.priority": {float64(100), float64(200)},
+	}
+	for setting, values := range expected {
+		difference, ok := differences[setting]
+		if !ok {
+			t.Errorf("Expected a difference in %s, got %+v", setting, result.Differences)
+			continue
+		}
+		if difference.Base != values[0] || difference.Compare != values[1] {
+			t.Errorf("Expected %s to differ as %v -> %v, got %v -> %v", setting, values[0], values[1], difference.Base, difference.Compare)
+		}
+	}
+	if len(result.Differences) != len(expected) {
+		t.Errorf("Expected %d differences, got %+v", len(expected), result.Differences)
+	}
+
+	onlyInCompare := make(map[string]interface{})
+	for _, setting := range result.OnlyInCompare {
+		onlyInCompare[setting.Setting] = setting.Value
+	}
+	for _, setting := range []string{
+		"agentPools.user1.vmSize",
+		"cluster.addonProfiles.azureKeyvaultSecretsProvider.enabled",
+		"cluster.addonProfiles.azureKeyvaultSecretsProvider.config.enableSecretRotation",
+		"network.nsg.securityRules[deny-ssh].properties.destinationPortRange",
+	} {
+		if _, ok := onlyInCompare[setting]; !ok {
+			t.Errorf("Expected %s only in the compare cluster, got %v", setting, result.OnlyInCompare)
+		}
+	}
+	if len(result.OnlyInBase) != 0 {
+		t.Errorf("Unexpected settings only in the base cluster: %+v", result.OnlyInBase)
+	}
+
+	// IDs, etags, provisioning states, timestamps and cluster-specific names are not drift
+	for _, setting := range append(keys(differences), keys(onlyInCompare)...) {
+		for _, ignored := range []string{"etag", "provisioningState", "timeCreated", "clientId", "resourceId", "dnsPrefix", "fqdn"} {
+			if strings.Contains(setting, ignored) {
+				t.Errorf("Setting %s should be ignored", setting)
+			}
+		}
+		if strings.HasPrefix(setting, "vmss.user1") {
+			t.Errorf("Node pool user1 has no VMSS, got %s", setting)
+		}
+	}
+}
+
+func TestGetClusterDiffHandlerIdentical(t *testing.T) {
+	client, cfg := newTestClient(t)
+
+	output, err := GetClusterDiffHandler(client, cfg).Handle(map[string]interface{}{
+		"subscription_id":         fakearm.SubscriptionID,
+		"resource_group":          fakearm.ResourceGroup,
+		"cluster_name":            fakearm.ClusterName,
+		"compare_subscription_id": fakearm.SubscriptionID,
+		"compare_resource_group":  fakearm.ResourceGroup,
+		"compare_cluster_name":    fakearm.ClusterName,
+	}, cfg)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	var result Result
+	if err := json.Unmarshal([]byte(output), &result); err != nil {
+		t.Fatalf("Failed to parse result: %v", err)
+	}
+	if !result.Identical || len(result.Differences) != 0 {
+		t.Errorf("Expected identical clusters, got %s", output)
+	}
+}
+
+func TestGetClusterDiffHandlerErrors(t *testing.T) {
+	client, cfg := newTestClient(t)
+
+	tests := []struct {
+		name   string
+		params map[string]interface{}
+		errMsg string
+	}{
+		{
+			name: "missing compare cluster",
+			params: map[string]interface{}{
+				"subscription_id": fakearm.SubscriptionID,
+				"resource_group":  fakearm.ResourceGroup,
+				"cluster_name":    fakearm.ClusterName,
+			},
+			errMsg: "compare_cluster_name",
+		},
+		{
+			name: "compare cluster not found",
+			params: map[string]interface{}{
+				"subscription_id":      fakearm.SubscriptionID,
+				"resource_group":       fakearm.ResourceGroup,
+				"cluster_name":         fakearm.ClusterName,
+				"compare_cluster_name": "missing-cluster",
+			},
+			errMsg: "failed to get cluster test-rg/missing-cluster",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := GetClusterDiffHandler(client, cfg).Handle(tt.params, cfg)
+			if err == nil || !strings.Contains(err.Error(), tt.errMsg) {
+				t.Errorf("Expected error containing %q, got %v", tt.errMsg, err)
+			}
+		})
+	}
+}
+
+func TestFlatten(t *testing.T) {
+	settings := make(map[string]interface{})
+	flatten("pool", map[string]interface{}{
+		"id":                     "/subscriptions/x",
+		"vnetSubnetID":           "/subscriptions/x/subnets/a",
+		"provisioningState":      "Succeeded",
+		"availabilityZones":      []interface{}{"3", "1", "2"},
+		"nodeTaints":             []interface{}{},
+		"lastModifiedTime":       "2026-01-01T00:00:00Z",
+		"maxPods":                float64(30),
+		"upgradeSettings":        map[string]interface{}{"maxSurge": "33%"},
+		"securityRules":          []interface{}{map[string]interface{}{"name": "b", "priority": float64(1)}},
+		"enableAutoScaling":      nil,
+		"enableEncryptionAtHost": false,
+	}, settings)
+
+	expected := map[string]interface{}{
+		"pool.vnetSubnetID":              "a",
+		"pool.availabilityZones":         []interface{}{"1", "2", "3"},
+		"pool.maxPods":                   float64(30),
+		"pool.upgradeSettings.maxSurge":  "33%",
+		"pool.securityRules[b].priority": float64(1),
+		"pool.enableEncryptionAtHost":    false,
+	}
+	if len(settings) != len(expected) {
+		t.Fatalf("Expected %d settings, got %v", len(expected), settings)
+	}
+	for key, value := range expected {
+		if !equal(settings[key], value) {
+			t.Errorf("Expected %s = %v, got %v", key, value, settings[key])
+		}
+	}
+}
+
+func TestDiffSubnetID(t *testing.T) {
+	tests := []struct {
+		name          string
+		compareSubnet string
+		differs       bool
+	}{
+		{"same subnet name in another resource group", "/subscriptions/y/resourceGroups/prod-net/providers/Microsoft.Network/virtualNetworks/prod-vnet/subnets/nodes", false},
+		{"other subnet", "/subscriptions/x/resourceGroups/net/providers/Microsoft.Network/virtualNetworks/vnet/subnets/legacy", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			base := make(map[string]interface{})
+			compare := make(map[string]interface{})
+			normalizeAgentPool("nodepool1", map[string]interface{}{
+				"id":           "/subscriptions/x/resourceGroups/a/providers/Microsoft.ContainerService/managedClusters/a/agentPools/nodepool1",
+				"vnetSubnetID": "/subscriptions/x/resourceGroups/net/providers/Microsoft.Network/virtualNetworks/vnet/subnets/nodes",
+			}, base)
+			normalizeAgentPool("nodepool1", map[string]interface{}{
+				"id":           "/subscriptions/y/resourceGroups/b/providers/Microsoft.ContainerService/managedClusters/b/agentPools/nodepool1",
+				"vnetSubnetID": tt.compareSubnet,
+			}, compare)
+
+			// Subnet references are compared by subnet name, and the node pools' own resource IDs are ignored
+			result := diff(base, compare)
+			if !tt.differs {
+				if len(result.Differences) != 0 {
+					t.Errorf("Expected no differences, got %+v", result.Differences)
+				}
+				return
+			}
+			if len(result.Differences) != 1 || result.Differences[0].Setting != "agentPools.nodepool1.vnetSubnetID" ||
+				result.Differences[0].Base != "nodes" || result.Differences[0].Compare != "legacy" {
+				t.Errorf("Expected only the subnet name to differ, got %+v", result.Differences)
+			}
+		})
+	}
+}
+
+func keys[V any](m map[string]V) []string {
+	out := make([]string, 0, len(m))
+	for key := range m {
+		out = append(out, key)
+	}
+	return out
+}
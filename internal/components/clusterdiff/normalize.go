@@ -0,0 +1,220 @@
+package clusterdiff
+
+import (
+	"encoding/json"
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+)
+
+// clusterOnlyProperties are ManagedCluster properties that identify the cluster rather than configure it,
+// or that are compared separately, so they are left out of the diff
+var clusterOnlyProperties = map[string]bool{
+	"agentPoolProfiles":       true,
+	"azurePortalFQDN":         true,
+	"dnsPrefix":               true,
+	"fqdnSubdomain":           true,
+	"identityProfile":         true,
+	"linuxProfile":            true,
+	"maxAgentPools":           true,
+	"nodeResourceGroup":       true,
+	"privateLinkResources":    true,
+	"servicePrincipalProfile": true,
+	"windowsProfile":          true,
+}
+
+// vmssOnlyProfiles are VMSS virtual machine profile sections that hold per-cluster names, keys and references
+var vmssOnlyProfiles = map[string]bool{
+	"networkProfile": true,
+	"osProfile":      true,
+}
+
+// volatileKeys are lowercased settings that identify or track the state of a resource rather than configure it:
+// resource IDs, etags, provisioning and power states, FQDNs, timestamps and the IDs of per-cluster managed identities.
+// References to other resources, such as vnetSubnetID, are compared by the name of the referenced resource only.
+var volatileKeys = map[string]bool{
+	"id":                true,
+	"etag":              true,
+	"provisioningstate": true,
+	"powerstate":        true,
+	"systemdata":        true,
+	"resourceguid":      true,
+	"resourceuid":       true,
+	"uniqueid":          true,
+	"issuerurl":         true,
+	"fqdn":              true,
+	"privatefqdn":       true,
+	"timecreated":       true,
+	"createdat":         true,
+	"lastmodifiedat":    true,
+	"lastmodifiedtime":  true,
+	"clientid":          true,
+	"objectid":          true,
+	"principalid":       true,
+	"resourceid":        true,
+}
+
+// ignoredKey reports whether a setting is one of the volatileKeys
+func ignoredKey(key string) bool {
+	return volatileKeys[strings.ToLower(key)]
+}
+
+// referenceName returns the name of the resource an ARM resource ID refers to, so references to resources with
+// the same name in other subscriptions or resource groups are equal. Other values are returned unchanged.
+func referenceName(value interface{}) interface{} {
+	id, ok := value.(string)
+	if !ok || !strings.HasPrefix(strings.ToLower(id), "/subscriptions/") {
+		return value
+	}
+	id = strings.TrimSuffix(id, "/")
+	return id[strings.LastIndex(id, "/")+1:]
+}
+
+// toMap converts an Azure SDK model to its ARM JSON form
+func toMap(resource interface{}) (map[string]interface{}, error) {
+	data, err := json.Marshal(resource)
+	if err != nil {
+		return nil, err
+	}
+	var out map[string]interface{}
+	if err := json.Unmarshal(data, &out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// flatten adds the settings in value to out as dotted paths under prefix.
+// Lists of objects with a name are keyed by name so reordering is not a difference,
+// and lists of scalars are sorted for the same reason. Resource references are replaced by the referenced name.
+// Null and empty values are left out, so a setting that is unset on one cluster and empty on the other is not a difference.
+func flatten(prefix string, value interface{}, out map[string]interface{}) {
+	switch v := value.(type) {
+	case nil:
+		return
+	case map[string]interface{}:
+		for key, child := range v {
+			if ignoredKey(key) {
+				continue
+			}
+			flatten(join(prefix, key), child, out)
+		}
+	case []interface{}:
+		if len(v) == 0 {
+			return
+		}
+		if !scalars(v) {
+			for i, item := range v {
+				key := fmt.Sprintf("[%d]", i)
+				if obj, ok := item.(map[string]interface{}); ok {
+					if name, ok := obj["name"].(string); ok && name != "" {
+						key = "[" + name + "]"
+						item = withoutName(obj)
+					}
+				}
+				flatten(prefix+key, item, out)
+			}
+			return
+		}
+		sorted := make([]interface{}, 0, len(v))
+		for _, item := range v {
+			sorted = append(sorted, referenceName(item))
+		}
+		sort.Slice(sorted, func(i, j int) bool {
+			return fmt.Sprint(sorted[i]) < fmt.Sprint(sorted[j])
+		})
+		out[prefix] = sorted
+	default:
+		out[prefix] = referenceName(v)
+	}
+}
+
+// scalars reports whether every item of a list is a scalar
+func scalars(items []interface{}) bool {
+	for _, item := range items {
+		switch item.(type) {
+		case map[string]interface{}, []interface{}:
+			return false
+		}
+	}
+	return true
+}
+
+// withoutName returns a copy of obj without its name, which is already part of the setting path
+func withoutName(obj map[string]interface{}) map[string]interface{} {
+	out := make(map[string]interface{}, len(obj))
+	for key, value := range obj {
+		if key != "name" {
+			out[key] = value
+		}
+	}
+	return out
+}
+
+// join appends key to a dotted setting path
+func join(prefix, key string) string {
+	if prefix == "" {
+		return key
+	}
+	return prefix + "." + key
+}
+
+// normalizeCluster returns the settings of a ManagedCluster, without its node pools
+func normalizeCluster(cluster map[string]interface{}, out map[string]interface{}) {
+	flatten("cluster.location", cluster["location"], out)
+	flatten("cluster.sku", cluster["sku"], out)
+	if identity, ok := cluster["identity"].(map[string]interface{}); ok {
+		flatten("cluster.identity.type", identity["type"], out)
+	}
+
+	properties, _ := cluster["properties"].(map[string]interface{})
+	for key, value := range properties {
+		if clusterOnlyProperties[key] || ignoredKey(key) {
+			continue
+		}
+		flatten(join("cluster", key), value, out)
+	}
+}
+
+// normalizeAgentPool returns the settings of a node pool, keyed by the node pool name
+func normalizeAgentPool(name string, pool map[string]interface{}, out map[string]interface{}) {
+	flatten("agentPools."+name, withoutName(pool), out)
+}
+
+// normalizeVMSS returns the model settings of the VMSS behind a node pool, keyed by the node pool name.
+// Tags and the OS and network profiles name per-cluster resources, so they are left out.
+func normalizeVMSS(poolName string, vmss map[string]interface{}, out map[string]interface{}) {
+	prefix := "vmss." + poolName
+	flatten(join(prefix, "sku"), vmss["sku"], out)
+	flatten(join(prefix, "zones"), vmss["zones"], out)
+
+	properties, _ := vmss["properties"].(map[string]interface{})
+	for key, value := range properties {
+		if ignoredKey(key) {
+			continue
+		}
+		if key != "virtualMachineProfile" {
+			flatten(join(prefix, key), value, out)
+			continue
+		}
+		profile, _ := value.(map[string]interface{})
+		for section, settings := range profile {
+			if vmssOnlyProfiles[section] || ignoredKey(section) {
+				continue
+			}
+			flatten(join(prefix, "virtualMachineProfile."+section), settings, out)
+		}
+	}
+}
+
+// normalizeNetwork adds the settings of a network resource's properties under prefix,
+// leaving out the listed properties
+func normalizeNetwork(prefix string, resource map[string]interface{}, out map[string]interface{}, skip ...string) {
+	properties, _ := resource["properties"].(map[string]interface{})
+	for key, value := range properties {
+		if ignoredKey(key) || slices.Contains(skip, key) {
+			continue
+		}
+		flatten(join(prefix, key), value, out)
+	}
+}
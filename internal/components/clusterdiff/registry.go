@@ -0,0 +1,44 @@
+package clusterdiff
+
+import (
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// RegisterClusterDiffTool registers the aks_cluster_diff tool
+func RegisterClusterDiffTool() mcp.Tool {
+	return mcp.NewTool(
+		"aks_cluster_diff",
+		mcp.WithDescription("Compare the configuration of two AKS clusters, e.g. staging and production, and return the settings that differ. "+
+			"Compares the cluster (Kubernetes version, SKU, network profile, addons, autoscaler profile, security profile and other profiles), "+
+			"node pools, their VMSS models, and the VNet, subnet and NSG rules of each cluster. "+
+			"Resource IDs, etags, provisioning states and timestamps are ignored, and references such as node pool subnet IDs are compared by resource name only; node pools, VMSS and NSG rules are matched by name."),
+		mcp.WithString("subscription_id",
+			mcp.Description("Azure Subscription ID of the base cluster"),
+			mcp.Required(),
+		),
+		mcp.WithString("resource_group",
+			mcp.Description("Azure Resource Group containing the base cluster"),
+			mcp.Required(),
+		),
+		mcp.WithString("cluster_name",
+			mcp.Description("Name of the base cluster"),
+			mcp.Required(),
+		),
+		mcp.WithString("compare_subscription_id",
+			mcp.Description("Azure Subscription ID of the cluster to compare with. Defaults to subscription_id."),
+		),
+		mcp.WithString("compare_resource_group",
+			mcp.Description("Azure Resource Group containing the cluster to compare with. Defaults to resource_group."),
+		),
+		mcp.WithString("compare_cluster_name",
+			mcp.Description("Name of the cluster to compare with"),
+			mcp.Required(),
+		),
+		mcp.WithString("tenant_id",
+			mcp.Description("Optional Entra tenant ID of the base subscription (inferred from az account list if omitted)"),
+		),
+		mcp.WithString("compare_tenant_id",
+			mcp.Description("Optional Entra tenant ID of the compare subscription (inferred from az account list if omitted)"),
+		),
+	)
+}
@@ -34,3 +34,18 @@ func GetClusterDetails(ctx context.Context, client *azureclient.AzureClient, sub
 	// Get the cluster from Azure client (which now handles caching internally)
 	return client.GetAKSCluster(ctx, subscriptionID, resourceGroup, clusterName)
 }
+
+// TenantContext returns ctx with the optional tenant_id parameter as the tenant of the call,
+// so Azure SDK calls made with it use a credential for that tenant.
+// When tenant_id is absent the tenant of the subscription is inferred from `az account list`.
+func TenantContext(ctx context.Context, client *azureclient.AzureClient, params map[string]interface{}) (context.Context, error) {
+	tenantID, ok := params["tenant_id"].(string)
+	if !ok || tenantID == "" || client == nil {
+		return ctx, nil
+	}
+
+	if err := client.CheckTenant(tenantID); err != nil {
+		return nil, fmt.Errorf("invalid tenant_id parameter: %v", err)
+	}
+	return azureclient.WithTenant(ctx, tenantID), nil
+}
@@ -1,7 +1,11 @@
 package common
 
 import (
+	"context"
 	"testing"
+
+	"github.com/Azure/aks-mcp/internal/azureclient"
+	"github.com/Azure/aks-mcp/internal/config"
 )
 
 // TestExtractAKSParameters tests the parameter extraction function
@@ -97,3 +101,27 @@ func TestExtractAKSParameters(t *testing.T) {
 		})
 	}
 }
+
+// TestTenantContext tests scoping calls to the optional tenant_id parameter
+func TestTenantContext(t *testing.T) {
+	client, err := azureclient.NewAzureClient(&config.ConfigData{AllowedTenants: "tenant-a"})
+	if err != nil {
+		t.Fatalf("Failed to create Azure client: %v", err)
+	}
+
+	// Absent tenant_id leaves the context unchanged
+	ctx := context.Background()
+	if got, err := TenantContext(ctx, client, map[string]interface{}{}); err != nil || got != ctx {
+		t.Errorf("Expected the context unchanged without tenant_id, got %v", err)
+	}
+
+	if _, err := TenantContext(ctx, client, map[string]interface{}{"tenant_id": "tenant-a"}); err != nil {
+		t.Fatalf("Expected allowed tenant to be accepted, got %v", err)
+	}
+	if _, err := TenantContext(ctx, client, map[string]interface{}{"tenant_id": "tenant-b"}); err == nil {
+		t.Error("Expected error for a tenant that is not allowed")
+	}
+	if _, err := TenantContext(ctx, client, map[string]interface{}{"tenant_id": "bad tenant"}); err == nil {
+		t.Error("Expected error for a malformed tenant ID")
+	}
+}
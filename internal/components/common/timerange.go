@@ -0,0 +1,322 @@
+package common
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// DefaultAroundMargin is the margin on each side of the timestamp of an "around" time range without one
+const DefaultAroundMargin = 15 * time.Minute
+
+// isoDurationPattern matches ISO 8601 durations with day, hour, minute and second parts, e.g. PT6H or P1DT12H
+var isoDurationPattern = regexp.MustCompile(`^P(?:(\d+)D)?(?:T(?:(\d+)H)?(?:(\d+)M)?(?:(\d+)S)?)?$`)
+
+// relativeDurationPattern matches durations written with a unit, e.g. 2h, 30 minutes, 1 day or hour
+var relativeDurationPattern = regexp.MustCompile(`^(\d+)?\s*(s|secs?|seconds?|m|mins?|minutes?|h|hrs?|hours?|d|days?|w|weeks?)$`)
+
+// aroundPattern matches "around <timestamp> ±<margin>", where the margin may also be written +/- or +-
+var aroundPattern = regexp.MustCompile(`(?i)^around\s+(\S+?)(?:\s*(?:±|\+/-|\+-)\s*(.+))?$`)
+
+// durationUnits maps the first letter of a duration unit to its length
+var durationUnits = map[byte]time.Duration{
+	's': time.Second,
+	'm': time.Minute,
+	'h': time.Hour,
+	'd': 24 * time.Hour,
+	'w': 7 * 24 * time.Hour,
+}
+
+// TimeRange is an absolute time window resolved from the time parameters of a tool call.
+// Tools echo it in their response so the caller sees the window that was actually queried.
+type TimeRange struct {
+	Start    time.Time `json:"start"`
+	End      time.Time `json:"end"`
+	Duration string    `json:"duration"`
+	// Expression is the relative time range the window was resolved from, e.g. "last 2h"
+	Expression string `json:"expression,omitempty"`
+}
+
+// Timespan returns the window as an ISO 8601 interval, the format of Azure CLI and Azure Monitor timespans
+func (r *TimeRange) Timespan() string {
+	return r.Start.Format(time.RFC3339) + "/" + r.End.Format(time.RFC3339)
+}
+
+// TimeRangeOptions constrains the time window a tool accepts
+type TimeRangeOptions struct {
+	// Default is the length of the window ending now when no time parameter is set.
+	// When zero, a start_time or time_range is required.
+	Default time.Duration
+	// MaxDuration is the longest window accepted; zero means no limit
+	MaxDuration time.Duration
+	// MaxAge is how far back the window may start; zero means no limit
+	MaxAge time.Duration
+}
+
+// ParseTimeRange resolves the time window of a tool call against the server time now.
+// The window is either a time range expression in time_range (or timespan), see ParseTimeRangeExpression,
+// or start_time and an optional end_time in RFC 3339, which defaults to now. start_time may also hold
+// a time range expression. Windows must not start or end in the future.
+func ParseTimeRange(params map[string]interface{}, now time.Time, opts TimeRangeOptions) (*TimeRange, error) {
+	now = now.UTC().Truncate(time.Second)
+
+	var key, expression string
+	for _, name := range []string{"time_range", "timespan"} {
+		value, _ := params[name].(string)
+		if value = strings.TrimSpace(value); value == "" {
+			continue
+		}
+		if expression != "" {
+			return nil, fmt.Errorf("set either %s or %s, not both", key, name)
+		}
+		key, expression = name, value
+	}
+
+	startTime, _ := params["start_time"].(string)
+	endTime, _ := params["end_time"].(string)
+	startTime, endTime = strings.TrimSpace(startTime), strings.TrimSpace(endTime)
+
+	var timeRange *TimeRange
+	var err error
+	switch {
+	case expression != "" && (startTime != "" || endTime != ""):
+		return nil, fmt.Errorf("set either %s or start_time/end_time, not both", key)
+	case expression != "":
+		timeRange, err = ParseTimeRangeExpression(expression, now)
+		if err != nil {
+			return nil, fmt.Errorf("invalid %s '%s': %w", key, expression, err)
+		}
+	case startTime == "" && endTime != "":
+		return nil, fmt.Errorf("end_time requires start_time")
+	case startTime == "" && opts.Default > 0:
+		timeRange = newTimeRange(now.Add(-opts.Default), now, "")
+	case startTime == "":
+		return nil, fmt.Errorf("missing or invalid start_time parameter, set start_time or a time_range such as \"last 2h\"")
+	default:
+		timeRange, err = parseStartEnd(startTime, endTime, now)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if timeRange.Start.After(now) {
+		return nil, fmt.Errorf("start_time cannot be in the future")
+	}
+	if timeRange.End.After(now) {
+		return nil, fmt.Errorf("end_time cannot be in the future")
+	}
+	if window := timeRange.End.Sub(timeRange.Start); opts.MaxDuration > 0 && window > opts.MaxDuration {
+		return nil, fmt.Errorf("time range cannot exceed %v, got %v", opts.MaxDuration, window)
+	}
+	if opts.MaxAge > 0 && timeRange.Start.Before(now.Add(-opts.MaxAge)) {
+		return nil, fmt.Errorf("start_time must be within the last %s", describeDuration(opts.MaxAge))
+	}
+
+	return timeRange, nil
+}
+
+// parseStartEnd resolves start_time and end_time, where start_time without end_time may be a time range expression
+func parseStartEnd(startTime, endTime string, now time.Time) (*TimeRange, error) {
+	start, err := time.Parse(time.RFC3339, startTime)
+	if err != nil {
+		if endTime == "" {
+			if timeRange, exprErr := ParseTimeRangeExpression(startTime, now); exprErr == nil {
+				return timeRange, nil
+			}
+		}
+		return nil, fmt.Errorf("invalid start_time format, expected RFC3339 (ISO 8601) or a time range such as \"last 2h\": %w", err)
+	}
+
+	if endTime == "" {
+		return newTimeRange(start, now, ""), nil
+	}
+
+	end, err := time.Parse(time.RFC3339, endTime)
+	if err != nil {
+		return nil, fmt.Errorf("invalid end_time format, expected RFC3339 (ISO 8601): %w", err)
+	}
+	if end.Before(start) {
+		return nil, fmt.Errorf("end_time must be after start_time")
+	}
+	return newTimeRange(start, end, ""), nil
+}
+
+// ParseTimeRangeExpression resolves a time range expression against now:
+//   - "last 2h", "last 30 minutes", "past day" or an ISO 8601 duration such as PT30M: the window ending now
+//   - "since 2026-03-01T09:00:00Z": from the timestamp until now
+//   - "around 2026-03-01T10:00:00Z ±15m": the window centred on the timestamp, ending no later than now
+//   - "2026-03-01T09:00:00Z/2026-03-01T12:00:00Z": an ISO 8601 interval
+func ParseTimeRangeExpression(expression string, now time.Time) (*TimeRange, error) {
+	expression = strings.TrimSpace(expression)
+	lower := strings.ToLower(expression)
+
+	switch {
+	case strings.HasPrefix(lower, "last ") || strings.HasPrefix(lower, "past "):
+		duration, err := parseRelativeDuration(strings.TrimSpace(lower[5:]))
+		if err != nil {
+			return nil, err
+		}
+		return newTimeRange(now.Add(-duration), now, expression), nil
+
+	case strings.HasPrefix(lower, "since "):
+		start, err := time.Parse(time.RFC3339, strings.TrimSpace(expression[6:]))
+		if err != nil {
+			return nil, fmt.Errorf("expected an RFC3339 timestamp after since: %w", err)
+		}
+		return newTimeRange(start, now, expression), nil
+
+	case strings.HasPrefix(lower, "around "):
+		match := aroundPattern.FindStringSubmatch(expression)
+		if match == nil {
+			return nil, fmt.Errorf("expected around <timestamp> ±<duration>")
+		}
+		at, err := time.Parse(time.RFC3339, match[1])
+		if err != nil {
+			return nil, fmt.Errorf("expected an RFC3339 timestamp after around: %w", err)
+		}
+		margin := DefaultAroundMargin
+		if match[2] != "" {
+			margin, err = parseRelativeDuration(strings.ToLower(strings.TrimSpace(match[2])))
+			if err != nil {
+				return nil, err
+			}
+		}
+		end := at.Add(margin)
+		if end.After(now) {
+			end = now
+		}
+		return newTimeRange(at.Add(-margin), end, expression), nil
+
+	case strings.Contains(expression, "/"):
+		startTime, endTime, _ := strings.Cut(expression, "/")
+		start, err := time.Parse(time.RFC3339, startTime)
+		if err != nil {
+			return nil, fmt.Errorf("expected an RFC3339 interval start: %w", err)
+		}
+		end, err := time.Parse(time.RFC3339, endTime)
+		if err != nil {
+			return nil, fmt.Errorf("expected an RFC3339 interval end: %w", err)
+		}
+		if !start.Before(end) {
+			return nil, fmt.Errorf("the interval start must be before its end")
+		}
+		return newTimeRange(start, end, expression), nil
+	}
+
+	duration, err := ParseISODuration(expression)
+	if err != nil {
+		return nil, fmt.Errorf("expected \"last 2h\", an ISO 8601 duration such as PT30M, \"since <timestamp>\", \"around <timestamp> ±15m\" or start/end times")
+	}
+	return newTimeRange(now.Add(-duration), now, expression), nil
+}
+
+// newTimeRange returns the window from start to end in UTC
+func newTimeRange(start, end time.Time, expression string) *TimeRange {
+	start, end = start.UTC(), end.UTC()
+	return &TimeRange{
+		Start:      start,
+		End:        end,
+		Duration:   end.Sub(start).String(),
+		Expression: expression,
+	}
+}
+
+// parseRelativeDuration parses the duration of a "last" expression: 2h, 1h30m, 30 minutes, 1 day, hour or PT2H
+func parseRelativeDuration(value string) (time.Duration, error) {
+	var duration time.Duration
+	if match := relativeDurationPattern.FindStringSubmatch(value); match != nil {
+		n := 1
+		if match[1] != "" {
+			var err error
+			if n, err = strconv.Atoi(match[1]); err != nil {
+				return 0, fmt.Errorf("invalid duration '%s': %w", value, err)
+			}
+		}
+		var err error
+		if duration, err = multiplyDuration(value, n, durationUnits[match[2][0]]); err != nil {
+			return 0, err
+		}
+	} else if parsed, err := time.ParseDuration(value); err == nil {
+		duration = parsed
+	} else if parsed, err := ParseISODuration(value); err == nil {
+		duration = parsed
+	} else {
+		return 0, fmt.Errorf("invalid duration '%s', expected e.g. 2h, 30m, 1 day or PT2H", value)
+	}
+
+	if duration <= 0 {
+		return 0, fmt.Errorf("invalid duration '%s', the duration must be positive", value)
+	}
+	return duration, nil
+}
+
+// ParseISODuration parses ISO 8601 durations with day, hour, minute and second parts
+func ParseISODuration(value string) (time.Duration, error) {
+	upper := strings.ToUpper(value)
+	match := isoDurationPattern.FindStringSubmatch(upper)
+	if match == nil || upper == "P" || strings.HasSuffix(upper, "T") {
+		return 0, fmt.Errorf("invalid duration '%s', expected an ISO 8601 duration such as PT6H or P1D", value)
+	}
+
+	units := []time.Duration{24 * time.Hour, time.Hour, time.Minute, time.Second}
+	var duration time.Duration
+	for i, unit := range units {
+		if match[i+1] == "" {
+			continue
+		}
+		n, err := strconv.Atoi(match[i+1])
+		if err != nil {
+			return 0, fmt.Errorf("invalid duration '%s': %w", value, err)
+		}
+		part, err := multiplyDuration(value, n, unit)
+		if err != nil {
+			return 0, err
+		}
+		if part > math.MaxInt64-duration {
+			return 0, fmt.Errorf("invalid duration '%s', the duration is too long", value)
+		}
+		duration += part
+	}
+	if duration <= 0 {
+		return 0, fmt.Errorf("invalid duration '%s', the duration must be positive", value)
+	}
+	return duration, nil
+}
+
+// multiplyDuration returns n units of a duration, or an error when the result does not fit in a time.Duration
+func multiplyDuration(value string, n int, unit time.Duration) (time.Duration, error) {
+	if int64(n) > math.MaxInt64/int64(unit) {
+		return 0, fmt.Errorf("invalid duration '%s', the duration is too long", value)
+	}
+	return time.Duration(n) * unit, nil
+}
+
+// describeDuration formats whole days as days and other durations in Go notation
+func describeDuration(d time.Duration) string {
+	if day := 24 * time.Hour; d%day == 0 {
+		return fmt.Sprintf("%d days", d/day)
+	}
+	return d.String()
+}
+
+// WithTimeRange wraps a tool's raw JSON output with the time window it covers.
+// Output that is not JSON is included as a string.
+func WithTimeRange(output string, timeRange *TimeRange) (string, error) {
+	var result interface{} = output
+	if json.Valid([]byte(output)) {
+		result = json.RawMessage(output)
+	}
+
+	wrapped, err := json.MarshalIndent(struct {
+		TimeRange *TimeRange  `json:"time_range"`
+		Result    interface{} `json:"result"`
+	}{timeRange, result}, "", "  ")
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal result: %w", err)
+	}
+	return string(wrapped), nil
+}
@@ -0,0 +1,134 @@
+package common
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParseTimeRange(t *testing.T) {
+	now := time.Date(2026, 3, 1, 12, 0, 0, 500, time.UTC)
+
+	tests := []struct {
+		name       string
+		params     map[string]interface{}
+		opts       TimeRangeOptions
+		start, end string
+	}{
+		{"last hours", map[string]interface{}{"time_range": "last 2h"}, TimeRangeOptions{}, "2026-03-01T10:00:00Z", "2026-03-01T12:00:00Z"},
+		{"last with words", map[string]interface{}{"time_range": "Last 30 minutes"}, TimeRangeOptions{}, "2026-03-01T11:30:00Z", "2026-03-01T12:00:00Z"},
+		{"past day without a number", map[string]interface{}{"time_range": "past day"}, TimeRangeOptions{}, "2026-02-28T12:00:00Z", "2026-03-01T12:00:00Z"},
+		{"last compound duration", map[string]interface{}{"time_range": "last 1h30m"}, TimeRangeOptions{}, "2026-03-01T10:30:00Z", "2026-03-01T12:00:00Z"},
+		{"iso duration", map[string]interface{}{"time_range": "PT30M"}, TimeRangeOptions{}, "2026-03-01T11:30:00Z", "2026-03-01T12:00:00Z"},
+		{"timespan alias", map[string]interface{}{"timespan": "P1DT6H"}, TimeRangeOptions{}, "2026-02-28T06:00:00Z", "2026-03-01T12:00:00Z"},
+		{"since", map[string]interface{}{"time_range": "since 2026-03-01T09:15:00+01:00"}, TimeRangeOptions{}, "2026-03-01T08:15:00Z", "2026-03-01T12:00:00Z"},
+		{"around", map[string]interface{}{"time_range": "around 2026-03-01T10:00:00Z ±15m"}, TimeRangeOptions{}, "2026-03-01T09:45:00Z", "2026-03-01T10:15:00Z"},
+		{"around with +/-", map[string]interface{}{"time_range": "around 2026-03-01T10:00:00Z +/- 1 hour"}, TimeRangeOptions{}, "2026-03-01T09:00:00Z", "2026-03-01T11:00:00Z"},
+		{"around default margin", map[string]interface{}{"time_range": "around 2026-03-01T10:00:00Z"}, TimeRangeOptions{}, "2026-03-01T09:45:00Z", "2026-03-01T10:15:00Z"},
+		{"around ends no later than now", map[string]interface{}{"time_range": "around 2026-03-01T11:55:00Z ±15m"}, TimeRangeOptions{}, "2026-03-01T11:40:00Z", "2026-03-01T12:00:00Z"},
+		{"interval", map[string]interface{}{"time_range": "2026-03-01T06:00:00Z/2026-03-01T08:00:00Z"}, TimeRangeOptions{}, "2026-03-01T06:00:00Z", "2026-03-01T08:00:00Z"},
+		{"start and end", map[string]interface{}{"start_time": "2026-03-01T06:00:00Z", "end_time": "2026-03-01T07:00:00Z"}, TimeRangeOptions{}, "2026-03-01T06:00:00Z", "2026-03-01T07:00:00Z"},
+		{"start until now", map[string]interface{}{"start_time": "2026-03-01T06:00:00Z"}, TimeRangeOptions{}, "2026-03-01T06:00:00Z", "2026-03-01T12:00:00Z"},
+		{"expression in start_time", map[string]interface{}{"start_time": "last 2h"}, TimeRangeOptions{}, "2026-03-01T10:00:00Z", "2026-03-01T12:00:00Z"},
+		{"default window", map[string]interface{}{}, TimeRangeOptions{Default: time.Hour}, "2026-03-01T11:00:00Z", "2026-03-01T12:00:00Z"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			timeRange, err := ParseTimeRange(tt.params, now, tt.opts)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if got := timeRange.Timespan(); got != tt.start+"/"+tt.end {
+				t.Errorf("Expected %s/%s, got %s", tt.start, tt.end, got)
+			}
+		})
+	}
+}
+
+func TestParseTimeRange_Errors(t *testing.T) {
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name   string
+		params map[string]interface{}
+		opts   TimeRangeOptions
+		errMsg string
+	}{
+		{"missing", map[string]interface{}{}, TimeRangeOptions{}, "missing or invalid start_time parameter"},
+		{"end without start", map[string]interface{}{"end_time": "2026-03-01T07:00:00Z"}, TimeRangeOptions{Default: time.Hour}, "end_time requires start_time"},
+		{"expression and start", map[string]interface{}{"time_range": "last 2h", "start_time": "2026-03-01T06:00:00Z"}, TimeRangeOptions{}, "set either time_range or start_time/end_time"},
+		{"time_range and timespan", map[string]interface{}{"time_range": "last 2h", "timespan": "PT2H"}, TimeRangeOptions{}, "set either time_range or timespan"},
+		{"unknown expression", map[string]interface{}{"time_range": "yesterday"}, TimeRangeOptions{}, "invalid time_range 'yesterday'"},
+		{"bare duration", map[string]interface{}{"timespan": "6 hours"}, TimeRangeOptions{}, "invalid timespan '6 hours'"},
+		{"bad unit", map[string]interface{}{"time_range": "last 2 fortnights"}, TimeRangeOptions{}, "invalid duration '2 fortnights'"},
+		{"zero duration", map[string]interface{}{"time_range": "last 0m"}, TimeRangeOptions{}, "the duration must be positive"},
+		{"overflowing duration", map[string]interface{}{"time_range": "last 45000 weeks"}, TimeRangeOptions{}, "invalid duration '45000 weeks', the duration is too long"},
+		{"overflowing ISO duration", map[string]interface{}{"timespan": "P200000D"}, TimeRangeOptions{}, "invalid timespan 'P200000D'"},
+		{"since without timestamp", map[string]interface{}{"time_range": "since monday"}, TimeRangeOptions{}, "RFC3339 timestamp after since"},
+		{"reversed interval", map[string]interface{}{"time_range": "2026-03-01T08:00:00Z/2026-03-01T06:00:00Z"}, TimeRangeOptions{}, "before its end"},
+		{"invalid start", map[string]interface{}{"start_time": "2026-03-01 06:00"}, TimeRangeOptions{}, "invalid start_time format"},
+		{"invalid end", map[string]interface{}{"start_time": "2026-03-01T06:00:00Z", "end_time": "noon"}, TimeRangeOptions{}, "invalid end_time format"},
+		{"end before start", map[string]interface{}{"start_time": "2026-03-01T06:00:00Z", "end_time": "2026-03-01T05:00:00Z"}, TimeRangeOptions{}, "end_time must be after start_time"},
+		{"start in future", map[string]interface{}{"time_range": "since 2026-03-01T13:00:00Z"}, TimeRangeOptions{}, "start_time cannot be in the future"},
+		{"end in future", map[string]interface{}{"start_time": "2026-03-01T11:00:00Z", "end_time": "2026-03-01T13:00:00Z"}, TimeRangeOptions{}, "end_time cannot be in the future"},
+		{"too long", map[string]interface{}{"time_range": "last 2d"}, TimeRangeOptions{MaxDuration: 24 * time.Hour}, "time range cannot exceed 24h0m0s, got 48h0m0s"},
+		{"too old", map[string]interface{}{"time_range": "around 2026-01-01T00:00:00Z ±1h"}, TimeRangeOptions{MaxAge: 30 * 24 * time.Hour}, "start_time must be within the last 30 days"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ParseTimeRange(tt.params, now, tt.opts)
+			if err == nil || !strings.Contains(err.Error(), tt.errMsg) {
+				t.Errorf("Expected error containing %q, got %v", tt.errMsg, err)
+			}
+		})
+	}
+}
+
+func TestParseISODuration_Overflow(t *testing.T) {
+	if d, err := ParseISODuration("P106751D"); err != nil || d != 106751*24*time.Hour {
+		t.Errorf("Expected the longest whole-day duration to parse, got %v (%v)", d, err)
+	}
+	for _, value := range []string{"P200000D", "P106751DT24H", "PT9999999999999S"} {
+		if _, err := ParseISODuration(value); err == nil || !strings.Contains(err.Error(), "the duration is too long") {
+			t.Errorf("Expected %s to be too long, got %v", value, err)
+		}
+	}
+}
+
+func TestWithTimeRange(t *testing.T) {
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	timeRange, err := ParseTimeRange(map[string]interface{}{"time_range": "last 2h"}, now, TimeRangeOptions{})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	output, err := WithTimeRange(`[{"name": "event"}]`, timeRange)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	var response struct {
+		TimeRange map[string]string   `json:"time_range"`
+		Result    []map[string]string `json:"result"`
+	}
+	if err := json.Unmarshal([]byte(output), &response); err != nil {
+		t.Fatalf("Failed to parse output: %v\n%s", err, output)
+	}
+	want := map[string]string{"start": "2026-03-01T10:00:00Z", "end": "2026-03-01T12:00:00Z", "duration": "2h0m0s", "expression": "last 2h"}
+	for key, value := range want {
+		if response.TimeRange[key] != value {
+			t.Errorf("Expected time_range %s %q, got %q", key, value, response.TimeRange[key])
+		}
+	}
+	if len(response.Result) != 1 || response.Result[0]["name"] != "event" {
+		t.Errorf("Expected the raw result, got %+v", response.Result)
+	}
+
+	// Output that is not JSON is kept as a string
+	output, err = WithTimeRange("No events found", timeRange)
+	if err != nil || !strings.Contains(output, `"result": "No events found"`) {
+		t.Errorf("Expected the text result, got %s (%v)", output, err)
+	}
+}
@@ -27,8 +27,12 @@ func GetAKSVMSSInfoHandler(client *azureclient.AzureClient, cfg *config.ConfigDa
 			return "", err
 		}
 
+		ctx, err := common.TenantContext(context.Background(), client, params)
+		if err != nil {
+			return "", err
+		}
+
 		// Get the cluster details
-		ctx := context.Background()
 		cluster, err := common.GetClusterDetails(ctx, client, subID, rg, clusterName)
 		if err != nil {
 			return "", fmt.Errorf("failed to get cluster details: %v", err)
@@ -1,8 +1,11 @@
 package compute
 
 import (
+	"encoding/json"
 	"testing"
 
+	"github.com/Azure/aks-mcp/internal/azureclient/fakearm"
+	"github.com/Azure/aks-mcp/internal/azureclient/fakearm/armtest"
 	"github.com/Azure/aks-mcp/internal/components/common"
 	"github.com/Azure/aks-mcp/internal/config"
 )
@@ -107,3 +110,45 @@ func TestGetAKSVMSSInfoHandler(t *testing.T) {
 	// and would make actual Azure API calls. The handler creation test above is sufficient
 	// to verify the basic functionality works.
 }
+
+// TestGetAKSVMSSInfoHandler_FakeARM runs the VMSS tool end to end against a local fake ARM server
+func TestGetAKSVMSSInfoHandler_FakeARM(t *testing.T) {
+	_, client, cfg := armtest.NewClient(t)
+
+	handler := GetAKSVMSSInfoHandler(client, cfg)
+	result, err := handler.Handle(map[string]interface{}{
+		"subscription_id": fakearm.SubscriptionID,
+		"resource_group":  fakearm.ResourceGroup,
+		"cluster_name":    fakearm.ClusterName,
+		"node_pool_name":  "nodepool1",
+	}, cfg)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	var vmss map[string]interface{}
+	if err := json.Unmarshal([]byte(result), &vmss); err != nil {
+		t.Fatalf("Failed to parse result: %v", err)
+	}
+	if vmss["name"] != "aks-nodepool1-12345678-vmss" {
+		t.Errorf("Expected aks-nodepool1-12345678-vmss, got %v", vmss["name"])
+	}
+
+	// All node pools
+	result, err = handler.Handle(map[string]interface{}{
+		"subscription_id": fakearm.SubscriptionID,
+		"resource_group":  fakearm.ResourceGroup,
+		"cluster_name":    fakearm.ClusterName,
+	}, cfg)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	var all map[string]interface{}
+	if err := json.Unmarshal([]byte(result), &all); err != nil {
+		t.Fatalf("Failed to parse result: %v", err)
+	}
+	if all["node_pools_count"] != float64(1) {
+		t.Errorf("Expected 1 node pool, got %v", all["node_pools_count"])
+	}
+}
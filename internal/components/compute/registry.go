@@ -26,6 +26,9 @@ func RegisterAKSVMSSInfoTool() mcp.Tool {
 		mcp.WithString("node_pool_name",
 			mcp.Description("Name of the node pool to get VMSS information for. Leave empty to get info for all node pools."),
 		),
+		mcp.WithString("tenant_id",
+			mcp.Description("Optional Entra tenant ID of the subscription (inferred from az account list if omitted)"),
+		),
 	)
 }
 
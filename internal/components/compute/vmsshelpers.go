@@ -84,7 +84,7 @@ func GetVMSSInstancesFromNodePool(
 	vmssName := parts[8]
 
 	// Get clients for the subscription
-	clients, err := client.GetOrCreateClientsForSubscription(subscriptionID)
+	clients, err := client.GetOrCreateClientsForContext(ctx, subscriptionID)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get clients for subscription %s: %v", subscriptionID, err)
 	}
@@ -115,7 +115,7 @@ func findVMSSForNodePool(
 	nodePoolName string,
 ) (string, error) {
 	// Get clients for the subscription
-	clients, err := client.GetOrCreateClientsForSubscription(subscriptionID)
+	clients, err := client.GetOrCreateClientsForContext(ctx, subscriptionID)
 	if err != nil {
 		return "", fmt.Errorf("failed to get clients for subscription %s: %v", subscriptionID, err)
 	}
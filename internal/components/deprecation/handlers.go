@@ -0,0 +1,341 @@
+package deprecation
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Azure/aks-mcp/internal/azureclient"
+	"github.com/Azure/aks-mcp/internal/components/common"
+	"github.com/Azure/aks-mcp/internal/components/monitor/diagnostics"
+	"github.com/Azure/aks-mcp/internal/config"
+	"github.com/Azure/aks-mcp/internal/kubeconfig"
+	"github.com/Azure/aks-mcp/internal/tools"
+)
+
+// defaultAuditWindow is how far back audit logs are searched when no time range is given
+const defaultAuditWindow = 24 * time.Hour
+
+// maxAuditRows limits the number of client and API combinations returned from audit logs
+const maxAuditRows = 200
+
+// deprecationNow returns the server time that audit log time ranges resolve against; tests replace it
+var deprecationNow = time.Now
+
+// auditCategories are the diagnostic log categories searched for deprecated API requests, in order of preference.
+// kube-audit-admin omits get and list requests, so it only shows clients that write with deprecated versions.
+var auditCategories = []string{"kube-audit", "kube-audit-admin"}
+
+// GetDeprecatedAPIsHandler returns a handler for the aks_deprecated_apis tool.
+// kubectl reads manifests and Helm releases from the requested cluster, which it is given as the cluster target parameters.
+func GetDeprecatedAPIsHandler(client *azureclient.AzureClient, kubectl tools.CommandExecutor, cfg *config.ConfigData) tools.ResourceHandler {
+	return tools.ResourceHandlerFunc(func(params map[string]interface{}, _ *config.ConfigData) (string, error) {
+		subID, rg, clusterName, err := common.ExtractAKSParameters(params)
+		if err != nil {
+			return "", err
+		}
+		target := kubeconfig.Target{SubscriptionID: subID, ResourceGroup: rg, ClusterName: clusterName}
+		if err := target.Validate(); err != nil {
+			return "", err
+		}
+		// The Kubernetes and Log Analytics scans run in the tenant of the az login, so the report cannot honor tenant_id
+		if tenantID, _ := params["tenant_id"].(string); tenantID != "" {
+			return "", fmt.Errorf("tenant_id is not supported by aks_deprecated_apis, which runs in the tenant of the Azure CLI login; use az login --tenant to switch tenants")
+		}
+
+		sources, err := parseSources(params)
+		if err != nil {
+			return "", err
+		}
+
+		targetVersion, _ := params["target_version"].(string)
+		if targetVersion != "" {
+			if _, err := parseMinorVersion(targetVersion); err != nil {
+				return "", fmt.Errorf("invalid target_version '%s', expected a version such as 1.32 or 1.32.1", targetVersion)
+			}
+		}
+
+		auditTimeRange, err := common.ParseTimeRange(params, deprecationNow(), common.TimeRangeOptions{
+			Default:     defaultAuditWindow,
+			MaxDuration: diagnostics.MaxQueryRangeDuration,
+		})
+		if err != nil {
+			return "", err
+		}
+
+		table, err := DefaultTable()
+		if err != nil {
+			return "", err
+		}
+
+		// Removals are checked against the current version, not a cached copy of the cluster
+		cluster, err := client.GetAKSClusterStatus(context.Background(), subID, rg, clusterName)
+		if err != nil {
+			return "", fmt.Errorf("failed to get cluster details: %v", err)
+		}
+
+		report := &Report{
+			ClusterName:   clusterName,
+			ResourceGroup: rg,
+			TableVersion:  table.Version,
+			Findings:      []Finding{},
+		}
+		if cluster.Properties != nil && cluster.Properties.CurrentKubernetesVersion != nil {
+			report.KubernetesVersion = *cluster.Properties.CurrentKubernetesVersion
+		}
+		report.TargetVersion = targetVersion
+		if report.TargetVersion == "" {
+			report.TargetVersion = report.KubernetesVersion
+		}
+
+		for _, source := range sources {
+			var findings []Finding
+			var err error
+			result := SourceResult{Name: source, Scanned: true}
+			switch source {
+			case SourceManifests:
+				findings, result.Message = scanManifests(table, kubectl, target, cfg)
+			case SourceHelm:
+				findings, err = listHelmFindings(table, kubectl, target, cfg)
+			case SourceAudit:
+				report.AuditTimeRange = auditTimeRange
+				findings, result.Scanned, result.Message, err = queryAuditLogs(table, subID, rg, clusterName, auditTimeRange.Timespan(), client, cfg)
+			}
+			if err != nil {
+				result.Scanned = false
+				result.Message = err.Error()
+			}
+			result.Findings = len(findings)
+			report.Sources = append(report.Sources, result)
+			report.Findings = append(report.Findings, findings...)
+		}
+		report.setStatus()
+
+		result, err := json.MarshalIndent(report, "", "  ")
+		if err != nil {
+			return "", fmt.Errorf("failed to marshal deprecated API report: %v", err)
+		}
+		return string(result), nil
+	})
+}
+
+// parseSources returns the sources to scan, defaulting to all of them
+func parseSources(params map[string]interface{}) ([]string, error) {
+	value, _ := params["sources"].(string)
+	if strings.TrimSpace(value) == "" {
+		return []string{SourceManifests, SourceHelm, SourceAudit}, nil
+	}
+
+	var sources []string
+	seen := make(map[string]bool)
+	for _, source := range strings.Split(value, ",") {
+		source = strings.TrimSpace(source)
+		switch source {
+		case SourceManifests, SourceHelm, SourceAudit:
+		default:
+			return nil, fmt.Errorf("invalid source '%s', expected one of %s, %s, %s", source, SourceManifests, SourceHelm, SourceAudit)
+		}
+		if !seen[source] {
+			seen[source] = true
+			sources = append(sources, source)
+		}
+	}
+	return sources, nil
+}
+
+// scanManifests lists the resources with a deprecated version that the cluster serves, in one request,
+// and checks the manifests last applied to them. Failures are summarized in the returned message.
+func scanManifests(table *Table, kubectl tools.CommandExecutor, target kubeconfig.Target, cfg *config.ConfigData) ([]Finding, string) {
+	resources, err := servedResources(table, kubectl, target, cfg)
+	if err != nil {
+		return nil, "failed to list API resources: " + err.Error()
+	}
+	if len(resources) == 0 {
+		return nil, ""
+	}
+
+	output, err := kubectl.Execute(target.Params(map[string]interface{}{
+		"command": fmt.Sprintf("get %s --all-namespaces --output json", strings.Join(resources, ",")),
+	}), cfg)
+	if err != nil {
+		return nil, "failed to list " + strings.Join(resources, ", ") + ": " + err.Error()
+	}
+	findings, err := scanAppliedManifests(table, output)
+	if err != nil {
+		return nil, "failed to list " + strings.Join(resources, ", ") + ": " + err.Error()
+	}
+	return findings, ""
+}
+
+// servedResources returns the table's resources that the cluster serves, since kubectl get
+// fails for every resource when one of them is not served
+func servedResources(table *Table, kubectl tools.CommandExecutor, target kubeconfig.Target, cfg *config.ConfigData) ([]string, error) {
+	output, err := kubectl.Execute(target.Params(map[string]interface{}{
+		"command": "api-resources --verbs=list --output name",
+	}), cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	// Names are the plural resource followed by the group, such as ingresses.networking.k8s.io
+	served := make(map[string]bool)
+	for _, line := range strings.Split(output, "\n") {
+		name, _, _ := strings.Cut(strings.TrimSpace(line), ".")
+		if name != "" {
+			served[name] = true
+		}
+	}
+
+	var resources []string
+	for _, resource := range table.Resources() {
+		if served[resource] {
+			resources = append(resources, resource)
+		}
+	}
+	return resources, nil
+}
+
+// listHelmFindings checks the manifests of deployed Helm releases
+func listHelmFindings(table *Table, kubectl tools.CommandExecutor, target kubeconfig.Target, cfg *config.ConfigData) ([]Finding, error) {
+	output, err := kubectl.Execute(target.Params(map[string]interface{}{
+		"command": "get secrets --all-namespaces --selector owner=helm,status=deployed --output json",
+	}), cfg)
+	if err != nil {
+		return nil, err
+	}
+	return scanHelmReleases(table, output)
+}
+
+// queryAuditLogs finds clients that requested deprecated API versions in the control plane audit logs.
+// It reports the source as not scanned, without an error, when no audit log category is enabled.
+func queryAuditLogs(table *Table, subscriptionID, resourceGroup, clusterName, timespan string, client *azureclient.AzureClient, cfg *config.ConfigData) ([]Finding, bool, string, error) {
+	var category, workspaceResourceID string
+	var isResourceSpecific bool
+	for _, c := range auditCategories {
+		workspaceID, resourceSpecific, err := diagnostics.FindDiagnosticSettingForCategory(subscriptionID, resourceGroup, clusterName, c, client, cfg)
+		if err == nil {
+			category, workspaceResourceID, isResourceSpecific = c, workspaceID, resourceSpecific
+			break
+		}
+	}
+	if category == "" {
+		return nil, false, "audit logs are not sent to a Log Analytics workspace; enable the kube-audit diagnostic category to find clients using deprecated APIs", nil
+	}
+
+	workspaceGUID, err := diagnostics.GetWorkspaceGUID(workspaceResourceID, cfg)
+	if err != nil {
+		return nil, false, "", err
+	}
+
+	clusterResourceID := fmt.Sprintf("/subscriptions/%s/resourceGroups/%s/providers/Microsoft.ContainerService/managedClusters/%s",
+		subscriptionID, resourceGroup, clusterName)
+	query, err := buildAuditQuery(category, clusterResourceID, isResourceSpecific)
+	if err != nil {
+		return nil, false, "", err
+	}
+
+	output, err := diagnostics.RunLogAnalyticsQuery(workspaceGUID, query, timespan, cfg)
+	if err != nil {
+		return nil, false, "", fmt.Errorf("failed to query %s logs: %v", category, err)
+	}
+
+	findings, err := parseAuditRows(table, output)
+	if err != nil {
+		return nil, false, "", err
+	}
+
+	message := fmt.Sprintf("searched %s logs", category)
+	if category == "kube-audit-admin" {
+		message += ", which omit get and list requests"
+	}
+	return findings, true, message, nil
+}
+
+// buildAuditQuery builds a KQL query that summarizes requests the API server annotated as deprecated
+func buildAuditQuery(category, clusterResourceID string, isResourceSpecific bool) (string, error) {
+	if err := diagnostics.ValidateClusterResourceID(clusterResourceID); err != nil {
+		return "", err
+	}
+	if !slices.Contains(auditCategories, category) {
+		return "", fmt.Errorf("invalid audit log category '%s'", category)
+	}
+
+	tableMode := diagnostics.AzureDiagnosticsMode
+	if isResourceSpecific {
+		tableMode = diagnostics.ResourceSpecificMode
+	}
+	builder, err := diagnostics.NewKQLQueryBuilder(category, "", maxAuditRows, clusterResourceID, tableMode)
+	if err != nil {
+		return "", err
+	}
+	base, err := builder.BuildBaseQuery()
+	if err != nil {
+		return "", err
+	}
+
+	summarize := fmt.Sprintf("summarize Requests = count(), LastSeen = max(TimeGenerated) by ApiGroup, ApiVersion, Resource, RemovedRelease, Username, UserAgent"+
+		" | order by Requests desc | limit %d", maxAuditRows)
+
+	if isResourceSpecific {
+		return base +
+			" | where tostring(Annotations['k8s.io/deprecated']) == 'true'" +
+			" | extend ApiGroup = tostring(ObjectRef.apiGroup), ApiVersion = tostring(ObjectRef.apiVersion), Resource = tostring(ObjectRef.resource)," +
+			" RemovedRelease = tostring(Annotations['k8s.io/removed-release']), Username = tostring(User.username)" +
+			" | " + summarize, nil
+	}
+
+	return base +
+		" | where log_s has 'k8s.io/deprecated' | extend Event = parse_json(log_s)" +
+		" | where tostring(Event.annotations['k8s.io/deprecated']) == 'true'" +
+		" | extend ApiGroup = tostring(Event.objectRef.apiGroup), ApiVersion = tostring(Event.objectRef.apiVersion), Resource = tostring(Event.objectRef.resource)," +
+		" RemovedRelease = tostring(Event.annotations['k8s.io/removed-release']), Username = tostring(Event.user.username), UserAgent = tostring(Event.userAgent)" +
+		" | " + summarize, nil
+}
+
+// parseAuditRows converts the rows of the audit query into findings.
+// APIs missing from the table are reported with the removal release the API server annotated.
+func parseAuditRows(table *Table, output string) ([]Finding, error) {
+	var rows []map[string]interface{}
+	if err := json.Unmarshal([]byte(output), &rows); err != nil {
+		return nil, fmt.Errorf("unexpected query output: %s", strings.TrimSpace(output))
+	}
+
+	var findings []Finding
+	for _, row := range rows {
+		group, version, resource := rowString(row, "ApiGroup"), rowString(row, "ApiVersion"), rowString(row, "Resource")
+
+		var finding Finding
+		if api := table.LookupResource(group, version, resource); api != nil {
+			finding = newFinding(SourceAudit, api)
+		} else {
+			finding = Finding{
+				Source:     SourceAudit,
+				APIVersion: strings.TrimPrefix(group+"/"+version, "/"),
+				Resource:   resource,
+				RemovedIn:  rowString(row, "RemovedRelease"),
+			}
+		}
+		finding.User = rowString(row, "Username")
+		finding.UserAgent = rowString(row, "UserAgent")
+		finding.Requests, _ = strconv.Atoi(rowString(row, "Requests"))
+		finding.LastSeen = rowString(row, "LastSeen")
+		findings = append(findings, finding)
+	}
+	return findings, nil
+}
+
+// rowString returns a column of a query result row as a string; az returns numbers as either strings or numbers
+func rowString(row map[string]interface{}, column string) string {
+	switch value := row[column].(type) {
+	case string:
+		return value
+	case float64:
+		return strconv.FormatFloat(value, 'f', -1, 64)
+	default:
+		return ""
+	}
+}
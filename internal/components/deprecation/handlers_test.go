@@ -0,0 +1,287 @@
+package deprecation
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Azure/aks-mcp/internal/azureclient/fakearm"
+	"github.com/Azure/aks-mcp/internal/azureclient/fakearm/armtest"
+	"github.com/Azure/aks-mcp/internal/command"
+	"github.com/Azure/aks-mcp/internal/config"
+)
+
+// stubKubectl returns fixed output for kubectl commands that target the fake cluster
+type stubKubectl struct {
+	outputs map[string]string
+}
+
+func (s *stubKubectl) Execute(params map[string]interface{}, _ *config.ConfigData) (string, error) {
+	if params["subscription_id"] != fakearm.SubscriptionID || params["resource_group"] != fakearm.ResourceGroup || params["cluster_name"] != fakearm.ClusterName {
+		return "", fmt.Errorf("expected kubectl to target %s, got %v", fakearm.ClusterResourceID, params)
+	}
+	cmd, _ := params["command"].(string)
+	if output, ok := s.outputs[cmd]; ok {
+		return output, nil
+	}
+	return "error: the server doesn't have a resource type", nil
+}
+
+const testIngresses = `{
+  "items": [
+    {
+      "apiVersion": "networking.k8s.io/v1",
+      "kind": "Ingress",
+      "metadata": {
+        "name": "storefront",
+        "namespace": "shop",
+        "annotations": {
+          "kubectl.kubernetes.io/last-applied-configuration": "{\"apiVersion\":\"networking.k8s.io/v1beta1\",\"kind\":\"Ingress\",\"metadata\":{\"name\":\"storefront\",\"namespace\":\"shop\"}}\n"
+        }
+      }
+    },
+    {
+      "apiVersion": "networking.k8s.io/v1",
+      "kind": "Ingress",
+      "metadata": {
+        "name": "api",
+        "namespace": "shop",
+        "annotations": {
+          "kubectl.kubernetes.io/last-applied-configuration": "{\"apiVersion\":\"networking.k8s.io/v1\",\"kind\":\"Ingress\",\"metadata\":{\"name\":\"api\",\"namespace\":\"shop\"}}\n"
+        }
+      }
+    },
+    {
+      "apiVersion": "networking.k8s.io/v1",
+      "kind": "Ingress",
+      "metadata": {"name": "created-by-controller", "namespace": "shop"}
+    }
+  ]
+}`
+
+// testAPIResources lists the resources the fake cluster serves, as kubectl api-resources --output name does
+const testAPIResources = `pods
+deployments.apps
+poddisruptionbudgets.policy
+ingresses.networking.k8s.io
+`
+
+const testHelmManifest = `---
+# Source: metrics/templates/flowschema.yaml
+apiVersion: flowcontrol.apiserver.k8s.io/v1beta3
+kind: FlowSchema
+metadata:
+  name: metrics-exporter
+---
+# Source: metrics/templates/deployment.yaml
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: metrics-exporter
+spec:
+  replicas: 1
+---
+# Source: metrics/templates/pdb.yaml
+apiVersion: policy/v1beta1
+kind: PodDisruptionBudget
+metadata:
+  name: metrics-exporter
+  namespace: observability
+`
+
+// helmSecrets builds kubectl output for a deployed Helm 3 release secret
+func helmSecrets(t *testing.T, name, namespace, manifest string) string {
+	t.Helper()
+
+	release, err := json.Marshal(map[string]interface{}{"name": name, "namespace": namespace, "manifest": manifest})
+	if err != nil {
+		t.Fatalf("Failed to marshal release: %v", err)
+	}
+	var compressed bytes.Buffer
+	writer := gzip.NewWriter(&compressed)
+	if _, err := writer.Write(release); err != nil {
+		t.Fatalf("Failed to compress release: %v", err)
+	}
+	if err := writer.Close(); err != nil {
+		t.Fatalf("Failed to compress release: %v", err)
+	}
+
+	// Helm base64 encodes the release, and the secret encodes it again
+	data := base64.StdEncoding.EncodeToString([]byte(base64.StdEncoding.EncodeToString(compressed.Bytes())))
+	return fmt.Sprintf(`{"items": [{"metadata": {"name": "sh.helm.release.v1.%s.v3", "namespace": "%s"}, "data": {"release": "%s"}}]}`, name, namespace, data)
+}
+
+func TestGetDeprecatedAPIsHandler(t *testing.T) {
+	stop, err := command.UseCassette(filepath.Join("testdata", "cassettes", "deprecated_apis.json"))
+	if err != nil {
+		t.Fatalf("Failed to load cassette: %v", err)
+	}
+	defer func() {
+		if err := stop(); err != nil {
+			t.Errorf("Failed to save cassette: %v", err)
+		}
+	}()
+
+	_, client, cfg := armtest.NewClient(t)
+
+	kubectl := &stubKubectl{outputs: map[string]string{
+		"api-resources --verbs=list --output name":                                         testAPIResources,
+		"get deployments,ingresses,poddisruptionbudgets --all-namespaces --output json":    testIngresses,
+		"get secrets --all-namespaces --selector owner=helm,status=deployed --output json": helmSecrets(t, "metrics", "monitoring", testHelmManifest),
+	}}
+
+	handler := GetDeprecatedAPIsHandler(client, kubectl, cfg)
+	output, err := handler.Handle(map[string]interface{}{
+		"subscription_id": fakearm.SubscriptionID,
+		"resource_group":  fakearm.ResourceGroup,
+		"cluster_name":    fakearm.ClusterName,
+		"target_version":  "1.32",
+		"start_time":      "2025-01-01T00:00:00Z",
+		"end_time":        "2025-01-02T00:00:00Z",
+	}, cfg)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	var report Report
+	if err := json.Unmarshal([]byte(output), &report); err != nil {
+		t.Fatalf("Expected a report, got %q: %v", output, err)
+	}
+	if report.KubernetesVersion != "1.30.4" || report.TargetVersion != "1.32" || report.TableVersion == "" {
+		t.Errorf("Unexpected report header: %+v", report)
+	}
+	if report.AuditTimeRange == nil || report.AuditTimeRange.Timespan() != "2025-01-01T00:00:00Z/2025-01-02T00:00:00Z" {
+		t.Errorf("Expected the audit time range in the report, got %+v", report.AuditTimeRange)
+	}
+
+	var got []string
+	for _, f := range report.Findings {
+		got = append(got, fmt.Sprintf("%s %s %s %s/%s %s %s", f.Source, f.APIVersion, f.Kind, f.Namespace, f.Name, f.RemovedIn, f.Status))
+	}
+	want := []string{
+		"audit example.com/v1alpha1  / 1.33 deprecated",
+		"audit flowcontrol.apiserver.k8s.io/v1beta3 FlowSchema / 1.32 removed",
+		"helm flowcontrol.apiserver.k8s.io/v1beta3 FlowSchema monitoring/metrics-exporter 1.32 removed",
+		"helm policy/v1beta1 PodDisruptionBudget observability/metrics-exporter 1.25 removed",
+		"manifests networking.k8s.io/v1beta1 Ingress shop/storefront 1.22 removed",
+	}
+	if strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Errorf("Unexpected findings:\n%s\nwant:\n%s", strings.Join(got, "\n"), strings.Join(want, "\n"))
+	}
+
+	for _, f := range report.Findings {
+		if f.Source == SourceHelm && f.HelmRelease != "metrics" {
+			t.Errorf("Expected Helm findings to name the release, got %+v", f)
+		}
+		if f.Source == SourceAudit && f.Resource == "flowschemas" && (f.Requests != 42 || f.UserAgent != "legacy-operator/v0.4.1") {
+			t.Errorf("Expected audit finding to identify the client, got %+v", f)
+		}
+	}
+
+	// The fake cluster only sends kube-audit-admin logs
+	for _, source := range report.Sources {
+		if !source.Scanned || source.Message != "" && source.Name != SourceAudit {
+			t.Errorf("Unexpected source result: %+v", source)
+		}
+		if source.Name == SourceAudit && !strings.Contains(source.Message, "kube-audit-admin") {
+			t.Errorf("Expected audit source to name the log category, got %+v", source)
+		}
+	}
+}
+
+func TestGetDeprecatedAPIsHandler_InvalidParams(t *testing.T) {
+	defer func(original func() time.Time) { deprecationNow = original }(deprecationNow)
+	deprecationNow = func() time.Time { return time.Date(2025, 1, 2, 0, 0, 0, 0, time.UTC) }
+
+	tests := []struct {
+		name   string
+		params map[string]interface{}
+		want   string
+	}{
+		{name: "source", params: map[string]interface{}{"sources": "manifests,etcd"}, want: "invalid source 'etcd'"},
+		{name: "target version", params: map[string]interface{}{"target_version": "latest"}, want: "invalid target_version"},
+		{name: "end without start", params: map[string]interface{}{"end_time": "2025-01-02T00:00:00Z"}, want: "end_time requires start_time"},
+		{name: "cluster name", params: map[string]interface{}{"cluster_name": "aks' or 1 == 1 or '"}, want: "invalid cluster_name"},
+		{name: "tenant", params: map[string]interface{}{"tenant_id": "11111111-1111-1111-1111-111111111111"}, want: "tenant_id is not supported"},
+		{name: "future start", params: map[string]interface{}{"start_time": "2025-01-03T00:00:00Z"}, want: "start_time cannot be in the future"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			params := map[string]interface{}{
+				"subscription_id": fakearm.SubscriptionID,
+				"resource_group":  fakearm.ResourceGroup,
+				"cluster_name":    fakearm.ClusterName,
+			}
+			for k, v := range tt.params {
+				params[k] = v
+			}
+
+			handler := GetDeprecatedAPIsHandler(nil, &stubKubectl{}, config.NewConfig())
+			_, err := handler.Handle(params, config.NewConfig())
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("Expected error containing %q, got %v", tt.want, err)
+			}
+		})
+	}
+}
+
+func TestBuildAuditQuery(t *testing.T) {
+	const id = "/subscriptions/sub/resourceGroups/RG/providers/Microsoft.ContainerService/managedClusters/AKS"
+
+	query, err := buildAuditQuery("kube-audit-admin", id, true)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if !strings.HasPrefix(query, "AKSAuditAdmin | where _ResourceId == '"+strings.ToLower(id)+"' | ") {
+		t.Errorf("Expected the resource-specific table filtered by the lowercase resource ID, got %s", query)
+	}
+
+	query, err = buildAuditQuery("kube-audit", id, false)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if !strings.HasPrefix(query, "AzureDiagnostics | where Category == 'kube-audit' and ResourceId == '"+strings.ToUpper(id)+"' | ") {
+		t.Errorf("Expected AzureDiagnostics filtered by category and the uppercase resource ID, got %s", query)
+	}
+
+	if _, err := buildAuditQuery("kube-apiserver", id, true); err == nil || !strings.Contains(err.Error(), "invalid audit log category") {
+		t.Errorf("Expected a category that is not an audit category to be rejected, got %v", err)
+	}
+}
+
+func TestBuildAuditQuery_InvalidResourceID(t *testing.T) {
+	for _, id := range []string{
+		"/subscriptions/sub/resourceGroups/rg/providers/Microsoft.ContainerService/managedClusters/aks' | union SecretTable | where '' == '",
+		"/subscriptions/sub/resourceGroups/rg/providers/Microsoft.Compute/virtualMachines/vm",
+	} {
+		if _, err := buildAuditQuery("kube-audit", id, true); err == nil || !strings.Contains(err.Error(), "invalid clusterResourceID") {
+			t.Errorf("Expected resource ID %q to be rejected, got %v", id, err)
+		}
+	}
+}
+
+func TestScanHelmReleases_UncompressedRelease(t *testing.T) {
+	table, err := DefaultTable()
+	if err != nil {
+		t.Fatalf("Failed to load table: %v", err)
+	}
+
+	release := `{"name": "jobs", "namespace": "batch", "manifest": "apiVersion: batch/v1beta1\nkind: CronJob\nmetadata:\n  name: nightly\n"}`
+	data := base64.StdEncoding.EncodeToString([]byte(base64.StdEncoding.EncodeToString([]byte(release))))
+	output := fmt.Sprintf(`{"items": [{"metadata": {"name": "sh.helm.release.v1.jobs.v1", "namespace": "batch"}, "data": {"release": "%s"}}]}`, data)
+
+	findings, err := scanHelmReleases(table, output)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(findings) != 1 || findings[0].Namespace != "batch" || findings[0].Name != "nightly" || findings[0].Replacement != "batch/v1" {
+		t.Errorf("Unexpected findings: %+v", findings)
+	}
+}
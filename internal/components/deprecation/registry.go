@@ -0,0 +1,43 @@
+package deprecation
+
+import (
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// RegisterDeprecatedAPIsTool registers the aks_deprecated_apis tool
+func RegisterDeprecatedAPIsTool() mcp.Tool {
+	return mcp.NewTool(
+		"aks_deprecated_apis",
+		mcp.WithDescription("Find deprecated and removed Kubernetes API versions still in use in an AKS cluster and the Kubernetes version that removes each one. "+
+			"Checks manifests last applied with kubectl, the manifests of deployed Helm releases, and, when kube-audit or kube-audit-admin "+
+			"diagnostic logs are sent to Log Analytics, the clients that requested deprecated APIs. "+
+			"Kubernetes checks run against the named cluster with credentials aks-mcp fetches for it. Findings are marked removed when the target version no longer serves the API."),
+		mcp.WithString("subscription_id",
+			mcp.Description("Azure Subscription ID"),
+			mcp.Required(),
+		),
+		mcp.WithString("resource_group",
+			mcp.Description("Azure Resource Group containing the AKS cluster"),
+			mcp.Required(),
+		),
+		mcp.WithString("cluster_name",
+			mcp.Description("Name of the AKS cluster"),
+			mcp.Required(),
+		),
+		mcp.WithString("target_version",
+			mcp.Description("Kubernetes version to evaluate removals against, e.g. 1.32. Defaults to the cluster's current version."),
+		),
+		mcp.WithString("sources",
+			mcp.Description("Comma-separated sources to scan: manifests, helm, audit. Defaults to all."),
+		),
+		mcp.WithString("time_range",
+			mcp.Description("Time range of the audit log search, e.g. \"last 6h\", PT30M, \"since 2025-01-01T00:00:00Z\" or \"around 2025-01-01T10:00:00Z ±15m\". Defaults to the last 24 hours."),
+		),
+		mcp.WithString("start_time",
+			mcp.Description("Start of the audit log search in RFC3339 format (e.g., 2025-01-01T00:00:00Z), instead of time_range."),
+		),
+		mcp.WithString("end_time",
+			mcp.Description("End of the audit log search in RFC3339 format. Defaults to now."),
+		),
+	)
+}
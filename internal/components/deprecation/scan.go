@@ -0,0 +1,254 @@
+package deprecation
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+
+	"github.com/Azure/aks-mcp/internal/components/common"
+	"k8s.io/apimachinery/pkg/util/yaml"
+)
+
+// Sources of deprecated API usage
+const (
+	SourceManifests = "manifests"
+	SourceHelm      = "helm"
+	SourceAudit     = "audit"
+)
+
+// Status values of a finding relative to the version the report is evaluated against
+const (
+	StatusRemoved    = "removed"
+	StatusDeprecated = "deprecated"
+)
+
+// lastAppliedAnnotation records the manifest last applied with kubectl apply, in the apiVersion it was written in
+const lastAppliedAnnotation = "kubectl.kubernetes.io/last-applied-configuration"
+
+// Report lists the deprecated API versions in use in a cluster
+type Report struct {
+	ClusterName       string            `json:"cluster_name"`
+	ResourceGroup     string            `json:"resource_group"`
+	KubernetesVersion string            `json:"kubernetes_version"`
+	TargetVersion     string            `json:"target_version"`
+	TableVersion      string            `json:"table_version"`
+	AuditTimeRange    *common.TimeRange `json:"audit_time_range,omitempty"`
+	Findings          []Finding         `json:"findings"`
+	Sources           []SourceResult    `json:"sources"`
+}
+
+// Finding is a use of a deprecated API version
+type Finding struct {
+	Source       string `json:"source"`
+	APIVersion   string `json:"api_version"`
+	Kind         string `json:"kind,omitempty"`
+	Resource     string `json:"resource,omitempty"`
+	Namespace    string `json:"namespace,omitempty"`
+	Name         string `json:"name,omitempty"`
+	HelmRelease  string `json:"helm_release,omitempty"`
+	User         string `json:"user,omitempty"`
+	UserAgent    string `json:"user_agent,omitempty"`
+	Requests     int    `json:"requests,omitempty"`
+	LastSeen     string `json:"last_seen,omitempty"`
+	DeprecatedIn string `json:"deprecated_in,omitempty"`
+	RemovedIn    string `json:"removed_in"`
+	Replacement  string `json:"replacement,omitempty"`
+	Status       string `json:"status"`
+}
+
+// SourceResult describes whether a source was scanned
+type SourceResult struct {
+	Name     string `json:"name"`
+	Scanned  bool   `json:"scanned"`
+	Findings int    `json:"findings"`
+	Message  string `json:"message,omitempty"`
+}
+
+// newFinding builds a finding for a table entry
+func newFinding(source string, api *DeprecatedAPI) Finding {
+	return Finding{
+		Source:       source,
+		APIVersion:   api.APIVersion(),
+		Kind:         api.Kind,
+		Resource:     api.Resource,
+		DeprecatedIn: api.DeprecatedIn,
+		RemovedIn:    api.RemovedIn,
+		Replacement:  api.Replacement,
+	}
+}
+
+// setStatus marks findings removed or deprecated relative to the target version
+func (r *Report) setStatus() {
+	target, err := parseMinorVersion(r.TargetVersion)
+	for i := range r.Findings {
+		r.Findings[i].Status = StatusDeprecated
+		if err != nil {
+			continue
+		}
+		if removed, err := parseMinorVersion(r.Findings[i].RemovedIn); err == nil && compareMinorVersions(target, removed) >= 0 {
+			r.Findings[i].Status = StatusRemoved
+		}
+	}
+
+	sort.SliceStable(r.Findings, func(i, j int) bool {
+		a, b := r.Findings[i], r.Findings[j]
+		if a.Source != b.Source {
+			return a.Source < b.Source
+		}
+		if a.APIVersion != b.APIVersion {
+			return a.APIVersion < b.APIVersion
+		}
+		if a.Namespace != b.Namespace {
+			return a.Namespace < b.Namespace
+		}
+		return a.Name < b.Name
+	})
+}
+
+// objectMeta holds the fields of a Kubernetes object used to report findings
+type objectMeta struct {
+	APIVersion string `json:"apiVersion"`
+	Kind       string `json:"kind"`
+	Metadata   struct {
+		Name        string            `json:"name"`
+		Namespace   string            `json:"namespace"`
+		Annotations map[string]string `json:"annotations"`
+	} `json:"metadata"`
+}
+
+// scanAppliedManifests finds objects of a kubectl list whose last applied manifest uses a deprecated API version
+func scanAppliedManifests(table *Table, output string) ([]Finding, error) {
+	var list struct {
+		Items []objectMeta `json:"items"`
+	}
+	if err := json.Unmarshal([]byte(output), &list); err != nil {
+		return nil, fmt.Errorf("unexpected kubectl output: %s", strings.TrimSpace(output))
+	}
+
+	var findings []Finding
+	for _, item := range list.Items {
+		applied := item.Metadata.Annotations[lastAppliedAnnotation]
+		if applied == "" {
+			continue
+		}
+
+		var manifest objectMeta
+		if err := json.Unmarshal([]byte(applied), &manifest); err != nil {
+			continue
+		}
+		if api := table.LookupKind(manifest.APIVersion, manifest.Kind); api != nil {
+			finding := newFinding(SourceManifests, api)
+			finding.Namespace = item.Metadata.Namespace
+			finding.Name = item.Metadata.Name
+			findings = append(findings, finding)
+		}
+	}
+	return findings, nil
+}
+
+// helmRelease holds the fields of a Helm 3 release used to report findings
+type helmRelease struct {
+	Name      string `json:"name"`
+	Namespace string `json:"namespace"`
+	Manifest  string `json:"manifest"`
+}
+
+// scanHelmReleases finds deprecated API versions in the manifests of Helm 3 releases stored as secrets
+func scanHelmReleases(table *Table, output string) ([]Finding, error) {
+	var list struct {
+		Items []struct {
+			Metadata struct {
+				Name      string `json:"name"`
+				Namespace string `json:"namespace"`
+			} `json:"metadata"`
+			Data map[string]string `json:"data"`
+		} `json:"items"`
+	}
+	if err := json.Unmarshal([]byte(output), &list); err != nil {
+		return nil, fmt.Errorf("unexpected kubectl output: %s", strings.TrimSpace(output))
+	}
+
+	var findings []Finding
+	for _, item := range list.Items {
+		release, err := decodeHelmRelease(item.Data["release"])
+		if err != nil {
+			return nil, fmt.Errorf("failed to decode Helm release secret %s/%s: %v", item.Metadata.Namespace, item.Metadata.Name, err)
+		}
+
+		objects, err := manifestObjects(release.Manifest)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse manifest of Helm release %s/%s: %v", release.Namespace, release.Name, err)
+		}
+		for _, object := range objects {
+			if api := table.LookupKind(object.APIVersion, object.Kind); api != nil {
+				finding := newFinding(SourceHelm, api)
+				finding.Namespace = object.Metadata.Namespace
+				if finding.Namespace == "" {
+					finding.Namespace = release.Namespace
+				}
+				finding.Name = object.Metadata.Name
+				finding.HelmRelease = release.Name
+				findings = append(findings, finding)
+			}
+		}
+	}
+	return findings, nil
+}
+
+// decodeHelmRelease decodes the release field of a Helm 3 secret, which holds base64 encoded,
+// gzipped release JSON and is base64 encoded again by the secret
+func decodeHelmRelease(data string) (*helmRelease, error) {
+	encoded, err := base64.StdEncoding.DecodeString(data)
+	if err != nil {
+		return nil, err
+	}
+	compressed, err := base64.StdEncoding.DecodeString(string(encoded))
+	if err != nil {
+		return nil, err
+	}
+
+	// Helm only compresses releases written by Helm 3, so accept plain JSON too
+	raw := compressed
+	if len(compressed) > 2 && compressed[0] == 0x1f && compressed[1] == 0x8b {
+		reader, err := gzip.NewReader(bytes.NewReader(compressed))
+		if err != nil {
+			return nil, err
+		}
+		defer func() {
+			_ = reader.Close()
+		}()
+		if raw, err = io.ReadAll(reader); err != nil {
+			return nil, err
+		}
+	}
+
+	var release helmRelease
+	if err := json.Unmarshal(raw, &release); err != nil {
+		return nil, err
+	}
+	return &release, nil
+}
+
+// manifestObjects parses the objects of a multi-document YAML manifest
+func manifestObjects(manifest string) ([]objectMeta, error) {
+	decoder := yaml.NewYAMLOrJSONDecoder(strings.NewReader(manifest), 4096)
+
+	var objects []objectMeta
+	for {
+		var object objectMeta
+		if err := decoder.Decode(&object); err != nil {
+			if err == io.EOF {
+				return objects, nil
+			}
+			return nil, err
+		}
+		if object.APIVersion != "" && object.Kind != "" {
+			objects = append(objects, object)
+		}
+	}
+}
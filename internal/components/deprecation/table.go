@@ -0,0 +1,143 @@
+// Package deprecation provides the aks_deprecated_apis tool, which finds deprecated and removed Kubernetes
+// API versions still used by a cluster's manifests, Helm releases and clients.
+package deprecation
+
+import (
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// tableData is the deprecation table shipped with the server. Update it when a Kubernetes release
+// deprecates or removes API versions, and bump its version.
+//
+//go:embed deprecations.json
+var tableData []byte
+
+// Table lists deprecated Kubernetes API versions and the releases that remove them
+type Table struct {
+	Version           string          `json:"version"`
+	KubernetesVersion string          `json:"kubernetes_version"`
+	APIs              []DeprecatedAPI `json:"apis"`
+}
+
+// DeprecatedAPI is a kind served by a deprecated group version
+type DeprecatedAPI struct {
+	Group        string `json:"group"`
+	Version      string `json:"version"`
+	Kind         string `json:"kind"`
+	Resource     string `json:"resource"`
+	DeprecatedIn string `json:"deprecated_in"`
+	RemovedIn    string `json:"removed_in"`
+	Replacement  string `json:"replacement,omitempty"`
+}
+
+// APIVersion returns the apiVersion written in manifests for the deprecated group version
+func (a DeprecatedAPI) APIVersion() string {
+	if a.Group == "" {
+		return a.Version
+	}
+	return a.Group + "/" + a.Version
+}
+
+// DefaultTable returns the deprecation table shipped with the server
+func DefaultTable() (*Table, error) {
+	return LoadTable(tableData)
+}
+
+// LoadTable parses and validates a deprecation table
+func LoadTable(data []byte) (*Table, error) {
+	var table Table
+	if err := json.Unmarshal(data, &table); err != nil {
+		return nil, fmt.Errorf("failed to parse deprecation table: %v", err)
+	}
+	if table.Version == "" {
+		return nil, fmt.Errorf("deprecation table has no version")
+	}
+
+	for _, api := range table.APIs {
+		if api.Version == "" || api.Kind == "" || api.Resource == "" {
+			return nil, fmt.Errorf("deprecation table entry %s %s is incomplete", api.APIVersion(), api.Kind)
+		}
+		deprecated, err := parseMinorVersion(api.DeprecatedIn)
+		if err != nil {
+			return nil, fmt.Errorf("deprecation table entry %s %s: %v", api.APIVersion(), api.Kind, err)
+		}
+		removed, err := parseMinorVersion(api.RemovedIn)
+		if err != nil {
+			return nil, fmt.Errorf("deprecation table entry %s %s: %v", api.APIVersion(), api.Kind, err)
+		}
+		if compareMinorVersions(removed, deprecated) <= 0 {
+			return nil, fmt.Errorf("deprecation table entry %s %s is removed before it is deprecated", api.APIVersion(), api.Kind)
+		}
+	}
+	return &table, nil
+}
+
+// LookupKind returns the table entry for a kind written with the given apiVersion, or nil
+func (t *Table) LookupKind(apiVersion, kind string) *DeprecatedAPI {
+	for i := range t.APIs {
+		if t.APIs[i].APIVersion() == apiVersion && t.APIs[i].Kind == kind {
+			return &t.APIs[i]
+		}
+	}
+	return nil
+}
+
+// LookupResource returns the table entry for a resource requested through the given group version, or nil
+func (t *Table) LookupResource(group, version, resource string) *DeprecatedAPI {
+	for i := range t.APIs {
+		if t.APIs[i].Group == group && t.APIs[i].Version == version && t.APIs[i].Resource == resource {
+			return &t.APIs[i]
+		}
+	}
+	return nil
+}
+
+// Resources returns the resources that have a deprecated version, sorted by name
+func (t *Table) Resources() []string {
+	seen := make(map[string]bool)
+	var resources []string
+	for _, api := range t.APIs {
+		if !seen[api.Resource] {
+			seen[api.Resource] = true
+			resources = append(resources, api.Resource)
+		}
+	}
+	sort.Strings(resources)
+	return resources
+}
+
+// parseMinorVersion parses the major and minor parts of a Kubernetes version such as 1.25 or v1.30.4
+func parseMinorVersion(version string) ([2]int, error) {
+	parts := strings.Split(strings.TrimPrefix(version, "v"), ".")
+	if len(parts) < 2 {
+		return [2]int{}, fmt.Errorf("invalid Kubernetes version '%s'", version)
+	}
+
+	var parsed [2]int
+	for i := range parsed {
+		n, err := strconv.Atoi(parts[i])
+		if err != nil {
+			return [2]int{}, fmt.Errorf("invalid Kubernetes version '%s'", version)
+		}
+		parsed[i] = n
+	}
+	return parsed, nil
+}
+
+// compareMinorVersions returns -1, 0 or 1 as a is older than, the same minor as, or newer than b
+func compareMinorVersions(a, b [2]int) int {
+	for i := range a {
+		if a[i] != b[i] {
+			if a[i] < b[i] {
+				return -1
+			}
+			return 1
+		}
+	}
+	return 0
+}
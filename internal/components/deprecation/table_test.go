@@ -0,0 +1,75 @@
+package deprecation
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestDefaultTable(t *testing.T) {
+	table, err := DefaultTable()
+	if err != nil {
+		t.Fatalf("Shipped deprecation table is invalid: %v", err)
+	}
+	if table.Version == "" || len(table.APIs) == 0 {
+		t.Fatalf("Expected a versioned table with entries, got %+v", table)
+	}
+
+	// Each group version and kind appears once so lookups are unambiguous
+	seen := make(map[string]bool)
+	for _, api := range table.APIs {
+		key := api.APIVersion() + " " + api.Kind
+		if seen[key] {
+			t.Errorf("Duplicate deprecation table entry %s", key)
+		}
+		seen[key] = true
+	}
+
+	api := table.LookupKind("batch/v1beta1", "CronJob")
+	if api == nil || api.RemovedIn != "1.25" || api.Replacement != "batch/v1" {
+		t.Errorf("Unexpected CronJob entry: %+v", api)
+	}
+	if api := table.LookupResource("flowcontrol.apiserver.k8s.io", "v1beta3", "flowschemas"); api == nil || api.RemovedIn != "1.32" {
+		t.Errorf("Unexpected flowschemas entry: %+v", api)
+	}
+	if api := table.LookupKind("apps/v1", "Deployment"); api != nil {
+		t.Errorf("Expected apps/v1 Deployment not to be deprecated, got %+v", api)
+	}
+}
+
+func TestLoadTable_Invalid(t *testing.T) {
+	tests := []struct {
+		name string
+		data string
+		want string
+	}{
+		{
+			name: "no version",
+			data: `{"apis": []}`,
+			want: "has no version",
+		},
+		{
+			name: "bad release",
+			data: `{"version": "1", "apis": [{"group": "batch", "version": "v1beta1", "kind": "CronJob", "resource": "cronjobs", "deprecated_in": "1.21", "removed_in": "next"}]}`,
+			want: "invalid Kubernetes version 'next'",
+		},
+		{
+			name: "removed before deprecated",
+			data: `{"version": "1", "apis": [{"group": "batch", "version": "v1beta1", "kind": "CronJob", "resource": "cronjobs", "deprecated_in": "1.25", "removed_in": "1.21"}]}`,
+			want: "removed before it is deprecated",
+		},
+		{
+			name: "missing resource",
+			data: `{"version": "1", "apis": [{"group": "batch", "version": "v1beta1", "kind": "CronJob", "deprecated_in": "1.21", "removed_in": "1.25"}]}`,
+			want: "is incomplete",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := LoadTable([]byte(tt.data))
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("Expected error containing %q, got %v", tt.want, err)
+			}
+		})
+	}
+}
@@ -27,7 +27,7 @@ func NewDetectorClient(azClient *azureclient.AzureClient) *DetectorClient {
 // ListDetectors lists all detectors for a cluster with caching
 func (c *DetectorClient) ListDetectors(ctx context.Context, subscriptionID, resourceGroup, clusterName string) (*DetectorListResponse, error) {
 	// Create cache key
-	cacheKey := fmt.Sprintf("detectors:list:%s:%s:%s", subscriptionID, resourceGroup, clusterName)
+	cacheKey := c.azClient.CacheKey(ctx, "detectors:list", subscriptionID, resourceGroup, clusterName)
 
 	// Check cache first
 	if cached, found := c.cache.Get(cacheKey); found {
@@ -36,8 +36,15 @@ func (c *DetectorClient) ListDetectors(ctx context.Context, subscriptionID, reso
 		}
 	}
 
+	// Check persistent cache next
+	var persisted DetectorListResponse
+	if c.cache.GetPersisted(cacheKey, &persisted) {
+		return &persisted, nil
+	}
+
 	// Build API URL
-	apiURL := fmt.Sprintf("https://management.azure.com/subscriptions/%s/resourceGroups/%s/providers/Microsoft.ContainerService/managedClusters/%s/detectors?api-version=2024-08-01",
+	apiURL := fmt.Sprintf("%s/subscriptions/%s/resourceGroups/%s/providers/Microsoft.ContainerService/managedClusters/%s/detectors?api-version=2024-08-01",
+		c.azClient.ResourceManagerEndpoint(),
 		url.PathEscape(subscriptionID),
 		url.PathEscape(resourceGroup),
 		url.PathEscape(clusterName))
@@ -61,7 +68,7 @@ func (c *DetectorClient) ListDetectors(ctx context.Context, subscriptionID, reso
 	}
 
 	// Cache the result
-	c.cache.Set(cacheKey, &detectorList)
+	c.cache.SetPersisted(cacheKey, &detectorList)
 
 	return &detectorList, nil
 }
@@ -69,7 +76,8 @@ func (c *DetectorClient) ListDetectors(ctx context.Context, subscriptionID, reso
 // RunDetector executes a specific detector
 func (c *DetectorClient) RunDetector(ctx context.Context, subscriptionID, resourceGroup, clusterName, detectorName, startTime, endTime string) (*DetectorRunResponse, error) {
 	// Build API URL with query parameters
-	apiURL := fmt.Sprintf("https://management.azure.com/subscriptions/%s/resourcegroups/%s/providers/microsoft.containerservice/managedclusters/%s/detectors/%s?startTime=%s&endTime=%s&api-version=2024-08-01",
+	apiURL := fmt.Sprintf("%s/subscriptions/%s/resourcegroups/%s/providers/microsoft.containerservice/managedclusters/%s/detectors/%s?startTime=%s&endTime=%s&api-version=2024-08-01",
+		c.azClient.ResourceManagerEndpoint(),
 		url.PathEscape(subscriptionID),
 		url.PathEscape(resourceGroup),
 		url.PathEscape(clusterName),
@@ -1,11 +1,17 @@
 package detectors
 
 import (
+	"encoding/json"
+	"strings"
 	"testing"
 	"time"
+
+	"github.com/Azure/aks-mcp/internal/azureclient/fakearm"
+	"github.com/Azure/aks-mcp/internal/azureclient/fakearm/armtest"
+	"github.com/Azure/aks-mcp/internal/components/common"
 )
 
-func TestValidateTimeParameters(t *testing.T) {
+func TestDetectorTimeRange(t *testing.T) {
 	now := time.Now()
 	validStart := now.Add(-1 * time.Hour).Format(time.RFC3339)
 	validEnd := now.Format(time.RFC3339)
@@ -56,14 +62,31 @@ func TestValidateTimeParameters(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			err := validateTimeParameters(tt.startTime, tt.endTime)
+			_, err := detectorTimeRange(map[string]interface{}{"start_time": tt.startTime, "end_time": tt.endTime})
 			if (err != nil) != tt.wantErr {
-				t.Errorf("validateTimeParameters() error = %v, wantErr %v", err, tt.wantErr)
+				t.Errorf("detectorTimeRange() error = %v, wantErr %v", err, tt.wantErr)
 			}
 		})
 	}
 }
 
+func TestDetectorTimeRange_RelativeRange(t *testing.T) {
+	defer func(original func() time.Time) { detectorNow = original }(detectorNow)
+	detectorNow = func() time.Time { return time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC) }
+
+	timeRange, err := detectorTimeRange(map[string]interface{}{"time_range": "last 6h"})
+	if err != nil {
+		t.Fatalf("detectorTimeRange() error = %v", err)
+	}
+	if got, want := timeRange.Timespan(), "2026-03-01T06:00:00Z/2026-03-01T12:00:00Z"; got != want {
+		t.Errorf("detectorTimeRange() = %s, want %s", got, want)
+	}
+
+	if _, err := detectorTimeRange(map[string]interface{}{"time_range": "last 2d"}); err == nil {
+		t.Error("detectorTimeRange() expected an error for a range over 24h")
+	}
+}
+
 func TestValidateCategory(t *testing.T) {
 	tests := []struct {
 		name     string
@@ -108,3 +131,70 @@ func TestValidateCategory(t *testing.T) {
 		})
 	}
 }
+
+// newFakeARMDetectorClient creates a detector client backed by a local fake ARM server
+func newFakeARMDetectorClient(t *testing.T) *DetectorClient {
+	t.Helper()
+
+	_, azClient, _ := armtest.NewClient(t)
+	return NewDetectorClient(azClient)
+}
+
+func TestHandleListDetectors_FakeARM(t *testing.T) {
+	client := newFakeARMDetectorClient(t)
+
+	result, err := HandleListDetectors(map[string]interface{}{
+		"cluster_resource_id": fakearm.ClusterResourceID,
+	}, client)
+	if err != nil {
+		t.Fatalf("Failed to list detectors: %v", err)
+	}
+
+	var detectors DetectorListResponse
+	if err := json.Unmarshal([]byte(result), &detectors); err != nil {
+		t.Fatalf("Failed to parse result: %v", err)
+	}
+	if len(detectors.Value) != 2 {
+		t.Errorf("Expected 2 detectors, got %d", len(detectors.Value))
+	}
+}
+
+func TestHandleRunDetector_FakeARM(t *testing.T) {
+	client := newFakeARMDetectorClient(t)
+	now := time.Now().UTC()
+
+	result, err := HandleRunDetector(map[string]interface{}{
+		"cluster_resource_id": fakearm.ClusterResourceID,
+		"detector_name":       "node-health",
+		"start_time":          now.Add(-1 * time.Hour).Format(time.RFC3339),
+		"end_time":            now.Format(time.RFC3339),
+	}, client)
+	if err != nil {
+		t.Fatalf("Failed to run detector: %v", err)
+	}
+
+	var response struct {
+		TimeRange common.TimeRange    `json:"time_range"`
+		Result    DetectorRunResponse `json:"result"`
+	}
+	if err := json.Unmarshal([]byte(result), &response); err != nil {
+		t.Fatalf("Failed to parse result: %v", err)
+	}
+	if response.TimeRange.Duration != "1h0m0s" {
+		t.Errorf("Expected the one hour time range, got %+v", response.TimeRange)
+	}
+	if run := response.Result; len(run.Properties.Dataset) != 1 || run.Properties.Dataset[0].Table.TableName != "NodeReadiness" {
+		t.Errorf("Expected NodeReadiness dataset, got %+v", response.Result.Properties.Dataset)
+	}
+
+	// Unknown detectors surface the ARM error
+	_, err = HandleRunDetector(map[string]interface{}{
+		"cluster_resource_id": fakearm.ClusterResourceID,
+		"detector_name":       "missing-detector",
+		"start_time":          now.Add(-1 * time.Hour).Format(time.RFC3339),
+		"end_time":            now.Format(time.RFC3339),
+	}, client)
+	if err == nil || !strings.Contains(err.Error(), "404") {
+		t.Errorf("Expected 404 error for unknown detector, got %v", err)
+	}
+}
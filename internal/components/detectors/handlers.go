@@ -8,6 +8,7 @@ import (
 	"time"
 
 	"github.com/Azure/aks-mcp/internal/azureclient"
+	"github.com/Azure/aks-mcp/internal/components/common"
 	"github.com/Azure/aks-mcp/internal/config"
 	"github.com/Azure/aks-mcp/internal/tools"
 )
@@ -55,8 +56,12 @@ func HandleListDetectors(params map[string]interface{}, client *DetectorClient)
 		return "", fmt.Errorf("failed to parse cluster resource ID: %v", err)
 	}
 
+	ctx, err := common.TenantContext(context.Background(), client.azClient, params)
+	if err != nil {
+		return "", err
+	}
+
 	// List detectors
-	ctx := context.Background()
 	detectors, err := client.ListDetectors(ctx, subscriptionID, resourceGroup, clusterName)
 	if err != nil {
 		return "", fmt.Errorf("failed to list detectors: %v", err)
@@ -85,20 +90,9 @@ func HandleRunDetector(params map[string]interface{}, client *DetectorClient) (s
 		return "", fmt.Errorf("missing or invalid detector_name parameter")
 	}
 
-	// Extract start time
-	startTime, ok := params["start_time"].(string)
-	if !ok || startTime == "" {
-		return "", fmt.Errorf("missing or invalid start_time parameter")
-	}
-
-	// Extract end time
-	endTime, ok := params["end_time"].(string)
-	if !ok || endTime == "" {
-		return "", fmt.Errorf("missing or invalid end_time parameter")
-	}
-
-	// Validate time format and constraints
-	if err := validateTimeParameters(startTime, endTime); err != nil {
+	// Resolve the time range from time_range or start_time and end_time
+	timeRange, err := detectorTimeRange(params)
+	if err != nil {
 		return "", fmt.Errorf("invalid time parameters: %v", err)
 	}
 
@@ -108,8 +102,13 @@ func HandleRunDetector(params map[string]interface{}, client *DetectorClient) (s
 		return "", fmt.Errorf("failed to parse cluster resource ID: %v", err)
 	}
 
+	ctx, err := common.TenantContext(context.Background(), client.azClient, params)
+	if err != nil {
+		return "", err
+	}
+
 	// Run detector
-	ctx := context.Background()
+	startTime, endTime := timeRange.Start.Format(time.RFC3339), timeRange.End.Format(time.RFC3339)
 	result, err := client.RunDetector(ctx, subscriptionID, resourceGroup, clusterName, detectorName, startTime, endTime)
 	if err != nil {
 		return "", fmt.Errorf("failed to run detector: %v", err)
@@ -121,7 +120,7 @@ func HandleRunDetector(params map[string]interface{}, client *DetectorClient) (s
 		return "", fmt.Errorf("failed to marshal detector result to JSON: %v", err)
 	}
 
-	return string(resultJSON), nil
+	return common.WithTimeRange(string(resultJSON), timeRange)
 }
 
 // HandleRunDetectorsByCategory implements the run_detectors_by_category functionality
@@ -138,20 +137,9 @@ func HandleRunDetectorsByCategory(params map[string]interface{}, client *Detecto
 		return "", fmt.Errorf("missing or invalid category parameter")
 	}
 
-	// Extract start time
-	startTime, ok := params["start_time"].(string)
-	if !ok || startTime == "" {
-		return "", fmt.Errorf("missing or invalid start_time parameter")
-	}
-
-	// Extract end time
-	endTime, ok := params["end_time"].(string)
-	if !ok || endTime == "" {
-		return "", fmt.Errorf("missing or invalid end_time parameter")
-	}
-
-	// Validate time parameters
-	if err := validateTimeParameters(startTime, endTime); err != nil {
+	// Resolve the time range from time_range or start_time and end_time
+	timeRange, err := detectorTimeRange(params)
+	if err != nil {
 		return "", fmt.Errorf("invalid time parameters: %v", err)
 	}
 
@@ -166,8 +154,13 @@ func HandleRunDetectorsByCategory(params map[string]interface{}, client *Detecto
 		return "", fmt.Errorf("failed to parse cluster resource ID: %v", err)
 	}
 
+	ctx, err := common.TenantContext(context.Background(), client.azClient, params)
+	if err != nil {
+		return "", err
+	}
+
 	// Run detectors by category
-	ctx := context.Background()
+	startTime, endTime := timeRange.Start.Format(time.RFC3339), timeRange.End.Format(time.RFC3339)
 	results, err := client.RunDetectorsByCategory(ctx, subscriptionID, resourceGroup, clusterName, category, startTime, endTime)
 	if err != nil {
 		return "", fmt.Errorf("failed to run detectors by category: %v", err)
@@ -176,6 +169,7 @@ func HandleRunDetectorsByCategory(params map[string]interface{}, client *Detecto
 	// Create response with metadata
 	response := map[string]interface{}{
 		"category":        category,
+		"time_range":      timeRange,
 		"detectors_count": len(results),
 		"results":         results,
 	}
@@ -193,42 +187,25 @@ func HandleRunDetectorsByCategory(params map[string]interface{}, client *Detecto
 // Validation Helper Functions
 // =============================================================================
 
-// validateTimeParameters validates start and end time parameters
-func validateTimeParameters(startTime, endTime string) error {
-	// Parse times
-	start, err := time.Parse(time.RFC3339, startTime)
-	if err != nil {
-		return fmt.Errorf("invalid start_time format, expected ISO 8601 (RFC3339): %v", err)
-	}
+// detectorNow returns the server time that detector time ranges resolve against; tests replace it
+var detectorNow = time.Now
 
-	end, err := time.Parse(time.RFC3339, endTime)
+// detectorTimeRange resolves the time range of a detector run against the server time.
+// Detectors cover at most 24 hours within the last 30 days.
+func detectorTimeRange(params map[string]interface{}) (*common.TimeRange, error) {
+	timeRange, err := common.ParseTimeRange(params, detectorNow(), common.TimeRangeOptions{
+		MaxDuration: 24 * time.Hour,
+		MaxAge:      30 * 24 * time.Hour,
+	})
 	if err != nil {
-		return fmt.Errorf("invalid end_time format, expected ISO 8601 (RFC3339): %v", err)
-	}
-
-	now := time.Now()
-	thirtyDaysAgo := now.AddDate(0, 0, -30)
-
-	// Check if times are within last 30 days
-	if start.Before(thirtyDaysAgo) {
-		return fmt.Errorf("start_time must be within the last 30 days")
-	}
-
-	if end.Before(thirtyDaysAgo) {
-		return fmt.Errorf("end_time must be within the last 30 days")
+		return nil, err
 	}
 
 	// Check if end time is after start time
-	if end.Before(start) || end.Equal(start) {
-		return fmt.Errorf("end_time must be after start_time")
+	if !timeRange.End.After(timeRange.Start) {
+		return nil, fmt.Errorf("end_time must be after start_time")
 	}
-
-	// Check if duration is max 24 hours
-	if end.Sub(start) > 24*time.Hour {
-		return fmt.Errorf("time range cannot exceed 24 hours")
-	}
-
-	return nil
+	return timeRange, nil
 }
 
 // validateCategory validates the category parameter
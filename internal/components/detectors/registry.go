@@ -15,6 +15,9 @@ func RegisterListDetectorsTool() mcp.Tool {
 			mcp.Description("AKS cluster resource ID"),
 			mcp.Required(),
 		),
+		mcp.WithString("tenant_id",
+			mcp.Description("Optional Entra tenant ID of the cluster's subscription (inferred from az account list if omitted)"),
+		),
 	)
 }
 
@@ -31,13 +34,17 @@ func RegisterRunDetectorTool() mcp.Tool {
 			mcp.Description("Name of the detector to run"),
 			mcp.Required(),
 		),
+		mcp.WithString("time_range",
+			mcp.Description("Time range to analyze (within last 30 days, max 24h), resolved against server time. Examples: \"last 2h\", PT30M, \"since 2025-07-11T10:55:13Z\", \"around 2025-07-11T10:55:13Z ±15m\""),
+		),
 		mcp.WithString("start_time",
-			mcp.Description("Start time in UTC ISO format (within last 30 days). Example: 2025-07-11T10:55:13Z"),
-			mcp.Required(),
+			mcp.Description("Start time in UTC ISO format (within last 30 days), instead of time_range. Example: 2025-07-11T10:55:13Z"),
 		),
 		mcp.WithString("end_time",
-			mcp.Description("End time in UTC ISO format (within last 30 days, max 24h from start). Example: 2025-07-11T14:55:13Z"),
-			mcp.Required(),
+			mcp.Description("End time in UTC ISO format (within last 30 days, max 24h from start). Defaults to now. Example: 2025-07-11T14:55:13Z"),
+		),
+		mcp.WithString("tenant_id",
+			mcp.Description("Optional Entra tenant ID of the cluster's subscription (inferred from az account list if omitted)"),
 		),
 	)
 }
@@ -55,13 +62,17 @@ func RegisterRunDetectorsByCategoryTool() mcp.Tool {
 			mcp.Description("Detector category to run (Best Practices, Cluster and Control Plane Availability and Performance, Connectivity Issues, Create/Upgrade/Delete and Scale, Deprecations, Identity and Security, Node Health, Storage)"),
 			mcp.Required(),
 		),
+		mcp.WithString("time_range",
+			mcp.Description("Time range to analyze (within last 30 days, max 24h), resolved against server time. Examples: \"last 2h\", PT30M, \"since 2025-07-11T10:55:13Z\", \"around 2025-07-11T10:55:13Z ±15m\""),
+		),
 		mcp.WithString("start_time",
-			mcp.Description("Start time in UTC ISO format (within last 30 days). Example: 2025-07-11T10:55:13Z"),
-			mcp.Required(),
+			mcp.Description("Start time in UTC ISO format (within last 30 days), instead of time_range. Example: 2025-07-11T10:55:13Z"),
 		),
 		mcp.WithString("end_time",
-			mcp.Description("End time in UTC ISO format (within last 30 days, max 24h from start). Example: 2025-07-11T14:55:13Z"),
-			mcp.Required(),
+			mcp.Description("End time in UTC ISO format (within last 30 days, max 24h from start). Defaults to now. Example: 2025-07-11T14:55:13Z"),
+		),
+		mcp.WithString("tenant_id",
+			mcp.Description("Optional Entra tenant ID of the cluster's subscription (inferred from az account list if omitted)"),
 		),
 	)
 }
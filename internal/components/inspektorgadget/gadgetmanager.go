@@ -20,6 +20,7 @@ import (
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/cli-runtime/pkg/genericclioptions"
 	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
 )
 
 const maxResultLen = 64 * 1024 // 64kb
@@ -59,26 +60,39 @@ func init() {
 	environment.Environment = environment.Kubernetes
 }
 
-// NewGadgetManager creates a new instance of GadgetManager
+// NewGadgetManager creates a new instance of GadgetManager for the current kubeconfig context
 func NewGadgetManager() (GadgetManager, error) {
+	return newGadgetManager(KubernetesFlags)
+}
+
+// NewGadgetManagerForKubeconfig creates a GadgetManager for the current context of a kubeconfig file
+func NewGadgetManagerForKubeconfig(kubeconfigPath string) (GadgetManager, error) {
+	flags := genericclioptions.NewConfigFlags(false)
+	flags.KubeConfig = &kubeconfigPath
+	return newGadgetManager(flags)
+}
+
+func newGadgetManager(flags *genericclioptions.ConfigFlags) (GadgetManager, error) {
 	rt := grpcruntime.New(grpcruntime.WithConnectUsingK8SProxy)
 	if err := rt.Init(nil); err != nil {
 		return nil, fmt.Errorf("initializing gadget runtime: %w", err)
 	}
 
-	restConfig, err := KubernetesFlags.ToRESTConfig()
+	restConfig, err := flags.ToRESTConfig()
 	if err != nil {
 		return nil, fmt.Errorf("creating REST config: %w", err)
 	}
 	rt.SetRestConfig(restConfig)
 
 	return &manager{
-		runtime: rt,
+		runtime:    rt,
+		restConfig: restConfig,
 	}, nil
 }
 
 type manager struct {
-	runtime runtime.Runtime
+	runtime    runtime.Runtime
+	restConfig *rest.Config
 }
 
 // RunGadget runs a gadget with the specified image and parameters for a given duration
@@ -236,12 +250,7 @@ func (g *manager) ListGadgets(ctx context.Context) ([]*GadgetInstance, error) {
 
 // IsDeployed checks if the Inspektor Gadget is deployed in the Kubernetes
 func (g *manager) IsDeployed(ctx context.Context) (bool, string, error) {
-	restConfig, err := KubernetesFlags.ToRESTConfig()
-	if err != nil {
-		return false, "", fmt.Errorf("creating RESTConfig: %w", err)
-	}
-
-	client, err := kubernetes.NewForConfig(restConfig)
+	client, err := kubernetes.NewForConfig(g.restConfig)
 	if err != nil {
 		return false, "", fmt.Errorf("setting up trace client: %w", err)
 	}
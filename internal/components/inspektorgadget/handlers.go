@@ -4,11 +4,14 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"log"
 	"slices"
+	"sync"
 	"time"
 
 	"github.com/Azure/aks-mcp/internal/command"
 	"github.com/Azure/aks-mcp/internal/config"
+	"github.com/Azure/aks-mcp/internal/kubeconfig"
 	"github.com/Azure/aks-mcp/internal/tools"
 )
 
@@ -18,8 +21,69 @@ import (
 
 var ErrNotDeployed = fmt.Errorf("inspektor gadget is not deployed, please deploy it first e.g. using 'inspektor_gadget' (action: deploy) tool (requires 'readwrite' or 'admin' access level)")
 
-// InspektorGadgetHandler returns a handler to manage gadgets
+// ManagerResolver returns the gadget manager and kubeconfig file for the cluster a tool call targets.
+// The kubeconfig path is empty for the current kubeconfig context.
+type ManagerResolver func(params map[string]interface{}) (GadgetManager, string, error)
+
+// newKubeconfigManager creates the gadget manager for a fetched kubeconfig file, replaceable in tests
+var newKubeconfigManager = NewGadgetManagerForKubeconfig
+
+// kubeconfigManager is a gadget manager created for a kubeconfig file, with the fetch time of the
+// credentials it loaded
+type kubeconfigManager struct {
+	manager GadgetManager
+	fetched time.Time
+}
+
+// NewManagerResolver returns a resolver that uses mgr for the current kubeconfig context and, for calls
+// that target a cluster, a manager created for the kubeconfig file the store fetched for that cluster.
+// Managers keep the credentials they loaded, so one is created again when the store refreshes the file.
+// mgr may be nil when the current context is unusable, in which case calls must target a cluster.
+func NewManagerResolver(mgr GadgetManager, store *kubeconfig.Store, cfg *config.ConfigData) ManagerResolver {
+	var mu sync.Mutex
+	managers := make(map[string]kubeconfigManager)
+
+	return func(params map[string]interface{}) (GadgetManager, string, error) {
+		kubeconfigPath, fetched, err := store.ResolveFetched(params, cfg.Timeout)
+		if err != nil {
+			return nil, "", err
+		}
+		if kubeconfigPath == "" {
+			if mgr == nil {
+				return nil, "", fmt.Errorf("inspektor gadget is not available for the current kubeconfig context, set subscription_id, resource_group and cluster_name to target a cluster")
+			}
+			return mgr, "", nil
+		}
+
+		mu.Lock()
+		defer mu.Unlock()
+		cached, ok := managers[kubeconfigPath]
+		if ok && cached.fetched.Equal(fetched) {
+			return cached.manager, kubeconfigPath, nil
+		}
+		m, err := newKubeconfigManager(kubeconfigPath)
+		if err != nil {
+			return nil, "", err
+		}
+		if ok {
+			if err := cached.manager.Close(); err != nil {
+				log.Printf("Failed to close gadget manager for refreshed kubeconfig %s: %v", kubeconfigPath, err)
+			}
+		}
+		managers[kubeconfigPath] = kubeconfigManager{manager: m, fetched: fetched}
+		return m, kubeconfigPath, nil
+	}
+}
+
+// InspektorGadgetHandler returns a handler to manage gadgets in the current kubeconfig context
 func InspektorGadgetHandler(mgr GadgetManager, cfg *config.ConfigData) tools.ResourceHandler {
+	return InspektorGadgetHandlerWithResolver(func(map[string]interface{}) (GadgetManager, string, error) {
+		return mgr, "", nil
+	}, cfg)
+}
+
+// InspektorGadgetHandlerWithResolver returns a handler to manage gadgets in the cluster each call targets
+func InspektorGadgetHandlerWithResolver(resolve ManagerResolver, cfg *config.ConfigData) tools.ResourceHandler {
 	return tools.ResourceHandlerFunc(func(params map[string]interface{}, _ *config.ConfigData) (string, error) {
 		ctx := context.Background()
 
@@ -33,6 +97,11 @@ func InspektorGadgetHandler(mgr GadgetManager, cfg *config.ConfigData) tools.Res
 			return "", fmt.Errorf("invalid action: %s, expected one of %v", action, validActions)
 		}
 
+		mgr, kubeconfigPath, err := resolve(params)
+		if err != nil {
+			return "", err
+		}
+
 		// Check if Inspektor Gadget is deployed
 		deployed, _, err := mgr.IsDeployed(ctx)
 		if err != nil {
@@ -71,7 +140,7 @@ func InspektorGadgetHandler(mgr GadgetManager, cfg *config.ConfigData) tools.Res
 		case listGadgetsAction:
 			return handleListGadgetsAction(ctx, mgr, cfg)
 		case isDeployedAction, undeployAction, deployAction:
-			return handleLifecycleAction(deployed, action, actionParams, cfg)
+			return handleLifecycleAction(deployed, action, actionParams, kubeconfigPath, cfg)
 		}
 
 		return "", fmt.Errorf("unsupported action: %s", action)
@@ -213,7 +282,7 @@ func handleListGadgetsAction(ctx context.Context, mgr GadgetManager, cfg *config
 	return string(JSONData), nil
 }
 
-func handleLifecycleAction(deployed bool, action string, actionParams map[string]interface{}, cfg *config.ConfigData) (string, error) {
+func handleLifecycleAction(deployed bool, action string, actionParams map[string]interface{}, kubeconfigPath string, cfg *config.ConfigData) (string, error) {
 	// TODO: use security.Validator once helm readwrite/admin operations are implemented
 	if !cfg.SecurityConfig.IsNamespaceAllowed(inspektorGadgetChartNamespace) {
 		return "", fmt.Errorf("namespace %s is not allowed by security policy", inspektorGadgetChartNamespace)
@@ -232,30 +301,37 @@ func handleLifecycleAction(deployed bool, action string, actionParams map[string
 		if !deployed {
 			return "inspektor gadget is not deployed", nil
 		}
-		return handleUndeployAction(cfg)
+		return handleUndeployAction(kubeconfigPath, cfg)
 	case deployAction:
 		if deployed {
 			return "inspektor gadget is already deployed", nil
 		}
-		return handleDeployAction(actionParams, cfg)
+		return handleDeployAction(actionParams, kubeconfigPath, cfg)
 	}
 
 	return "", fmt.Errorf("unsupported lifecycle action %q, must be one of %v", action, getLifecycleActions())
 }
 
-func handleDeployAction(actionParams map[string]interface{}, cfg *config.ConfigData) (string, error) {
+func handleDeployAction(actionParams map[string]interface{}, kubeconfigPath string, cfg *config.ConfigData) (string, error) {
 	chartVersion, ok := actionParams["chart_version"].(string)
 	if !ok || chartVersion == "" {
 		chartVersion = getChartVersion()
 	}
 	chartUrl := fmt.Sprintf("%s:%s", inspektorGadgetChartURL, chartVersion)
 	helmArgs := fmt.Sprintf("install %s -n %s --create-namespace %s", inspektorGadgetChartRelease, inspektorGadgetChartNamespace, chartUrl)
-	process := command.NewShellProcess("helm", cfg.Timeout)
-	return process.Run(helmArgs)
+	return runHelm(helmArgs, kubeconfigPath, cfg)
 }
 
-func handleUndeployAction(cfg *config.ConfigData) (string, error) {
+func handleUndeployAction(kubeconfigPath string, cfg *config.ConfigData) (string, error) {
 	helmArgs := fmt.Sprintf("uninstall %s -n %s", inspektorGadgetChartRelease, inspektorGadgetChartNamespace)
+	return runHelm(helmArgs, kubeconfigPath, cfg)
+}
+
+// runHelm runs helm against the given kubeconfig file, or the current context when it is empty
+func runHelm(helmArgs, kubeconfigPath string, cfg *config.ConfigData) (string, error) {
+	if kubeconfigPath != "" {
+		helmArgs = kubeconfig.WithFlag(helmArgs, "helm", kubeconfigPath)
+	}
 	process := command.NewShellProcess("helm", cfg.Timeout)
 	return process.Run(helmArgs)
 }
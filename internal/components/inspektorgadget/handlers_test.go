@@ -4,10 +4,13 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"os"
 	"testing"
 	"time"
 
+	"github.com/Azure/aks-mcp/internal/command"
 	"github.com/Azure/aks-mcp/internal/config"
+	"github.com/Azure/aks-mcp/internal/kubeconfig"
 )
 
 // mockGadgetManager implements GadgetManager interface for testing
@@ -212,3 +215,76 @@ func TestInspektorGadgetHandler(t *testing.T) {
 		}
 	})
 }
+
+// credentialsRunner serves az aks get-credentials by writing an empty kubeconfig
+type credentialsRunner struct{}
+
+func (credentialsRunner) Run(_ context.Context, argv []string) (*command.Result, error) {
+	for i, arg := range argv {
+		if arg == "--file" {
+			if err := os.WriteFile(argv[i+1], []byte("apiVersion: v1\nkind: Config\n"), 0o600); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return &command.Result{}, nil
+}
+
+func (credentialsRunner) LookPath(file string) (string, error) {
+	return file, nil
+}
+
+// closeCountingManager counts how often it was closed
+type closeCountingManager struct {
+	mockGadgetManager
+	closed int
+}
+
+func (m *closeCountingManager) Close() error {
+	m.closed++
+	return nil
+}
+
+func TestNewManagerResolver_RefreshedCredentials(t *testing.T) {
+	defer command.SetDefaultRunner(credentialsRunner{})()
+
+	var created []*closeCountingManager
+	original := newKubeconfigManager
+	newKubeconfigManager = func(string) (GadgetManager, error) {
+		m := &closeCountingManager{}
+		created = append(created, m)
+		return m, nil
+	}
+	defer func() { newKubeconfigManager = original }()
+
+	resolve := NewManagerResolver(nil, kubeconfig.NewStore(t.TempDir(), ""), &config.ConfigData{Timeout: 60})
+	params := map[string]interface{}{
+		"subscription_id": "00000000-0000-0000-0000-000000000000",
+		"resource_group":  "rg",
+		"cluster_name":    "aks",
+	}
+
+	first, path, err := resolve(params)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if again, _, err := resolve(params); err != nil || again != first {
+		t.Errorf("Expected the manager to be reused while credentials are unchanged, got %v", err)
+	}
+
+	// A missing kubeconfig file makes the store fetch credentials again
+	time.Sleep(time.Millisecond)
+	if err := os.Remove(path); err != nil {
+		t.Fatalf("Failed to remove kubeconfig: %v", err)
+	}
+	refreshed, _, err := resolve(params)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if refreshed == first || len(created) != 2 {
+		t.Errorf("Expected a new manager for refreshed credentials, created %d", len(created))
+	}
+	if created[0].closed != 1 {
+		t.Errorf("Expected the replaced manager to be closed once, got %d", created[0].closed)
+	}
+}
@@ -0,0 +1,452 @@
+package monitor
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/Azure/aks-mcp/internal/azureclient"
+	"github.com/Azure/aks-mcp/internal/components/common"
+	"github.com/Azure/aks-mcp/internal/components/network/resourcehelpers"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/arm"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/containerservice/armcontainerservice/v2"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/monitor/armmonitor"
+)
+
+const (
+	// defaultActivityLogWindow is the time range queried when no time parameter is set
+	defaultActivityLogWindow = 24 * time.Hour
+	// maxActivityLogAge is how far back the activity log is retained
+	maxActivityLogAge = 90 * 24 * time.Hour
+	// defaultActivityLogMaxChanges is the number of changes returned when max_changes is not set
+	defaultActivityLogMaxChanges = 50
+	// maxActivityLogMaxChanges is the most changes max_changes may ask for
+	maxActivityLogMaxChanges = 500
+	// maxActivityLogEvents is the most events read per resource group query
+	maxActivityLogEvents = 2000
+	// activityLogCategory is the activity log category of control plane writes, deletes and actions
+	activityLogCategory = "Administrative"
+)
+
+// Activity log scopes, in the order events are classified
+const (
+	activityScopeCluster           = "cluster"
+	activityScopeNetwork           = "network"
+	activityScopeNodeResourceGroup = "node_resource_group"
+)
+
+// activityLogScopes are the resource scopes the activity_log operation can query
+var activityLogScopes = []string{activityScopeCluster, activityScopeNodeResourceGroup, activityScopeNetwork}
+
+// activityLogQuery is a typed activity log query with client-side filters
+type activityLogQuery struct {
+	TimeRange  *common.TimeRange
+	Scopes     []string
+	Operation  string // case-insensitive substring of the operation name
+	Caller     string // case-insensitive substring of the caller
+	Status     string // final status of the change, case-insensitive
+	MaxChanges int
+}
+
+// ActivityEvent is one activity log event of a change
+type ActivityEvent struct {
+	Time      string `json:"time"`
+	Operation string `json:"operation"`
+	Status    string `json:"status,omitempty"`
+	SubStatus string `json:"sub_status,omitempty"`
+	Resource  string `json:"resource"`
+}
+
+// ActivityChange is a control plane change: the activity log events sharing a correlation ID
+type ActivityChange struct {
+	CorrelationID    string          `json:"correlation_id"`
+	Operation        string          `json:"operation"`
+	OperationDisplay string          `json:"operation_display,omitempty"`
+	Caller           string          `json:"caller,omitempty"`
+	Status           string          `json:"status"`
+	Scopes           []string        `json:"scopes"`
+	Resources        []string        `json:"resources"`
+	Start            string          `json:"start"`
+	End              string          `json:"end"`
+	Events           []ActivityEvent `json:"events"`
+}
+
+// ActivityLogScope describes the resources a scope covers, or why it could not be queried
+type ActivityLogScope struct {
+	Name          string   `json:"name"`
+	ResourceGroup string   `json:"resource_group,omitempty"`
+	Resources     []string `json:"resources,omitempty"`
+	Message       string   `json:"message,omitempty"`
+}
+
+// ActivityLogResult is the response of the activity_log operation
+type ActivityLogResult struct {
+	Cluster      string             `json:"cluster"`
+	TimeRange    *common.TimeRange  `json:"time_range"`
+	Scopes       []ActivityLogScope `json:"scopes"`
+	TotalEvents  int                `json:"total_events"`
+	TotalChanges int                `json:"total_changes"`
+	Truncated    bool               `json:"truncated,omitempty"`
+	Changes      []ActivityChange   `json:"changes"`
+}
+
+// activityTargets are the resources discovered from the cluster that events are classified against
+type activityTargets struct {
+	ClusterID         string
+	NodeResourceGroup string // resource ID prefix of the node resource group
+	NetworkIDs        []string
+}
+
+// scope returns the scope of an event's resource, or "" when it belongs to none of the targets
+func (t activityTargets) scope(resourceID string) string {
+	switch {
+	case isResourceOrChild(resourceID, t.ClusterID):
+		return activityScopeCluster
+	case slices.ContainsFunc(t.NetworkIDs, func(id string) bool { return isResourceOrChild(resourceID, id) }):
+		return activityScopeNetwork
+	case t.NodeResourceGroup != "" && isResourceOrChild(resourceID, t.NodeResourceGroup):
+		return activityScopeNodeResourceGroup
+	}
+	return ""
+}
+
+// isResourceOrChild reports whether resourceID is parentID or one of its child resources, ignoring case
+func isResourceOrChild(resourceID, parentID string) bool {
+	resourceID, parentID = strings.ToLower(resourceID), strings.ToLower(strings.TrimSuffix(parentID, "/"))
+	return parentID != "" && (resourceID == parentID || strings.HasPrefix(resourceID, parentID+"/"))
+}
+
+// activityLogGroup is a resource group whose activity log is queried
+type activityLogGroup struct {
+	SubscriptionID string
+	ResourceGroup  string
+}
+
+// handleActivityLogOperation lists the administrative activity log events of the cluster, its node resource group
+// and its network resources, and groups them into changes by correlation ID
+func handleActivityLogOperation(params map[string]interface{}, azClient *azureclient.AzureClient) (string, error) {
+	if azClient == nil {
+		return "", fmt.Errorf("azure client is required but not provided")
+	}
+
+	subscriptionID, resourceGroup, clusterName, err := common.ExtractAKSParameters(params)
+	if err != nil {
+		return "", err
+	}
+
+	query, err := parseActivityLogQuery(params)
+	if err != nil {
+		return "", err
+	}
+
+	ctx, err := common.TenantContext(context.Background(), azClient, params)
+	if err != nil {
+		return "", err
+	}
+	cluster, err := azClient.GetAKSCluster(ctx, subscriptionID, resourceGroup, clusterName)
+	if err != nil {
+		return "", fmt.Errorf("failed to get AKS cluster: %w", err)
+	}
+
+	targets, scopes, groups := discoverActivityLogScopes(ctx, azClient, cluster, query.Scopes)
+	result := ActivityLogResult{
+		Cluster:   stringValue(cluster.ID),
+		TimeRange: query.TimeRange,
+		Scopes:    scopes,
+		Changes:   []ActivityChange{},
+	}
+
+	// A resource group that cannot be queried is reported on its scopes unless every query fails
+	var events []*armmonitor.EventData
+	var queryErr error
+	succeeded := 0
+	for _, group := range groups {
+		groupEvents, err := azClient.ListActivityLogs(ctx, group.SubscriptionID, activityLogFilter(query.TimeRange, group.ResourceGroup), maxActivityLogEvents)
+		if err != nil {
+			queryErr = err
+			for i := range result.Scopes {
+				if strings.EqualFold(result.Scopes[i].ResourceGroup, group.ResourceGroup) {
+					result.Scopes[i].Message = err.Error()
+				}
+			}
+			continue
+		}
+		succeeded++
+		if len(groupEvents) >= maxActivityLogEvents {
+			result.Truncated = true
+		}
+		events = append(events, groupEvents...)
+	}
+	if queryErr != nil && succeeded == 0 {
+		return "", queryErr
+	}
+
+	changes, total := groupActivityChanges(events, targets, query)
+	result.TotalEvents = total
+	result.TotalChanges = len(changes)
+	if len(changes) > query.MaxChanges {
+		// Keep the most recent changes
+		changes = changes[len(changes)-query.MaxChanges:]
+		result.Truncated = true
+	}
+	result.Changes = append(result.Changes, changes...)
+
+	resultJSON, err := json.MarshalIndent(result, "", "  ")
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal activity log result to JSON: %w", err)
+	}
+	return string(resultJSON), nil
+}
+
+// parseActivityLogQuery builds an activity log query from the merged operation parameters
+func parseActivityLogQuery(params map[string]interface{}) (*activityLogQuery, error) {
+	timeRange, err := common.ParseTimeRange(params, monitorNow(), common.TimeRangeOptions{
+		Default: defaultActivityLogWindow,
+		MaxAge:  maxActivityLogAge,
+	})
+	if err != nil {
+		return nil, err
+	}
+	query := &activityLogQuery{TimeRange: timeRange}
+
+	for _, scope := range stringList(params["scope"]) {
+		scope = strings.ToLower(scope)
+		if !slices.Contains(activityLogScopes, scope) {
+			return nil, fmt.Errorf("invalid scope: %s. Supported scopes: %s", scope, strings.Join(activityLogScopes, ", "))
+		}
+		if !slices.Contains(query.Scopes, scope) {
+			query.Scopes = append(query.Scopes, scope)
+		}
+	}
+	if len(query.Scopes) == 0 {
+		query.Scopes = activityLogScopes
+	}
+
+	query.Operation, _ = params["operation_name"].(string)
+	query.Caller, _ = params["caller"].(string)
+	query.Status, _ = params["status"].(string)
+	query.Operation, query.Caller, query.Status = strings.TrimSpace(query.Operation), strings.TrimSpace(query.Caller), strings.TrimSpace(query.Status)
+
+	query.MaxChanges, err = positiveInt(params, "max_changes", defaultActivityLogMaxChanges, maxActivityLogMaxChanges)
+	if err != nil {
+		return nil, err
+	}
+
+	return query, nil
+}
+
+// discoverActivityLogScopes resolves the requested scopes to the resources events are classified against
+// and the resource groups whose activity log is queried. Network resources are discovered from the cluster's
+// subnet; a scope that cannot be resolved is reported with a message instead of failing the query.
+func discoverActivityLogScopes(ctx context.Context, azClient *azureclient.AzureClient, cluster *armcontainerservice.ManagedCluster, requested []string) (activityTargets, []ActivityLogScope, []activityLogGroup) {
+	var targets activityTargets
+	var scopes []ActivityLogScope
+	var groups []activityLogGroup
+	addGroup := func(subscriptionID, resourceGroup string) {
+		if !slices.ContainsFunc(groups, func(g activityLogGroup) bool {
+			return strings.EqualFold(g.SubscriptionID, subscriptionID) && strings.EqualFold(g.ResourceGroup, resourceGroup)
+		}) {
+			groups = append(groups, activityLogGroup{SubscriptionID: subscriptionID, ResourceGroup: resourceGroup})
+		}
+	}
+
+	clusterID := stringValue(cluster.ID)
+	parsedClusterID, err := arm.ParseResourceID(clusterID)
+	if err != nil {
+		return targets, []ActivityLogScope{{Name: activityScopeCluster, Message: fmt.Sprintf("invalid cluster resource ID: %v", err)}}, nil
+	}
+	for _, name := range activityLogScopes {
+		if !slices.Contains(requested, name) {
+			continue
+		}
+		scope := ActivityLogScope{Name: name}
+
+		switch name {
+		case activityScopeCluster:
+			targets.ClusterID = clusterID
+			scope.ResourceGroup = parsedClusterID.ResourceGroupName
+			scope.Resources = []string{clusterID}
+			addGroup(parsedClusterID.SubscriptionID, scope.ResourceGroup)
+
+		case activityScopeNodeResourceGroup:
+			if cluster.Properties == nil || stringValue(cluster.Properties.NodeResourceGroup) == "" {
+				scope.Message = "the cluster has no node resource group"
+				break
+			}
+			scope.ResourceGroup = *cluster.Properties.NodeResourceGroup
+			targets.NodeResourceGroup = fmt.Sprintf("/subscriptions/%s/resourceGroups/%s", parsedClusterID.SubscriptionID, scope.ResourceGroup)
+			addGroup(parsedClusterID.SubscriptionID, scope.ResourceGroup)
+
+		case activityScopeNetwork:
+			var errs []string
+			for _, discover := range []func(context.Context, *armcontainerservice.ManagedCluster, *azureclient.AzureClient) (string, error){
+				resourcehelpers.GetVNetIDFromAKS,
+				resourcehelpers.GetNSGIDFromAKS,
+				resourcehelpers.GetRouteTableIDFromAKS,
+			} {
+				id, err := discover(ctx, cluster, azClient)
+				if err != nil {
+					errs = append(errs, err.Error())
+					continue
+				}
+				parsed, err := arm.ParseResourceID(id)
+				if err != nil {
+					errs = append(errs, fmt.Sprintf("invalid network resource ID %s: %v", id, err))
+					continue
+				}
+				targets.NetworkIDs = append(targets.NetworkIDs, id)
+				scope.Resources = append(scope.Resources, id)
+				addGroup(parsed.SubscriptionID, parsed.ResourceGroupName)
+				// Bring-your-own network resources may span resource groups
+				if len(scope.Resources) == 1 {
+					scope.ResourceGroup = parsed.ResourceGroupName
+				} else if !strings.EqualFold(scope.ResourceGroup, parsed.ResourceGroupName) {
+					scope.ResourceGroup = ""
+				}
+			}
+			if len(targets.NetworkIDs) == 0 {
+				scope.Message = "no network resources discovered: " + strings.Join(errs, "; ")
+			}
+		}
+
+		scopes = append(scopes, scope)
+	}
+
+	return targets, scopes, groups
+}
+
+// activityLogFilter returns the activity log OData filter for the events of a resource group in a time range
+func activityLogFilter(timeRange *common.TimeRange, resourceGroup string) string {
+	return fmt.Sprintf("eventTimestamp ge '%s' and eventTimestamp le '%s' and resourceGroupName eq '%s'",
+		timeRange.Start.Format(time.RFC3339), timeRange.End.Format(time.RFC3339), strings.ReplaceAll(resourceGroup, "'", "''"))
+}
+
+// groupActivityChanges deduplicates administrative events in the requested scopes and groups them by correlation ID.
+// It returns the changes that match the query filters in chronological order and the number of distinct events in scope.
+func groupActivityChanges(events []*armmonitor.EventData, targets activityTargets, query *activityLogQuery) ([]ActivityChange, int) {
+	seen := make(map[string]bool)
+	byCorrelation := make(map[string]*ActivityChange)
+	var changes []*ActivityChange
+	first := make(map[string]time.Time)
+	last := make(map[string]time.Time)
+	total := 0
+
+	for _, event := range events {
+		if event == nil || event.EventTimestamp == nil || !strings.EqualFold(localizedValue(event.Category, false), activityLogCategory) {
+			continue
+		}
+		resourceID := stringValue(event.ResourceID)
+		scope := targets.scope(resourceID)
+		if scope == "" || !slices.Contains(query.Scopes, scope) {
+			continue
+		}
+
+		key := stringValue(event.EventDataID)
+		if key == "" {
+			key = stringValue(event.ID)
+		}
+		if key != "" {
+			if seen[key] {
+				continue
+			}
+			seen[key] = true
+		}
+		total++
+
+		correlationID := stringValue(event.CorrelationID)
+		if correlationID == "" {
+			correlationID = key
+		}
+		change, ok := byCorrelation[correlationID]
+		if !ok {
+			change = &ActivityChange{CorrelationID: correlationID}
+			byCorrelation[correlationID] = change
+			changes = append(changes, change)
+		}
+
+		at := event.EventTimestamp.UTC()
+		status := localizedValue(event.Status, false)
+		change.Events = append(change.Events, ActivityEvent{
+			Time:      at.Format(time.RFC3339),
+			Operation: localizedValue(event.OperationName, false),
+			Status:    status,
+			SubStatus: localizedValue(event.SubStatus, true),
+			Resource:  resourceID,
+		})
+		if !slices.Contains(change.Scopes, scope) {
+			change.Scopes = append(change.Scopes, scope)
+		}
+		if !slices.ContainsFunc(change.Resources, func(r string) bool { return strings.EqualFold(r, resourceID) }) {
+			change.Resources = append(change.Resources, resourceID)
+		}
+		if change.Caller == "" {
+			change.Caller = stringValue(event.Caller)
+		}
+		if start, ok := first[correlationID]; !ok || at.Before(start) {
+			change.Operation = localizedValue(event.OperationName, false)
+			change.OperationDisplay = localizedValue(event.OperationName, true)
+			first[correlationID] = at
+		}
+		if status != "" && !at.Before(last[correlationID]) {
+			change.Status = status
+			last[correlationID] = at
+		}
+	}
+
+	var matched []ActivityChange
+	for _, change := range changes {
+		sort.SliceStable(change.Events, func(i, j int) bool { return change.Events[i].Time < change.Events[j].Time })
+		change.Start, change.End = change.Events[0].Time, change.Events[len(change.Events)-1].Time
+		if change.OperationDisplay == change.Operation {
+			change.OperationDisplay = ""
+		}
+
+		if query.matches(change) {
+			matched = append(matched, *change)
+		}
+	}
+
+	sort.SliceStable(matched, func(i, j int) bool { return matched[i].Start < matched[j].Start })
+	return matched, total
+}
+
+// matches reports whether a change passes the operation, caller and status filters of the query
+func (q *activityLogQuery) matches(change *ActivityChange) bool {
+	if q.Operation != "" && !slices.ContainsFunc(change.Events, func(e ActivityEvent) bool {
+		return containsFold(e.Operation, q.Operation)
+	}) && !containsFold(change.OperationDisplay, q.Operation) {
+		return false
+	}
+	if q.Caller != "" && !containsFold(change.Caller, q.Caller) {
+		return false
+	}
+	return q.Status == "" || strings.EqualFold(change.Status, q.Status)
+}
+
+// containsFold reports whether substr is within s, ignoring case
+func containsFold(s, substr string) bool {
+	return strings.Contains(strings.ToLower(s), strings.ToLower(substr))
+}
+
+// localizedValue returns the value of a localizable string, or its localized value when preferred and set
+func localizedValue(value *armmonitor.LocalizableString, localized bool) string {
+	if value == nil {
+		return ""
+	}
+	if localized && value.LocalizedValue != nil && *value.LocalizedValue != "" {
+		return *value.LocalizedValue
+	}
+	return stringValue(value.Value)
+}
+
+// stringValue dereferences an optional string
+func stringValue(value *string) string {
+	if value == nil {
+		return ""
+	}
+	return *value
+}
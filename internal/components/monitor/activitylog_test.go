@@ -0,0 +1,157 @@
+package monitor
+
+import (
+	"encoding/json"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Azure/aks-mcp/internal/azureclient/fakearm"
+	"github.com/Azure/aks-mcp/internal/azureclient/fakearm/armtest"
+	"github.com/Azure/aks-mcp/internal/components/common"
+)
+
+const testActivityLogPath = "/subscriptions/" + fakearm.SubscriptionID + "/providers/Microsoft.Insights/eventtypes/management/values"
+
+// runActivityLog runs the activity_log operation against the activity log fixture, which the fake server
+// returns for every resource group query
+func runActivityLog(t *testing.T, parameters string) (*fakearm.Server, ActivityLogResult) {
+	t.Helper()
+
+	defer func(original func() time.Time) { monitorNow = original }(monitorNow)
+	monitorNow = func() time.Time { return time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC) }
+
+	srv, client, cfg := armtest.NewClient(t, filepath.Join("testdata", "activity_log.json"))
+
+	output, err := GetAzMonitoringHandler(client, cfg).Handle(map[string]interface{}{
+		"operation":       "activity_log",
+		"subscription_id": fakearm.SubscriptionID,
+		"resource_group":  fakearm.ResourceGroup,
+		"cluster_name":    fakearm.ClusterName,
+		"parameters":      parameters,
+	}, cfg)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	var result ActivityLogResult
+	if err := json.Unmarshal([]byte(output), &result); err != nil {
+		t.Fatalf("Failed to parse result: %v\n%s", err, output)
+	}
+	return srv, result
+}
+
+func TestGetAzMonitoringHandler_ActivityLog(t *testing.T) {
+	srv, result := runActivityLog(t, `{}`)
+
+	if result.Cluster != fakearm.ClusterResourceID || result.TimeRange.Duration != "24h0m0s" {
+		t.Errorf("Unexpected query details: %+v", result)
+	}
+	// The network resources are in the node resource group, so two resource groups are queried
+	if count := srv.RequestCount(testActivityLogPath); count != 2 {
+		t.Errorf("Expected one query per resource group, got %d", count)
+	}
+	if len(result.Scopes) != 3 || result.Scopes[2].Name != "network" || len(result.Scopes[2].Resources) != 2 {
+		t.Errorf("Expected the VNet and NSG in the network scope, got %+v", result.Scopes)
+	}
+
+	// Duplicate events, other resources in the cluster's resource group and resource health events are left out
+	if result.TotalEvents != 7 || result.TotalChanges != 3 || result.Truncated {
+		t.Fatalf("Expected 7 events in 3 changes, got %d events in %d changes", result.TotalEvents, result.TotalChanges)
+	}
+
+	upgrade := result.Changes[0]
+	if upgrade.CorrelationID != "corr-upgrade" || upgrade.Operation != "Microsoft.ContainerService/managedClusters/write" || upgrade.OperationDisplay != "Create or Update Managed Cluster" {
+		t.Errorf("Unexpected change: %+v", upgrade)
+	}
+	if upgrade.Status != "Succeeded" || upgrade.Caller != "alice@contoso.com" || upgrade.Start != "2026-03-01T10:00:00Z" || upgrade.End != "2026-03-01T10:20:00Z" {
+		t.Errorf("Unexpected change: %+v", upgrade)
+	}
+	if len(upgrade.Events) != 4 || len(upgrade.Resources) != 2 || upgrade.Events[2].SubStatus != "Created" {
+		t.Errorf("Expected 4 events on the cluster and its agent pool, got %+v", upgrade.Events)
+	}
+
+	if scopes := []string{result.Changes[1].Scopes[0], result.Changes[2].Scopes[0]}; scopes[0] != "node_resource_group" || scopes[1] != "network" {
+		t.Errorf("Expected the scale set change then the security rule change, got %v", scopes)
+	}
+}
+
+func TestGetAzMonitoringHandler_ActivityLogFilters(t *testing.T) {
+	tests := []struct {
+		name        string
+		parameters  string
+		correlation []string
+	}{
+		{"failed changes", `{"status": "failed"}`, []string{"corr-nsg"}},
+		{"caller in the cluster scope", `{"caller": "ALICE", "scope": "cluster"}`, []string{"corr-upgrade"}},
+		{"operation of a child event", `{"operation_name": "agentPools/write"}`, []string{"corr-upgrade"}},
+		{"operation display name", `{"operation_name": "scale set"}`, []string{"corr-vmss"}},
+		{"scopes", `{"scope": ["node_resource_group", "network"]}`, []string{"corr-vmss", "corr-nsg"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, result := runActivityLog(t, tt.parameters)
+			var got []string
+			for _, change := range result.Changes {
+				got = append(got, change.CorrelationID)
+			}
+			if strings.Join(got, ",") != strings.Join(tt.correlation, ",") {
+				t.Errorf("Expected changes %v, got %v", tt.correlation, got)
+			}
+		})
+	}
+}
+
+func TestGetAzMonitoringHandler_ActivityLogMaxChanges(t *testing.T) {
+	_, result := runActivityLog(t, `{"max_changes": 1}`)
+
+	if result.TotalChanges != 3 || !result.Truncated || len(result.Changes) != 1 || result.Changes[0].CorrelationID != "corr-nsg" {
+		t.Errorf("Expected only the most recent change, got %+v", result)
+	}
+}
+
+func TestParseActivityLogQuery(t *testing.T) {
+	defer func(original func() time.Time) { monitorNow = original }(monitorNow)
+	monitorNow = func() time.Time { return time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC) }
+
+	query, err := parseActivityLogQuery(map[string]interface{}{"scope": "Network, cluster,network"})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if strings.Join(query.Scopes, ",") != "network,cluster" || query.MaxChanges != defaultActivityLogMaxChanges {
+		t.Errorf("Unexpected query: %+v", query)
+	}
+
+	errorCases := []struct {
+		name   string
+		params map[string]interface{}
+		errMsg string
+	}{
+		{"bad scope", map[string]interface{}{"scope": "subscription"}, "invalid scope: subscription"},
+		{"too old", map[string]interface{}{"time_range": "since 2025-11-01T00:00:00Z"}, "within the last 90 days"},
+		{"bad max_changes", map[string]interface{}{"max_changes": "none"}, "max_changes must be"},
+		{"max_changes too large", map[string]interface{}{"max_changes": 1e300}, "max_changes cannot exceed 500"},
+	}
+	for _, tc := range errorCases {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := parseActivityLogQuery(tc.params)
+			if err == nil || !strings.Contains(err.Error(), tc.errMsg) {
+				t.Errorf("Expected error containing %q, got %v", tc.errMsg, err)
+			}
+		})
+	}
+}
+
+func TestActivityLogFilter(t *testing.T) {
+	timeRange, err := common.ParseTimeRange(map[string]interface{}{"time_range": "2026-03-01T10:00:00Z/2026-03-01T11:00:00Z"}, time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC), common.TimeRangeOptions{})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	want := "eventTimestamp ge '2026-03-01T10:00:00Z' and eventTimestamp le '2026-03-01T11:00:00Z' and resourceGroupName eq 'o''brien-rg'"
+	if got := activityLogFilter(timeRange, "o'brien-rg"); got != want {
+		t.Errorf("Expected %s, got %s", want, got)
+	}
+}
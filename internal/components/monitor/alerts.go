@@ -0,0 +1,807 @@
+package monitor
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Azure/aks-mcp/internal/azureclient"
+	"github.com/Azure/aks-mcp/internal/components/common"
+	"github.com/Azure/aks-mcp/internal/components/monitor/diagnostics"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/monitor/armmonitor"
+)
+
+const (
+	// prometheusRuleGroupsAPIVersion is the Alerts Management API version of Prometheus rule groups
+	prometheusRuleGroupsAPIVersion = "2023-03-01"
+	// alertsManagementAPIVersion is the Alerts Management API version of fired alerts
+	alertsManagementAPIVersion = "2019-05-05-preview"
+	// defaultAlertsWindow is the time range queried when no time parameter is set
+	defaultAlertsWindow = 24 * time.Hour
+	// maxAlertsAge is how long Alerts Management keeps alerts
+	maxAlertsAge = 30 * 24 * time.Hour
+	// defaultMaxAlerts is the number of alerts returned when max_alerts is not set
+	defaultMaxAlerts = 50
+	// maxMaxAlerts is the most alerts max_alerts may ask for
+	maxMaxAlerts = 500
+	// maxAlertsRead is the most alerts read per target resource, or Prometheus rule groups read per subscription
+	maxAlertsRead = 2000
+	// managedClusterType is the resource type of AKS clusters
+	managedClusterType = "Microsoft.ContainerService/managedClusters"
+)
+
+// Alert rule types
+const (
+	alertRuleTypeMetric     = "metric"
+	alertRuleTypeLogSearch  = "log_search"
+	alertRuleTypePrometheus = "prometheus"
+)
+
+// alertRuleTypes are the alert rule types the alert_rules and alerts operations read, in the order rules are listed
+var alertRuleTypes = []string{alertRuleTypeMetric, alertRuleTypeLogSearch, alertRuleTypePrometheus}
+
+// How the scopes of an alert rule relate to the cluster, from the most to the least specific
+const (
+	alertScopeCluster       = "cluster"
+	alertScopeWorkspace     = "workspace"
+	alertScopeResourceGroup = "resource_group"
+	alertScopeSubscription  = "subscription"
+)
+
+// alertScopes orders the scope matches of a rule
+var alertScopes = []string{alertScopeCluster, alertScopeWorkspace, alertScopeResourceGroup, alertScopeSubscription}
+
+// AlertCondition is one condition of an alert rule: a metric criterion, a log search condition or a Prometheus alerting rule
+type AlertCondition struct {
+	Name        string `json:"name,omitempty"`
+	Expression  string `json:"expression"`
+	Query       string `json:"query,omitempty"`
+	For         string `json:"for,omitempty"`
+	Severity    string `json:"severity,omitempty"`
+	Description string `json:"description,omitempty"`
+}
+
+// AlertRule is the definition of an alert rule
+type AlertRule struct {
+	ID                  string           `json:"id"`
+	Name                string           `json:"name"`
+	Type                string           `json:"type"`
+	Enabled             bool             `json:"enabled"`
+	Severity            string           `json:"severity,omitempty"`
+	Description         string           `json:"description,omitempty"`
+	Scope               string           `json:"scope,omitempty"`
+	Scopes              []string         `json:"scopes"`
+	EvaluationFrequency string           `json:"evaluation_frequency,omitempty"`
+	WindowSize          string           `json:"window_size,omitempty"`
+	Conditions          []AlertCondition `json:"conditions"`
+}
+
+// FiredAlert is an alert from Alerts Management joined to the definition of the rule that raised it
+type FiredAlert struct {
+	ID               string     `json:"id"`
+	Name             string     `json:"name"`
+	Severity         string     `json:"severity"`
+	MonitorCondition string     `json:"monitor_condition"`
+	State            string     `json:"state"`
+	SignalType       string     `json:"signal_type,omitempty"`
+	MonitorService   string     `json:"monitor_service,omitempty"`
+	TargetResource   string     `json:"target_resource"`
+	Description      string     `json:"description,omitempty"`
+	Started          string     `json:"started"`
+	Resolved         string     `json:"resolved,omitempty"`
+	Duration         string     `json:"duration,omitempty"`
+	LastModified     string     `json:"last_modified,omitempty"`
+	RuleID           string     `json:"rule_id,omitempty"`
+	Rule             *AlertRule `json:"rule,omitempty"`
+}
+
+// AlertRulesResult is the response of the alert_rules operation
+type AlertRulesResult struct {
+	Cluster    string      `json:"cluster"`
+	Workspaces []string    `json:"workspaces,omitempty"`
+	Rules      []AlertRule `json:"rules"`
+	Messages   []string    `json:"messages,omitempty"`
+}
+
+// AlertsResult is the response of the alerts operation
+type AlertsResult struct {
+	Cluster     string            `json:"cluster"`
+	TimeRange   *common.TimeRange `json:"time_range"`
+	TotalAlerts int               `json:"total_alerts"`
+	Truncated   bool              `json:"truncated,omitempty"`
+	Alerts      []FiredAlert      `json:"alerts"`
+	Messages    []string          `json:"messages,omitempty"`
+}
+
+// alertsQuery is a typed query of fired alerts with client-side filters
+type alertsQuery struct {
+	TimeRange        *common.TimeRange
+	MonitorCondition string   // Fired or Resolved
+	States           []string // New, Acknowledged or Closed
+	Severities       []string // Sev0 to Sev4
+	MaxAlerts        int
+}
+
+// alertTargets are the resources the scopes of alert rules are matched against
+type alertTargets struct {
+	ClusterID  string
+	Workspaces []string // Log Analytics workspaces the cluster sends logs to
+}
+
+// match returns how the most specific of scopes relates to the cluster, or "" when none does. Resource groups and
+// subscriptions containing the cluster only match rules that target every resource type or managed clusters.
+func (t alertTargets) match(scopes, resourceTypes []string) string {
+	best := len(alertScopes)
+	for _, scope := range scopes {
+		var match string
+		switch {
+		case isResourceOrChild(scope, t.ClusterID):
+			match = alertScopeCluster
+		case slices.ContainsFunc(t.Workspaces, func(w string) bool { return strings.EqualFold(w, scope) }):
+			match = alertScopeWorkspace
+		case isResourceOrChild(t.ClusterID, scope):
+			if len(resourceTypes) > 0 && !slices.ContainsFunc(resourceTypes, func(r string) bool { return strings.EqualFold(r, managedClusterType) }) {
+				continue
+			}
+			match = alertScopeSubscription
+			if strings.Contains(strings.ToLower(scope), "/resourcegroups/") {
+				match = alertScopeResourceGroup
+			}
+		default:
+			continue
+		}
+		best = min(best, slices.Index(alertScopes, match))
+	}
+	if best == len(alertScopes) {
+		return ""
+	}
+	return alertScopes[best]
+}
+
+// ownsTarget reports whether an alert of rule raised on targetResource, which is not the cluster or one of its children,
+// belongs to the cluster. Rules scoped to resource groups, subscriptions or several clusters raise alerts on each
+// resource they cover, so only alerts of workspace rules on the cluster's workspaces, and of cluster rules on their
+// other scopes such as the Azure Monitor workspace of a Prometheus rule group, qualify.
+func (t alertTargets) ownsTarget(rule AlertRule, targetResource string) bool {
+	switch rule.Scope {
+	case alertScopeWorkspace:
+		return slices.ContainsFunc(t.Workspaces, func(w string) bool { return strings.EqualFold(w, targetResource) })
+	case alertScopeCluster:
+		if slices.ContainsFunc(rule.Scopes, func(scope string) bool { return isManagedCluster(scope) && !isResourceOrChild(scope, t.ClusterID) }) {
+			return false
+		}
+		return slices.ContainsFunc(rule.Scopes, func(scope string) bool { return strings.EqualFold(scope, targetResource) })
+	}
+	return false
+}
+
+// alertTargetResources returns the resources whose alerts can belong to the cluster: the cluster, its workspaces
+// and the other scopes of rules scoped to the cluster, such as the Azure Monitor workspace of a Prometheus rule group
+func (t alertTargets) alertTargetResources(rules []AlertRule) []string {
+	resources := []string{t.ClusterID}
+	add := func(id string) {
+		if !slices.ContainsFunc(resources, func(r string) bool { return strings.EqualFold(r, id) }) {
+			resources = append(resources, id)
+		}
+	}
+	for _, workspace := range t.Workspaces {
+		add(workspace)
+	}
+	for _, rule := range rules {
+		if rule.Scope != alertScopeCluster || slices.ContainsFunc(rule.Scopes, func(scope string) bool { return isManagedCluster(scope) && !isResourceOrChild(scope, t.ClusterID) }) {
+			continue
+		}
+		for _, scope := range rule.Scopes {
+			add(scope)
+		}
+	}
+	return resources
+}
+
+// isManagedCluster reports whether resourceID is a managed cluster or one of its children
+func isManagedCluster(resourceID string) bool {
+	return strings.Contains(strings.ToLower(resourceID), "/providers/"+strings.ToLower(managedClusterType)+"/")
+}
+
+// handleAlertRulesOperation lists the metric, log search and Prometheus alert rules scoped to the cluster
+func handleAlertRulesOperation(params map[string]interface{}, azClient *azureclient.AzureClient) (string, error) {
+	if azClient == nil {
+		return "", fmt.Errorf("azure client is required but not provided")
+	}
+
+	subscriptionID, resourceGroup, clusterName, err := common.ExtractAKSParameters(params)
+	if err != nil {
+		return "", err
+	}
+
+	ruleTypes, err := parseAlertRuleTypes(params)
+	if err != nil {
+		return "", err
+	}
+
+	ctx, err := common.TenantContext(context.Background(), azClient, params)
+	if err != nil {
+		return "", err
+	}
+	targets, err := discoverAlertTargets(ctx, azClient, subscriptionID, resourceGroup, clusterName)
+	if err != nil {
+		return "", err
+	}
+
+	rules, messages, err := listAlertRules(ctx, azClient, subscriptionID, ruleTypes, targets)
+	if err != nil {
+		return "", err
+	}
+
+	result := AlertRulesResult{
+		Cluster:    targets.ClusterID,
+		Workspaces: targets.Workspaces,
+		Rules:      []AlertRule{},
+		Messages:   messages,
+	}
+	for _, rule := range rules {
+		if rule.Scope != "" {
+			result.Rules = append(result.Rules, rule)
+		}
+	}
+
+	resultJSON, err := json.MarshalIndent(result, "", "  ")
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal alert rules result to JSON: %w", err)
+	}
+	return string(resultJSON), nil
+}
+
+// handleAlertsOperation lists the alerts of the cluster that Alerts Management raised in a time range, each joined
+// to the definition of its rule. Alerts belong to the cluster when it is their target or their rule is scoped to it.
+func handleAlertsOperation(params map[string]interface{}, azClient *azureclient.AzureClient) (string, error) {
+	if azClient == nil {
+		return "", fmt.Errorf("azure client is required but not provided")
+	}
+
+	subscriptionID, resourceGroup, clusterName, err := common.ExtractAKSParameters(params)
+	if err != nil {
+		return "", err
+	}
+
+	query, err := parseAlertsQuery(params)
+	if err != nil {
+		return "", err
+	}
+
+	ctx, err := common.TenantContext(context.Background(), azClient, params)
+	if err != nil {
+		return "", err
+	}
+	targets, err := discoverAlertTargets(ctx, azClient, subscriptionID, resourceGroup, clusterName)
+	if err != nil {
+		return "", err
+	}
+
+	// Alerts are still listed when the rule definitions cannot be read
+	rules, messages, err := listAlertRules(ctx, azClient, subscriptionID, alertRuleTypes, targets)
+	if err != nil {
+		messages = append(messages, err.Error())
+	}
+	rulesByID := make(map[string]AlertRule, len(rules))
+	for _, rule := range rules {
+		rulesByID[strings.ToLower(rule.ID)] = rule
+	}
+
+	result := AlertsResult{
+		Cluster:   targets.ClusterID,
+		TimeRange: query.TimeRange,
+		Alerts:    []FiredAlert{},
+		Messages:  messages,
+	}
+
+	// Alerts Management filters by target, so the alerts of busy subscriptions do not crowd out the cluster's
+	var managementAlerts []managementAlert
+	seen := make(map[string]bool)
+	for _, target := range targets.alertTargetResources(rules) {
+		items, err := azClient.ListARMResources(ctx, subscriptionID,
+			fmt.Sprintf("/subscriptions/%s/providers/Microsoft.AlertsManagement/alerts", subscriptionID),
+			alertsManagementAPIVersion, url.Values{
+				"targetResource":  {target},
+				"customTimeRange": {query.TimeRange.Timespan()},
+				"sortBy":          {"startDateTime"},
+				"sortOrder":       {"desc"},
+			}, maxAlertsRead)
+		if err != nil {
+			return "", fmt.Errorf("failed to list alerts of %s: %w", target, err)
+		}
+		if len(items) >= maxAlertsRead {
+			result.Truncated = true
+		}
+		for _, item := range items {
+			var alert managementAlert
+			if err := json.Unmarshal(item, &alert); err != nil {
+				return "", fmt.Errorf("failed to parse alert: %w", err)
+			}
+			if id := strings.ToLower(alert.ID); !seen[id] {
+				seen[id] = true
+				managementAlerts = append(managementAlerts, alert)
+			}
+		}
+	}
+
+	var alerts []FiredAlert
+	for _, alert := range managementAlerts {
+		essentials := alert.Properties.Essentials
+		if essentials.StartDateTime == nil || essentials.StartDateTime.Before(query.TimeRange.Start) || essentials.StartDateTime.After(query.TimeRange.End) {
+			continue
+		}
+
+		rule, hasRule := rulesByID[strings.ToLower(essentials.AlertRule)]
+		if !isResourceOrChild(essentials.TargetResource, targets.ClusterID) && (!hasRule || !targets.ownsTarget(rule, essentials.TargetResource)) {
+			continue
+		}
+		if !query.matches(essentials) {
+			continue
+		}
+
+		fired := alert.fired()
+		if hasRule {
+			fired.Rule = ruleForAlert(rule, alert.Name)
+		}
+		alerts = append(alerts, fired)
+	}
+
+	sort.SliceStable(alerts, func(i, j int) bool { return alerts[i].Started < alerts[j].Started })
+	result.TotalAlerts = len(alerts)
+	if len(alerts) > query.MaxAlerts {
+		// Keep the most recent alerts
+		alerts = alerts[len(alerts)-query.MaxAlerts:]
+		result.Truncated = true
+	}
+	result.Alerts = append(result.Alerts, alerts...)
+
+	resultJSON, err := json.MarshalIndent(result, "", "  ")
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal alerts result to JSON: %w", err)
+	}
+	return string(resultJSON), nil
+}
+
+// parseAlertRuleTypes returns the rule types requested by the rule_type parameter, or every type when it is not set
+func parseAlertRuleTypes(params map[string]interface{}) ([]string, error) {
+	var ruleTypes []string
+	for _, ruleType := range stringList(params["rule_type"]) {
+		ruleType = strings.ToLower(ruleType)
+		if !slices.Contains(alertRuleTypes, ruleType) {
+			return nil, fmt.Errorf("invalid rule_type: %s. Supported rule types: %s", ruleType, strings.Join(alertRuleTypes, ", "))
+		}
+		if !slices.Contains(ruleTypes, ruleType) {
+			ruleTypes = append(ruleTypes, ruleType)
+		}
+	}
+	if len(ruleTypes) == 0 {
+		return alertRuleTypes, nil
+	}
+	return ruleTypes, nil
+}
+
+// parseAlertsQuery builds a fired alerts query from the merged operation parameters
+func parseAlertsQuery(params map[string]interface{}) (*alertsQuery, error) {
+	timeRange, err := common.ParseTimeRange(params, monitorNow(), common.TimeRangeOptions{
+		Default: defaultAlertsWindow,
+		MaxAge:  maxAlertsAge,
+	})
+	if err != nil {
+		return nil, err
+	}
+	query := &alertsQuery{TimeRange: timeRange}
+
+	if condition, _ := params["monitor_condition"].(string); condition != "" {
+		switch strings.ToLower(strings.TrimSpace(condition)) {
+		case "fired":
+			query.MonitorCondition = "Fired"
+		case "resolved":
+			query.MonitorCondition = "Resolved"
+		default:
+			return nil, fmt.Errorf("invalid monitor_condition: %s. Supported conditions: fired, resolved", condition)
+		}
+	}
+
+	for _, state := range stringList(params["state"]) {
+		switch strings.ToLower(state) {
+		case "new", "acknowledged", "closed":
+			query.States = append(query.States, strings.ToUpper(state[:1])+strings.ToLower(state[1:]))
+		default:
+			return nil, fmt.Errorf("invalid state: %s. Supported states: new, acknowledged, closed", state)
+		}
+	}
+
+	for _, severity := range stringList(params["severity"]) {
+		level, err := strconv.Atoi(strings.TrimPrefix(strings.ToLower(severity), "sev"))
+		if err != nil || level < 0 || level > 4 {
+			return nil, fmt.Errorf("invalid severity: %s. Supported severities: Sev0 (critical) to Sev4 (verbose)", severity)
+		}
+		query.Severities = append(query.Severities, severityName(level))
+	}
+
+	query.MaxAlerts, err = positiveInt(params, "max_alerts", defaultMaxAlerts, maxMaxAlerts)
+	if err != nil {
+		return nil, err
+	}
+
+	return query, nil
+}
+
+// matches reports whether an alert passes the monitor condition, state and severity filters of the query
+func (q *alertsQuery) matches(essentials managementAlertEssentials) bool {
+	if q.MonitorCondition != "" && !strings.EqualFold(essentials.MonitorCondition, q.MonitorCondition) {
+		return false
+	}
+	if len(q.States) > 0 && !slices.ContainsFunc(q.States, func(s string) bool { return strings.EqualFold(s, essentials.AlertState) }) {
+		return false
+	}
+	return len(q.Severities) == 0 || slices.ContainsFunc(q.Severities, func(s string) bool { return strings.EqualFold(s, essentials.Severity) })
+}
+
+// discoverAlertTargets resolves the cluster and the Log Analytics workspaces it sends control plane logs and
+// Container Insights data to, which log search alert rules are usually scoped to
+func discoverAlertTargets(ctx context.Context, azClient *azureclient.AzureClient, subscriptionID, resourceGroup, clusterName string) (alertTargets, error) {
+	cluster, err := azClient.GetAKSCluster(ctx, subscriptionID, resourceGroup, clusterName)
+	if err != nil {
+		return alertTargets{}, fmt.Errorf("failed to get AKS cluster: %w", err)
+	}
+	targets := alertTargets{ClusterID: stringValue(cluster.ID)}
+	addWorkspace := func(id string) {
+		if id != "" && !slices.ContainsFunc(targets.Workspaces, func(w string) bool { return strings.EqualFold(w, id) }) {
+			targets.Workspaces = append(targets.Workspaces, id)
+		}
+	}
+
+	// Workspaces are best effort: rules scoped to the cluster are still found without them
+	if settings, err := azClient.GetDiagnosticSettings(ctx, subscriptionID, targets.ClusterID); err == nil {
+		for _, setting := range settings {
+			if setting != nil && setting.Properties != nil {
+				addWorkspace(stringValue(setting.Properties.WorkspaceID))
+			}
+		}
+	}
+	if workspace, err := diagnostics.FindContainerInsightsWorkspace(ctx, subscriptionID, resourceGroup, clusterName, azClient); err == nil {
+		addWorkspace(workspace)
+	}
+
+	return targets, nil
+}
+
+// listAlertRules lists the alert rules of the requested types in the subscription, with the scope of each rule set
+// when it relates to the cluster. A rule type that cannot be listed is reported in the messages unless every type fails.
+func listAlertRules(ctx context.Context, azClient *azureclient.AzureClient, subscriptionID string, ruleTypes []string, targets alertTargets) ([]AlertRule, []string, error) {
+	var rules []AlertRule
+	var messages []string
+	var listErr error
+	for _, ruleType := range ruleTypes {
+		var typeRules []AlertRule
+		var err error
+		switch ruleType {
+		case alertRuleTypeMetric:
+			var resources []*armmonitor.MetricAlertResource
+			resources, err = azClient.ListMetricAlerts(ctx, subscriptionID)
+			for _, resource := range resources {
+				if resource != nil {
+					typeRules = append(typeRules, metricAlertRule(resource, targets))
+				}
+			}
+		case alertRuleTypeLogSearch:
+			var resources []*armmonitor.ScheduledQueryRuleResource
+			resources, err = azClient.ListScheduledQueryRules(ctx, subscriptionID)
+			for _, resource := range resources {
+				if resource != nil {
+					typeRules = append(typeRules, logSearchAlertRule(resource, targets))
+				}
+			}
+		case alertRuleTypePrometheus:
+			typeRules, err = listPrometheusAlertRules(ctx, azClient, subscriptionID, targets)
+		}
+		if err != nil {
+			listErr = fmt.Errorf("failed to list %s alert rules: %w", ruleType, err)
+			messages = append(messages, listErr.Error())
+			continue
+		}
+
+		sort.SliceStable(typeRules, func(i, j int) bool { return strings.ToLower(typeRules[i].Name) < strings.ToLower(typeRules[j].Name) })
+		rules = append(rules, typeRules...)
+	}
+	if len(messages) == len(ruleTypes) {
+		return nil, nil, listErr
+	}
+
+	return rules, messages, nil
+}
+
+// metricAlertRule converts a metric alert rule
+func metricAlertRule(resource *armmonitor.MetricAlertResource, targets alertTargets) AlertRule {
+	rule := AlertRule{ID: stringValue(resource.ID), Name: stringValue(resource.Name), Type: alertRuleTypeMetric, Conditions: []AlertCondition{}}
+	properties := resource.Properties
+	if properties == nil {
+		return rule
+	}
+
+	rule.Enabled = properties.Enabled != nil && *properties.Enabled
+	if properties.Severity != nil {
+		rule.Severity = severityName(int(*properties.Severity))
+	}
+	rule.Description = stringValue(properties.Description)
+	rule.Scopes = stringValues(properties.Scopes)
+	rule.EvaluationFrequency = stringValue(properties.EvaluationFrequency)
+	rule.WindowSize = stringValue(properties.WindowSize)
+	var resourceTypes []string
+	if properties.TargetResourceType != nil {
+		resourceTypes = []string{*properties.TargetResourceType}
+	}
+	rule.Scope = targets.match(rule.Scopes, resourceTypes)
+
+	switch criteria := properties.Criteria.(type) {
+	case *armmonitor.MetricAlertSingleResourceMultipleMetricCriteria:
+		for _, criterion := range criteria.AllOf {
+			if criterion != nil {
+				rule.Conditions = append(rule.Conditions, staticMetricCondition(criterion))
+			}
+		}
+	case *armmonitor.MetricAlertMultipleResourceMultipleMetricCriteria:
+		for _, criterion := range criteria.AllOf {
+			switch criterion := criterion.(type) {
+			case *armmonitor.MetricCriteria:
+				rule.Conditions = append(rule.Conditions, staticMetricCondition(criterion))
+			case *armmonitor.DynamicMetricCriteria:
+				expression := fmt.Sprintf("%s %s %s dynamic threshold (%s sensitivity)", stringValue((*string)(criterion.TimeAggregation)),
+					stringValue(criterion.MetricName), stringValue((*string)(criterion.Operator)), stringValue((*string)(criterion.AlertSensitivity)))
+				rule.Conditions = append(rule.Conditions, AlertCondition{
+					Name:       stringValue(criterion.Name),
+					Expression: expression + metricDimensionsFilter(criterion.Dimensions),
+				})
+			}
+		}
+	case *armmonitor.WebtestLocationAvailabilityCriteria:
+		failedLocations := float32(0)
+		if criteria.FailedLocationCount != nil {
+			failedLocations = *criteria.FailedLocationCount
+		}
+		rule.Conditions = append(rule.Conditions, AlertCondition{
+			Expression: fmt.Sprintf("availability test %s fails in %g locations", stringValue(criteria.WebTestID), failedLocations),
+		})
+	}
+
+	return rule
+}
+
+// staticMetricCondition describes a static threshold metric criterion, e.g. "Average node_cpu_usage_percentage GreaterThan 80"
+func staticMetricCondition(criterion *armmonitor.MetricCriteria) AlertCondition {
+	threshold := 0.0
+	if criterion.Threshold != nil {
+		threshold = *criterion.Threshold
+	}
+	expression := fmt.Sprintf("%s %s %s %g", stringValue((*string)(criterion.TimeAggregation)), stringValue(criterion.MetricName),
+		stringValue((*string)(criterion.Operator)), threshold)
+	return AlertCondition{Name: stringValue(criterion.Name), Expression: expression + metricDimensionsFilter(criterion.Dimensions)}
+}
+
+// metricDimensionsFilter describes the dimension filters of a metric criterion, e.g. " where node Include (*)"
+func metricDimensionsFilter(dimensions []*armmonitor.MetricDimension) string {
+	var filters []string
+	for _, dimension := range dimensions {
+		if dimension != nil {
+			filters = append(filters, fmt.Sprintf("%s %s (%s)", stringValue(dimension.Name), stringValue(dimension.Operator), strings.Join(stringValues(dimension.Values), ", ")))
+		}
+	}
+	if len(filters) == 0 {
+		return ""
+	}
+	return " where " + strings.Join(filters, " and ")
+}
+
+// logSearchAlertRule converts a log search (scheduled query) alert rule
+func logSearchAlertRule(resource *armmonitor.ScheduledQueryRuleResource, targets alertTargets) AlertRule {
+	rule := AlertRule{ID: stringValue(resource.ID), Name: stringValue(resource.Name), Type: alertRuleTypeLogSearch, Conditions: []AlertCondition{}}
+	properties := resource.Properties
+	if properties == nil {
+		return rule
+	}
+
+	rule.Enabled = properties.Enabled != nil && *properties.Enabled
+	if properties.Severity != nil {
+		rule.Severity = severityName(int(*properties.Severity))
+	}
+	rule.Description = stringValue(properties.Description)
+	rule.Scopes = stringValues(properties.Scopes)
+	rule.EvaluationFrequency = stringValue(properties.EvaluationFrequency)
+	rule.WindowSize = stringValue(properties.WindowSize)
+	rule.Scope = targets.match(rule.Scopes, stringValues(properties.TargetResourceTypes))
+
+	if properties.Criteria != nil {
+		for _, condition := range properties.Criteria.AllOf {
+			if condition == nil {
+				continue
+			}
+			threshold := 0.0
+			if condition.Threshold != nil {
+				threshold = *condition.Threshold
+			}
+			measure := stringValue((*string)(condition.TimeAggregation))
+			if column := stringValue(condition.MetricMeasureColumn); column != "" {
+				measure += " " + column
+			}
+			expression := fmt.Sprintf("%s %s %g", measure, stringValue((*string)(condition.Operator)), threshold)
+
+			var filters []string
+			for _, dimension := range condition.Dimensions {
+				if dimension != nil {
+					filters = append(filters, fmt.Sprintf("%s %s (%s)", stringValue(dimension.Name), stringValue((*string)(dimension.Operator)), strings.Join(stringValues(dimension.Values), ", ")))
+				}
+			}
+			if len(filters) > 0 {
+				expression += " where " + strings.Join(filters, " and ")
+			}
+			if periods := condition.FailingPeriods; periods != nil && periods.MinFailingPeriodsToAlert != nil && periods.NumberOfEvaluationPeriods != nil {
+				expression += fmt.Sprintf(" in %d of %d evaluations", *periods.MinFailingPeriodsToAlert, *periods.NumberOfEvaluationPeriods)
+			}
+
+			rule.Conditions = append(rule.Conditions, AlertCondition{Expression: expression, Query: stringValue(condition.Query)})
+		}
+	}
+
+	return rule
+}
+
+// prometheusRuleGroup is a Prometheus rule group of Alerts Management, which has no SDK client
+type prometheusRuleGroup struct {
+	ID         string `json:"id"`
+	Name       string `json:"name"`
+	Properties struct {
+		Description string   `json:"description"`
+		Scopes      []string `json:"scopes"`
+		Enabled     *bool    `json:"enabled"`
+		Interval    string   `json:"interval"`
+		Rules       []struct {
+			Alert       string            `json:"alert"`
+			Record      string            `json:"record"`
+			Expression  string            `json:"expression"`
+			For         string            `json:"for"`
+			Severity    *int              `json:"severity"`
+			Enabled     *bool             `json:"enabled"`
+			Annotations map[string]string `json:"annotations"`
+		} `json:"rules"`
+	} `json:"properties"`
+}
+
+// listPrometheusAlertRules lists the Prometheus rule groups of the subscription as alert rules, each alerting rule of
+// a group being a condition. Groups match the cluster by their scopes; recording rules are left out.
+func listPrometheusAlertRules(ctx context.Context, azClient *azureclient.AzureClient, subscriptionID string, targets alertTargets) ([]AlertRule, error) {
+	items, err := azClient.ListARMResources(ctx, subscriptionID,
+		fmt.Sprintf("/subscriptions/%s/providers/Microsoft.AlertsManagement/prometheusRuleGroups", subscriptionID),
+		prometheusRuleGroupsAPIVersion, nil, maxAlertsRead)
+	if err != nil {
+		return nil, err
+	}
+
+	var rules []AlertRule
+	for _, item := range items {
+		var group prometheusRuleGroup
+		if err := json.Unmarshal(item, &group); err != nil {
+			return nil, fmt.Errorf("failed to parse Prometheus rule group: %v", err)
+		}
+
+		properties := group.Properties
+		rule := AlertRule{
+			ID:                  group.ID,
+			Name:                group.Name,
+			Type:                alertRuleTypePrometheus,
+			Enabled:             properties.Enabled == nil || *properties.Enabled,
+			Description:         properties.Description,
+			Scopes:              properties.Scopes,
+			EvaluationFrequency: properties.Interval,
+			Conditions:          []AlertCondition{},
+		}
+		// The cluster name of a group is not unique across resource groups, so only its scopes identify the cluster
+		rule.Scope = targets.match(rule.Scopes, nil)
+
+		for _, promRule := range properties.Rules {
+			if promRule.Alert == "" || (promRule.Enabled != nil && !*promRule.Enabled) {
+				continue
+			}
+			condition := AlertCondition{Name: promRule.Alert, Expression: promRule.Expression, For: promRule.For}
+			if promRule.Severity != nil {
+				condition.Severity = severityName(*promRule.Severity)
+			}
+			condition.Description = promRule.Annotations["description"]
+			if condition.Description == "" {
+				condition.Description = promRule.Annotations["summary"]
+			}
+			rule.Conditions = append(rule.Conditions, condition)
+		}
+		rules = append(rules, rule)
+	}
+
+	return rules, nil
+}
+
+// ruleForAlert returns the rule definition joined to an alert. An alert of a Prometheus rule group is raised by one of
+// its alerting rules, named like the alert, so only that condition is kept.
+func ruleForAlert(rule AlertRule, alertName string) *AlertRule {
+	if rule.Type == alertRuleTypePrometheus {
+		for _, condition := range rule.Conditions {
+			if strings.EqualFold(condition.Name, alertName) {
+				rule.Conditions = []AlertCondition{condition}
+				rule.Severity = condition.Severity
+				break
+			}
+		}
+	}
+	return &rule
+}
+
+// managementAlert is an alert of Alerts Management, which has no SDK client
+type managementAlert struct {
+	ID         string `json:"id"`
+	Name       string `json:"name"`
+	Properties struct {
+		Essentials managementAlertEssentials `json:"essentials"`
+	} `json:"properties"`
+}
+
+// managementAlertEssentials are the common properties of an alert of any signal type
+type managementAlertEssentials struct {
+	Severity                         string     `json:"severity"`
+	SignalType                       string     `json:"signalType"`
+	AlertState                       string     `json:"alertState"`
+	MonitorCondition                 string     `json:"monitorCondition"`
+	MonitorService                   string     `json:"monitorService"`
+	TargetResource                   string     `json:"targetResource"`
+	AlertRule                        string     `json:"alertRule"`
+	Description                      string     `json:"description"`
+	StartDateTime                    *time.Time `json:"startDateTime"`
+	LastModifiedDateTime             *time.Time `json:"lastModifiedDateTime"`
+	MonitorConditionResolvedDateTime *time.Time `json:"monitorConditionResolvedDateTime"`
+}
+
+// fired converts an alert without its rule definition
+func (a managementAlert) fired() FiredAlert {
+	essentials := a.Properties.Essentials
+	alert := FiredAlert{
+		ID:               a.ID,
+		Name:             a.Name,
+		Severity:         essentials.Severity,
+		MonitorCondition: essentials.MonitorCondition,
+		State:            essentials.AlertState,
+		SignalType:       essentials.SignalType,
+		MonitorService:   essentials.MonitorService,
+		TargetResource:   essentials.TargetResource,
+		Description:      essentials.Description,
+		Started:          essentials.StartDateTime.UTC().Format(time.RFC3339),
+		RuleID:           essentials.AlertRule,
+	}
+	if resolved := essentials.MonitorConditionResolvedDateTime; resolved != nil && strings.EqualFold(essentials.MonitorCondition, "Resolved") {
+		alert.Resolved = resolved.UTC().Format(time.RFC3339)
+		alert.Duration = resolved.Sub(*essentials.StartDateTime).String()
+	}
+	if essentials.LastModifiedDateTime != nil {
+		alert.LastModified = essentials.LastModifiedDateTime.UTC().Format(time.RFC3339)
+	}
+	return alert
+}
+
+// severityName returns the Alerts Management name of a severity level, e.g. Sev2
+func severityName(level int) string {
+	return fmt.Sprintf("Sev%d", level)
+}
+
+// stringValues dereferences a list of optional strings, leaving out the unset ones
+func stringValues(values []*string) []string {
+	out := []string{}
+	for _, value := range values {
+		if value != nil {
+			out = append(out, *value)
+		}
+	}
+	return out
+}
@@ -0,0 +1,272 @@
+package monitor
+
+import (
+	"encoding/json"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Azure/aks-mcp/internal/azureclient"
+	"github.com/Azure/aks-mcp/internal/azureclient/fakearm"
+	"github.com/Azure/aks-mcp/internal/azureclient/fakearm/armtest"
+	"github.com/Azure/aks-mcp/internal/config"
+)
+
+const (
+	testMetricAlertsPath         = "/subscriptions/" + fakearm.SubscriptionID + "/providers/Microsoft.Insights/metricAlerts"
+	testAlertsPath               = "/subscriptions/" + fakearm.SubscriptionID + "/providers/Microsoft.AlertsManagement/alerts"
+	testPrometheusRuleGroupsPath = "/subscriptions/" + fakearm.SubscriptionID + "/providers/Microsoft.AlertsManagement/prometheusRuleGroups"
+)
+
+// newAlertsServer starts a fake ARM server with the alert rules and alerts fixture. The cluster's diagnostic setting
+// sends logs to test-workspace, which the aks-oom-killed log search rule is scoped to.
+func newAlertsServer(t *testing.T) (*fakearm.Server, *azureclient.AzureClient, *config.ConfigData) {
+	t.Helper()
+
+	return armtest.NewClient(t, filepath.Join("testdata", "alerts.json"))
+}
+
+// runAlertsOperation runs an alerts operation at noon on 2026-03-01 and decodes its result
+func runAlertsOperation(t *testing.T, client *azureclient.AzureClient, cfg *config.ConfigData, operation, parameters string, result interface{}) {
+	t.Helper()
+
+	defer func(original func() time.Time) { monitorNow = original }(monitorNow)
+	monitorNow = func() time.Time { return time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC) }
+
+	output, err := GetAzMonitoringHandler(client, cfg).Handle(map[string]interface{}{
+		"operation":       operation,
+		"subscription_id": fakearm.SubscriptionID,
+		"resource_group":  fakearm.ResourceGroup,
+		"cluster_name":    fakearm.ClusterName,
+		"parameters":      parameters,
+	}, cfg)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if err := json.Unmarshal([]byte(output), result); err != nil {
+		t.Fatalf("Failed to parse result: %v\n%s", err, output)
+	}
+}
+
+func TestGetAzMonitoringHandler_AlertRules(t *testing.T) {
+	_, client, cfg := newAlertsServer(t)
+
+	var result AlertRulesResult
+	runAlertsOperation(t, client, cfg, "alert_rules", `{}`, &result)
+
+	if result.Cluster != fakearm.ClusterResourceID || len(result.Workspaces) != 1 || !strings.HasSuffix(result.Workspaces[0], "/workspaces/test-workspace") {
+		t.Errorf("Unexpected targets: %+v", result)
+	}
+
+	// Rules of other clusters, including a cluster of the same name in another resource group, workspaces and resource types are left out
+	var got []string
+	for _, rule := range result.Rules {
+		got = append(got, rule.Type+" "+rule.Name+" "+rule.Scope)
+	}
+	want := []string{
+		"metric aks-high-node-cpu cluster",
+		"metric aks-nodes-not-ready subscription",
+		"log_search aks-oom-killed workspace",
+		"prometheus KubernetesAlert-RecommendedMetricAlerts-test-cluster cluster",
+	}
+	if strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Fatalf("Expected rules:\n%s\ngot:\n%s", strings.Join(want, "\n"), strings.Join(got, "\n"))
+	}
+
+	cpu, notReady, oom, prometheus := result.Rules[0], result.Rules[1], result.Rules[2], result.Rules[3]
+	if !cpu.Enabled || cpu.Severity != "Sev2" || cpu.WindowSize != "PT15M" || cpu.Conditions[0].Expression != "Average node_cpu_usage_percentage GreaterThan 80 where node Include (*)" {
+		t.Errorf("Unexpected metric rule: %+v", cpu)
+	}
+	if notReady.Enabled || notReady.Conditions[0].Expression != "Average kube_node_status_condition GreaterThan dynamic threshold (Medium sensitivity) where status2 Include (NotReady)" {
+		t.Errorf("Unexpected dynamic threshold rule: %+v", notReady)
+	}
+	if oom.Severity != "Sev1" || oom.Conditions[0].Expression != "Count GreaterThan 0 where Namespace Include (*) in 1 of 1 evaluations" || !strings.Contains(oom.Conditions[0].Query, "OOMKilled") {
+		t.Errorf("Unexpected log search rule: %+v", oom)
+	}
+	// Recording rules and disabled alerting rules are not conditions
+	if len(prometheus.Conditions) != 2 || prometheus.Conditions[0].Name != "KubePodCrashLooping" || prometheus.Conditions[0].For != "PT15M" ||
+		prometheus.Conditions[1].Severity != "Sev3" || prometheus.Conditions[1].Description != "Node is not ready" {
+		t.Errorf("Unexpected Prometheus rule group: %+v", prometheus)
+	}
+}
+
+func TestGetAzMonitoringHandler_AlertRulesFilters(t *testing.T) {
+	t.Run("rule type", func(t *testing.T) {
+		srv, client, cfg := newAlertsServer(t)
+
+		var result AlertRulesResult
+		runAlertsOperation(t, client, cfg, "alert_rules", `{"rule_type": "prometheus"}`, &result)
+		if len(result.Rules) != 1 || result.Rules[0].Type != "prometheus" {
+			t.Errorf("Expected the Prometheus rule group only, got %+v", result.Rules)
+		}
+		if count := srv.RequestCount(testMetricAlertsPath); count != 0 {
+			t.Errorf("Expected metric alert rules not to be listed, got %d requests", count)
+		}
+	})
+
+	t.Run("rule type that cannot be listed", func(t *testing.T) {
+		srv, client, cfg := newAlertsServer(t)
+		srv.RemoveResponse(testPrometheusRuleGroupsPath)
+
+		var result AlertRulesResult
+		runAlertsOperation(t, client, cfg, "alert_rules", `{}`, &result)
+		if len(result.Rules) != 3 || len(result.Messages) != 1 || !strings.Contains(result.Messages[0], "failed to list prometheus alert rules") {
+			t.Errorf("Expected the other rules and a message, got %+v", result)
+		}
+	})
+}
+
+func TestGetAzMonitoringHandler_Alerts(t *testing.T) {
+	srv, client, cfg := newAlertsServer(t)
+
+	var result AlertsResult
+	runAlertsOperation(t, client, cfg, "alerts", `{}`, &result)
+
+	if result.TimeRange.Duration != "24h0m0s" || len(result.Messages) != 0 {
+		t.Errorf("Unexpected query details: %+v", result)
+	}
+
+	// The virtual machine alert and the alert started before the time range are left out
+	var got []string
+	for _, alert := range result.Alerts {
+		got = append(got, alert.Started+" "+alert.Name+" "+alert.MonitorCondition)
+	}
+	want := []string{
+		"2026-03-01T01:00:00Z deleted-api-latency Resolved",
+		"2026-03-01T02:10:00Z aks-high-node-cpu Fired",
+		"2026-03-01T03:00:00Z KubePodCrashLooping Resolved",
+		"2026-03-01T04:00:00Z aks-oom-killed Fired",
+	}
+	if strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Fatalf("Expected alerts:\n%s\ngot:\n%s", strings.Join(want, "\n"), strings.Join(got, "\n"))
+	}
+	if result.TotalAlerts != 4 || result.Truncated {
+		t.Errorf("Expected 4 alerts, got %d", result.TotalAlerts)
+	}
+
+	// Alerts are listed per target: the cluster, its workspace and the Azure Monitor workspace of its Prometheus rule group
+	var targets []string
+	for _, query := range srv.Queries(testAlertsPath) {
+		targets = append(targets, query.Get("targetResource"))
+	}
+	wantTargets := []string{
+		fakearm.ClusterResourceID,
+		"/subscriptions/" + fakearm.SubscriptionID + "/resourceGroups/test-rg/providers/Microsoft.OperationalInsights/workspaces/test-workspace",
+		"/subscriptions/" + fakearm.SubscriptionID + "/resourceGroups/test-rg/providers/Microsoft.Monitor/accounts/test-amw",
+	}
+	if strings.Join(targets, "\n") != strings.Join(wantTargets, "\n") {
+		t.Errorf("Expected alerts listed for targets:\n%s\ngot:\n%s", strings.Join(wantTargets, "\n"), strings.Join(targets, "\n"))
+	}
+
+	deleted, cpu, crashLoop, oom := result.Alerts[0], result.Alerts[1], result.Alerts[2], result.Alerts[3]
+	if deleted.Rule != nil || deleted.Duration != "40m0s" {
+		t.Errorf("Expected the alert of a deleted rule without definition, got %+v", deleted)
+	}
+	// Rule IDs are joined ignoring case
+	if cpu.Rule == nil || cpu.Rule.Name != "aks-high-node-cpu" || cpu.Rule.Conditions[0].Name != "cpu" || cpu.Resolved != "" {
+		t.Errorf("Expected the CPU alert joined to its metric rule, got %+v", cpu)
+	}
+	if crashLoop.Rule == nil || len(crashLoop.Rule.Conditions) != 1 || crashLoop.Rule.Conditions[0].Name != "KubePodCrashLooping" || crashLoop.Rule.Severity != "Sev4" {
+		t.Errorf("Expected the crash loop alert joined to its Prometheus alerting rule, got %+v", crashLoop.Rule)
+	}
+	if oom.State != "Acknowledged" || oom.Rule == nil || oom.Rule.Scope != "workspace" || !strings.Contains(oom.Rule.Conditions[0].Query, "KubePodInventory") {
+		t.Errorf("Expected the OOM alert joined to its workspace log search rule, got %+v", oom)
+	}
+}
+
+func TestGetAzMonitoringHandler_AlertsOfOtherClusters(t *testing.T) {
+	_, client, cfg := newAlertsServer(t)
+
+	var result AlertsResult
+	runAlertsOperation(t, client, cfg, "alerts", `{"monitor_condition": "fired"}`, &result)
+
+	// The subscription-scoped aks-nodes-not-ready rule covers the cluster, but its alert on other-cluster is left out
+	for _, alert := range result.Alerts {
+		if alert.Name == "aks-nodes-not-ready" {
+			t.Errorf("Expected the alert of another cluster to be left out, got %+v", alert)
+		}
+	}
+	if result.TotalAlerts != 2 {
+		t.Errorf("Expected 2 fired alerts of the cluster, got %d", result.TotalAlerts)
+	}
+}
+
+func TestGetAzMonitoringHandler_AlertsFilters(t *testing.T) {
+	tests := []struct {
+		name       string
+		parameters string
+		alerts     []string
+		total      int
+	}{
+		{"fired", `{"monitor_condition": "fired"}`, []string{"aks-high-node-cpu", "aks-oom-killed"}, 2},
+		{"severity and state", `{"severity": "1,Sev2", "state": "new"}`, []string{"aks-high-node-cpu"}, 1},
+		{"time range", `{"time_range": "2026-02-27T00:00:00Z/2026-02-28T00:00:00Z"}`, []string{"aks-high-node-cpu"}, 1},
+		{"max alerts", `{"max_alerts": 2}`, []string{"KubePodCrashLooping", "aks-oom-killed"}, 4},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, client, cfg := newAlertsServer(t)
+
+			var result AlertsResult
+			runAlertsOperation(t, client, cfg, "alerts", tt.parameters, &result)
+
+			var got []string
+			for _, alert := range result.Alerts {
+				got = append(got, alert.Name)
+			}
+			if strings.Join(got, ",") != strings.Join(tt.alerts, ",") || result.TotalAlerts != tt.total || result.Truncated != (tt.total > len(tt.alerts)) {
+				t.Errorf("Expected %v of %d alerts, got %v of %d (truncated %v)", tt.alerts, tt.total, got, result.TotalAlerts, result.Truncated)
+			}
+		})
+	}
+
+	// Alerts of the cluster are listed without rule definitions when no rule type can be read
+	srv, client, cfg := newAlertsServer(t)
+	for _, path := range []string{testMetricAlertsPath, testPrometheusRuleGroupsPath, "/subscriptions/" + fakearm.SubscriptionID + "/providers/Microsoft.Insights/scheduledQueryRules"} {
+		srv.RemoveResponse(path)
+	}
+	var result AlertsResult
+	runAlertsOperation(t, client, cfg, "alerts", `{}`, &result)
+	if len(result.Alerts) != 2 || result.Alerts[1].Rule != nil || len(result.Messages) != 1 {
+		t.Errorf("Expected the 2 alerts targeting the cluster and a message, got %+v", result)
+	}
+}
+
+func TestParseAlertsQuery(t *testing.T) {
+	defer func(original func() time.Time) { monitorNow = original }(monitorNow)
+	monitorNow = func() time.Time { return time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC) }
+
+	query, err := parseAlertsQuery(map[string]interface{}{"severity": []interface{}{"sev0", "3"}, "state": "Acknowledged,closed"})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if query.MaxAlerts != defaultMaxAlerts || strings.Join(query.Severities, ",") != "Sev0,Sev3" || strings.Join(query.States, ",") != "Acknowledged,Closed" {
+		t.Errorf("Unexpected query: %+v", query)
+	}
+
+	errorCases := []struct {
+		name   string
+		params map[string]interface{}
+		errMsg string
+	}{
+		{"bad condition", map[string]interface{}{"monitor_condition": "active"}, "invalid monitor_condition: active"},
+		{"bad state", map[string]interface{}{"state": "new,open"}, "invalid state: open"},
+		{"bad severity", map[string]interface{}{"severity": "Sev5"}, "invalid severity: Sev5"},
+		{"too old", map[string]interface{}{"time_range": "2026-01-01T00:00:00Z/2026-01-02T00:00:00Z"}, "start_time must be within the last"},
+		{"bad max alerts", map[string]interface{}{"max_alerts": 0.0}, "max_alerts must be"},
+		{"max alerts too large", map[string]interface{}{"max_alerts": 1e6}, "max_alerts cannot exceed 500"},
+	}
+	for _, tc := range errorCases {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := parseAlertsQuery(tc.params)
+			if err == nil || !strings.Contains(err.Error(), tc.errMsg) {
+				t.Errorf("Expected error containing %q, got %v", tc.errMsg, err)
+			}
+		})
+	}
+
+	if _, err := parseAlertRuleTypes(map[string]interface{}{"rule_type": "metric,activity_log"}); err == nil || !strings.Contains(err.Error(), "invalid rule_type: activity_log") {
+		t.Errorf("Expected rule_type error, got %v", err)
+	}
+}
@@ -0,0 +1,272 @@
+package diagnostics
+
+import (
+	"fmt"
+	"regexp"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/Azure/aks-mcp/internal/azureclient"
+	"github.com/Azure/aks-mcp/internal/components/common"
+	"github.com/Azure/aks-mcp/internal/config"
+)
+
+// Kube-audit query templates
+const (
+	AuditUserActions     = "user_actions"
+	AuditObjectMutations = "object_mutations"
+	AuditDeniedRequests  = "denied_requests"
+	AuditExecSessions    = "exec_sessions"
+	AuditSecretReads     = "secret_reads"
+)
+
+// auditTemplates maps each template to the parameters it requires; object_mutations requires a namespace or a name
+var auditTemplates = map[string][]string{
+	AuditUserActions:     {"user"},
+	AuditObjectMutations: nil,
+	AuditDeniedRequests:  nil,
+	AuditExecSessions:    nil,
+	AuditSecretReads:     nil,
+}
+
+// auditReadTemplates are the templates that need read requests, which kube-audit-admin does not log
+var auditReadTemplates = []string{AuditSecretReads}
+
+// auditUserPattern matches Kubernetes usernames: Entra ID user principal names and object IDs,
+// service accounts (system:serviceaccount:<namespace>:<name>) and other system users
+var auditUserPattern = regexp.MustCompile(`^[A-Za-z0-9]([-A-Za-z0-9@._:]*[A-Za-z0-9])?$`)
+
+// auditObjectNamePattern matches Kubernetes object names, including RBAC names such as system:controller:job-controller
+var auditObjectNamePattern = regexp.MustCompile(`^[a-z0-9]([-a-z0-9.:]*[a-z0-9])?$`)
+
+// auditColumns extracts the audit event fields the templates use from each table into the same columns
+var auditColumns = map[TableMode]string{
+	// Resource-specific tables have a column per top-level audit event field
+	ResourceSpecificMode: " | extend Username = tostring(User.username), Namespace = tostring(ObjectRef.namespace)," +
+		" Resource = tostring(ObjectRef.resource), Name = tostring(ObjectRef.name), Subresource = tostring(ObjectRef.subresource)," +
+		" Code = toint(ResponseStatus.code), StatusMessage = tostring(ResponseStatus.message), SourceIP = tostring(SourceIps[0])",
+	// AzureDiagnostics stores the audit event as JSON in log_s
+	AzureDiagnosticsMode: " | extend Event = parse_json(log_s)" +
+		" | extend AuditId = tostring(Event.auditID), Stage = tostring(Event.stage), Verb = tostring(Event.verb)," +
+		" RequestUri = tostring(Event.requestURI), UserAgent = tostring(Event.userAgent), Username = tostring(Event.user.username)," +
+		" Namespace = tostring(Event.objectRef.namespace), Resource = tostring(Event.objectRef.resource), Name = tostring(Event.objectRef.name)," +
+		" Subresource = tostring(Event.objectRef.subresource), Code = toint(Event.responseStatus.code)," +
+		" StatusMessage = tostring(Event.responseStatus.message), SourceIP = tostring(Event.sourceIPs[0])",
+}
+
+// KubeAuditQuery holds the validated inputs of a kube-audit query template
+type KubeAuditQuery struct {
+	Template   string
+	User       string // username, e.g. alice@contoso.com or system:serviceaccount:<namespace>:<name>
+	Namespace  string
+	Resource   string // plural resource name, e.g. deployments
+	Name       string // object name
+	MaxRecords int
+}
+
+// GetSupportedAuditTemplates returns the names of the kube-audit query templates
+func GetSupportedAuditTemplates() []string {
+	templates := make([]string, 0, len(auditTemplates))
+	for template := range auditTemplates {
+		templates = append(templates, template)
+	}
+	sort.Strings(templates)
+	return templates
+}
+
+// Validate checks the template, its required parameters and every value that is placed in the query
+func (q KubeAuditQuery) Validate() error {
+	required, ok := auditTemplates[q.Template]
+	if !ok {
+		return fmt.Errorf("invalid template '%s'. Valid templates: %s", q.Template, strings.Join(GetSupportedAuditTemplates(), ", "))
+	}
+	if q.Template == AuditObjectMutations && q.Namespace == "" && q.Name == "" {
+		return fmt.Errorf("missing namespace or name parameter, required by the %s template", q.Template)
+	}
+
+	values := []struct {
+		param, value string
+		pattern      *regexp.Regexp
+		description  string
+	}{
+		{"user", q.User, auditUserPattern, "a Kubernetes username"},
+		{"namespace", q.Namespace, kubernetesNamePattern, "a valid Kubernetes name"},
+		{"resource", q.Resource, kubernetesNamePattern, "a plural resource name such as deployments"},
+		{"name", q.Name, auditObjectNamePattern, "a valid Kubernetes name"},
+	}
+	for _, v := range values {
+		if v.value == "" {
+			if slices.Contains(required, v.param) {
+				return fmt.Errorf("missing %s parameter, required by the %s template", v.param, q.Template)
+			}
+			continue
+		}
+		if len(v.value) > 253 || !v.pattern.MatchString(v.value) {
+			return fmt.Errorf("invalid %s '%s': must be %s", v.param, v.value, v.description)
+		}
+	}
+
+	if q.MaxRecords < MinMaxRecords || q.MaxRecords > MaxMaxRecords {
+		return fmt.Errorf("maxRecords must be between %d and %d, got %d", MinMaxRecords, MaxMaxRecords, q.MaxRecords)
+	}
+
+	return nil
+}
+
+// BuildKubeAuditQuery builds a pre-validated KQL query from a kube-audit template, scoped to the given AKS cluster,
+// for the kube-audit or kube-audit-admin category in either table mode. Every value placed in the query is validated
+// first to prevent injection.
+func BuildKubeAuditQuery(q KubeAuditQuery, category, clusterResourceID string, isResourceSpecific bool) (string, error) {
+	if err := q.Validate(); err != nil {
+		return "", fmt.Errorf("invalid kube-audit query parameters: %w", err)
+	}
+	if !auditCategories[category] {
+		return "", fmt.Errorf("invalid audit log category '%s'. Valid categories: kube-audit, kube-audit-admin", category)
+	}
+	if strings.ContainsAny(clusterResourceID, `'"\`) {
+		return "", fmt.Errorf("invalid clusterResourceID format. Expected format: /subscriptions/{subscription-id}/resourceGroups/{resource-group}/providers/Microsoft.ContainerService/managedClusters/{cluster-name}")
+	}
+
+	tableMode := AzureDiagnosticsMode
+	if isResourceSpecific {
+		tableMode = ResourceSpecificMode
+	}
+	builder, err := NewKQLQueryBuilder(category, "", q.MaxRecords, clusterResourceID, tableMode)
+	if err != nil {
+		return "", fmt.Errorf("failed to create KQL query builder: %w", err)
+	}
+	if err := builder.determineTableStrategy(); err != nil {
+		return "", err
+	}
+	base, err := builder.buildBaseQuery()
+	if err != nil {
+		return "", err
+	}
+
+	// Each request is logged once per stage; ResponseComplete carries the response code
+	query := base + auditColumns[tableMode] + " | where Stage == 'ResponseComplete'"
+	filter := func(column, value string) string {
+		if value == "" {
+			return ""
+		}
+		return fmt.Sprintf(" | where %s == '%s'", column, value)
+	}
+	query += filter("Username", q.User) + filter("Namespace", q.Namespace) + filter("Resource", q.Resource) + filter("Name", q.Name)
+	limit := fmt.Sprintf(" | limit %d", q.MaxRecords)
+
+	switch q.Template {
+	case AuditUserActions:
+		return query +
+			" | summarize Requests = count(), Failed = countif(Code >= 400), FirstSeen = min(TimeGenerated), LastSeen = max(TimeGenerated)" +
+			" by Verb, Namespace, Resource, Subresource, Username" +
+			" | order by LastSeen desc" + limit, nil
+
+	case AuditObjectMutations:
+		return query +
+			" | where Verb in ('create', 'update', 'patch', 'delete', 'deletecollection')" +
+			" | order by TimeGenerated desc" + limit +
+			" | project TimeGenerated, Verb, Username, Namespace, Resource, Name, Subresource, Code, UserAgent, SourceIP, AuditId", nil
+
+	case AuditDeniedRequests:
+		return query +
+			" | where Code == 403" +
+			" | summarize Denied = count(), FirstSeen = min(TimeGenerated), LastSeen = max(TimeGenerated), Message = take_any(StatusMessage)" +
+			" by Username, Verb, Namespace, Resource, Subresource" +
+			" | order by Denied desc" + limit, nil
+
+	case AuditExecSessions:
+		// The command of exec requests is in the request URI, e.g. ?command=sh&stdin=true
+		return query +
+			" | where Resource == 'pods' and Subresource in ('exec', 'attach', 'portforward')" +
+			" | order by TimeGenerated desc" + limit +
+			" | project TimeGenerated, Username, Namespace, Pod = Name, Subresource, Code, SourceIP, UserAgent, RequestUri", nil
+
+	case AuditSecretReads:
+		return query +
+			" | where Resource == 'secrets' and Verb in ('get', 'list', 'watch')" +
+			" | summarize Reads = count(), FirstSeen = min(TimeGenerated), LastSeen = max(TimeGenerated)" +
+			" by Username, Verb, Namespace, Name" +
+			" | order by LastSeen desc" + limit, nil
+
+	default:
+		// This should never happen if validation is working correctly
+		return "", fmt.Errorf("no query for template '%s'", q.Template)
+	}
+}
+
+// findAuditDiagnosticSetting finds the diagnostic setting that sends the audit category to query.
+// Without log_category, kube-audit is preferred and kube-audit-admin is used for templates that do not need reads.
+func findAuditDiagnosticSetting(subscriptionID, resourceGroup, clusterName, template, category string, azClient *azureclient.AzureClient, cfg *config.ConfigData) (string, string, bool, error) {
+	needsReads := slices.Contains(auditReadTemplates, template)
+	switch category {
+	case "", "kube-audit":
+	case "kube-audit-admin":
+		if needsReads {
+			return "", "", false, fmt.Errorf("the %s template needs the kube-audit category, kube-audit-admin does not log read requests", template)
+		}
+	default:
+		return "", "", false, fmt.Errorf("invalid log_category '%s'. Valid categories: kube-audit, kube-audit-admin", category)
+	}
+
+	if category == "" {
+		workspaceResourceID, isResourceSpecific, err := FindDiagnosticSettingForCategory(subscriptionID, resourceGroup, clusterName, "kube-audit", azClient, cfg)
+		if err == nil {
+			return workspaceResourceID, "kube-audit", isResourceSpecific, nil
+		}
+		if needsReads {
+			return "", "", false, fmt.Errorf("%w: the %s template needs read requests, which only kube-audit logs", err, template)
+		}
+		category = "kube-audit-admin"
+	}
+
+	workspaceResourceID, isResourceSpecific, err := FindDiagnosticSettingForCategory(subscriptionID, resourceGroup, clusterName, category, azClient, cfg)
+	return workspaceResourceID, category, isResourceSpecific, err
+}
+
+// HandleKubeAuditQuery runs a kube-audit query template against the workspace of the cluster's audit diagnostic setting
+func HandleKubeAuditQuery(params map[string]interface{}, azClient *azureclient.AzureClient, cfg *config.ConfigData) (string, error) {
+	subscriptionID, resourceGroup, clusterName, err := common.ExtractAKSParameters(params)
+	if err != nil {
+		return "", err
+	}
+
+	timeRange, err := ResolveTimeRange(params)
+	if err != nil {
+		return "", err
+	}
+
+	template, _ := params["template"].(string)
+	query := KubeAuditQuery{Template: template, MaxRecords: GetMaxRecords(params)}
+	query.User, _ = params["user"].(string)
+	query.Namespace, _ = params["namespace"].(string)
+	query.Resource, _ = params["resource"].(string)
+	query.Name, _ = params["name"].(string)
+	if err := query.Validate(); err != nil {
+		return "", fmt.Errorf("invalid kube-audit query parameters: %w", err)
+	}
+
+	category, _ := params["log_category"].(string)
+	workspaceResourceID, category, isResourceSpecific, err := findAuditDiagnosticSetting(subscriptionID, resourceGroup, clusterName, template, category, azClient, cfg)
+	if err != nil {
+		return "", fmt.Errorf("failed to find audit diagnostic setting in cluster %s: %w", clusterName, err)
+	}
+
+	clusterResourceID := buildClusterResourceID(subscriptionID, resourceGroup, clusterName)
+	kqlQuery, err := BuildKubeAuditQuery(query, category, clusterResourceID, isResourceSpecific)
+	if err != nil {
+		return "", err
+	}
+
+	workspaceGUID, err := GetWorkspaceGUID(workspaceResourceID, cfg)
+	if err != nil {
+		return "", fmt.Errorf("failed to get workspace GUID for cluster %s: %w", clusterName, err)
+	}
+
+	result, err := RunLogAnalyticsQuery(workspaceGUID, kqlQuery, timeRange.Timespan(), cfg)
+	if err != nil {
+		return "", fmt.Errorf("failed to run kube-audit query %s on %s in cluster %s: %w", template, category, clusterName, err)
+	}
+
+	return common.WithTimeRange(result, timeRange)
+}
@@ -0,0 +1,193 @@
+package diagnostics
+
+import (
+	"encoding/json"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/Azure/aks-mcp/internal/azureclient/fakearm"
+	"github.com/Azure/aks-mcp/internal/azureclient/fakearm/armtest"
+	"github.com/Azure/aks-mcp/internal/command"
+)
+
+func TestBuildKubeAuditQuery(t *testing.T) {
+	tests := []struct {
+		name               string
+		query              KubeAuditQuery
+		category           string
+		isResourceSpecific bool
+		expectedContains   []string
+		notExpected        []string
+	}{
+		{
+			name:               "service account actions in resource-specific tables",
+			query:              KubeAuditQuery{Template: AuditUserActions, User: "system:serviceaccount:default:deployer", MaxRecords: 100},
+			category:           "kube-audit",
+			isResourceSpecific: true,
+			expectedContains: []string{
+				"AKSAudit | where _ResourceId == '" + testClusterResourceID + "'",
+				"extend Username = tostring(User.username)",
+				"where Stage == 'ResponseComplete'",
+				"where Username == 'system:serviceaccount:default:deployer'",
+				"by Verb, Namespace, Resource, Subresource, Username",
+				"limit 100",
+			},
+			notExpected: []string{"log_s", "where Namespace =="},
+		},
+		{
+			name:     "user actions in AzureDiagnostics",
+			query:    KubeAuditQuery{Template: AuditUserActions, User: "alice@contoso.com", MaxRecords: 100},
+			category: "kube-audit",
+			expectedContains: []string{
+				"AzureDiagnostics | where Category == 'kube-audit' and ResourceId == '" + strings.ToUpper(testClusterResourceID) + "'",
+				"extend Event = parse_json(log_s)",
+				"Username = tostring(Event.user.username)",
+				"where Username == 'alice@contoso.com'",
+			},
+		},
+		{
+			name:               "mutations to an object in kube-audit-admin",
+			query:              KubeAuditQuery{Template: AuditObjectMutations, Namespace: "default", Resource: "deployments", Name: "web", MaxRecords: 50},
+			category:           "kube-audit-admin",
+			isResourceSpecific: true,
+			expectedContains: []string{
+				"AKSAuditAdmin | where _ResourceId ==",
+				"where Namespace == 'default' | where Resource == 'deployments' | where Name == 'web'",
+				"where Verb in ('create', 'update', 'patch', 'delete', 'deletecollection')",
+				"order by TimeGenerated desc | limit 50",
+			},
+		},
+		{
+			name:     "denied requests",
+			query:    KubeAuditQuery{Template: AuditDeniedRequests, MaxRecords: 100},
+			category: "kube-audit-admin",
+			expectedContains: []string{
+				"AzureDiagnostics | where Category == 'kube-audit-admin'",
+				"Code = toint(Event.responseStatus.code)",
+				"where Code == 403",
+				"Message = take_any(StatusMessage) by Username, Verb, Namespace, Resource, Subresource",
+			},
+		},
+		{
+			name:               "exec sessions in a namespace",
+			query:              KubeAuditQuery{Template: AuditExecSessions, Namespace: "kube-system", MaxRecords: 100},
+			category:           "kube-audit",
+			isResourceSpecific: true,
+			expectedContains: []string{
+				"where Resource == 'pods' and Subresource in ('exec', 'attach', 'portforward')",
+				"project TimeGenerated, Username, Namespace, Pod = Name, Subresource, Code, SourceIP, UserAgent, RequestUri",
+			},
+		},
+		{
+			name:     "secret reads",
+			query:    KubeAuditQuery{Template: AuditSecretReads, Namespace: "default", MaxRecords: 100},
+			category: "kube-audit",
+			expectedContains: []string{
+				"where Resource == 'secrets' and Verb in ('get', 'list', 'watch')",
+				"summarize Reads = count()",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			query, err := BuildKubeAuditQuery(tt.query, tt.category, testClusterResourceID, tt.isResourceSpecific)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			for _, expected := range tt.expectedContains {
+				if !strings.Contains(query, expected) {
+					t.Errorf("Expected query to contain '%s', got: %s", expected, query)
+				}
+			}
+			for _, notExpected := range tt.notExpected {
+				if strings.Contains(query, notExpected) {
+					t.Errorf("Expected query not to contain '%s', got: %s", notExpected, query)
+				}
+			}
+		})
+	}
+}
+
+func TestBuildKubeAuditQueryValidation(t *testing.T) {
+	tests := []struct {
+		name     string
+		query    KubeAuditQuery
+		category string
+		errMsg   string
+	}{
+		{"unknown template", KubeAuditQuery{Template: "everything", MaxRecords: 100}, "kube-audit", "invalid template 'everything'"},
+		{"user actions without user", KubeAuditQuery{Template: AuditUserActions, MaxRecords: 100}, "kube-audit", "missing user parameter"},
+		{"mutations without namespace or name", KubeAuditQuery{Template: AuditObjectMutations, Resource: "deployments", MaxRecords: 100}, "kube-audit", "missing namespace or name"},
+		{"user injection", KubeAuditQuery{Template: AuditUserActions, User: "alice' or 1==1 //", MaxRecords: 100}, "kube-audit", "invalid user"},
+		{"resource with pipe", KubeAuditQuery{Template: AuditDeniedRequests, Resource: "pods | take 1", MaxRecords: 100}, "kube-audit", "invalid resource"},
+		{"name with quote", KubeAuditQuery{Template: AuditObjectMutations, Name: "web'", MaxRecords: 100}, "kube-audit", "invalid name"},
+		{"too many records", KubeAuditQuery{Template: AuditExecSessions, MaxRecords: MaxMaxRecords + 1}, "kube-audit", "maxRecords must be between"},
+		{"not an audit category", KubeAuditQuery{Template: AuditExecSessions, MaxRecords: 100}, "kube-apiserver", "invalid audit log category"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := BuildKubeAuditQuery(tt.query, tt.category, testClusterResourceID, true)
+			if err == nil || !strings.Contains(err.Error(), tt.errMsg) {
+				t.Errorf("Expected error containing '%s', got %v", tt.errMsg, err)
+			}
+		})
+	}
+}
+
+func TestHandleKubeAuditQuery(t *testing.T) {
+	stop, err := command.UseCassette(filepath.Join("testdata", "cassettes", "kube_audit.json"))
+	if err != nil {
+		t.Fatalf("Failed to load cassette: %v", err)
+	}
+	defer func() {
+		if err := stop(); err != nil {
+			t.Errorf("Failed to save cassette: %v", err)
+		}
+	}()
+
+	// The default cluster fixture sends kube-audit-admin, but not kube-audit, to resource-specific tables
+	_, client, cfg := armtest.NewClient(t)
+
+	params := map[string]interface{}{
+		"subscription_id": fakearm.SubscriptionID,
+		"resource_group":  fakearm.ResourceGroup,
+		"cluster_name":    fakearm.ClusterName,
+		"template":        AuditObjectMutations,
+		"namespace":       "default",
+		"resource":        "deployments",
+		"start_time":      "2026-03-01T11:00:00Z",
+		"end_time":        "2026-03-01T12:00:00Z",
+		"max_records":     "20",
+	}
+
+	result, err := HandleKubeAuditQuery(params, client, cfg)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	var response struct {
+		Result []map[string]interface{} `json:"result"`
+	}
+	if err := json.Unmarshal([]byte(result), &response); err != nil {
+		t.Fatalf("Expected JSON rows, got %q: %v", result, err)
+	}
+	if rows := response.Result; len(rows) != 1 || rows[0]["Verb"] != "patch" || rows[0]["Username"] != "alice@contoso.com" {
+		t.Errorf("Unexpected rows: %v", rows)
+	}
+
+	t.Run("secret reads need kube-audit", func(t *testing.T) {
+		params["template"] = AuditSecretReads
+		_, err := HandleKubeAuditQuery(params, client, cfg)
+		if err == nil || !strings.Contains(err.Error(), "only kube-audit logs") {
+			t.Errorf("Expected kube-audit error, got %v", err)
+		}
+
+		params["log_category"] = "kube-audit-admin"
+		_, err = HandleKubeAuditQuery(params, client, cfg)
+		if err == nil || !strings.Contains(err.Error(), "does not log read requests") {
+			t.Errorf("Expected kube-audit-admin error, got %v", err)
+		}
+	})
+}
@@ -0,0 +1,358 @@
+package diagnostics
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/Azure/aks-mcp/internal/azureclient"
+	"github.com/Azure/aks-mcp/internal/components/common"
+	"github.com/Azure/aks-mcp/internal/config"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/to"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/monitor/armmonitor"
+)
+
+// Table modes of a diagnostic setting's Log Analytics destination
+const (
+	TableModeDedicated        = "dedicated"         // resource-specific tables such as AKSAudit
+	TableModeAzureDiagnostics = "azure_diagnostics" // the shared AzureDiagnostics table
+)
+
+// DefaultDiagnosticSettingName is the name of the diagnostic setting created when none sends logs to the workspace
+const DefaultDiagnosticSettingName = "aks-mcp-diagnostics"
+
+// categoryGroups maps the AKS diagnostic setting category groups to the log categories they enable
+var categoryGroups = map[string][]string{
+	"allLogs": controlPlaneLogCategories,
+	"audit":   {"kube-audit", "kube-audit-admin", "guard"},
+}
+
+// workspaceResourceIDPattern matches Log Analytics workspace resource IDs
+var workspaceResourceIDPattern = regexp.MustCompile(`(?i)^/subscriptions/[a-zA-Z0-9-]+/resourcegroups/[^/]+/providers/microsoft\.operationalinsights/workspaces/[^/]+$`)
+
+// diagnosticSettingNamePattern matches diagnostic setting names
+var diagnosticSettingNamePattern = regexp.MustCompile(`^[A-Za-z0-9][-A-Za-z0-9_.]{0,259}$`)
+
+// DiagnosticSettingSummary describes the Log Analytics destination and enabled log categories of a diagnostic setting
+type DiagnosticSettingSummary struct {
+	Name        string   `json:"name"`
+	WorkspaceID string   `json:"workspace_id,omitempty"`
+	TableMode   string   `json:"table_mode"`
+	Categories  []string `json:"categories"`
+}
+
+// DiagnosticsConfigureResult is the response of the diagnostics_configure operation
+type DiagnosticsConfigureResult struct {
+	DryRun          bool                      `json:"dry_run"`
+	Action          string                    `json:"action"` // create, update or none
+	Previous        *DiagnosticSettingSummary `json:"previous,omitempty"`
+	Setting         DiagnosticSettingSummary  `json:"setting"`
+	AddedCategories []string                  `json:"added_categories,omitempty"`
+	Messages        []string                  `json:"messages,omitempty"`
+}
+
+// diagnosticsConfigureParams are the validated parameters of the diagnostics_configure operation
+type diagnosticsConfigureParams struct {
+	Categories  []string
+	WorkspaceID string
+	TableMode   string
+	SettingName string
+	DryRun      bool
+}
+
+// parseDiagnosticsConfigureParams validates the categories, destination and table mode to configure
+func parseDiagnosticsConfigureParams(params map[string]interface{}) (*diagnosticsConfigureParams, error) {
+	p := &diagnosticsConfigureParams{}
+
+	var categories []string
+	switch value := params["categories"].(type) {
+	case string:
+		categories = strings.Split(value, ",")
+	case []interface{}:
+		for _, item := range value {
+			categories = append(categories, fmt.Sprint(item))
+		}
+	}
+	for _, category := range categories {
+		category = strings.TrimSpace(category)
+		if category == "" || slices.Contains(p.Categories, category) {
+			continue
+		}
+		if !slices.Contains(controlPlaneLogCategories, category) {
+			return nil, fmt.Errorf("invalid log category: %s. Valid categories: %s", category, strings.Join(controlPlaneLogCategories, ", "))
+		}
+		p.Categories = append(p.Categories, category)
+	}
+	if len(p.Categories) == 0 {
+		return nil, fmt.Errorf("missing categories parameter, e.g. kube-audit-admin,kube-apiserver")
+	}
+
+	p.WorkspaceID, _ = params["workspace_id"].(string)
+	if p.WorkspaceID = strings.TrimSpace(p.WorkspaceID); p.WorkspaceID != "" && !workspaceResourceIDPattern.MatchString(p.WorkspaceID) {
+		return nil, fmt.Errorf("invalid workspace_id format. Expected format: /subscriptions/{subscription-id}/resourceGroups/{resource-group}/providers/Microsoft.OperationalInsights/workspaces/{workspace-name}")
+	}
+
+	p.TableMode, _ = params["table_mode"].(string)
+	switch p.TableMode = strings.ToLower(strings.TrimSpace(p.TableMode)); p.TableMode {
+	case "", TableModeDedicated, TableModeAzureDiagnostics:
+	default:
+		return nil, fmt.Errorf("invalid table_mode: %s. Valid modes: %s, %s", p.TableMode, TableModeDedicated, TableModeAzureDiagnostics)
+	}
+
+	p.SettingName, _ = params["setting_name"].(string)
+	if p.SettingName = strings.TrimSpace(p.SettingName); p.SettingName != "" && !diagnosticSettingNamePattern.MatchString(p.SettingName) {
+		return nil, fmt.Errorf("invalid setting_name '%s': use letters, digits, hyphens, underscores and periods", p.SettingName)
+	}
+
+	switch dryRun := params["dry_run"].(type) {
+	case bool:
+		p.DryRun = dryRun
+	case string:
+		p.DryRun = strings.EqualFold(dryRun, "true")
+	}
+
+	return p, nil
+}
+
+// summarizeDiagnosticSetting returns the destination and enabled log categories of a diagnostic setting,
+// expanding category groups
+func summarizeDiagnosticSetting(setting *armmonitor.DiagnosticSettingsResource) DiagnosticSettingSummary {
+	summary := DiagnosticSettingSummary{TableMode: TableModeAzureDiagnostics, Categories: []string{}}
+	if setting.Name != nil {
+		summary.Name = *setting.Name
+	}
+	if setting.Properties == nil {
+		return summary
+	}
+
+	if setting.Properties.WorkspaceID != nil {
+		summary.WorkspaceID = *setting.Properties.WorkspaceID
+	}
+	if setting.Properties.LogAnalyticsDestinationType != nil && strings.EqualFold(*setting.Properties.LogAnalyticsDestinationType, "Dedicated") {
+		summary.TableMode = TableModeDedicated
+	}
+	for _, logConfig := range setting.Properties.Logs {
+		if logConfig == nil || logConfig.Enabled == nil || !*logConfig.Enabled {
+			continue
+		}
+		enabled := []string{}
+		if logConfig.Category != nil {
+			enabled = append(enabled, *logConfig.Category)
+		} else if logConfig.CategoryGroup != nil {
+			for group, categories := range categoryGroups {
+				if strings.EqualFold(group, *logConfig.CategoryGroup) {
+					enabled = categories
+				}
+			}
+		}
+		for _, category := range enabled {
+			if !slices.Contains(summary.Categories, category) {
+				summary.Categories = append(summary.Categories, category)
+			}
+		}
+	}
+	return summary
+}
+
+// usesCategoryGroups reports whether a diagnostic setting enables logs by category group, which cannot be mixed with categories
+func usesCategoryGroups(setting *armmonitor.DiagnosticSettingsResource) bool {
+	if setting.Properties == nil {
+		return false
+	}
+	return slices.ContainsFunc(setting.Properties.Logs, func(logConfig *armmonitor.LogSettings) bool {
+		return logConfig != nil && logConfig.CategoryGroup != nil
+	})
+}
+
+// selectDiagnosticSetting returns the existing diagnostic setting to update: the one named setting_name, or else
+// the one sending logs to the requested workspace, or else the only one sending logs to a workspace. A setting
+// already named like the one that would be created is updated instead, since creating it would replace it. It returns
+// nil when a new setting should be created.
+func selectDiagnosticSetting(settings []*armmonitor.DiagnosticSettingsResource, p *diagnosticsConfigureParams) (*armmonitor.DiagnosticSettingsResource, error) {
+	var withWorkspace []*armmonitor.DiagnosticSettingsResource
+	var defaultSetting *armmonitor.DiagnosticSettingsResource
+	for _, setting := range settings {
+		if setting == nil {
+			continue
+		}
+		summary := summarizeDiagnosticSetting(setting)
+		if strings.EqualFold(summary.Name, DefaultDiagnosticSettingName) {
+			defaultSetting = setting
+		}
+		if p.SettingName != "" {
+			if strings.EqualFold(summary.Name, p.SettingName) {
+				return setting, nil
+			}
+			continue
+		}
+		if summary.WorkspaceID == "" {
+			continue
+		}
+		if p.WorkspaceID != "" && strings.EqualFold(summary.WorkspaceID, p.WorkspaceID) {
+			return setting, nil
+		}
+		withWorkspace = append(withWorkspace, setting)
+	}
+
+	if p.SettingName != "" {
+		return nil, nil
+	}
+	if p.WorkspaceID != "" {
+		return defaultSetting, nil
+	}
+	switch len(withWorkspace) {
+	case 0:
+		return nil, fmt.Errorf("missing workspace_id parameter: the cluster has no diagnostic setting sending logs to a Log Analytics workspace")
+	case 1:
+		return withWorkspace[0], nil
+	default:
+		names := make([]string, 0, len(withWorkspace))
+		for _, setting := range withWorkspace {
+			names = append(names, summarizeDiagnosticSetting(setting).Name)
+		}
+		return nil, fmt.Errorf("the cluster has several diagnostic settings sending logs to a workspace (%s), set setting_name or workspace_id", strings.Join(names, ", "))
+	}
+}
+
+// planDiagnosticSetting builds the desired diagnostic setting from the existing one, if any, keeping its other
+// destinations, metrics and enabled categories
+func planDiagnosticSetting(existing *armmonitor.DiagnosticSettingsResource, p *diagnosticsConfigureParams) (armmonitor.DiagnosticSettingsResource, *DiagnosticsConfigureResult, error) {
+	result := &DiagnosticsConfigureResult{DryRun: p.DryRun, Action: "create"}
+	desired := armmonitor.DiagnosticSettingsResource{
+		Name:       to.Ptr(DefaultDiagnosticSettingName),
+		Properties: &armmonitor.DiagnosticSettings{},
+	}
+	if p.SettingName != "" {
+		desired.Name = to.Ptr(p.SettingName)
+	}
+
+	var enabled []string
+	if existing != nil {
+		previous := summarizeDiagnosticSetting(existing)
+		result.Previous = &previous
+		result.Action = "update"
+		enabled = previous.Categories
+		desired.Name = existing.Name
+		if existing.Properties != nil {
+			properties := *existing.Properties
+			properties.Logs = slices.Clone(properties.Logs)
+			desired.Properties = &properties
+		}
+	}
+
+	for _, category := range p.Categories {
+		if !slices.Contains(enabled, category) {
+			result.AddedCategories = append(result.AddedCategories, category)
+		}
+	}
+	if len(result.AddedCategories) > 0 && existing != nil && usesCategoryGroups(existing) {
+		return desired, nil, fmt.Errorf("diagnostic setting %s enables logs by category group, which cannot be combined with categories: set setting_name to create a separate setting for %s",
+			*existing.Name, strings.Join(result.AddedCategories, ", "))
+	}
+	for _, category := range result.AddedCategories {
+		found := false
+		for i, logConfig := range desired.Properties.Logs {
+			if logConfig != nil && logConfig.Category != nil && *logConfig.Category == category {
+				updated := *logConfig
+				updated.Enabled = to.Ptr(true)
+				desired.Properties.Logs[i] = &updated
+				found = true
+			}
+		}
+		if !found {
+			desired.Properties.Logs = append(desired.Properties.Logs, &armmonitor.LogSettings{Category: to.Ptr(category), Enabled: to.Ptr(true)})
+		}
+	}
+
+	if p.WorkspaceID != "" {
+		desired.Properties.WorkspaceID = to.Ptr(p.WorkspaceID)
+	}
+	if desired.Properties.WorkspaceID == nil || *desired.Properties.WorkspaceID == "" {
+		return desired, nil, fmt.Errorf("missing workspace_id parameter: diagnostic setting %s has no Log Analytics workspace", *desired.Name)
+	}
+
+	tableMode := p.TableMode
+	if tableMode == "" && existing == nil {
+		tableMode = TableModeDedicated
+	}
+	switch tableMode {
+	case TableModeDedicated:
+		desired.Properties.LogAnalyticsDestinationType = to.Ptr("Dedicated")
+	case TableModeAzureDiagnostics:
+		desired.Properties.LogAnalyticsDestinationType = nil
+	}
+
+	result.Setting = summarizeDiagnosticSetting(&desired)
+	if previous := result.Previous; previous != nil {
+		if !strings.EqualFold(previous.WorkspaceID, result.Setting.WorkspaceID) {
+			result.Messages = append(result.Messages, fmt.Sprintf("logs will be sent to %s instead of %s", result.Setting.WorkspaceID, previous.WorkspaceID))
+		} else if previous.TableMode == result.Setting.TableMode && len(result.AddedCategories) == 0 {
+			result.Action = "none"
+			result.Messages = append(result.Messages, "the categories are already enabled")
+		}
+		if previous.TableMode != result.Setting.TableMode {
+			result.Messages = append(result.Messages, fmt.Sprintf("new logs will be written to %s tables; logs already collected stay in the %s tables", result.Setting.TableMode, previous.TableMode))
+		}
+	}
+
+	return desired, result, nil
+}
+
+// HandleDiagnosticsConfigure enables control plane log categories on a diagnostic setting of the cluster, creating
+// the setting when none sends logs to the workspace. With dry_run, it returns the planned change without applying it.
+func HandleDiagnosticsConfigure(params map[string]interface{}, azClient *azureclient.AzureClient, cfg *config.ConfigData) (string, error) {
+	subscriptionID, resourceGroup, clusterName, err := common.ExtractAKSParameters(params)
+	if err != nil {
+		return "", err
+	}
+
+	p, err := parseDiagnosticsConfigureParams(params)
+	if err != nil {
+		return "", err
+	}
+
+	// Azure client is required
+	if azClient == nil {
+		return "", fmt.Errorf("azure client is required but not provided")
+	}
+
+	ctx, err := common.TenantContext(context.Background(), azClient, params)
+	if err != nil {
+		return "", err
+	}
+	// The settings are read uncached, so the plan is made against the settings the write will replace
+	clusterResourceID := buildClusterResourceID(subscriptionID, resourceGroup, clusterName)
+	settings, err := azClient.ListDiagnosticSettings(ctx, subscriptionID, clusterResourceID)
+	if err != nil {
+		return "", fmt.Errorf("failed to get diagnostic settings for cluster %s in resource group %s: %w", clusterName, resourceGroup, err)
+	}
+
+	existing, err := selectDiagnosticSetting(settings, p)
+	if err != nil {
+		return "", err
+	}
+	desired, result, err := planDiagnosticSetting(existing, p)
+	if err != nil {
+		return "", err
+	}
+
+	if !p.DryRun && result.Action != "none" {
+		applied, err := azClient.CreateOrUpdateDiagnosticSetting(ctx, subscriptionID, clusterResourceID, *desired.Name, armmonitor.DiagnosticSettingsResource{Properties: desired.Properties})
+		if err != nil {
+			return "", fmt.Errorf("failed to configure diagnostic settings for cluster %s: %w", clusterName, err)
+		}
+		if applied.Name == nil {
+			applied.Name = desired.Name
+		}
+		result.Setting = summarizeDiagnosticSetting(applied)
+	}
+
+	resultJSON, err := json.MarshalIndent(result, "", "  ")
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal diagnostics configuration result to JSON: %w", err)
+	}
+	return string(resultJSON), nil
+}
@@ -0,0 +1,234 @@
+package diagnostics
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/Azure/aks-mcp/internal/azureclient"
+	"github.com/Azure/aks-mcp/internal/azureclient/fakearm"
+	"github.com/Azure/aks-mcp/internal/azureclient/fakearm/armtest"
+	"github.com/Azure/aks-mcp/internal/config"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/to"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/monitor/armmonitor"
+)
+
+const (
+	testDiagnosticSettingsPath = fakearm.ClusterResourceID + "/providers/Microsoft.Insights/diagnosticSettings"
+	testWorkspaceID            = "/subscriptions/" + fakearm.SubscriptionID + "/resourceGroups/test-rg/providers/Microsoft.OperationalInsights/workspaces/test-workspace"
+)
+
+// runDiagnosticsConfigure runs diagnostics_configure against the default cluster fixture, whose aks-diagnostics
+// setting sends kube-apiserver and kube-audit-admin logs to test-workspace in dedicated tables
+func runDiagnosticsConfigure(t *testing.T, params map[string]interface{}) (*fakearm.Server, *azureclient.AzureClient, DiagnosticsConfigureResult, error) {
+	t.Helper()
+
+	srv, client, cfg := armtest.NewClient(t)
+
+	result, err := configureWithClient(t, client, cfg, params)
+	return srv, client, result, err
+}
+
+// configureWithClient runs diagnostics_configure against the default cluster with an existing client
+func configureWithClient(t *testing.T, client *azureclient.AzureClient, cfg *config.ConfigData, params map[string]interface{}) (DiagnosticsConfigureResult, error) {
+	t.Helper()
+
+	params["subscription_id"] = fakearm.SubscriptionID
+	params["resource_group"] = fakearm.ResourceGroup
+	params["cluster_name"] = fakearm.ClusterName
+
+	var result DiagnosticsConfigureResult
+	output, err := HandleDiagnosticsConfigure(params, client, cfg)
+	if err == nil {
+		if err := json.Unmarshal([]byte(output), &result); err != nil {
+			t.Fatalf("Failed to parse result: %v\n%s", err, output)
+		}
+	}
+	return result, err
+}
+
+func TestHandleDiagnosticsConfigure_DryRun(t *testing.T) {
+	srv, _, result, err := runDiagnosticsConfigure(t, map[string]interface{}{"categories": "kube-audit,kube-apiserver", "dry_run": "true"})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if !result.DryRun || result.Action != "update" || result.Previous == nil || result.Previous.Name != "aks-diagnostics" {
+		t.Errorf("Expected a planned update of aks-diagnostics, got %+v", result)
+	}
+	if strings.Join(result.AddedCategories, ",") != "kube-audit" {
+		t.Errorf("Expected only kube-audit to be added, got %v", result.AddedCategories)
+	}
+	if strings.Join(result.Setting.Categories, ",") != "kube-apiserver,kube-audit-admin,kube-audit" || result.Setting.TableMode != TableModeDedicated {
+		t.Errorf("Unexpected planned setting: %+v", result.Setting)
+	}
+	if count := srv.RequestCount(testDiagnosticSettingsPath + "/aks-diagnostics"); count != 0 {
+		t.Errorf("Expected no write on a dry run, got %d requests", count)
+	}
+}
+
+func TestHandleDiagnosticsConfigure_UpdatesExistingSetting(t *testing.T) {
+	srv, client, result, err := runDiagnosticsConfigure(t, map[string]interface{}{"categories": []interface{}{"kube-audit"}})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if result.DryRun || result.Action != "update" || result.Setting.Name != "aks-diagnostics" || result.Setting.WorkspaceID != testWorkspaceID {
+		t.Errorf("Expected aks-diagnostics to be updated, got %+v", result)
+	}
+	if strings.Join(result.Setting.Categories, ",") != "kube-apiserver,kube-audit-admin,kube-audit" {
+		t.Errorf("Expected the applied setting to keep its categories, got %v", result.Setting.Categories)
+	}
+	if count := srv.RequestCount(testDiagnosticSettingsPath + "/aks-diagnostics"); count != 1 {
+		t.Errorf("Expected one write, got %d requests", count)
+	}
+
+	// The cached diagnostic settings are invalidated, so the next read goes to ARM
+	listed := srv.RequestCount(testDiagnosticSettingsPath)
+	if _, err := client.GetDiagnosticSettings(context.Background(), fakearm.SubscriptionID, fakearm.ClusterResourceID); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if count := srv.RequestCount(testDiagnosticSettingsPath); count != listed+1 {
+		t.Errorf("Expected the diagnostic settings cache to be invalidated, got %d list requests after %d", count, listed)
+	}
+}
+
+func TestHandleDiagnosticsConfigure_UpdatesSettingWithDefaultName(t *testing.T) {
+	srv, client, cfg := armtest.NewClient(t)
+
+	// aks-mcp-diagnostics is created after the settings were cached, and must not be replaced as a new setting
+	otherWorkspaceID := strings.Replace(testWorkspaceID, "test-workspace", "other-workspace", 1)
+	archiveWorkspaceID := strings.Replace(testWorkspaceID, "test-workspace", "archive-workspace", 1)
+	if _, err := client.GetDiagnosticSettings(context.Background(), fakearm.SubscriptionID, fakearm.ClusterResourceID); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	settings := map[string]interface{}{"value": []interface{}{
+		map[string]interface{}{
+			"name": DefaultDiagnosticSettingName,
+			"properties": map[string]interface{}{
+				"workspaceId":                 otherWorkspaceID,
+				"logAnalyticsDestinationType": "Dedicated",
+				"logs":                        []interface{}{map[string]interface{}{"category": "guard", "enabled": true}},
+			},
+		},
+	}}
+	if err := srv.SetResponse(testDiagnosticSettingsPath, settings); err != nil {
+		t.Fatalf("Failed to set diagnostic settings: %v", err)
+	}
+
+	result, err := configureWithClient(t, client, cfg, map[string]interface{}{"categories": "kube-audit", "workspace_id": archiveWorkspaceID})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if result.Action != "update" || result.Previous == nil || result.Previous.Name != DefaultDiagnosticSettingName || result.Previous.WorkspaceID != otherWorkspaceID {
+		t.Errorf("Expected an update of %s, got %+v", DefaultDiagnosticSettingName, result)
+	}
+	if strings.Join(result.Setting.Categories, ",") != "guard,kube-audit" || result.Setting.WorkspaceID != archiveWorkspaceID {
+		t.Errorf("Expected the setting to keep guard and move to the archive workspace, got %+v", result.Setting)
+	}
+	if !strings.Contains(strings.Join(result.Messages, "\n"), "instead of "+otherWorkspaceID) {
+		t.Errorf("Expected a message about the workspace change, got %v", result.Messages)
+	}
+}
+
+func TestHandleDiagnosticsConfigure_Plans(t *testing.T) {
+	tests := []struct {
+		name        string
+		params      map[string]interface{}
+		action      string
+		settingName string
+		tableMode   string
+		categories  string
+		message     string
+	}{
+		{
+			name:        "already enabled",
+			params:      map[string]interface{}{"categories": "kube-audit-admin"},
+			action:      "none",
+			settingName: "aks-diagnostics",
+			tableMode:   TableModeDedicated,
+			categories:  "kube-apiserver,kube-audit-admin",
+			message:     "already enabled",
+		},
+		{
+			name:        "table mode change",
+			params:      map[string]interface{}{"categories": "kube-audit-admin", "table_mode": "azure_diagnostics", "dry_run": true},
+			action:      "update",
+			settingName: "aks-diagnostics",
+			tableMode:   TableModeAzureDiagnostics,
+			categories:  "kube-apiserver,kube-audit-admin",
+			message:     "new logs will be written to azure_diagnostics tables",
+		},
+		{
+			name:        "new setting",
+			params:      map[string]interface{}{"categories": "guard", "setting_name": "audit-archive", "workspace_id": testWorkspaceID, "dry_run": true},
+			action:      "create",
+			settingName: "audit-archive",
+			tableMode:   TableModeDedicated,
+			categories:  "guard",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, _, result, err := runDiagnosticsConfigure(t, tt.params)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if result.Action != tt.action || result.Setting.Name != tt.settingName || result.Setting.TableMode != tt.tableMode {
+				t.Errorf("Expected %s of %s in %s mode, got %+v", tt.action, tt.settingName, tt.tableMode, result)
+			}
+			if got := strings.Join(result.Setting.Categories, ","); got != tt.categories {
+				t.Errorf("Expected categories %s, got %s", tt.categories, got)
+			}
+			if tt.message != "" && !strings.Contains(strings.Join(result.Messages, "\n"), tt.message) {
+				t.Errorf("Expected a message containing %q, got %v", tt.message, result.Messages)
+			}
+		})
+	}
+}
+
+func TestHandleDiagnosticsConfigure_Errors(t *testing.T) {
+	tests := []struct {
+		name   string
+		params map[string]interface{}
+		errMsg string
+	}{
+		{"missing categories", map[string]interface{}{}, "missing categories"},
+		{"unknown category", map[string]interface{}{"categories": "kube-audit,everything"}, "invalid log category: everything"},
+		{"bad workspace", map[string]interface{}{"categories": "guard", "workspace_id": "test-workspace"}, "invalid workspace_id"},
+		{"bad table mode", map[string]interface{}{"categories": "guard", "table_mode": "resource_specific"}, "invalid table_mode"},
+		{"bad setting name", map[string]interface{}{"categories": "guard", "setting_name": "a/b"}, "invalid setting_name"},
+		{"new setting without workspace", map[string]interface{}{"categories": "guard", "setting_name": "audit-archive"}, "missing workspace_id"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, _, _, err := runDiagnosticsConfigure(t, tt.params)
+			if err == nil || !strings.Contains(err.Error(), tt.errMsg) {
+				t.Errorf("Expected error containing %q, got %v", tt.errMsg, err)
+			}
+		})
+	}
+}
+
+func TestPlanDiagnosticSetting_CategoryGroups(t *testing.T) {
+	existing := &armmonitor.DiagnosticSettingsResource{
+		Name: to.Ptr("audit-group"),
+		Properties: &armmonitor.DiagnosticSettings{
+			WorkspaceID: to.Ptr(testWorkspaceID),
+			Logs:        []*armmonitor.LogSettings{{CategoryGroup: to.Ptr("audit"), Enabled: to.Ptr(true)}},
+		},
+	}
+
+	_, result, err := planDiagnosticSetting(existing, &diagnosticsConfigureParams{Categories: []string{"kube-audit"}})
+	if err != nil || result.Action != "none" {
+		t.Errorf("Expected kube-audit to be enabled by the audit group, got %+v, %v", result, err)
+	}
+
+	_, _, err = planDiagnosticSetting(existing, &diagnosticsConfigureParams{Categories: []string{"kube-apiserver"}})
+	if err == nil || !strings.Contains(err.Error(), "category group") {
+		t.Errorf("Expected category group error, got %v", err)
+	}
+}
@@ -36,7 +36,10 @@ func HandleControlPlaneDiagnosticSettings(params map[string]interface{}, azClien
 	}
 
 	// Get diagnostic settings using Azure SDK
-	ctx := context.Background()
+	ctx, err := common.TenantContext(context.Background(), azClient, params)
+	if err != nil {
+		return "", err
+	}
 	diagnosticSettings, err := azClient.GetDiagnosticSettings(ctx, subscriptionID, clusterResourceID)
 	if err != nil {
 		return "", fmt.Errorf("failed to get diagnostic settings for cluster %s in resource group %s: %w", clusterName, resourceGroup, err)
@@ -61,8 +64,6 @@ func HandleControlPlaneLogs(params map[string]interface{}, azClient *azureclient
 
 	// Extract remaining parameters
 	logCategory, _ := params["log_category"].(string)
-	startTime, _ := params["start_time"].(string)
-	endTime, _ := params["end_time"].(string)
 	maxRecords := GetMaxRecords(params)
 	logLevel, _ := params["log_level"].(string)
 
@@ -71,6 +72,21 @@ func HandleControlPlaneLogs(params map[string]interface{}, azClient *azureclient
 		return "", err
 	}
 
+	// Resolve the time range of the query
+	timeRange, err := ResolveTimeRange(params)
+	if err != nil {
+		return "", err
+	}
+
+	// Summaries cover up to MaxAllowedRecords records unless max_records is set
+	summary, err := ParseLogSummaryOptions(params, timeRange)
+	if err != nil {
+		return "", err
+	}
+	if _, ok := params["max_records"]; summary != nil && !ok {
+		maxRecords = MaxAllowedRecords
+	}
+
 	// Find the diagnostic setting that has the requested log category enabled
 	// This handles cases where multiple diagnostic settings exist for the same cluster
 	workspaceResourceID, isResourceSpecific, err := FindDiagnosticSettingForCategory(subscriptionID, resourceGroup, clusterName, logCategory, azClient, cfg)
@@ -79,7 +95,7 @@ func HandleControlPlaneLogs(params map[string]interface{}, azClient *azureclient
 	}
 
 	// Get workspace GUID from the workspace resource ID
-	workspaceGUID, err := getWorkspaceGUID(workspaceResourceID, cfg)
+	workspaceGUID, err := GetWorkspaceGUID(workspaceResourceID, cfg)
 	if err != nil {
 		return "", fmt.Errorf("failed to get workspace GUID for cluster %s: %w", clusterName, err)
 	}
@@ -93,12 +109,17 @@ func HandleControlPlaneLogs(params map[string]interface{}, azClient *azureclient
 		return "", fmt.Errorf("failed to build KQL query for cluster %s: %w", clusterName, err)
 	}
 
-	// Calculate timespan for the query
-	timespan, err := CalculateTimespan(startTime, endTime)
-	if err != nil {
-		return "", fmt.Errorf("failed to calculate timespan: %w", err)
+	// Summarize the messages into patterns instead of returning every record
+	if summary != nil {
+		result, err := summarizeLogQuery(workspaceGUID, kqlQuery, maxRecords, summary, cfg)
+		if err != nil {
+			return "", fmt.Errorf("failed to summarize control plane logs for category %s in cluster %s: %w", logCategory, clusterName, err)
+		}
+		return result, nil
 	}
 
+	timespan := timeRange.Timespan()
+
 	// Execute log query with properly quoted KQL
 	executor := azcli.NewExecutor()
 
@@ -118,8 +139,8 @@ func HandleControlPlaneLogs(params map[string]interface{}, azClient *azureclient
 		return "", fmt.Errorf("failed to query control plane logs for category %s in cluster %s: %w", logCategory, clusterName, err)
 	}
 
-	// Return raw JSON result from Azure CLI
-	return result, nil
+	// Return raw JSON result from Azure CLI with the queried time range
+	return common.WithTimeRange(result, timeRange)
 }
 
 // Resource handler functions for control plane diagnostics tools
@@ -0,0 +1,296 @@
+package diagnostics
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/Azure/aks-mcp/internal/azureclient"
+	"github.com/Azure/aks-mcp/internal/components/common"
+	"github.com/Azure/aks-mcp/internal/config"
+)
+
+// Container Insights query templates
+const (
+	InsightsPodRestarts  = "pod_restarts"
+	InsightsOOMKilled    = "oom_killed"
+	InsightsContainerLog = "container_logs"
+	InsightsNodeNotReady = "node_not_ready"
+	InsightsTopConsumers = "top_consumers"
+)
+
+// MaxInsightsGrepLength is the maximum length of the grep text for container logs
+const MaxInsightsGrepLength = 256
+
+// insightsTemplates maps each template to the parameters it requires
+var insightsTemplates = map[string][]string{
+	InsightsPodRestarts:  nil,
+	InsightsOOMKilled:    nil,
+	InsightsContainerLog: {"namespace", "pod_name"},
+	InsightsNodeNotReady: nil,
+	InsightsTopConsumers: nil,
+}
+
+// topConsumerCounters maps the top_consumers sort_by value to its Container Insights performance counter
+var topConsumerCounters = map[string]string{
+	"cpu":    "cpuUsageNanoCores",
+	"memory": "memoryWorkingSetBytes",
+}
+
+// kubernetesNamePattern matches Kubernetes object names (DNS subdomains), which covers namespaces, pods, containers and nodes
+var kubernetesNamePattern = regexp.MustCompile(`^[a-z0-9]([-a-z0-9.]*[a-z0-9])?$`)
+
+// ContainerInsightsQuery holds the validated inputs of a Container Insights query template
+type ContainerInsightsQuery struct {
+	Template      string
+	Namespace     string
+	PodName       string
+	ContainerName string
+	NodeName      string
+	Grep          string
+	SortBy        string // cpu or memory, for top_consumers
+	MaxRecords    int
+}
+
+// GetSupportedInsightsTemplates returns the names of the Container Insights query templates
+func GetSupportedInsightsTemplates() []string {
+	templates := make([]string, 0, len(insightsTemplates))
+	for template := range insightsTemplates {
+		templates = append(templates, template)
+	}
+	sort.Strings(templates)
+	return templates
+}
+
+// Validate checks the template, its required parameters and every value that is placed in the query
+func (q ContainerInsightsQuery) Validate() error {
+	required, ok := insightsTemplates[q.Template]
+	if !ok {
+		return fmt.Errorf("invalid template '%s'. Valid templates: %s", q.Template, strings.Join(GetSupportedInsightsTemplates(), ", "))
+	}
+
+	names := []struct{ param, value string }{
+		{"namespace", q.Namespace},
+		{"pod_name", q.PodName},
+		{"container_name", q.ContainerName},
+		{"node_name", q.NodeName},
+	}
+	for _, name := range names {
+		if name.value == "" {
+			if slices.Contains(required, name.param) {
+				return fmt.Errorf("missing %s parameter, required by the %s template", name.param, q.Template)
+			}
+			continue
+		}
+		if len(name.value) > 253 || !kubernetesNamePattern.MatchString(name.value) {
+			return fmt.Errorf("invalid %s '%s': must be a valid Kubernetes name", name.param, name.value)
+		}
+	}
+
+	if q.Grep != "" {
+		if q.Template != InsightsContainerLog {
+			return fmt.Errorf("grep is only supported by the %s template", InsightsContainerLog)
+		}
+		if len(q.Grep) > MaxInsightsGrepLength {
+			return fmt.Errorf("grep cannot exceed %d characters", MaxInsightsGrepLength)
+		}
+		// Quotes and backslashes would end or escape the KQL string literal
+		for _, r := range q.Grep {
+			if r == '\'' || r == '"' || r == '\\' || r < 0x20 || r == 0x7f {
+				return fmt.Errorf("grep cannot contain quotes, backslashes or control characters")
+			}
+		}
+	}
+
+	if q.SortBy != "" {
+		if q.Template != InsightsTopConsumers {
+			return fmt.Errorf("sort_by is only supported by the %s template", InsightsTopConsumers)
+		}
+		if _, ok := topConsumerCounters[q.SortBy]; !ok {
+			return fmt.Errorf("invalid sort_by '%s'. Valid values: cpu, memory", q.SortBy)
+		}
+	}
+
+	if q.MaxRecords < MinMaxRecords || q.MaxRecords > MaxMaxRecords {
+		return fmt.Errorf("maxRecords must be between %d and %d, got %d", MinMaxRecords, MaxMaxRecords, q.MaxRecords)
+	}
+
+	return nil
+}
+
+// BuildContainerInsightsQuery builds a pre-validated KQL query from a Container Insights template,
+// scoped to the given AKS cluster. Every value placed in the query is validated first to prevent injection.
+func BuildContainerInsightsQuery(q ContainerInsightsQuery, clusterResourceID string) (string, error) {
+	if err := q.Validate(); err != nil {
+		return "", fmt.Errorf("invalid Container Insights query parameters: %w", err)
+	}
+	if err := ValidateClusterResourceID(clusterResourceID); err != nil {
+		return "", err
+	}
+
+	// Container Insights tables store _ResourceId with varying case, so compare case-insensitively
+	scope := fmt.Sprintf("| where _ResourceId =~ '%s'", clusterResourceID)
+	filter := func(column, value string) string {
+		if value == "" {
+			return ""
+		}
+		return fmt.Sprintf(" | where %s == '%s'", column, value)
+	}
+	limit := fmt.Sprintf(" | limit %d", q.MaxRecords)
+
+	switch q.Template {
+	case InsightsPodRestarts:
+		return "KubePodInventory " + scope +
+			filter("Namespace", q.Namespace) + filter("Name", q.PodName) + filter("Computer", q.NodeName) +
+			" | where ContainerRestartCount > 0" +
+			" | extend Container = tostring(split(ContainerName, '/')[1]), Reason = tostring(parse_json(ContainerLastStatus).reason)" +
+			filter("Container", q.ContainerName) +
+			" | summarize Restarts = max(ContainerRestartCount), LastSeen = max(TimeGenerated) by Namespace, PodName = Name, Container, Reason" +
+			" | order by Restarts desc" + limit, nil
+
+	case InsightsOOMKilled:
+		return "KubePodInventory " + scope +
+			filter("Namespace", q.Namespace) + filter("Name", q.PodName) + filter("Computer", q.NodeName) +
+			" | extend Container = tostring(split(ContainerName, '/')[1]), LastStatus = parse_json(ContainerLastStatus)" +
+			filter("Container", q.ContainerName) +
+			" | where tostring(LastStatus.reason) == 'OOMKilled'" +
+			" | summarize Restarts = max(ContainerRestartCount), LastOOMKilled = max(todatetime(LastStatus.finishedAt)) by Namespace, PodName = Name, Container, Node = Computer" +
+			" | order by LastOOMKilled desc" + limit, nil
+
+	case InsightsContainerLog:
+		query := "ContainerLogV2 " + scope +
+			filter("PodNamespace", q.Namespace) + filter("PodName", q.PodName) + filter("ContainerName", q.ContainerName) + filter("Computer", q.NodeName)
+		if q.Grep != "" {
+			query += fmt.Sprintf(" | where tostring(LogMessage) contains '%s'", q.Grep)
+		}
+		return query + " | order by TimeGenerated desc" + limit +
+			" | project TimeGenerated, PodNamespace, PodName, ContainerName, LogSource, LogMessage", nil
+
+	case InsightsNodeNotReady:
+		return "KubeNodeInventory " + scope + filter("Computer", q.NodeName) +
+			" | project TimeGenerated, Node = Computer, Status" +
+			" | order by Node asc, TimeGenerated asc" +
+			" | extend PreviousNode = prev(Node), PreviousStatus = prev(Status)" +
+			" | where Node == PreviousNode and Status != PreviousStatus and (Status has 'NotReady' or PreviousStatus has 'NotReady')" +
+			" | project TimeGenerated, Node, PreviousStatus, Status" +
+			" | order by TimeGenerated desc" + limit, nil
+
+	case InsightsTopConsumers:
+		resource := q.SortBy
+		if resource == "" {
+			resource = "cpu"
+		}
+		// Perf instance names end with <pod UID>/<container name>; pod names come from KubePodInventory
+		return "Perf " + scope + filter("Computer", q.NodeName) +
+			fmt.Sprintf(" | where ObjectName == 'K8SContainer' and CounterName == '%s'", topConsumerCounters[resource]) +
+			" | extend PodUid = tostring(split(InstanceName, '/')[-2]), Container = tostring(split(InstanceName, '/')[-1])" +
+			filter("Container", q.ContainerName) +
+			" | summarize Average = avg(CounterValue), Peak = max(CounterValue) by PodUid, Container, Node = Computer" +
+			" | join kind=inner (KubePodInventory " + scope + " | distinct PodUid, Namespace, PodName = Name) on PodUid" +
+			filter("Namespace", q.Namespace) + filter("PodName", q.PodName) +
+			" | order by Average desc" + limit +
+			fmt.Sprintf(" | project Namespace, PodName, Container, Node, Resource = '%s', Average, Peak", resource), nil
+
+	default:
+		// This should never happen if validation is working correctly
+		return "", fmt.Errorf("no query for template '%s'", q.Template)
+	}
+}
+
+// FindContainerInsightsWorkspace returns the Log Analytics workspace resource ID the cluster sends Container Insights data to
+func FindContainerInsightsWorkspace(ctx context.Context, subscriptionID, resourceGroup, clusterName string, azClient *azureclient.AzureClient) (string, error) {
+	// Azure client is required
+	if azClient == nil {
+		return "", fmt.Errorf("azure client is required but not provided")
+	}
+
+	cluster, err := azClient.GetAKSCluster(ctx, subscriptionID, resourceGroup, clusterName)
+	if err != nil {
+		return "", fmt.Errorf("failed to get cluster details: %w", err)
+	}
+
+	if cluster.Properties != nil {
+		for name, addon := range cluster.Properties.AddonProfiles {
+			if !strings.EqualFold(name, "omsagent") || addon == nil || addon.Enabled == nil || !*addon.Enabled {
+				continue
+			}
+			for key, value := range addon.Config {
+				if strings.EqualFold(key, "logAnalyticsWorkspaceResourceID") && value != nil && *value != "" {
+					return *value, nil
+				}
+			}
+		}
+	}
+
+	return "", fmt.Errorf("cluster %s does not send Container Insights data to a Log Analytics workspace, enable the monitoring addon first", clusterName)
+}
+
+// HandleContainerInsightsQuery runs a Container Insights query template against the cluster's Log Analytics workspace
+func HandleContainerInsightsQuery(params map[string]interface{}, azClient *azureclient.AzureClient, cfg *config.ConfigData) (string, error) {
+	subscriptionID, resourceGroup, clusterName, err := common.ExtractAKSParameters(params)
+	if err != nil {
+		return "", err
+	}
+
+	timeRange, err := ResolveTimeRange(params)
+	if err != nil {
+		return "", err
+	}
+
+	template, _ := params["template"].(string)
+	query := ContainerInsightsQuery{Template: template, MaxRecords: GetMaxRecords(params)}
+
+	// Summaries cover up to MaxAllowedRecords records unless max_records is set
+	summary, err := ParseLogSummaryOptions(params, timeRange)
+	if err != nil {
+		return "", err
+	}
+	if summary != nil {
+		if template != InsightsContainerLog {
+			return "", fmt.Errorf("summarize is only supported by the %s template", InsightsContainerLog)
+		}
+		if _, ok := params["max_records"]; !ok {
+			query.MaxRecords = MaxAllowedRecords
+		}
+	}
+	query.Namespace, _ = params["namespace"].(string)
+	query.PodName, _ = params["pod_name"].(string)
+	query.ContainerName, _ = params["container_name"].(string)
+	query.NodeName, _ = params["node_name"].(string)
+	query.Grep, _ = params["grep"].(string)
+	query.SortBy, _ = params["sort_by"].(string)
+
+	clusterResourceID := buildClusterResourceID(subscriptionID, resourceGroup, clusterName)
+	kqlQuery, err := BuildContainerInsightsQuery(query, clusterResourceID)
+	if err != nil {
+		return "", err
+	}
+
+	workspaceResourceID, err := FindContainerInsightsWorkspace(context.Background(), subscriptionID, resourceGroup, clusterName, azClient)
+	if err != nil {
+		return "", err
+	}
+	workspaceGUID, err := GetWorkspaceGUID(workspaceResourceID, cfg)
+	if err != nil {
+		return "", fmt.Errorf("failed to get workspace GUID for cluster %s: %w", clusterName, err)
+	}
+
+	// Summarize the log messages into patterns instead of returning every record
+	if summary != nil {
+		result, err := summarizeLogQuery(workspaceGUID, kqlQuery, query.MaxRecords, summary, cfg)
+		if err != nil {
+			return "", fmt.Errorf("failed to summarize container logs in cluster %s: %w", clusterName, err)
+		}
+		return result, nil
+	}
+
+	result, err := RunLogAnalyticsQuery(workspaceGUID, kqlQuery, timeRange.Timespan(), cfg)
+	if err != nil {
+		return "", fmt.Errorf("failed to run Container Insights query %s in cluster %s: %w", template, clusterName, err)
+	}
+
+	return common.WithTimeRange(result, timeRange)
+}
@@ -0,0 +1,249 @@
+package diagnostics
+
+import (
+	"context"
+	"encoding/json"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/Azure/aks-mcp/internal/azureclient/fakearm"
+	"github.com/Azure/aks-mcp/internal/azureclient/fakearm/armtest"
+	"github.com/Azure/aks-mcp/internal/command"
+	"github.com/Azure/aks-mcp/internal/components/common"
+)
+
+const testClusterResourceID = "/subscriptions/test/resourcegroups/rg/providers/microsoft.containerservice/managedclusters/cluster"
+
+func TestBuildContainerInsightsQuery(t *testing.T) {
+	tests := []struct {
+		name             string
+		query            ContainerInsightsQuery
+		expectedContains []string
+		notExpected      []string
+	}{
+		{
+			name:  "pod restarts in a namespace",
+			query: ContainerInsightsQuery{Template: InsightsPodRestarts, Namespace: "default", MaxRecords: 100},
+			expectedContains: []string{
+				"KubePodInventory | where _ResourceId =~ '" + testClusterResourceID + "'",
+				"where Namespace == 'default'",
+				"where ContainerRestartCount > 0",
+				"by Namespace, PodName = Name, Container, Reason",
+				"limit 100",
+			},
+			notExpected: []string{"where Name ==", "where Computer =="},
+		},
+		{
+			name:  "oom killed containers on a node",
+			query: ContainerInsightsQuery{Template: InsightsOOMKilled, NodeName: "aks-nodepool1-12345678-vmss000000", MaxRecords: 20},
+			expectedContains: []string{
+				"KubePodInventory | where _ResourceId =~",
+				"where Computer == 'aks-nodepool1-12345678-vmss000000'",
+				"where tostring(LastStatus.reason) == 'OOMKilled'",
+				"limit 20",
+			},
+		},
+		{
+			name:  "container logs with grep",
+			query: ContainerInsightsQuery{Template: InsightsContainerLog, Namespace: "default", PodName: "web-0", ContainerName: "web", Grep: "connection refused", MaxRecords: 50},
+			expectedContains: []string{
+				"ContainerLogV2 | where _ResourceId =~",
+				"where PodNamespace == 'default' | where PodName == 'web-0' | where ContainerName == 'web'",
+				"where tostring(LogMessage) contains 'connection refused'",
+				"order by TimeGenerated desc | limit 50",
+			},
+		},
+		{
+			name:  "node not ready transitions",
+			query: ContainerInsightsQuery{Template: InsightsNodeNotReady, MaxRecords: 100},
+			expectedContains: []string{
+				"KubeNodeInventory | where _ResourceId =~",
+				"extend PreviousNode = prev(Node), PreviousStatus = prev(Status)",
+				"Status has 'NotReady' or PreviousStatus has 'NotReady'",
+			},
+		},
+		{
+			name:  "top consumers defaults to cpu",
+			query: ContainerInsightsQuery{Template: InsightsTopConsumers, MaxRecords: 10},
+			expectedContains: []string{
+				"Perf | where _ResourceId =~",
+				"CounterName == 'cpuUsageNanoCores'",
+				"join kind=inner (KubePodInventory | where _ResourceId =~",
+				"order by Average desc | limit 10",
+			},
+		},
+		{
+			name:             "top memory consumers in a namespace",
+			query:            ContainerInsightsQuery{Template: InsightsTopConsumers, Namespace: "kube-system", SortBy: "memory", MaxRecords: 10},
+			expectedContains: []string{"CounterName == 'memoryWorkingSetBytes'", "where Namespace == 'kube-system'"},
+			notExpected:      []string{"cpuUsageNanoCores"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			query, err := BuildContainerInsightsQuery(tt.query, testClusterResourceID)
+			if err != nil {
+				t.Fatalf("BuildContainerInsightsQuery failed: %v", err)
+			}
+			for _, expected := range tt.expectedContains {
+				if !strings.Contains(query, expected) {
+					t.Errorf("Expected query to contain '%s', got: %s", expected, query)
+				}
+			}
+			for _, notExpected := range tt.notExpected {
+				if strings.Contains(query, notExpected) {
+					t.Errorf("Expected query not to contain '%s', got: %s", notExpected, query)
+				}
+			}
+		})
+	}
+}
+
+func TestBuildContainerInsightsQueryValidation(t *testing.T) {
+	tests := []struct {
+		name              string
+		query             ContainerInsightsQuery
+		clusterResourceID string
+		errMsg            string
+	}{
+		{
+			name:   "unknown template",
+			query:  ContainerInsightsQuery{Template: "everything", MaxRecords: 100},
+			errMsg: "invalid template 'everything'",
+		},
+		{
+			name:   "container logs without pod",
+			query:  ContainerInsightsQuery{Template: InsightsContainerLog, Namespace: "default", MaxRecords: 100},
+			errMsg: "missing pod_name parameter",
+		},
+		{
+			name:   "namespace injection",
+			query:  ContainerInsightsQuery{Template: InsightsPodRestarts, Namespace: "default' or 1==1 //", MaxRecords: 100},
+			errMsg: "invalid namespace",
+		},
+		{
+			name:   "pod name with pipe",
+			query:  ContainerInsightsQuery{Template: InsightsOOMKilled, PodName: "web | take 1", MaxRecords: 100},
+			errMsg: "invalid pod_name",
+		},
+		{
+			name:   "grep closing the string literal",
+			query:  ContainerInsightsQuery{Template: InsightsContainerLog, Namespace: "default", PodName: "web-0", Grep: "x' | union SecurityEvent | where '1' == '1", MaxRecords: 100},
+			errMsg: "grep cannot contain quotes",
+		},
+		{
+			name:   "grep with newline",
+			query:  ContainerInsightsQuery{Template: InsightsContainerLog, Namespace: "default", PodName: "web-0", Grep: "a\nb", MaxRecords: 100},
+			errMsg: "grep cannot contain quotes",
+		},
+		{
+			name:   "grep too long",
+			query:  ContainerInsightsQuery{Template: InsightsContainerLog, Namespace: "default", PodName: "web-0", Grep: strings.Repeat("a", MaxInsightsGrepLength+1), MaxRecords: 100},
+			errMsg: "grep cannot exceed",
+		},
+		{
+			name:   "grep on another template",
+			query:  ContainerInsightsQuery{Template: InsightsPodRestarts, Grep: "error", MaxRecords: 100},
+			errMsg: "grep is only supported",
+		},
+		{
+			name:   "invalid sort_by",
+			query:  ContainerInsightsQuery{Template: InsightsTopConsumers, SortBy: "disk", MaxRecords: 100},
+			errMsg: "invalid sort_by 'disk'",
+		},
+		{
+			name:   "too many records",
+			query:  ContainerInsightsQuery{Template: InsightsNodeNotReady, MaxRecords: MaxMaxRecords + 1},
+			errMsg: "maxRecords must be between",
+		},
+		{
+			name:              "cluster resource ID with quote",
+			query:             ContainerInsightsQuery{Template: InsightsNodeNotReady, MaxRecords: 100},
+			clusterResourceID: "/subscriptions/test/resourcegroups/rg/providers/microsoft.containerservice/managedclusters/clu'ster",
+			errMsg:            "invalid clusterResourceID",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			clusterResourceID := tt.clusterResourceID
+			if clusterResourceID == "" {
+				clusterResourceID = testClusterResourceID
+			}
+			_, err := BuildContainerInsightsQuery(tt.query, clusterResourceID)
+			if err == nil || !strings.Contains(err.Error(), tt.errMsg) {
+				t.Errorf("Expected error containing '%s', got %v", tt.errMsg, err)
+			}
+		})
+	}
+}
+
+func TestHandleContainerInsightsQuery(t *testing.T) {
+	stop, err := command.UseCassette(filepath.Join("testdata", "cassettes", "container_insights.json"))
+	if err != nil {
+		t.Fatalf("Failed to load cassette: %v", err)
+	}
+	defer func() {
+		if err := stop(); err != nil {
+			t.Errorf("Failed to save cassette: %v", err)
+		}
+	}()
+
+	_, client, cfg := armtest.NewClient(t, filepath.Join("testdata", "container_insights.json"))
+
+	params := map[string]interface{}{
+		"subscription_id": fakearm.SubscriptionID,
+		"resource_group":  fakearm.ResourceGroup,
+		"cluster_name":    fakearm.ClusterName,
+		"template":        InsightsContainerLog,
+		"namespace":       "default",
+		"pod_name":        "web-7d9f8c6b5-x2kq4",
+		"grep":            "connection  refused",
+		"start_time":      "2026-03-01T11:00:00Z",
+		"end_time":        "2026-03-01T12:00:00Z",
+		"max_records":     "50",
+	}
+
+	// The grep text reaches the query unchanged, including repeated spaces
+	result, err := HandleContainerInsightsQuery(params, client, cfg)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	var response struct {
+		TimeRange common.TimeRange         `json:"time_range"`
+		Result    []map[string]interface{} `json:"result"`
+	}
+	if err := json.Unmarshal([]byte(result), &response); err != nil {
+		t.Fatalf("Expected JSON rows, got %q: %v", result, err)
+	}
+	if rows := response.Result; len(rows) != 1 || rows[0]["PodName"] != "web-7d9f8c6b5-x2kq4" {
+		t.Errorf("Unexpected rows: %v", rows)
+	}
+	if response.TimeRange.Duration != "1h0m0s" || response.TimeRange.Timespan() != "2026-03-01T11:00:00Z/2026-03-01T12:00:00Z" {
+		t.Errorf("Unexpected time range: %+v", response.TimeRange)
+	}
+
+	t.Run("missing start time", func(t *testing.T) {
+		delete(params, "start_time")
+		_, err := HandleContainerInsightsQuery(params, client, cfg)
+		if err == nil || !strings.Contains(err.Error(), "start_time") {
+			t.Errorf("Expected start_time error, got %v", err)
+		}
+	})
+}
+
+func TestFindContainerInsightsWorkspace(t *testing.T) {
+	// The default cluster fixture does not have the monitoring addon
+	_, client, _ := armtest.NewClient(t)
+
+	_, err := FindContainerInsightsWorkspace(context.Background(), fakearm.SubscriptionID, fakearm.ResourceGroup, fakearm.ClusterName, client)
+	if err == nil || !strings.Contains(err.Error(), "does not send Container Insights data") {
+		t.Errorf("Expected monitoring addon error, got %v", err)
+	}
+
+	if _, err := FindContainerInsightsWorkspace(context.Background(), fakearm.SubscriptionID, fakearm.ResourceGroup, fakearm.ClusterName, nil); err == nil {
+		t.Error("Expected error without an Azure client")
+	}
+}
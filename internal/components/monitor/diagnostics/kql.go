@@ -4,7 +4,8 @@ import (
 	"fmt"
 	"regexp"
 	"strings"
-	"time"
+
+	"github.com/Azure/aks-mcp/internal/components/common"
 )
 
 // LogLevelMapping defines the mapping between log levels and their representations
@@ -118,6 +119,14 @@ func ValidateKQLQueryParams(category, logLevel string, maxRecords int, clusterRe
 	return nil
 }
 
+// ValidateClusterResourceID checks that clusterResourceID is an AKS cluster resource ID that is safe to quote in a KQL string
+func ValidateClusterResourceID(clusterResourceID string) error {
+	if !azureResourceIDPattern.MatchString(clusterResourceID) || strings.ContainsAny(clusterResourceID, `'"\`) {
+		return fmt.Errorf("invalid clusterResourceID format. Expected format: /subscriptions/{subscription-id}/resourceGroups/{resource-group}/providers/Microsoft.ContainerService/managedClusters/{cluster-name}")
+	}
+	return nil
+}
+
 // NewKQLQueryBuilder creates a new KQL query builder instance
 func NewKQLQueryBuilder(category, logLevel string, maxRecords int, clusterResourceID string, tableMode TableMode) (*KQLQueryBuilder, error) {
 	// Validate all input parameters
@@ -175,6 +184,14 @@ func (q *KQLQueryBuilder) buildBaseQuery() (string, error) {
 	}
 }
 
+// BuildBaseQuery constructs the table selection and cluster filter of a query, for callers that add their own clauses
+func (q *KQLQueryBuilder) BuildBaseQuery() (string, error) {
+	if err := q.determineTableStrategy(); err != nil {
+		return "", err
+	}
+	return q.buildBaseQuery()
+}
+
 // isAuditCategory checks if the current category is an audit log category
 func (q *KQLQueryBuilder) isAuditCategory() bool {
 	return auditCategories[q.category]
@@ -285,23 +302,14 @@ func BuildSafeKQLQuery(category, logLevel string, maxRecords int, clusterResourc
 
 // CalculateTimespan converts start/end times to Azure CLI timespan format
 func CalculateTimespan(startTime, endTime string) (string, error) {
-	start, err := time.Parse(time.RFC3339, startTime)
+	timeRange, err := common.ParseTimeRange(map[string]interface{}{
+		"start_time": startTime,
+		"end_time":   endTime,
+	}, diagnosticsNow(), common.TimeRangeOptions{})
 	if err != nil {
-		return "", fmt.Errorf("invalid start time format: %w", err)
-	}
-
-	var end time.Time
-	if endTime != "" {
-		end, err = time.Parse(time.RFC3339, endTime)
-		if err != nil {
-			return "", fmt.Errorf("invalid end time format: %w", err)
-		}
-	} else {
-		// Default to current time if no end time specified
-		end = time.Now()
+		return "", err
 	}
 
 	// Azure CLI timespan format: start_time/end_time in ISO8601
-	timespan := fmt.Sprintf("%s/%s", start.Format(time.RFC3339), end.Format(time.RFC3339))
-	return timespan, nil
+	return timeRange.Timespan(), nil
 }
@@ -0,0 +1,317 @@
+package diagnostics
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Azure/aks-mcp/internal/components/common"
+	"github.com/Azure/aks-mcp/internal/config"
+)
+
+// Orders of the log patterns of a summary
+const (
+	RankByFrequency = "frequency" // most records first
+	RankByIncrease  = "increase"  // largest increase over the baseline window first
+)
+
+// Log summary limits
+const (
+	DefaultMaxPatterns = 20
+	MaxMaxPatterns     = 100
+	// maxPatternLength is the longest template or example returned, in characters
+	maxPatternLength = 500
+)
+
+// k8sRandomAlphabet is the alphabet of the random suffixes Kubernetes appends to generated names
+const k8sRandomAlphabet = `[bcdfghjklmnpqrstvwxz2456789]`
+
+// klogHeaderPattern matches the header of klog lines, e.g. "E0301 11:05:00.123456       1 ", keeping the severity
+var klogHeaderPattern = regexp.MustCompile(`^([IWEF])\d{4}\s+\d{2}:\d{2}:\d{2}\.\d+\s+\d+\s+`)
+
+// logMasks replace the variable parts of log messages with placeholders, in order, so that messages
+// differing only in those parts share a template
+var logMasks = []struct {
+	pattern     *regexp.Regexp
+	placeholder string
+}{
+	{regexp.MustCompile(`(?i)\b[0-9a-f]{8}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{12}\b`), "<uuid>"},
+	{regexp.MustCompile(`\b\d{4}-\d{2}-\d{2}[T ]\d{2}:\d{2}:\d{2}(\.\d+)?(Z|[+-]\d{2}:?\d{2})?`), "<time>"},
+	{regexp.MustCompile(`\b\d{2}:\d{2}:\d{2}(\.\d+)?\b`), "<time>"},
+	{regexp.MustCompile(`\b(\d{1,3}\.){3}\d{1,3}(:\d+)?\b`), "<ip>"},
+	{regexp.MustCompile(`(?i)\[?\b([0-9a-f]{1,4}:){7}[0-9a-f]{1,4}\b\]?(:\d+)?`), "<ip>"},
+	// Pods of deployments (<name>-<replica set hash>-<suffix>), then of daemon sets and jobs (<name>-<suffix>)
+	{regexp.MustCompile(`\b[a-z0-9]([-a-z0-9]*[a-z0-9])?-` + k8sRandomAlphabet + `{6,10}-` + k8sRandomAlphabet + `{5}\b`), "<pod>"},
+	{regexp.MustCompile(`\b[a-z0-9]([-a-z0-9]*[a-z0-9])?-` + k8sRandomAlphabet + `{5}\b`), "<pod>"},
+	// Nodes of virtual machine scale sets, e.g. aks-nodepool1-12345678-vmss000001
+	{regexp.MustCompile(`\b[a-z0-9]([-a-z0-9]*[a-z0-9])?-vmss[0-9a-z]{6}\b`), "<node>"},
+	{regexp.MustCompile(`(?i)\b[0-9a-f]{12,}\b`), "<hex>"},
+	{regexp.MustCompile(`\b\d+(\.\d+)?`), "<num>"},
+}
+
+// whitespacePattern matches runs of whitespace, which are collapsed in templates
+var whitespacePattern = regexp.MustCompile(`\s+`)
+
+// LogSummaryOptions configures the server-side summary of log query results into message patterns
+type LogSummaryOptions struct {
+	RankBy      string
+	MaxPatterns int
+	TimeRange   *common.TimeRange
+	Baseline    *common.TimeRange // the window compared against when ranking by increase
+}
+
+// LogRecord is the time and message of a log query result row
+type LogRecord struct {
+	Time    time.Time
+	Message string
+}
+
+// LogPattern is a cluster of log messages sharing a template
+type LogPattern struct {
+	Template      string    `json:"template"`
+	Count         int       `json:"count"`
+	FirstSeen     time.Time `json:"first_seen"`
+	LastSeen      time.Time `json:"last_seen"`
+	Example       string    `json:"example"`
+	BaselineCount *int      `json:"baseline_count,omitempty"`
+	// Increase is the count minus the baseline count scaled to the length of the queried window
+	Increase *float64 `json:"increase,omitempty"`
+	New      bool     `json:"new,omitempty"` // not seen in the baseline window
+}
+
+// LogPatternSummary is the summary of log query results returned instead of the records
+type LogPatternSummary struct {
+	TotalRecords       int               `json:"total_records"`
+	RecordLimitReached bool              `json:"record_limit_reached,omitempty"` // the query returned max_records rows
+	TotalPatterns      int               `json:"total_patterns"`
+	RankBy             string            `json:"rank_by"`
+	Baseline           *common.TimeRange `json:"baseline,omitempty"`
+	BaselineRecords    int               `json:"baseline_records,omitempty"`
+	// BaselineLimitReached reports that the baseline query returned max_records rows, so baseline counts are low
+	BaselineLimitReached bool         `json:"baseline_limit_reached,omitempty"`
+	Patterns             []LogPattern `json:"patterns"`
+}
+
+// ParseLogSummaryOptions returns the summary options of a log query over timeRange, or nil when summarize is not set.
+// The baseline window defaults to the window of the same length just before timeRange.
+func ParseLogSummaryOptions(params map[string]interface{}, timeRange *common.TimeRange) (*LogSummaryOptions, error) {
+	switch summarize := params["summarize"].(type) {
+	case bool:
+		if !summarize {
+			return nil, nil
+		}
+	case string:
+		if !strings.EqualFold(summarize, "true") {
+			return nil, nil
+		}
+	default:
+		return nil, nil
+	}
+
+	opts := &LogSummaryOptions{RankBy: RankByFrequency, MaxPatterns: DefaultMaxPatterns, TimeRange: timeRange}
+	if rankBy, _ := params["rank_by"].(string); rankBy != "" {
+		if rankBy != RankByFrequency && rankBy != RankByIncrease {
+			return nil, fmt.Errorf("invalid rank_by '%s'. Valid values: %s, %s", rankBy, RankByFrequency, RankByIncrease)
+		}
+		opts.RankBy = rankBy
+	}
+
+	switch value := params["max_patterns"].(type) {
+	case float64:
+		if value < 1 || value > MaxMaxPatterns || value != math.Trunc(value) {
+			return nil, fmt.Errorf("max_patterns must be a whole number between 1 and %d", MaxMaxPatterns)
+		}
+		opts.MaxPatterns = int(value)
+	case string:
+		n, err := strconv.Atoi(value)
+		if err != nil {
+			return nil, fmt.Errorf("max_patterns must be a whole number between 1 and %d", MaxMaxPatterns)
+		}
+		opts.MaxPatterns = n
+	}
+	if opts.MaxPatterns < 1 || opts.MaxPatterns > MaxMaxPatterns {
+		return nil, fmt.Errorf("max_patterns must be a whole number between 1 and %d", MaxMaxPatterns)
+	}
+
+	baseline, _ := params["baseline"].(string)
+	if opts.RankBy != RankByIncrease {
+		if baseline != "" {
+			return nil, fmt.Errorf("baseline is only used with rank_by %s", RankByIncrease)
+		}
+		return opts, nil
+	}
+	if baseline == "" {
+		window := timeRange.End.Sub(timeRange.Start)
+		opts.Baseline = &common.TimeRange{
+			Start:    timeRange.Start.Add(-window),
+			End:      timeRange.Start,
+			Duration: window.String(),
+		}
+		return opts, nil
+	}
+	baselineRange, err := common.ParseTimeRangeExpression(baseline, diagnosticsNow().UTC().Truncate(time.Second))
+	if err != nil {
+		return nil, fmt.Errorf("invalid baseline: %w", err)
+	}
+	if baselineRange.End.After(timeRange.Start) {
+		return nil, fmt.Errorf("baseline must end before the queried time range starts at %s", timeRange.Start.Format(time.RFC3339))
+	}
+	opts.Baseline = baselineRange
+	return opts, nil
+}
+
+// LogTemplate returns the template of a log message: the klog header is removed, UUIDs, times, IP addresses,
+// generated pod and node names, hexadecimal IDs and numbers are masked, and whitespace is collapsed
+func LogTemplate(message string) string {
+	template := klogHeaderPattern.ReplaceAllString(strings.TrimSpace(message), "$1 ")
+	for _, mask := range logMasks {
+		template = mask.pattern.ReplaceAllString(template, mask.placeholder)
+	}
+	return truncatePattern(whitespacePattern.ReplaceAllString(template, " "))
+}
+
+// truncatePattern shortens templates and examples to maxPatternLength characters
+func truncatePattern(value string) string {
+	if runes := []rune(value); len(runes) > maxPatternLength {
+		return string(runes[:maxPatternLength]) + "..."
+	}
+	return value
+}
+
+// ParseLogRecords extracts the time and message of each row of a log query result. The message is the Message,
+// log_s or LogMessage column, or the verb and request URI of audit events.
+func ParseLogRecords(output string) ([]LogRecord, error) {
+	var rows []map[string]interface{}
+	if err := json.Unmarshal([]byte(output), &rows); err != nil {
+		return nil, fmt.Errorf("failed to parse query result: %w", err)
+	}
+
+	records := make([]LogRecord, 0, len(rows))
+	for _, row := range rows {
+		timeGenerated, _ := row["TimeGenerated"].(string)
+		timestamp, err := time.Parse(time.RFC3339Nano, timeGenerated)
+		if err != nil {
+			return nil, fmt.Errorf("invalid TimeGenerated '%s': %w", timeGenerated, err)
+		}
+
+		var message string
+		for _, column := range []string{"Message", "log_s", "LogMessage"} {
+			if value, ok := row[column]; ok && value != nil {
+				if text, ok := value.(string); ok {
+					message = text
+				} else if data, err := json.Marshal(value); err == nil {
+					message = string(data)
+				}
+				break
+			}
+		}
+		if verb, ok := row["Verb"].(string); ok && message == "" {
+			requestURI, _ := row["RequestUri"].(string)
+			message = verb + " " + requestURI
+		}
+		records = append(records, LogRecord{Time: timestamp.UTC(), Message: message})
+	}
+	return records, nil
+}
+
+// SummarizeLogPatterns clusters log records by template and ranks the patterns. When ranking by increase,
+// baseline holds the records of the baseline window.
+func SummarizeLogPatterns(records, baseline []LogRecord, opts LogSummaryOptions) LogPatternSummary {
+	summary := LogPatternSummary{TotalRecords: len(records), RankBy: opts.RankBy, Patterns: []LogPattern{}}
+
+	byTemplate := map[string]*LogPattern{}
+	var patterns []*LogPattern
+	for _, record := range records {
+		template := LogTemplate(record.Message)
+		pattern, ok := byTemplate[template]
+		if !ok {
+			pattern = &LogPattern{Template: template, FirstSeen: record.Time, LastSeen: record.Time, Example: truncatePattern(strings.TrimSpace(record.Message))}
+			byTemplate[template] = pattern
+			patterns = append(patterns, pattern)
+		}
+		pattern.Count++
+		if record.Time.Before(pattern.FirstSeen) {
+			pattern.FirstSeen = record.Time
+		}
+		if record.Time.After(pattern.LastSeen) {
+			pattern.LastSeen = record.Time
+			pattern.Example = truncatePattern(strings.TrimSpace(record.Message))
+		}
+	}
+	summary.TotalPatterns = len(patterns)
+
+	if opts.RankBy == RankByIncrease && opts.Baseline != nil {
+		summary.Baseline = opts.Baseline
+		summary.BaselineRecords = len(baseline)
+		baselineCounts := map[string]int{}
+		for _, record := range baseline {
+			baselineCounts[LogTemplate(record.Message)]++
+		}
+
+		// Scale the baseline counts to the length of the queried window
+		scale := 1.0
+		if window, baselineWindow := opts.TimeRange.End.Sub(opts.TimeRange.Start), opts.Baseline.End.Sub(opts.Baseline.Start); baselineWindow > 0 {
+			scale = float64(window) / float64(baselineWindow)
+		}
+		for _, pattern := range patterns {
+			count := baselineCounts[pattern.Template]
+			increase := math.Round((float64(pattern.Count)-float64(count)*scale)*10) / 10
+			pattern.BaselineCount, pattern.Increase, pattern.New = &count, &increase, count == 0
+		}
+	}
+
+	sort.SliceStable(patterns, func(i, j int) bool {
+		if opts.RankBy == RankByIncrease && patterns[i].Increase != nil && *patterns[i].Increase != *patterns[j].Increase {
+			return *patterns[i].Increase > *patterns[j].Increase
+		}
+		if patterns[i].Count != patterns[j].Count {
+			return patterns[i].Count > patterns[j].Count
+		}
+		return patterns[i].LastSeen.After(patterns[j].LastSeen)
+	})
+	for i, pattern := range patterns {
+		if i == opts.MaxPatterns {
+			break
+		}
+		summary.Patterns = append(summary.Patterns, *pattern)
+	}
+	return summary
+}
+
+// summarizeLogQuery runs a log query over the time range, and over the baseline window when ranking by increase,
+// and returns the message patterns of the results with the queried time range
+func summarizeLogQuery(workspaceGUID, kqlQuery string, maxRecords int, opts *LogSummaryOptions, cfg *config.ConfigData) (string, error) {
+	output, err := RunLogAnalyticsQuery(workspaceGUID, kqlQuery, opts.TimeRange.Timespan(), cfg)
+	if err != nil {
+		return "", err
+	}
+	records, err := ParseLogRecords(output)
+	if err != nil {
+		return "", err
+	}
+
+	var baseline []LogRecord
+	if opts.Baseline != nil {
+		output, err := RunLogAnalyticsQuery(workspaceGUID, kqlQuery, opts.Baseline.Timespan(), cfg)
+		if err != nil {
+			return "", fmt.Errorf("failed to query the baseline window: %w", err)
+		}
+		if baseline, err = ParseLogRecords(output); err != nil {
+			return "", err
+		}
+	}
+
+	summary := SummarizeLogPatterns(records, baseline, *opts)
+	summary.RecordLimitReached = len(records) >= maxRecords
+	summary.BaselineLimitReached = opts.Baseline != nil && len(baseline) >= maxRecords
+	summaryJSON, err := json.Marshal(summary)
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal log summary to JSON: %w", err)
+	}
+	return common.WithTimeRange(string(summaryJSON), opts.TimeRange)
+}
@@ -0,0 +1,239 @@
+package diagnostics
+
+import (
+	"encoding/json"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Azure/aks-mcp/internal/azureclient/fakearm"
+	"github.com/Azure/aks-mcp/internal/azureclient/fakearm/armtest"
+	"github.com/Azure/aks-mcp/internal/command"
+	"github.com/Azure/aks-mcp/internal/components/common"
+	"github.com/Azure/aks-mcp/internal/config"
+)
+
+func TestLogTemplate(t *testing.T) {
+	tests := []struct {
+		message  string
+		template string
+	}{
+		{
+			"E0301 11:05:00.123456       1 status.go:71] apiserver received an error that is not an metav1.Status: context canceled",
+			"E status.go:<num>] apiserver received an error that is not an metav1.Status: context canceled",
+		},
+		{
+			`Post "https://gatekeeper-webhook-service.gatekeeper-system.svc:443/v1/admit?timeout=3s": dial tcp 10.0.12.34:443: i/o timeout`,
+			`Post "https://gatekeeper-webhook-service.gatekeeper-system.svc:<num>/v1/admit?timeout=<num>s": dial tcp <ip>: i/o timeout`,
+		},
+		{
+			"failed to sync pod coredns-6f8b5c7d9b-x2k4p (uid 7f1c2d3e-4b5a-6789-abcd-ef0123456789) on node aks-nodepool1-12345678-vmss000001",
+			"failed to sync pod <pod> (uid <uuid>) on node <node>",
+		},
+		{
+			"kube-proxy-xk2vz  restarted after 1.5s at 2026-03-01T11:00:00.5Z, trace 0123456789abcdef",
+			"<pod> restarted after <num>s at <time>, trace <hex>",
+		},
+		{
+			// Names without a generated suffix are kept
+			"leader election lost for cluster-autoscaler in kube-system",
+			"leader election lost for cluster-autoscaler in kube-system",
+		},
+	}
+
+	for _, tt := range tests {
+		if got := LogTemplate(tt.message); got != tt.template {
+			t.Errorf("LogTemplate(%q)\n got: %s\nwant: %s", tt.message, got, tt.template)
+		}
+	}
+}
+
+func TestSummarizeLogPatterns(t *testing.T) {
+	at := func(minute int) time.Time { return time.Date(2026, 3, 1, 11, minute, 0, 0, time.UTC) }
+	records := []LogRecord{
+		{at(50), "watch of *v1.Pod ended with: too old resource version: 1234 (5678)"},
+		{at(10), "watch of *v1.Pod ended with: too old resource version: 1200 (5600)"},
+		{at(30), "watch of *v1.Pod ended with: too old resource version: 1210 (5610)"},
+		{at(20), "etcdserver: request timed out"},
+	}
+	timeRange := &common.TimeRange{Start: at(0), End: at(0).Add(time.Hour)}
+
+	summary := SummarizeLogPatterns(records, nil, LogSummaryOptions{RankBy: RankByFrequency, MaxPatterns: 1, TimeRange: timeRange})
+	if summary.TotalRecords != 4 || summary.TotalPatterns != 2 || len(summary.Patterns) != 1 {
+		t.Fatalf("Expected the most frequent of 2 patterns, got %+v", summary)
+	}
+	pattern := summary.Patterns[0]
+	if pattern.Count != 3 || !pattern.FirstSeen.Equal(at(10)) || !pattern.LastSeen.Equal(at(50)) || !strings.Contains(pattern.Example, "1234") {
+		t.Errorf("Expected 3 watch records with the latest as example, got %+v", pattern)
+	}
+	if pattern.BaselineCount != nil || pattern.Increase != nil {
+		t.Errorf("Expected no baseline without rank_by increase, got %+v", pattern)
+	}
+
+	// The baseline window is twice as long, so its counts are halved
+	baseline := []LogRecord{
+		{at(0).Add(-time.Hour), "watch of *v1.Pod ended with: too old resource version: 1000 (5000)"},
+		{at(0).Add(-90 * time.Minute), "watch of *v1.Pod ended with: too old resource version: 1001 (5001)"},
+		{at(0).Add(-100 * time.Minute), "watch of *v1.Pod ended with: too old resource version: 1002 (5002)"},
+		{at(0).Add(-110 * time.Minute), "watch of *v1.Pod ended with: too old resource version: 1003 (5003)"},
+		{at(0).Add(-115 * time.Minute), "watch of *v1.Pod ended with: too old resource version: 1004 (5004)"},
+	}
+	summary = SummarizeLogPatterns(records, baseline, LogSummaryOptions{
+		RankBy:      RankByIncrease,
+		MaxPatterns: 10,
+		TimeRange:   timeRange,
+		Baseline:    &common.TimeRange{Start: at(0).Add(-2 * time.Hour), End: at(0)},
+	})
+	if summary.BaselineRecords != 5 || len(summary.Patterns) != 2 {
+		t.Fatalf("Unexpected summary: %+v", summary)
+	}
+	first, second := summary.Patterns[0], summary.Patterns[1]
+	if first.Template != "etcdserver: request timed out" || !first.New || *first.Increase != 1 {
+		t.Errorf("Expected the new pattern first, got %+v", first)
+	}
+	if *second.BaselineCount != 5 || *second.Increase != 0.5 || second.New {
+		t.Errorf("Expected 3 records over 2.5 expected, got %+v", second)
+	}
+}
+
+func TestParseLogSummaryOptions(t *testing.T) {
+	timeRange := &common.TimeRange{Start: time.Date(2026, 3, 1, 11, 0, 0, 0, time.UTC), End: time.Date(2026, 3, 1, 11, 30, 0, 0, time.UTC)}
+
+	if opts, err := ParseLogSummaryOptions(map[string]interface{}{"summarize": "false"}, timeRange); opts != nil || err != nil {
+		t.Errorf("Expected no summary, got %+v, %v", opts, err)
+	}
+
+	opts, err := ParseLogSummaryOptions(map[string]interface{}{"summarize": true, "rank_by": "increase", "max_patterns": 5.0}, timeRange)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if opts.MaxPatterns != 5 || opts.Baseline == nil || opts.Baseline.Timespan() != "2026-03-01T10:30:00Z/2026-03-01T11:00:00Z" {
+		t.Errorf("Expected the preceding half hour as baseline, got %+v", opts)
+	}
+
+	// Relative baselines resolve against the server clock
+	defer func(original func() time.Time) { diagnosticsNow = original }(diagnosticsNow)
+	diagnosticsNow = func() time.Time { return time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC) }
+	opts, err = ParseLogSummaryOptions(map[string]interface{}{"summarize": true, "rank_by": "increase", "baseline": "last 3h"}, timeRange)
+	if err == nil || !strings.Contains(err.Error(), "baseline must end before") {
+		t.Errorf("Expected a baseline ending at the server time to overlap, got %+v, %v", opts, err)
+	}
+
+	errorCases := []struct {
+		name   string
+		params map[string]interface{}
+		errMsg string
+	}{
+		{"bad rank", map[string]interface{}{"summarize": true, "rank_by": "severity"}, "invalid rank_by"},
+		{"too many patterns", map[string]interface{}{"summarize": "true", "max_patterns": "500"}, "max_patterns must be"},
+		{"fractional patterns", map[string]interface{}{"summarize": true, "max_patterns": 2.5}, "max_patterns must be a whole number"},
+		{"baseline without increase", map[string]interface{}{"summarize": true, "baseline": "2026-03-01T10:00:00Z/2026-03-01T10:30:00Z"}, "only used with rank_by increase"},
+		{"overlapping baseline", map[string]interface{}{"summarize": true, "rank_by": "increase", "baseline": "2026-03-01T10:30:00Z/2026-03-01T11:15:00Z"}, "baseline must end before"},
+	}
+	for _, tc := range errorCases {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := ParseLogSummaryOptions(tc.params, timeRange)
+			if err == nil || !strings.Contains(err.Error(), tc.errMsg) {
+				t.Errorf("Expected error containing %q, got %v", tc.errMsg, err)
+			}
+		})
+	}
+}
+
+func TestHandleControlPlaneLogs_Summarize(t *testing.T) {
+	stop, err := command.UseCassette(filepath.Join("testdata", "cassettes", "log_patterns.json"))
+	if err != nil {
+		t.Fatalf("Failed to load cassette: %v", err)
+	}
+	defer func() {
+		if err := stop(); err != nil {
+			t.Errorf("Failed to save cassette: %v", err)
+		}
+	}()
+
+	_, client, cfg := armtest.NewClient(t)
+
+	tests := []struct {
+		rankBy string
+		first  string
+	}{
+		{RankByFrequency, "E status.go:<num>] apiserver received an error that is not an metav1.Status: context canceled"},
+		{RankByIncrease, `E dispatcher.go:<num>] failed calling webhook "validation.gatekeeper.sh": failed to call webhook: Post "https://gatekeeper-webhook-service.gatekeeper-system.svc:<num>/v1/admit?timeout=<num>s": dial tcp <ip>: i/o timeout`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.rankBy, func(t *testing.T) {
+			output, err := HandleControlPlaneLogs(map[string]interface{}{
+				"subscription_id": fakearm.SubscriptionID,
+				"resource_group":  fakearm.ResourceGroup,
+				"cluster_name":    fakearm.ClusterName,
+				"log_category":    "kube-apiserver",
+				"log_level":       "error",
+				"start_time":      "2026-03-01T11:00:00Z",
+				"end_time":        "2026-03-01T12:00:00Z",
+				"summarize":       true,
+				"rank_by":         tt.rankBy,
+			}, client, cfg)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+
+			var response struct {
+				Result LogPatternSummary `json:"result"`
+			}
+			if err := json.Unmarshal([]byte(output), &response); err != nil {
+				t.Fatalf("Failed to parse result: %v\n%s", err, output)
+			}
+			summary := response.Result
+			if summary.TotalRecords != 7 || summary.TotalPatterns != 2 || summary.Patterns[0].Template != tt.first {
+				t.Errorf("Expected 7 records in 2 patterns starting with %s, got %+v", tt.first, summary)
+			}
+			if tt.rankBy == RankByIncrease && (summary.BaselineRecords != 4 || !summary.Patterns[0].New) {
+				t.Errorf("Expected the webhook errors to be new over the baseline, got %+v", summary)
+			}
+			if summary.RecordLimitReached || summary.BaselineLimitReached {
+				t.Errorf("Expected no record limit to be reached, got %+v", summary)
+			}
+		})
+	}
+
+	// A baseline query that returns max_records rows is reported as truncated
+	output, err := HandleControlPlaneLogs(map[string]interface{}{
+		"subscription_id": fakearm.SubscriptionID,
+		"resource_group":  fakearm.ResourceGroup,
+		"cluster_name":    fakearm.ClusterName,
+		"log_category":    "kube-apiserver",
+		"log_level":       "error",
+		"start_time":      "2026-03-01T11:00:00Z",
+		"end_time":        "2026-03-01T12:00:00Z",
+		"max_records":     "4",
+		"summarize":       true,
+		"rank_by":         RankByIncrease,
+	}, client, cfg)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	var response struct {
+		Result LogPatternSummary `json:"result"`
+	}
+	if err := json.Unmarshal([]byte(output), &response); err != nil {
+		t.Fatalf("Failed to parse result: %v\n%s", err, output)
+	}
+	if !response.Result.RecordLimitReached || !response.Result.BaselineLimitReached {
+		t.Errorf("Expected both record limits to be reached, got %+v", response.Result)
+	}
+}
+
+func TestHandleContainerInsightsQuery_SummarizeNeedsContainerLogs(t *testing.T) {
+	_, err := HandleContainerInsightsQuery(map[string]interface{}{
+		"subscription_id": fakearm.SubscriptionID,
+		"resource_group":  fakearm.ResourceGroup,
+		"cluster_name":    fakearm.ClusterName,
+		"template":        InsightsPodRestarts,
+		"time_range":      "last 1h",
+		"summarize":       true,
+	}, nil, config.NewConfig())
+	if err == nil || !strings.Contains(err.Error(), "summarize is only supported by the container_logs template") {
+		t.Errorf("Expected summarize error, got %v", err)
+	}
+}
@@ -0,0 +1,240 @@
+package diagnostics
+
+import (
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/Azure/aks-mcp/internal/azureclient"
+	"github.com/Azure/aks-mcp/internal/components/common"
+	"github.com/Azure/aks-mcp/internal/config"
+)
+
+// Control plane log sources of the timeline; audit is the kube-audit-admin or kube-audit category
+const (
+	TimelineSourceAPIServer         = "kube-apiserver"
+	TimelineSourceControllerManager = "kube-controller-manager"
+	TimelineSourceScheduler         = "kube-scheduler"
+	TimelineSourceAutoscaler        = "cluster-autoscaler"
+	TimelineSourceAudit             = "audit"
+)
+
+// TimelineLogSources are the control plane log sources of the timeline, in the order their entries are merged
+var TimelineLogSources = []string{
+	TimelineSourceAPIServer, TimelineSourceControllerManager, TimelineSourceScheduler, TimelineSourceAutoscaler, TimelineSourceAudit,
+}
+
+// timelineDefaultLevels is the lowest log level read from each component when min_level is not set:
+// kube-apiserver logs too much at warning level to be useful in a timeline
+var timelineDefaultLevels = map[string]string{
+	TimelineSourceAPIServer:         "error",
+	TimelineSourceControllerManager: "warning",
+	TimelineSourceScheduler:         "warning",
+	TimelineSourceAutoscaler:        "warning",
+}
+
+// timelineLevels lists the log levels at or above each minimum level
+var timelineLevels = map[string][]string{
+	"error":   {"error"},
+	"warning": {"warning", "error"},
+	"info":    nil, // no filtering
+}
+
+// TimelineEntry is one event of the control plane timeline
+type TimelineEntry struct {
+	Time    time.Time `json:"time"`
+	Source  string    `json:"source"`
+	Level   string    `json:"level,omitempty"`
+	Message string    `json:"message"`
+}
+
+// TimelineSource reports how many entries a source contributed to the timeline, or why it could not be read
+type TimelineSource struct {
+	Name      string `json:"name"`
+	Category  string `json:"category,omitempty"`
+	MinLevel  string `json:"min_level,omitempty"`
+	Records   int    `json:"records"`
+	Truncated bool   `json:"truncated,omitempty"` // the per-source record cap was reached
+	Error     string `json:"error,omitempty"`
+}
+
+// BuildTimelineQuery builds the query of a control plane log source of the timeline. Every row is projected to
+// TimeGenerated, Level and Message so that sources in either table mode can be merged. For the audit source, category
+// is kube-audit or kube-audit-admin and only mutating requests are read.
+func BuildTimelineQuery(category, minLevel string, maxRecords int, clusterResourceID string, isResourceSpecific bool) (string, error) {
+	if !slices.Contains(controlPlaneLogCategories, category) {
+		return "", fmt.Errorf("invalid log category: %s", category)
+	}
+	levels, ok := timelineLevels[minLevel]
+	if !ok && !auditCategories[category] {
+		return "", fmt.Errorf("invalid min_level '%s'. Valid levels: error, warning, info", minLevel)
+	}
+
+	tableMode := AzureDiagnosticsMode
+	if isResourceSpecific {
+		tableMode = ResourceSpecificMode
+	}
+	builder, err := NewKQLQueryBuilder(category, "", maxRecords, clusterResourceID, tableMode)
+	if err != nil {
+		return "", fmt.Errorf("failed to create KQL query builder: %w", err)
+	}
+	if err := builder.determineTableStrategy(); err != nil {
+		return "", err
+	}
+	query, err := builder.buildBaseQuery()
+	if err != nil {
+		return "", err
+	}
+	limit := fmt.Sprintf(" | order by TimeGenerated desc | limit %d", maxRecords)
+
+	if auditCategories[category] {
+		return query + auditColumns[tableMode] +
+			" | where Stage == 'ResponseComplete' and Verb in ('create', 'update', 'patch', 'delete', 'deletecollection')" +
+			limit +
+			" | extend Object = iff(Namespace == '', strcat(Resource, '/', Name), strcat(Resource, '/', Namespace, '/', Name))" +
+			" | project TimeGenerated, Level = iff(Code >= 400, 'WARNING', 'INFO')," +
+			" Message = strcat(Username, ' ', Verb, ' ', Object, ' (', Code, ')')", nil
+	}
+
+	// AKSControlPlane holds every component, so it is filtered by category too
+	if tableMode == ResourceSpecificMode {
+		query += fmt.Sprintf(" | where Category == '%s'", category)
+	}
+	if len(levels) > 0 {
+		conditions := make([]string, 0, len(levels))
+		for _, level := range levels {
+			mapping := logLevelMappings[level]
+			if tableMode == ResourceSpecificMode {
+				conditions = append(conditions, fmt.Sprintf("Level == '%s'", mapping.ResourceSpecificLevel))
+			} else {
+				conditions = append(conditions, fmt.Sprintf("log_s startswith '%s'", mapping.AzureDiagnosticsPrefix))
+			}
+		}
+		query += " | where " + strings.Join(conditions, " or ")
+	}
+	if tableMode == ResourceSpecificMode {
+		return query + limit + " | project TimeGenerated, Level, Message", nil
+	}
+	return query + limit + " | project TimeGenerated, Level, Message = log_s", nil
+}
+
+// NormalizeTimelineLevel maps the levels of log records and activity log events to ERROR, WARNING or INFO
+func NormalizeTimelineLevel(level string) string {
+	switch lower := strings.ToLower(strings.TrimSpace(level)); {
+	case lower == "":
+		return ""
+	case strings.HasPrefix(lower, "e"), strings.HasPrefix(lower, "crit"), strings.HasPrefix(lower, "f"):
+		return "ERROR"
+	case strings.HasPrefix(lower, "w"):
+		return "WARNING"
+	case strings.HasPrefix(lower, "i"):
+		return "INFO"
+	default:
+		return strings.ToUpper(lower)
+	}
+}
+
+// parseTimelineRows converts the rows of a timeline query into entries of the source
+func parseTimelineRows(output, source string) ([]TimelineEntry, error) {
+	var rows []struct {
+		TimeGenerated string `json:"TimeGenerated"`
+		Level         string `json:"Level"`
+		Message       string `json:"Message"`
+	}
+	if err := json.Unmarshal([]byte(output), &rows); err != nil {
+		return nil, fmt.Errorf("failed to parse query result: %w", err)
+	}
+
+	entries := make([]TimelineEntry, 0, len(rows))
+	for _, row := range rows {
+		timestamp, err := time.Parse(time.RFC3339Nano, row.TimeGenerated)
+		if err != nil {
+			return nil, fmt.Errorf("invalid TimeGenerated '%s': %w", row.TimeGenerated, err)
+		}
+		entries = append(entries, TimelineEntry{
+			Time:    timestamp.UTC(),
+			Source:  source,
+			Level:   NormalizeTimelineLevel(row.Level),
+			Message: strings.TrimSpace(row.Message),
+		})
+	}
+	return entries, nil
+}
+
+// findTimelineCategory returns the category read for a source with the workspace and table mode of its diagnostic
+// setting. The audit source prefers kube-audit-admin, which only logs the mutating requests the timeline shows.
+func findTimelineCategory(subscriptionID, resourceGroup, clusterName, source string, azClient *azureclient.AzureClient, cfg *config.ConfigData) (string, string, bool, error) {
+	if source != TimelineSourceAudit {
+		workspaceResourceID, isResourceSpecific, err := FindDiagnosticSettingForCategory(subscriptionID, resourceGroup, clusterName, source, azClient, cfg)
+		return source, workspaceResourceID, isResourceSpecific, err
+	}
+
+	workspaceResourceID, isResourceSpecific, err := FindDiagnosticSettingForCategory(subscriptionID, resourceGroup, clusterName, "kube-audit-admin", azClient, cfg)
+	if err == nil {
+		return "kube-audit-admin", workspaceResourceID, isResourceSpecific, nil
+	}
+	workspaceResourceID, isResourceSpecific, err = FindDiagnosticSettingForCategory(subscriptionID, resourceGroup, clusterName, "kube-audit", azClient, cfg)
+	if err != nil {
+		return "", "", false, fmt.Errorf("neither kube-audit-admin nor kube-audit is enabled: %w", err)
+	}
+	return "kube-audit", workspaceResourceID, isResourceSpecific, nil
+}
+
+// CollectControlPlaneTimeline reads the most recent records of each control plane log source in the time range, at
+// most maxRecords per source. minLevel overrides the default level of each component when set. A source that cannot
+// be read, e.g. because its category is not enabled in a diagnostic setting, is reported with its error and skipped.
+func CollectControlPlaneTimeline(subscriptionID, resourceGroup, clusterName string, sources []string, minLevel string, maxRecords int, timeRange *common.TimeRange, azClient *azureclient.AzureClient, cfg *config.ConfigData) ([]TimelineSource, []TimelineEntry) {
+	clusterResourceID := buildClusterResourceID(subscriptionID, resourceGroup, clusterName)
+	workspaceGUIDs := map[string]string{}
+
+	var results []TimelineSource
+	var entries []TimelineEntry
+	for _, source := range sources {
+		result := TimelineSource{Name: source}
+		if source != TimelineSourceAudit {
+			result.MinLevel = minLevel
+			if result.MinLevel == "" {
+				result.MinLevel = timelineDefaultLevels[source]
+			}
+		}
+
+		sourceEntries, err := func() ([]TimelineEntry, error) {
+			category, workspaceResourceID, isResourceSpecific, err := findTimelineCategory(subscriptionID, resourceGroup, clusterName, source, azClient, cfg)
+			if err != nil {
+				return nil, err
+			}
+			result.Category = category
+
+			kqlQuery, err := BuildTimelineQuery(category, result.MinLevel, maxRecords, clusterResourceID, isResourceSpecific)
+			if err != nil {
+				return nil, err
+			}
+
+			// Sources sending logs to the same workspace share one lookup
+			workspaceGUID, ok := workspaceGUIDs[strings.ToLower(workspaceResourceID)]
+			if !ok {
+				if workspaceGUID, err = GetWorkspaceGUID(workspaceResourceID, cfg); err != nil {
+					return nil, fmt.Errorf("failed to get workspace GUID: %w", err)
+				}
+				workspaceGUIDs[strings.ToLower(workspaceResourceID)] = workspaceGUID
+			}
+
+			output, err := RunLogAnalyticsQuery(workspaceGUID, kqlQuery, timeRange.Timespan(), cfg)
+			if err != nil {
+				return nil, fmt.Errorf("failed to query %s logs: %w", category, err)
+			}
+			return parseTimelineRows(output, source)
+		}()
+		if err != nil {
+			result.Error = err.Error()
+		}
+
+		result.Records = len(sourceEntries)
+		result.Truncated = len(sourceEntries) >= maxRecords
+		results = append(results, result)
+		entries = append(entries, sourceEntries...)
+	}
+	return results, entries
+}
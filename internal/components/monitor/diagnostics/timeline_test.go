@@ -0,0 +1,87 @@
+package diagnostics
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestBuildTimelineQuery(t *testing.T) {
+	tests := []struct {
+		name               string
+		category           string
+		minLevel           string
+		isResourceSpecific bool
+		expectedContains   []string
+	}{
+		{
+			name:               "kube-apiserver errors in resource-specific tables",
+			category:           "kube-apiserver",
+			minLevel:           "error",
+			isResourceSpecific: true,
+			expectedContains: []string{
+				"AKSControlPlane | where _ResourceId == '" + strings.ToLower(testClusterResourceID) + "' | where Category == 'kube-apiserver'",
+				"where Level == 'ERROR' | order by TimeGenerated desc | limit 25",
+				"project TimeGenerated, Level, Message",
+			},
+		},
+		{
+			name:     "autoscaler warnings in AzureDiagnostics",
+			category: "cluster-autoscaler",
+			minLevel: "warning",
+			expectedContains: []string{
+				"AzureDiagnostics | where Category == 'cluster-autoscaler' and ResourceId == '" + strings.ToUpper(testClusterResourceID) + "'",
+				"where log_s startswith 'W' or log_s startswith 'E'",
+				"project TimeGenerated, Level, Message = log_s",
+			},
+		},
+		{
+			name:     "every scheduler level",
+			category: "kube-scheduler",
+			minLevel: "info",
+			expectedContains: []string{
+				"AzureDiagnostics | where Category == 'kube-scheduler' and ResourceId == '" + strings.ToUpper(testClusterResourceID) + "' | order by TimeGenerated desc",
+			},
+		},
+		{
+			name:     "audited changes",
+			category: "kube-audit",
+			expectedContains: []string{
+				"extend Event = parse_json(log_s)",
+				"where Stage == 'ResponseComplete' and Verb in ('create', 'update', 'patch', 'delete', 'deletecollection')",
+				"Level = iff(Code >= 400, 'WARNING', 'INFO')",
+				"Message = strcat(Username, ' ', Verb, ' ', Object, ' (', Code, ')')",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			query, err := BuildTimelineQuery(tt.category, tt.minLevel, 25, testClusterResourceID, tt.isResourceSpecific)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			for _, expected := range tt.expectedContains {
+				if !strings.Contains(query, expected) {
+					t.Errorf("Expected query to contain '%s', got: %s", expected, query)
+				}
+			}
+		})
+	}
+
+	if _, err := BuildTimelineQuery("kube-apiserver", "debug", 25, testClusterResourceID, true); err == nil || !strings.Contains(err.Error(), "invalid min_level") {
+		t.Errorf("Expected min_level error, got %v", err)
+	}
+	if _, err := BuildTimelineQuery("etcd", "error", 25, testClusterResourceID, true); err == nil || !strings.Contains(err.Error(), "invalid log category") {
+		t.Errorf("Expected category error, got %v", err)
+	}
+}
+
+func TestNormalizeTimelineLevel(t *testing.T) {
+	for level, want := range map[string]string{
+		"ERROR": "ERROR", "Critical": "ERROR", "Warning": "WARNING", "Informational": "INFO", "I": "INFO", "": "", "verbose": "VERBOSE",
+	} {
+		if got := NormalizeTimelineLevel(level); got != want {
+			t.Errorf("NormalizeTimelineLevel(%q) = %q, want %q", level, got, want)
+		}
+	}
+}
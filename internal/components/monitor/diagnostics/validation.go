@@ -17,6 +17,27 @@ const (
 	MaxAllowedRecords     = 1000
 )
 
+// diagnosticsNow returns the server time that relative time ranges resolve against; tests replace it
+var diagnosticsNow = time.Now
+
+// controlPlaneLogCategories are the AKS control plane log categories of diagnostic settings
+var controlPlaneLogCategories = []string{
+	"kube-apiserver",
+	"kube-audit",
+	"kube-audit-admin",
+	"kube-controller-manager",
+	"kube-scheduler",
+	"cluster-autoscaler",
+	"cloud-controller-manager",
+	"guard",
+	"csi-azuredisk-controller",
+	"csi-azurefile-controller",
+	"csi-snapshot-controller",
+	"fleet-member-agent",
+	"fleet-member-net-controller-manager",
+	"fleet-mcs-controller-manager",
+}
+
 // ValidateControlPlaneLogsParams validates all parameters for control plane logs query
 func ValidateControlPlaneLogsParams(params map[string]interface{}) error {
 	// Validate AKS parameters using common helper
@@ -26,7 +47,7 @@ func ValidateControlPlaneLogsParams(params map[string]interface{}) error {
 	}
 
 	// Validate remaining required parameters
-	required := []string{"log_category", "start_time"}
+	required := []string{"log_category"}
 	for _, param := range required {
 		if value, ok := params[param].(string); !ok || value == "" {
 			return fmt.Errorf("missing or invalid %s parameter", param)
@@ -35,37 +56,19 @@ func ValidateControlPlaneLogsParams(params map[string]interface{}) error {
 
 	// Validate log category
 	logCategory := params["log_category"].(string)
-	validCategories := []string{
-		"kube-apiserver",
-		"kube-audit",
-		"kube-audit-admin",
-		"kube-controller-manager",
-		"kube-scheduler",
-		"cluster-autoscaler",
-		"cloud-controller-manager",
-		"guard",
-		"csi-azuredisk-controller",
-		"csi-azurefile-controller",
-		"csi-snapshot-controller",
-		"fleet-member-agent",
-		"fleet-member-net-controller-manager",
-		"fleet-mcs-controller-manager",
-	}
-
 	valid := false
-	for _, validCat := range validCategories {
+	for _, validCat := range controlPlaneLogCategories {
 		if logCategory == validCat {
 			valid = true
 			break
 		}
 	}
 	if !valid {
-		return fmt.Errorf("invalid log category: %s. Valid categories: %s", logCategory, strings.Join(validCategories, ", "))
+		return fmt.Errorf("invalid log category: %s. Valid categories: %s", logCategory, strings.Join(controlPlaneLogCategories, ", "))
 	}
 
 	// Validate time range
-	startTime := params["start_time"].(string)
-	if err := ValidateTimeRange(startTime, params); err != nil {
+	if _, err := ResolveTimeRange(params); err != nil {
 		return err
 	}
 
@@ -87,46 +90,42 @@ func ValidateControlPlaneLogsParams(params map[string]interface{}) error {
 	return nil
 }
 
-// ValidateTimeRange validates start and end time parameters
-func ValidateTimeRange(startTime string, params map[string]interface{}) error {
-	start, err := time.Parse(time.RFC3339, startTime)
+// ResolveTimeRange resolves the time range of a log query from time_range, or start_time and end_time,
+// against the server time. The range cannot be in the future. Explicit and relative ranges cannot exceed
+// MaxQueryRangeDuration, while a start_time timestamp without end_time may reach further back.
+func ResolveTimeRange(params map[string]interface{}) (*common.TimeRange, error) {
+	timeRange, err := common.ParseTimeRange(params, diagnosticsNow(), common.TimeRangeOptions{})
 	if err != nil {
-		return fmt.Errorf("invalid start_time format, expected RFC3339 (ISO 8601): %w", err)
+		return nil, err
 	}
-
-	// // Check if start time is not more than the maximum retention period
-	// maxRetentionAgo := time.Now().AddDate(0, 0, -MaxLogRetentionDays)
-	// if start.Before(maxRetentionAgo) {
-	// 	return fmt.Errorf("start_time cannot be more than %d days ago", MaxLogRetentionDays)
-	// }
-
-	// Check if start time is in the future
-	if start.After(time.Now()) {
-		return fmt.Errorf("start_time cannot be in the future")
+	if window := timeRange.End.Sub(timeRange.Start); window > MaxQueryRangeDuration && !isStartOnly(params) {
+		return nil, fmt.Errorf("time range cannot exceed %v, got %v", MaxQueryRangeDuration, window)
 	}
+	return timeRange, nil
+}
 
-	// Validate end time if provided
-	if endTimeStr, ok := params["end_time"].(string); ok && endTimeStr != "" {
-		end, err := time.Parse(time.RFC3339, endTimeStr)
-		if err != nil {
-			return fmt.Errorf("invalid end_time format, expected RFC3339 (ISO 8601): %w", err)
-		}
-
-		// Check if time range exceeds maximum query duration
-		if end.Sub(start) > MaxQueryRangeDuration {
-			return fmt.Errorf("time range cannot exceed %v", MaxQueryRangeDuration)
-		}
-
-		if end.Before(start) {
-			return fmt.Errorf("end_time must be after start_time")
+// isStartOnly reports whether the time range is a start_time timestamp without end_time
+func isStartOnly(params map[string]interface{}) bool {
+	for _, name := range []string{"time_range", "timespan", "end_time"} {
+		if value, _ := params[name].(string); strings.TrimSpace(value) != "" {
+			return false
 		}
+	}
+	startTime, _ := params["start_time"].(string)
+	_, err := time.Parse(time.RFC3339, strings.TrimSpace(startTime))
+	return err == nil
+}
 
-		if end.After(time.Now()) {
-			return fmt.Errorf("end_time cannot be in the future")
-		}
+// ValidateTimeRange validates start and end time parameters
+func ValidateTimeRange(startTime string, params map[string]interface{}) error {
+	merged := make(map[string]interface{}, len(params)+1)
+	for key, value := range params {
+		merged[key] = value
 	}
+	merged["start_time"] = startTime
 
-	return nil
+	_, err := ResolveTimeRange(merged)
+	return err
 }
 
 // GetMaxRecords extracts and validates the max_records parameter
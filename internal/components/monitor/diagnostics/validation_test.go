@@ -450,3 +450,71 @@ func TestValidateTimeRange_EdgeCases(t *testing.T) {
 		t.Errorf("Expected exactly 24-hour range to be valid, got error: %v", err)
 	}
 }
+
+func TestResolveTimeRange(t *testing.T) {
+	defer func(original func() time.Time) { diagnosticsNow = original }(diagnosticsNow)
+	diagnosticsNow = func() time.Time { return time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC) }
+
+	tests := []struct {
+		name      string
+		params    map[string]interface{}
+		wantStart string
+		wantEnd   string
+		errorMsg  string
+	}{
+		{
+			name:      "last hours",
+			params:    map[string]interface{}{"time_range": "last 2h"},
+			wantStart: "2026-03-01T10:00:00Z",
+			wantEnd:   "2026-03-01T12:00:00Z",
+		},
+		{
+			name:      "start time only",
+			params:    map[string]interface{}{"start_time": "2026-03-01T06:00:00Z"},
+			wantStart: "2026-03-01T06:00:00Z",
+			wantEnd:   "2026-03-01T12:00:00Z",
+		},
+		{
+			name:     "last days over the limit",
+			params:   map[string]interface{}{"time_range": "last 90d"},
+			errorMsg: "time range cannot exceed 24h0m0s",
+		},
+		{
+			name:      "start time only over the limit",
+			params:    map[string]interface{}{"start_time": "2026-02-27T12:00:00Z"},
+			wantStart: "2026-02-27T12:00:00Z",
+			wantEnd:   "2026-03-01T12:00:00Z",
+		},
+		{
+			name:     "start and end time over the limit",
+			params:   map[string]interface{}{"start_time": "2026-02-27T12:00:00Z", "end_time": "2026-03-01T00:00:00Z"},
+			errorMsg: "time range cannot exceed 24h0m0s",
+		},
+		{
+			name:     "relative start time over the limit",
+			params:   map[string]interface{}{"start_time": "last 3d"},
+			errorMsg: "time range cannot exceed 24h0m0s",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			timeRange, err := ResolveTimeRange(tt.params)
+			if tt.errorMsg != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.errorMsg) {
+					t.Fatalf("Expected error containing '%s', got %v", tt.errorMsg, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Expected no error but got: %v", err)
+			}
+			if got := timeRange.Start.Format(time.RFC3339); got != tt.wantStart {
+				t.Errorf("Expected start %s, got %s", tt.wantStart, got)
+			}
+			if got := timeRange.End.Format(time.RFC3339); got != tt.wantEnd {
+				t.Errorf("Expected end %s, got %s", tt.wantEnd, got)
+			}
+		})
+	}
+}
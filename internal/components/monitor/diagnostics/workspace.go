@@ -8,7 +8,9 @@ import (
 
 	"github.com/Azure/aks-mcp/internal/azcli"
 	"github.com/Azure/aks-mcp/internal/azureclient"
+	"github.com/Azure/aks-mcp/internal/command"
 	"github.com/Azure/aks-mcp/internal/config"
+	"github.com/Azure/aks-mcp/internal/security"
 )
 
 // ExtractWorkspaceGUIDFromDiagnosticSettings extracts workspace GUID from diagnostic settings
@@ -33,15 +35,15 @@ func ExtractWorkspaceGUIDFromDiagnosticSettings(subscriptionID, resourceGroup, c
 		setting := diagnosticSettings[0]
 		if setting.Properties != nil && setting.Properties.WorkspaceID != nil && *setting.Properties.WorkspaceID != "" {
 			// Extract workspace GUID from the workspace resource ID
-			return getWorkspaceGUID(*setting.Properties.WorkspaceID, cfg)
+			return GetWorkspaceGUID(*setting.Properties.WorkspaceID, cfg)
 		}
 	}
 
 	return "", fmt.Errorf("no Log Analytics workspace found in diagnostic settings")
 }
 
-// getWorkspaceGUID extracts the workspace GUID from a workspace resource ID
-func getWorkspaceGUID(workspaceResourceID string, cfg *config.ConfigData) (string, error) {
+// GetWorkspaceGUID extracts the workspace GUID from a workspace resource ID
+func GetWorkspaceGUID(workspaceResourceID string, cfg *config.ConfigData) (string, error) {
 	// Parse the workspace resource ID to extract resource group and workspace name
 	// Format: /subscriptions/{sub}/resourcegroups/{rg}/providers/microsoft.operationalinsights/workspaces/{workspace-name}
 	parts := strings.Split(workspaceResourceID, "/")
@@ -85,6 +87,25 @@ func getWorkspaceGUID(workspaceResourceID string, cfg *config.ConfigData) (strin
 	return workspaceGUID, nil
 }
 
+// RunLogAnalyticsQuery runs a KQL query against a Log Analytics workspace over a timespan and returns the rows as JSON.
+// The query is passed as a single argument so it is never split or unquoted.
+func RunLogAnalyticsQuery(workspaceGUID, kqlQuery, timespan string, cfg *config.ConfigData) (string, error) {
+	argv := []string{"az", "monitor", "log-analytics", "query",
+		"--workspace", workspaceGUID,
+		"--analytics-query", kqlQuery,
+		"--timespan", timespan,
+		"--output", "json",
+	}
+	validator := security.NewValidator(cfg.SecurityConfig)
+	cmd := fmt.Sprintf("az monitor log-analytics query --workspace %s --analytics-query \"%s\" --timespan %s --output json", workspaceGUID, kqlQuery, timespan)
+	if err := validator.ValidateCommand(cmd, security.CommandTypeAz); err != nil {
+		return "", err
+	}
+
+	process := command.NewShellProcess(argv[0], cfg.Timeout)
+	return process.RunArgs(argv[1:])
+}
+
 // FindDiagnosticSettingForCategory finds the first diagnostic setting that has the specified log category enabled
 // Returns the workspace ID and whether it uses resource-specific tables
 func FindDiagnosticSettingForCategory(subscriptionID, resourceGroup, clusterName, logCategory string, azClient *azureclient.AzureClient, cfg *config.ConfigData) (string, bool, error) {
@@ -147,5 +168,5 @@ func FindDiagnosticSettingForCategory(subscriptionID, resourceGroup, clusterName
 		}
 	}
 
-	return "", false, fmt.Errorf("no diagnostic setting found with log category '%s' enabled", logCategory)
+	return "", false, fmt.Errorf("no diagnostic setting found with log category '%s' enabled; enable it with the diagnostics_configure operation", logCategory)
 }
@@ -47,7 +47,7 @@ func TestGetWorkspaceGUID(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			_, err := getWorkspaceGUID(tt.workspaceResourceID, cfg)
+			_, err := GetWorkspaceGUID(tt.workspaceResourceID, cfg)
 			if tt.wantError {
 				if err == nil {
 					t.Errorf("Expected error but got none")
@@ -76,7 +76,7 @@ func TestGetWorkspaceGUID_ResourceIDParsing(t *testing.T) {
 	}
 
 	// This will fail at Azure CLI execution but we can check that parsing doesn't fail immediately
-	_, err := getWorkspaceGUID(validResourceID, cfg)
+	_, err := GetWorkspaceGUID(validResourceID, cfg)
 
 	// Should get an Azure CLI execution error, not a parsing error
 	if err != nil && strings.Contains(err.Error(), "invalid workspace resource ID format") {
@@ -161,7 +161,7 @@ func TestGetWorkspaceGUID_EdgeCases(t *testing.T) {
 
 	for _, tc := range testCases {
 		t.Run(tc.name, func(t *testing.T) {
-			_, err := getWorkspaceGUID(tc.resourceID, cfg)
+			_, err := GetWorkspaceGUID(tc.resourceID, cfg)
 			if err == nil {
 				t.Errorf("Expected error for case '%s', got nil", tc.name)
 				return
@@ -8,11 +8,15 @@ import (
 
 	"github.com/Azure/aks-mcp/internal/azcli"
 	"github.com/Azure/aks-mcp/internal/azureclient"
+	"github.com/Azure/aks-mcp/internal/components/common"
 	"github.com/Azure/aks-mcp/internal/components/monitor/diagnostics"
 	"github.com/Azure/aks-mcp/internal/config"
 	"github.com/Azure/aks-mcp/internal/tools"
 )
 
+// defaultAppInsightsWindow is the time range of Application Insights queries without a time parameter
+const defaultAppInsightsWindow = time.Hour
+
 // mergeMonitoringParams merges top-level parameters with nested "parameters" JSON string
 func mergeMonitoringParams(params map[string]interface{}) (map[string]interface{}, error) {
 	merged := make(map[string]interface{})
@@ -56,13 +60,9 @@ func HandleResourceHealthQuery(params map[string]interface{}, cfg *config.Config
 		return "", fmt.Errorf("missing or invalid cluster_name parameter")
 	}
 
-	startTime, ok := params["start_time"].(string)
-	if !ok || startTime == "" {
-		return "", fmt.Errorf("missing or invalid start_time parameter")
-	}
-
-	// Validate parameters
-	if err := validateResourceHealthParams(params); err != nil {
+	// Validate parameters and resolve the time range
+	timeRange, err := validateResourceHealthParams(params)
+	if err != nil {
 		return "", err
 	}
 
@@ -75,16 +75,12 @@ func HandleResourceHealthQuery(params map[string]interface{}, cfg *config.Config
 	args := []string{
 		"monitor", "activity-log", "list",
 		"--resource-id", resourceID,
-		"--start-time", startTime,
+		"--start-time", timeRange.Start.Format(time.RFC3339),
+		"--end-time", timeRange.End.Format(time.RFC3339),
 		"--query", "[?category.value=='ResourceHealth']",
 		"--output", "json",
 	}
 
-	// Add end time if provided
-	if endTime, ok := params["end_time"].(string); ok && endTime != "" {
-		args = append(args, "--end-time", endTime)
-	}
-
 	// Add status filter if provided
 	if status, ok := params["status"].(string); ok && status != "" {
 		// Apply status filter in the query
@@ -102,31 +98,24 @@ func HandleResourceHealthQuery(params map[string]interface{}, cfg *config.Config
 		return "", fmt.Errorf("failed to execute resource health query: %w", err)
 	}
 
-	// Return the raw JSON result from Azure CLI
-	return result, nil
+	// Return the raw JSON result from Azure CLI with the queried time range
+	return common.WithTimeRange(result, timeRange)
 }
 
-// validateResourceHealthParams validates the parameters for resource health queries
-func validateResourceHealthParams(params map[string]interface{}) error {
+// validateResourceHealthParams validates the parameters for resource health queries and resolves their time range
+func validateResourceHealthParams(params map[string]interface{}) (*common.TimeRange, error) {
 	// Validate required parameters
-	required := []string{"subscription_id", "resource_group", "cluster_name", "start_time"}
+	required := []string{"subscription_id", "resource_group", "cluster_name"}
 	for _, param := range required {
 		if value, ok := params[param].(string); !ok || value == "" {
-			return fmt.Errorf("missing or invalid %s parameter", param)
+			return nil, fmt.Errorf("missing or invalid %s parameter", param)
 		}
 	}
 
-	// Validate time format
-	startTime := params["start_time"].(string)
-	if _, err := time.Parse(time.RFC3339, startTime); err != nil {
-		return fmt.Errorf("invalid start_time format, expected RFC3339 (ISO 8601): %w", err)
-	}
-
-	// Validate end_time if provided
-	if endTime, ok := params["end_time"].(string); ok && endTime != "" {
-		if _, err := time.Parse(time.RFC3339, endTime); err != nil {
-			return fmt.Errorf("invalid end_time format, expected RFC3339 (ISO 8601): %w", err)
-		}
+	// Resolve start_time/end_time or time_range against the server time
+	timeRange, err := common.ParseTimeRange(params, monitorNow(), common.TimeRangeOptions{})
+	if err != nil {
+		return nil, err
 	}
 
 	// Validate status if provided
@@ -140,11 +129,11 @@ func validateResourceHealthParams(params map[string]interface{}) error {
 			}
 		}
 		if !valid {
-			return fmt.Errorf("invalid status parameter, must be one of: %s", strings.Join(validStatuses, ", "))
+			return nil, fmt.Errorf("invalid status parameter, must be one of: %s", strings.Join(validStatuses, ", "))
 		}
 	}
 
-	return nil
+	return timeRange, nil
 }
 
 // GetResourceHealthHandler returns a ResourceHandler for the resource health tool
@@ -177,8 +166,9 @@ func HandleAppInsightsQuery(params map[string]interface{}, cfg *config.ConfigDat
 		return "", fmt.Errorf("missing or invalid query parameter")
 	}
 
-	// Validate parameters
-	if err := validateAppInsightsParams(params); err != nil {
+	// Validate parameters and resolve the time range
+	timeRange, err := validateAppInsightsParams(params)
+	if err != nil {
 		return "", err
 	}
 
@@ -192,24 +182,11 @@ func HandleAppInsightsQuery(params map[string]interface{}, cfg *config.ConfigDat
 		"monitor", "app-insights", "query",
 		"--app", appResourceID,
 		"--analytics-query", query,
+		"--start-time", timeRange.Start.Format(time.RFC3339),
+		"--end-time", timeRange.End.Format(time.RFC3339),
 		"--output", "json",
 	}
 
-	// Add start time if provided
-	if startTime, ok := params["start_time"].(string); ok && startTime != "" {
-		args = append(args, "--start-time", startTime)
-	}
-
-	// Add end time if provided
-	if endTime, ok := params["end_time"].(string); ok && endTime != "" {
-		args = append(args, "--end-time", endTime)
-	}
-
-	// Add timespan if provided
-	if timespan, ok := params["timespan"].(string); ok && timespan != "" {
-		args = append(args, "--timespan", timespan)
-	}
-
 	// Execute command
 	cmdParams := map[string]interface{}{
 		"command": "az " + strings.Join(args, " "),
@@ -220,42 +197,23 @@ func HandleAppInsightsQuery(params map[string]interface{}, cfg *config.ConfigDat
 		return "", fmt.Errorf("failed to execute Application Insights query: %w", err)
 	}
 
-	// Return the raw JSON result from Azure CLI
-	return result, nil
+	// Return the raw JSON result from Azure CLI with the queried time range
+	return common.WithTimeRange(result, timeRange)
 }
 
-// validateAppInsightsParams validates the parameters for Application Insights queries
-func validateAppInsightsParams(params map[string]interface{}) error {
+// validateAppInsightsParams validates the parameters for Application Insights queries and resolves their time range.
+// Without a time parameter the query covers the last hour.
+func validateAppInsightsParams(params map[string]interface{}) (*common.TimeRange, error) {
 	// Validate required parameters
 	required := []string{"subscription_id", "resource_group", "app_insights_name", "query"}
 	for _, param := range required {
 		if value, ok := params[param].(string); !ok || value == "" {
-			return fmt.Errorf("missing or invalid %s parameter", param)
-		}
-	}
-
-	// Validate time format for start_time if provided
-	if startTime, ok := params["start_time"].(string); ok && startTime != "" {
-		if _, err := time.Parse(time.RFC3339, startTime); err != nil {
-			return fmt.Errorf("invalid start_time format, expected RFC3339 (ISO 8601): %w", err)
-		}
-	}
-
-	// Validate time format for end_time if provided
-	if endTime, ok := params["end_time"].(string); ok && endTime != "" {
-		if _, err := time.Parse(time.RFC3339, endTime); err != nil {
-			return fmt.Errorf("invalid end_time format, expected RFC3339 (ISO 8601): %w", err)
-		}
-	}
-
-	// Validate timespan format if provided (basic validation for ISO 8601 duration)
-	if timespan, ok := params["timespan"].(string); ok && timespan != "" {
-		if !strings.HasPrefix(timespan, "P") && !strings.HasPrefix(timespan, "PT") {
-			return fmt.Errorf("invalid timespan format, expected ISO 8601 duration (e.g., PT1H, P1D)")
+			return nil, fmt.Errorf("missing or invalid %s parameter", param)
 		}
 	}
 
-	return nil
+	// Resolve start_time/end_time, timespan or time_range against the server time
+	return common.ParseTimeRange(params, monitorNow(), common.TimeRangeOptions{Default: defaultAppInsightsWindow})
 }
 
 // GetAppInsightsHandler returns a ResourceHandler for the Application Insights tool
@@ -265,6 +223,16 @@ func GetAppInsightsHandler(cfg *config.ConfigData) tools.ResourceHandler {
 	})
 }
 
+// cliMonitoringOperations query through the Azure CLI, which uses the tenant of the az login rather than tenant_id
+var cliMonitoringOperations = map[string]bool{
+	string(OpResourceHealth):    true,
+	string(OpAppInsights):       true,
+	string(OpControlPlaneLogs):  true,
+	string(OpContainerInsights): true,
+	string(OpKubeAudit):         true,
+	string(OpTimeline):          true,
+}
+
 // GetAzMonitoringHandler returns a ResourceHandler for the monitoring tool
 func GetAzMonitoringHandler(azClient *azureclient.AzureClient, cfg *config.ConfigData) tools.ResourceHandler {
 	return tools.ResourceHandlerFunc(func(params map[string]interface{}, _ *config.ConfigData) (string, error) {
@@ -280,10 +248,23 @@ func GetAzMonitoringHandler(azClient *azureclient.AzureClient, cfg *config.Confi
 			return "", fmt.Errorf("unsupported operation: %s. Supported operations: %v", operation, supportedOps)
 		}
 
+		// Check access level
+		if err := ValidateMonitoringOperationAccess(operation, cfg); err != nil {
+			return "", err
+		}
+
+		mergedParams, err := mergeMonitoringParams(params)
+		if err != nil {
+			return "", fmt.Errorf("failed to merge parameters: %w", err)
+		}
+		if tenantID, _ := mergedParams["tenant_id"].(string); tenantID != "" && cliMonitoringOperations[operation] {
+			return "", fmt.Errorf("tenant_id is not supported by the %s operation, which runs in the tenant of the Azure CLI login; use az login --tenant to switch tenants", operation)
+		}
+
 		// Handle different operations
 		switch operation {
 		case string(OpMetrics):
-			return handleMetricsOperation(params, cfg)
+			return handleMetricsOperation(params, azClient, cfg)
 		case string(OpResourceHealth):
 			return handleResourceHealthOperation(params, cfg)
 		case string(OpAppInsights):
@@ -292,6 +273,22 @@ func GetAzMonitoringHandler(azClient *azureclient.AzureClient, cfg *config.Confi
 			return handleDiagnosticsOperation(params, azClient, cfg)
 		case string(OpControlPlaneLogs):
 			return handleLogsOperation(params, azClient, cfg)
+		case string(OpTimeline):
+			return handleTimelineOperation(mergedParams, azClient, cfg)
+		case string(OpKubeAudit):
+			return diagnostics.HandleKubeAuditQuery(mergedParams, azClient, cfg)
+		case string(OpDiagnosticsConfigure):
+			return diagnostics.HandleDiagnosticsConfigure(mergedParams, azClient, cfg)
+		case string(OpContainerInsights):
+			return handleContainerInsightsOperation(params, azClient, cfg)
+		case string(OpPrometheus):
+			return handlePrometheusOperation(mergedParams, azClient)
+		case string(OpActivityLog):
+			return handleActivityLogOperation(mergedParams, azClient)
+		case string(OpAlertRules):
+			return handleAlertRulesOperation(mergedParams, azClient)
+		case string(OpAlerts):
+			return handleAlertsOperation(mergedParams, azClient)
 		default:
 			return "", fmt.Errorf("operation '%s' not implemented", operation)
 		}
@@ -300,7 +297,7 @@ func GetAzMonitoringHandler(azClient *azureclient.AzureClient, cfg *config.Confi
 
 // Helper functions for different monitoring operations
 
-func handleMetricsOperation(params map[string]interface{}, cfg *config.ConfigData) (string, error) {
+func handleMetricsOperation(params map[string]interface{}, azClient *azureclient.AzureClient, cfg *config.ConfigData) (string, error) {
 	queryType, ok := params["query_type"].(string)
 	if !ok {
 		return "", fmt.Errorf("missing or invalid 'query_type' parameter for metrics operation")
@@ -310,23 +307,27 @@ func handleMetricsOperation(params map[string]interface{}, cfg *config.ConfigDat
 		return "", fmt.Errorf("invalid query_type: %s. Supported types: list, list-definitions, list-namespaces", queryType)
 	}
 
-	// Extract parameters from JSON string
-	parametersStr, ok := params["parameters"].(string)
-	if !ok {
-		return "", fmt.Errorf("missing or invalid 'parameters' parameter")
+	// Merge parameters from top-level and nested JSON
+	mergedParams, err := mergeMonitoringParams(params)
+	if err != nil {
+		return "", fmt.Errorf("failed to merge parameters: %w", err)
 	}
 
-	// Parse JSON parameters and convert to command-line arguments
-	var jsonParams map[string]interface{}
-	if err := json.Unmarshal([]byte(parametersStr), &jsonParams); err != nil {
-		return "", fmt.Errorf("failed to parse parameters JSON: %w", err)
+	// Metric values are queried through the Azure Monitor metrics API and summarized per series
+	if queryType == "list" {
+		return handleMetricsList(mergedParams, azClient)
 	}
 
-	// Convert JSON parameters to command-line argument format [--key1 value1 --key2 value2]
-	var args []string
-	for key, value := range jsonParams {
-		args = append(args, "--"+key)
-		args = append(args, fmt.Sprintf("%v", value))
+	// Metric definitions and namespaces only take the resource and, for definitions, the metric namespace
+	resourceID, _ := mergedParams["resource"].(string)
+	if resourceID == "" {
+		return "", fmt.Errorf("missing or invalid 'resource' parameter")
+	}
+	args := []string{"--resource", resourceID}
+	if queryType == "list-definitions" {
+		if namespace, _ := mergedParams["metric_namespace"].(string); namespace != "" {
+			args = append(args, "--namespace", namespace)
+		}
 	}
 
 	// Map query type to command
@@ -387,3 +388,13 @@ func handleLogsOperation(params map[string]interface{}, azClient *azureclient.Az
 	// Use existing control plane logs handler
 	return diagnostics.GetControlPlaneLogsHandler(azClient, cfg).Handle(mergedParams, cfg)
 }
+
+func handleContainerInsightsOperation(params map[string]interface{}, azClient *azureclient.AzureClient, cfg *config.ConfigData) (string, error) {
+	// Merge parameters from top-level and nested JSON
+	mergedParams, err := mergeMonitoringParams(params)
+	if err != nil {
+		return "", fmt.Errorf("failed to merge parameters: %w", err)
+	}
+
+	return diagnostics.HandleContainerInsightsQuery(mergedParams, azClient, cfg)
+}
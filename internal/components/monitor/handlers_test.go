@@ -1,7 +1,15 @@
 package monitor
 
 import (
+	"encoding/json"
+	"path/filepath"
+	"strings"
 	"testing"
+	"time"
+
+	"github.com/Azure/aks-mcp/internal/command"
+	"github.com/Azure/aks-mcp/internal/components/common"
+	"github.com/Azure/aks-mcp/internal/config"
 )
 
 func TestHandleAppInsightsQuery_ValidParameters(t *testing.T) {
@@ -14,7 +22,7 @@ func TestHandleAppInsightsQuery_ValidParameters(t *testing.T) {
 
 	// This would normally execute the Azure CLI command, but since we don't have
 	// Azure CLI available in test, we just validate that parameters are processed correctly
-	err := validateAppInsightsParams(params)
+	_, err := validateAppInsightsParams(params)
 	if err != nil {
 		t.Errorf("Expected no error for valid parameters, got: %v", err)
 	}
@@ -61,7 +69,7 @@ func TestHandleAppInsightsQuery_MissingParameters(t *testing.T) {
 
 	for _, tc := range testCases {
 		t.Run(tc.name, func(t *testing.T) {
-			err := validateAppInsightsParams(tc.params)
+			_, err := validateAppInsightsParams(tc.params)
 			if err == nil {
 				t.Errorf("Expected error for %s, got nil", tc.name)
 			}
@@ -119,11 +127,34 @@ func TestValidateAppInsightsParams_TimeValidation(t *testing.T) {
 			},
 			expectError: true,
 		},
+		{
+			name: "relative time_range",
+			params: map[string]interface{}{
+				"subscription_id":   "test-sub",
+				"resource_group":    "test-rg",
+				"app_insights_name": "test-ai",
+				"query":             "requests | limit 10",
+				"time_range":        "last 2h",
+			},
+			expectError: false,
+		},
+		{
+			name: "time_range with start_time",
+			params: map[string]interface{}{
+				"subscription_id":   "test-sub",
+				"resource_group":    "test-rg",
+				"app_insights_name": "test-ai",
+				"query":             "requests | limit 10",
+				"time_range":        "last 2h",
+				"start_time":        "2025-01-01T00:00:00Z",
+			},
+			expectError: true,
+		},
 	}
 
 	for _, tc := range testCases {
 		t.Run(tc.name, func(t *testing.T) {
-			err := validateAppInsightsParams(tc.params)
+			_, err := validateAppInsightsParams(tc.params)
 			if tc.expectError && err == nil {
 				t.Errorf("Expected error for %s, got nil", tc.name)
 			} else if !tc.expectError && err != nil {
@@ -132,3 +163,79 @@ func TestValidateAppInsightsParams_TimeValidation(t *testing.T) {
 		})
 	}
 }
+
+func TestGetAzMonitoringHandler_Replay(t *testing.T) {
+	stop, err := command.UseCassette(filepath.Join("testdata", "cassettes", "monitoring.json"))
+	if err != nil {
+		t.Fatalf("Failed to load cassette: %v", err)
+	}
+	defer func() {
+		if err := stop(); err != nil {
+			t.Errorf("Failed to save cassette: %v", err)
+		}
+	}()
+
+	cfg := config.NewConfig()
+	handler := GetAzMonitoringHandler(nil, cfg)
+	clusterID := "/subscriptions/00000000-0000-0000-0000-000000000000/resourceGroups/test-rg/providers/Microsoft.ContainerService/managedClusters/test-cluster"
+
+	t.Run("metrics", func(t *testing.T) {
+		result, err := handler.Handle(map[string]interface{}{
+			"operation":  "metrics",
+			"query_type": "list-definitions",
+			"parameters": `{"resource": "` + clusterID + `"}`,
+		}, cfg)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if !strings.Contains(result, "apiserver_current_inflight_requests") {
+			t.Errorf("Expected metric definitions, got %q", result)
+		}
+	})
+
+	t.Run("resource_health", func(t *testing.T) {
+		defer func(original func() time.Time) { monitorNow = original }(monitorNow)
+		monitorNow = func() time.Time { return time.Date(2025, 1, 2, 0, 0, 0, 0, time.UTC) }
+
+		result, err := handler.Handle(map[string]interface{}{
+			"operation":       "resource_health",
+			"subscription_id": "00000000-0000-0000-0000-000000000000",
+			"resource_group":  "test-rg",
+			"cluster_name":    "test-cluster",
+			"parameters":      `{"start_time": "2025-01-01T00:00:00Z"}`,
+		}, cfg)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+
+		// The events are returned with the resolved time range, which ends now when end_time is not set
+		var response struct {
+			TimeRange common.TimeRange         `json:"time_range"`
+			Result    []map[string]interface{} `json:"result"`
+		}
+		if err := json.Unmarshal([]byte(result), &response); err != nil {
+			t.Fatalf("Expected JSON events with a time range, got %q: %v", result, err)
+		}
+		if len(response.Result) != 1 {
+			t.Errorf("Expected 1 resource health event, got %d", len(response.Result))
+		}
+		if response.TimeRange.Duration != "24h0m0s" {
+			t.Errorf("Unexpected time range: %+v", response.TimeRange)
+		}
+	})
+}
+
+func TestGetAzMonitoringHandler_TenantIDWithAzureCLI(t *testing.T) {
+	cfg := config.NewConfig()
+	_, err := GetAzMonitoringHandler(nil, cfg).Handle(map[string]interface{}{
+		"operation":       "control_plane_logs",
+		"subscription_id": "00000000-0000-0000-0000-000000000000",
+		"resource_group":  "test-rg",
+		"cluster_name":    "test-cluster",
+		"tenant_id":       "72f988bf-86f1-41af-91ab-2d7cd011db47",
+		"parameters":      `{"log_category": "kube-apiserver", "time_range": "last 1h"}`,
+	}, cfg)
+	if err == nil || !strings.Contains(err.Error(), "tenant_id is not supported by the control_plane_logs operation") {
+		t.Errorf("Expected tenant_id to be rejected for an Azure CLI operation, got %v", err)
+	}
+}
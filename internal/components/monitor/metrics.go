@@ -0,0 +1,470 @@
+package monitor
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Azure/aks-mcp/internal/azureclient"
+	"github.com/Azure/aks-mcp/internal/components/common"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/arm"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/to"
+	"github.com/Azure/azure-sdk-for-go/sdk/monitor/azquery"
+)
+
+const (
+	// defaultMetricsWindow is the time range queried when no time parameter is set
+	defaultMetricsWindow = time.Hour
+	// defaultMetricsAggregation is the aggregation queried when aggregation is not set
+	defaultMetricsAggregation = "Average"
+	// maxMetricsTop is the largest number of series a metrics query may ask for with top
+	maxMetricsTop = 1000
+	// flatTrendPercent is the change over the time range, relative to the average, below which a series is flat
+	flatTrendPercent = 5.0
+)
+
+// metricsAggregations are the aggregation types Azure Monitor metrics support
+var metricsAggregations = []string{"Average", "Minimum", "Maximum", "Total", "Count"}
+
+// metricsIntervals are the time grains Azure Monitor metrics support
+var metricsIntervals = []string{"PT1M", "PT5M", "PT15M", "PT30M", "PT1H", "PT6H", "PT12H", "P1D", "FULL"}
+
+// monitorNow returns the server time that relative time ranges resolve against; tests replace it
+var monitorNow = time.Now
+
+// metricsQuery is a typed metrics list query
+type metricsQuery struct {
+	SubscriptionID  string
+	ResourceID      string
+	MetricNames     []string
+	Aggregation     string
+	Interval        string
+	TimeRange       *common.TimeRange
+	Filter          string
+	MetricNamespace string
+	Top             int32
+	IncludePoints   bool
+}
+
+// MetricPoint is one value of a metric time series
+type MetricPoint struct {
+	Timestamp string   `json:"timestamp"`
+	Value     *float64 `json:"value"`
+}
+
+// MetricGap is a range of consecutive intervals without a value
+type MetricGap struct {
+	Start  string `json:"start"`
+	End    string `json:"end"`
+	Points int    `json:"points"`
+}
+
+// SeriesSummary summarizes the values of a time series for the queried aggregation
+type SeriesSummary struct {
+	Points        int         `json:"points"`
+	Missing       int         `json:"missing"`
+	Min           *float64    `json:"min,omitempty"`
+	Max           *float64    `json:"max,omitempty"`
+	Avg           *float64    `json:"avg,omitempty"`
+	P95           *float64    `json:"p95,omitempty"`
+	First         *float64    `json:"first,omitempty"`
+	Last          *float64    `json:"last,omitempty"`
+	Trend         string      `json:"trend,omitempty"`
+	ChangePercent *float64    `json:"change_percent,omitempty"`
+	Gaps          []MetricGap `json:"gaps,omitempty"`
+}
+
+// MetricSeries is one time series of a metric, identified by its dimension values
+type MetricSeries struct {
+	Dimensions map[string]string `json:"dimensions,omitempty"`
+	Summary    SeriesSummary     `json:"summary"`
+	Points     []MetricPoint     `json:"points,omitempty"`
+}
+
+// MetricResult is the result for one metric name
+type MetricResult struct {
+	Name   string         `json:"name"`
+	Unit   string         `json:"unit,omitempty"`
+	Error  string         `json:"error,omitempty"`
+	Series []MetricSeries `json:"series"`
+}
+
+// MetricsResult is the response of the metrics list query
+type MetricsResult struct {
+	Resource    string            `json:"resource"`
+	TimeRange   *common.TimeRange `json:"time_range"`
+	Timespan    string            `json:"timespan"`
+	Interval    string            `json:"interval,omitempty"`
+	Aggregation string            `json:"aggregation"`
+	Metrics     []MetricResult    `json:"metrics"`
+}
+
+// handleMetricsList queries metric values through the Azure Monitor metrics API and summarizes each time series
+func handleMetricsList(params map[string]interface{}, azClient *azureclient.AzureClient) (string, error) {
+	if azClient == nil {
+		return "", fmt.Errorf("azure client is required but not provided")
+	}
+
+	query, err := parseMetricsQuery(params)
+	if err != nil {
+		return "", err
+	}
+
+	options := &azquery.MetricsClientQueryResourceOptions{
+		MetricNames: to.Ptr(strings.Join(query.MetricNames, ",")),
+		Aggregation: []*azquery.AggregationType{to.Ptr(azquery.AggregationType(query.Aggregation))},
+		Timespan:    to.Ptr(azquery.TimeInterval(query.TimeRange.Timespan())),
+		ResultType:  to.Ptr(azquery.ResultTypeData),
+	}
+	if query.Interval != "" {
+		options.Interval = to.Ptr(query.Interval)
+	}
+	if query.Filter != "" {
+		options.Filter = to.Ptr(query.Filter)
+	}
+	if query.MetricNamespace != "" {
+		options.MetricNamespace = to.Ptr(query.MetricNamespace)
+	}
+	if query.Top > 0 {
+		options.Top = to.Ptr(query.Top)
+	}
+
+	ctx, err := common.TenantContext(context.Background(), azClient, params)
+	if err != nil {
+		return "", err
+	}
+	response, err := azClient.GetMetrics(ctx, query.SubscriptionID, query.ResourceID, options)
+	if err != nil {
+		return "", err
+	}
+
+	result := summarizeMetrics(query, response)
+	resultJSON, err := json.MarshalIndent(result, "", "  ")
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal metrics to JSON: %w", err)
+	}
+	return string(resultJSON), nil
+}
+
+// parseMetricsQuery builds a metrics query from the merged operation parameters.
+// The resource defaults to the AKS cluster given by subscription_id, resource_group and cluster_name.
+func parseMetricsQuery(params map[string]interface{}) (*metricsQuery, error) {
+	query := &metricsQuery{}
+
+	resourceID, _ := params["resource"].(string)
+	if resourceID == "" {
+		subscriptionID, _ := params["subscription_id"].(string)
+		resourceGroup, _ := params["resource_group"].(string)
+		clusterName, _ := params["cluster_name"].(string)
+		if subscriptionID == "" || resourceGroup == "" || clusterName == "" {
+			return nil, fmt.Errorf("missing resource parameter: set resource to a resource ID, or subscription_id, resource_group and cluster_name for an AKS cluster")
+		}
+		resourceID = fmt.Sprintf("/subscriptions/%s/resourceGroups/%s/providers/Microsoft.ContainerService/managedClusters/%s",
+			subscriptionID, resourceGroup, clusterName)
+	}
+	parsed, err := arm.ParseResourceID(resourceID)
+	if err != nil || parsed.SubscriptionID == "" {
+		return nil, fmt.Errorf("invalid resource ID '%s'", resourceID)
+	}
+	query.ResourceID = resourceID
+	query.SubscriptionID = parsed.SubscriptionID
+
+	query.MetricNames = stringList(params["metric_names"])
+	if len(query.MetricNames) == 0 {
+		return nil, fmt.Errorf("missing metric_names parameter, e.g. node_cpu_usage_percentage")
+	}
+
+	query.Aggregation = defaultMetricsAggregation
+	if aggregation, _ := params["aggregation"].(string); aggregation != "" {
+		query.Aggregation = ""
+		for _, supported := range metricsAggregations {
+			if strings.EqualFold(aggregation, supported) {
+				query.Aggregation = supported
+			}
+		}
+		if query.Aggregation == "" {
+			return nil, fmt.Errorf("invalid aggregation '%s', expected one of: %s", aggregation, strings.Join(metricsAggregations, ", "))
+		}
+	}
+
+	if interval, _ := params["interval"].(string); interval != "" {
+		for _, supported := range metricsIntervals {
+			if strings.EqualFold(interval, supported) {
+				query.Interval = supported
+			}
+		}
+		if query.Interval == "" {
+			return nil, fmt.Errorf("invalid interval '%s', expected one of: %s", interval, strings.Join(metricsIntervals, ", "))
+		}
+	}
+
+	query.TimeRange, err = common.ParseTimeRange(params, monitorNow(), common.TimeRangeOptions{Default: defaultMetricsWindow})
+	if err != nil {
+		return nil, err
+	}
+
+	query.Filter, err = metricsFilter(params)
+	if err != nil {
+		return nil, err
+	}
+
+	query.MetricNamespace, _ = params["metric_namespace"].(string)
+
+	switch top := params["top"].(type) {
+	case float64:
+		if top < 1 || top > maxMetricsTop || top != math.Trunc(top) {
+			return nil, fmt.Errorf("top must be a whole number between 1 and %d", maxMetricsTop)
+		}
+		query.Top = int32(top)
+	case string:
+		value, err := strconv.ParseInt(top, 10, 32)
+		if err != nil || value < 1 || value > maxMetricsTop {
+			return nil, fmt.Errorf("top must be a whole number between 1 and %d", maxMetricsTop)
+		}
+		query.Top = int32(value)
+	}
+
+	switch include := params["include_points"].(type) {
+	case bool:
+		query.IncludePoints = include
+	case string:
+		query.IncludePoints = strings.EqualFold(include, "true")
+	}
+
+	return query, nil
+}
+
+// metricsFilter builds the $filter expression from the dimensions parameter, a map of dimension name
+// to a value, a list of values, or "*" to split the result into one series per value.
+// A raw filter expression may be given instead.
+func metricsFilter(params map[string]interface{}) (string, error) {
+	filter, _ := params["filter"].(string)
+
+	var dimensions map[string]interface{}
+	switch value := params["dimensions"].(type) {
+	case nil:
+	case map[string]interface{}:
+		dimensions = value
+	case string:
+		if value != "" {
+			if err := json.Unmarshal([]byte(value), &dimensions); err != nil {
+				return "", fmt.Errorf("invalid dimensions, expected a JSON object of dimension names to values: %w", err)
+			}
+		}
+	default:
+		return "", fmt.Errorf("invalid dimensions, expected an object of dimension names to values")
+	}
+
+	if len(dimensions) == 0 {
+		return filter, nil
+	}
+	if filter != "" {
+		return "", fmt.Errorf("set either dimensions or filter, not both")
+	}
+
+	names := make([]string, 0, len(dimensions))
+	for name := range dimensions {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	clauses := make([]string, 0, len(names))
+	for _, name := range names {
+		values := stringList(dimensions[name])
+		if len(values) == 0 {
+			return "", fmt.Errorf("dimension '%s' has no value", name)
+		}
+		var parts []string
+		for _, value := range values {
+			parts = append(parts, fmt.Sprintf("%s eq '%s'", name, strings.ReplaceAll(value, "'", "''")))
+		}
+		clause := strings.Join(parts, " or ")
+		if len(parts) > 1 {
+			clause = "(" + clause + ")"
+		}
+		clauses = append(clauses, clause)
+	}
+	return strings.Join(clauses, " and "), nil
+}
+
+// summarizeMetrics converts the metrics response into per-series summaries
+func summarizeMetrics(query *metricsQuery, response *azquery.Response) MetricsResult {
+	result := MetricsResult{
+		Resource:    query.ResourceID,
+		TimeRange:   query.TimeRange,
+		Timespan:    query.TimeRange.Timespan(),
+		Aggregation: query.Aggregation,
+		Metrics:     []MetricResult{},
+	}
+	if response.Timespan != nil {
+		result.Timespan = string(*response.Timespan)
+	}
+	if response.Interval != nil {
+		result.Interval = *response.Interval
+	}
+
+	for _, metric := range response.Value {
+		if metric == nil {
+			continue
+		}
+		metricResult := MetricResult{Series: []MetricSeries{}}
+		if metric.Name != nil && metric.Name.Value != nil {
+			metricResult.Name = *metric.Name.Value
+		}
+		if metric.Unit != nil {
+			metricResult.Unit = string(*metric.Unit)
+		}
+		if metric.ErrorCode != nil && *metric.ErrorCode != "" && *metric.ErrorCode != "Success" {
+			metricResult.Error = *metric.ErrorCode
+			if metric.ErrorMessage != nil {
+				metricResult.Error += ": " + *metric.ErrorMessage
+			}
+		}
+
+		for _, timeseries := range metric.TimeSeries {
+			if timeseries == nil {
+				continue
+			}
+			series := MetricSeries{}
+			for _, metadata := range timeseries.MetadataValues {
+				if metadata == nil || metadata.Name == nil || metadata.Name.Value == nil || metadata.Value == nil {
+					continue
+				}
+				if series.Dimensions == nil {
+					series.Dimensions = make(map[string]string)
+				}
+				series.Dimensions[*metadata.Name.Value] = *metadata.Value
+			}
+
+			points := seriesPoints(timeseries.Data, query.Aggregation)
+			series.Summary = summarizeSeries(points)
+			if query.IncludePoints {
+				series.Points = points
+			}
+			metricResult.Series = append(metricResult.Series, series)
+		}
+		result.Metrics = append(result.Metrics, metricResult)
+	}
+	return result
+}
+
+// seriesPoints returns the value of the queried aggregation at each timestamp, in time order
+func seriesPoints(data []*azquery.MetricValue, aggregation string) []MetricPoint {
+	points := make([]MetricPoint, 0, len(data))
+	for _, value := range data {
+		if value == nil || value.TimeStamp == nil {
+			continue
+		}
+		var v *float64
+		switch aggregation {
+		case "Average":
+			v = value.Average
+		case "Minimum":
+			v = value.Minimum
+		case "Maximum":
+			v = value.Maximum
+		case "Total":
+			v = value.Total
+		case "Count":
+			v = value.Count
+		}
+		points = append(points, MetricPoint{Timestamp: value.TimeStamp.UTC().Format(time.RFC3339), Value: v})
+	}
+	sort.SliceStable(points, func(i, j int) bool { return points[i].Timestamp < points[j].Timestamp })
+	return points
+}
+
+// summarizeSeries computes the statistics, trend and gaps of a time series.
+// Timestamps without a value are gaps, e.g. when a node was down or the agent stopped reporting.
+func summarizeSeries(points []MetricPoint) SeriesSummary {
+	summary := SeriesSummary{Points: len(points)}
+
+	var values []float64
+	var indexes []float64
+	var gap *MetricGap
+	for i, point := range points {
+		if point.Value == nil {
+			summary.Missing++
+			if gap == nil {
+				summary.Gaps = append(summary.Gaps, MetricGap{Start: point.Timestamp})
+				gap = &summary.Gaps[len(summary.Gaps)-1]
+			}
+			gap.End = point.Timestamp
+			gap.Points++
+			continue
+		}
+		gap = nil
+		values = append(values, *point.Value)
+		indexes = append(indexes, float64(i))
+	}
+	if len(values) == 0 {
+		return summary
+	}
+
+	sum := 0.0
+	minValue, maxValue := values[0], values[0]
+	for _, value := range values {
+		sum += value
+		minValue = math.Min(minValue, value)
+		maxValue = math.Max(maxValue, value)
+	}
+	avg := sum / float64(len(values))
+
+	sorted := append([]float64(nil), values...)
+	sort.Float64s(sorted)
+	p95 := sorted[int(math.Ceil(0.95*float64(len(sorted))))-1]
+
+	summary.Min = round(minValue)
+	summary.Max = round(maxValue)
+	summary.Avg = round(avg)
+	summary.P95 = round(p95)
+	summary.First = round(values[0])
+	summary.Last = round(values[len(values)-1])
+
+	if len(values) >= 2 {
+		change := slope(indexes, values) * (indexes[len(indexes)-1] - indexes[0])
+		summary.Trend = "flat"
+		if avg != 0 {
+			percent := change / math.Abs(avg) * 100
+			summary.ChangePercent = round(percent)
+			if percent >= flatTrendPercent {
+				summary.Trend = "rising"
+			} else if percent <= -flatTrendPercent {
+				summary.Trend = "falling"
+			}
+		} else if change > 0 {
+			summary.Trend = "rising"
+		} else if change < 0 {
+			summary.Trend = "falling"
+		}
+	}
+	return summary
+}
+
+// slope returns the least-squares slope of y over x
+func slope(x, y []float64) float64 {
+	n := float64(len(x))
+	var sumX, sumY, sumXY, sumXX float64
+	for i := range x {
+		sumX += x[i]
+		sumY += y[i]
+		sumXY += x[i] * y[i]
+		sumXX += x[i] * x[i]
+	}
+	denominator := n*sumXX - sumX*sumX
+	if denominator == 0 {
+		return 0
+	}
+	return (n*sumXY - sumX*sumY) / denominator
+}
+
+// round rounds a value to 4 decimal places to keep summaries compact
+func round(value float64) *float64 {
+	rounded := math.Round(value*10000) / 10000
+	return &rounded
+}
@@ -0,0 +1,171 @@
+package monitor
+
+import (
+	"encoding/json"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Azure/aks-mcp/internal/azureclient/fakearm"
+	"github.com/Azure/aks-mcp/internal/azureclient/fakearm/armtest"
+	"github.com/Azure/aks-mcp/internal/config"
+)
+
+func TestGetAzMonitoringHandler_MetricsList(t *testing.T) {
+	_, client, cfg := armtest.NewClient(t, filepath.Join("testdata", "metrics.json"))
+
+	params := map[string]interface{}{
+		"operation":       "metrics",
+		"query_type":      "list",
+		"subscription_id": fakearm.SubscriptionID,
+		"resource_group":  fakearm.ResourceGroup,
+		"cluster_name":    fakearm.ClusterName,
+		"parameters":      `{"metric_names": "node_cpu_usage_percentage", "interval": "PT30M", "timespan": "PT6H", "dimensions": {"node": "*"}}`,
+	}
+	output, err := GetAzMonitoringHandler(client, cfg).Handle(params, cfg)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	var result MetricsResult
+	if err := json.Unmarshal([]byte(output), &result); err != nil {
+		t.Fatalf("Failed to parse result: %v\n%s", err, output)
+	}
+	if result.Resource != fakearm.ClusterResourceID || result.Interval != "PT30M" || result.Aggregation != "Average" {
+		t.Errorf("Unexpected query details: %+v", result)
+	}
+	if len(result.Metrics) != 1 || len(result.Metrics[0].Series) != 2 {
+		t.Fatalf("Expected 1 metric with 2 series, got %s", output)
+	}
+	metric := result.Metrics[0]
+	if metric.Name != "node_cpu_usage_percentage" || metric.Unit != "Percent" || metric.Error != "" {
+		t.Errorf("Unexpected metric: %+v", metric)
+	}
+
+	rising := metric.Series[0]
+	if rising.Dimensions["node"] != "aks-nodepool1-12345678-vmss000000" {
+		t.Errorf("Unexpected dimensions: %v", rising.Dimensions)
+	}
+	if rising.Summary.Trend != "rising" || *rising.Summary.Min != 20 || *rising.Summary.Max != 55 || *rising.Summary.P95 != 55 {
+		t.Errorf("Unexpected summary for the rising series: %+v", rising.Summary)
+	}
+	if rising.Summary.Missing != 2 || len(rising.Summary.Gaps) != 1 || rising.Summary.Gaps[0].Start != "2026-03-01T07:30:00Z" || rising.Summary.Gaps[0].Points != 2 {
+		t.Errorf("Expected one gap of 2 points at 07:30, got %+v", rising.Summary.Gaps)
+	}
+	if rising.Points != nil {
+		t.Errorf("Expected no raw points unless include_points is set, got %d", len(rising.Points))
+	}
+
+	if flat := metric.Series[1].Summary; flat.Trend != "flat" || *flat.Avg != 40.0833 || flat.Missing != 0 {
+		t.Errorf("Unexpected summary for the flat series: %+v", flat)
+	}
+
+	// Raw points are added on request
+	params["parameters"] = `{"metric_names": "node_cpu_usage_percentage", "include_points": true}`
+	output, err = GetAzMonitoringHandler(client, cfg).Handle(params, cfg)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if err := json.Unmarshal([]byte(output), &result); err != nil {
+		t.Fatalf("Failed to parse result: %v", err)
+	}
+	if len(result.Metrics[0].Series[0].Points) != 12 || result.Metrics[0].Series[0].Points[3].Value != nil {
+		t.Errorf("Expected 12 raw points with a gap, got %+v", result.Metrics[0].Series[0].Points)
+	}
+}
+
+func TestGetAzMonitoringHandler_MetricsListRequiresClient(t *testing.T) {
+	cfg := config.NewConfig()
+	_, err := GetAzMonitoringHandler(nil, cfg).Handle(map[string]interface{}{
+		"operation":  "metrics",
+		"query_type": "list",
+		"parameters": `{"resource": "` + fakearm.ClusterResourceID + `", "metric_names": "node_cpu_usage_percentage"}`,
+	}, cfg)
+	if err == nil || !strings.Contains(err.Error(), "azure client is required") {
+		t.Errorf("Expected missing client error, got %v", err)
+	}
+}
+
+func TestParseMetricsQuery(t *testing.T) {
+	defer func(original func() time.Time) { monitorNow = original }(monitorNow)
+	monitorNow = func() time.Time { return time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC) }
+
+	query, err := parseMetricsQuery(map[string]interface{}{
+		"resource":     fakearm.ClusterResourceID,
+		"metric_names": "node_cpu_usage_percentage, node_memory_working_set_percentage",
+		"aggregation":  "maximum",
+		"interval":     "pt5m",
+		"timespan":     "P1DT6H",
+		"dimensions":   map[string]interface{}{"node": "*", "phase": []interface{}{"Failed", "Pending"}},
+		"top":          float64(20),
+	})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if query.SubscriptionID != fakearm.SubscriptionID {
+		t.Errorf("Expected subscription from the resource ID, got %s", query.SubscriptionID)
+	}
+	if strings.Join(query.MetricNames, ",") != "node_cpu_usage_percentage,node_memory_working_set_percentage" {
+		t.Errorf("Unexpected metric names: %v", query.MetricNames)
+	}
+	if query.Aggregation != "Maximum" || query.Interval != "PT5M" || query.Top != 20 {
+		t.Errorf("Unexpected query: %+v", query)
+	}
+	if query.TimeRange.Timespan() != "2026-02-28T06:00:00Z/2026-03-01T12:00:00Z" {
+		t.Errorf("Unexpected timespan: %s", query.TimeRange.Timespan())
+	}
+	if query.Filter != "node eq '*' and (phase eq 'Failed' or phase eq 'Pending')" {
+		t.Errorf("Unexpected filter: %s", query.Filter)
+	}
+
+	errorCases := []struct {
+		name   string
+		params map[string]interface{}
+		errMsg string
+	}{
+		{"no resource", map[string]interface{}{"metric_names": "x"}, "missing resource parameter"},
+		{"no metric names", map[string]interface{}{"resource": fakearm.ClusterResourceID}, "missing metric_names"},
+		{"bad aggregation", map[string]interface{}{"resource": fakearm.ClusterResourceID, "metric_names": "x", "aggregation": "median"}, "invalid aggregation"},
+		{"bad interval", map[string]interface{}{"resource": fakearm.ClusterResourceID, "metric_names": "x", "interval": "PT2M"}, "invalid interval"},
+		{"bad timespan", map[string]interface{}{"resource": fakearm.ClusterResourceID, "metric_names": "x", "timespan": "6 hours"}, "invalid timespan"},
+		{"reversed range", map[string]interface{}{"resource": fakearm.ClusterResourceID, "metric_names": "x", "timespan": "2026-03-01T12:00:00Z/2026-03-01T06:00:00Z"}, "before its end"},
+		{"filter and dimensions", map[string]interface{}{"resource": fakearm.ClusterResourceID, "metric_names": "x", "filter": "node eq '*'", "dimensions": `{"node": "*"}`}, "either dimensions or filter, not both"},
+		{"top too large", map[string]interface{}{"resource": fakearm.ClusterResourceID, "metric_names": "x", "top": float64(1e12)}, "top must be a whole number between 1 and 1000"},
+		{"top too large string", map[string]interface{}{"resource": fakearm.ClusterResourceID, "metric_names": "x", "top": "5000"}, "top must be a whole number between 1 and 1000"},
+	}
+	for _, tc := range errorCases {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := parseMetricsQuery(tc.params)
+			if err == nil || !strings.Contains(err.Error(), tc.errMsg) {
+				t.Errorf("Expected error containing %q, got %v", tc.errMsg, err)
+			}
+		})
+	}
+}
+
+func TestSummarizeSeries(t *testing.T) {
+	value := func(v float64) *float64 { return &v }
+	points := func(values ...*float64) []MetricPoint {
+		var out []MetricPoint
+		for i, v := range values {
+			out = append(out, MetricPoint{Timestamp: time.Date(2026, 3, 1, 0, i, 0, 0, time.UTC).Format(time.RFC3339), Value: v})
+		}
+		return out
+	}
+
+	falling := summarizeSeries(points(value(100), value(80), value(60), nil, value(20)))
+	if falling.Trend != "falling" || *falling.First != 100 || *falling.Last != 20 || falling.Missing != 1 {
+		t.Errorf("Unexpected falling summary: %+v", falling)
+	}
+
+	empty := summarizeSeries(points(nil, nil))
+	if empty.Avg != nil || empty.Trend != "" || len(empty.Gaps) != 1 || empty.Gaps[0].Points != 2 {
+		t.Errorf("Unexpected summary without values: %+v", empty)
+	}
+
+	single := summarizeSeries(points(value(5)))
+	if single.Trend != "" || *single.P95 != 5 {
+		t.Errorf("Unexpected single point summary: %+v", single)
+	}
+}
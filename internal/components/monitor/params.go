@@ -0,0 +1,56 @@
+package monitor
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// stringList returns a comma-separated string or a list as trimmed, non-empty strings
+func stringList(value interface{}) []string {
+	var items []string
+	switch v := value.(type) {
+	case string:
+		items = strings.Split(v, ",")
+	case []interface{}:
+		for _, item := range v {
+			items = append(items, fmt.Sprint(item))
+		}
+	}
+
+	var out []string
+	for _, item := range items {
+		if item = strings.TrimSpace(item); item != "" {
+			out = append(out, item)
+		}
+	}
+	return out
+}
+
+// positiveInt returns a whole number parameter between 1 and maxValue given as a JSON number or a string,
+// or fallback when it is not set
+func positiveInt(params map[string]interface{}, name string, fallback, maxValue int) (int, error) {
+	var n int
+	switch value := params[name].(type) {
+	case float64:
+		if value < 1 || value != math.Trunc(value) {
+			return 0, fmt.Errorf("%s must be a positive whole number", name)
+		}
+		if value > float64(maxValue) {
+			return 0, fmt.Errorf("%s cannot exceed %d", name, maxValue)
+		}
+		n = int(value)
+	case string:
+		var err error
+		if n, err = strconv.Atoi(value); err != nil || n < 1 {
+			return 0, fmt.Errorf("%s must be a positive whole number", name)
+		}
+		if n > maxValue {
+			return 0, fmt.Errorf("%s cannot exceed %d", name, maxValue)
+		}
+	default:
+		return fallback, nil
+	}
+	return n, nil
+}
@@ -0,0 +1,58 @@
+package monitor
+
+import (
+	"math"
+	"strings"
+	"testing"
+)
+
+func TestStringList(t *testing.T) {
+	if got := stringList(" a, ,b "); strings.Join(got, "|") != "a|b" {
+		t.Errorf("Unexpected list from a string: %v", got)
+	}
+	if got := stringList([]interface{}{"a", 2.0, " "}); strings.Join(got, "|") != "a|2" {
+		t.Errorf("Unexpected list from a list: %v", got)
+	}
+	if got := stringList(nil); got != nil {
+		t.Errorf("Expected no items, got %v", got)
+	}
+}
+
+func TestPositiveInt(t *testing.T) {
+	valid := []struct {
+		value interface{}
+		want  int
+	}{
+		{nil, 50},
+		{float64(1), 1},
+		{float64(100), 100},
+		{"25", 25},
+	}
+	for _, tc := range valid {
+		got, err := positiveInt(map[string]interface{}{"n": tc.value}, "n", 50, 100)
+		if err != nil || got != tc.want {
+			t.Errorf("Expected %d for %v, got %d (%v)", tc.want, tc.value, got, err)
+		}
+	}
+
+	invalid := []struct {
+		value  interface{}
+		errMsg string
+	}{
+		{float64(0), "n must be a positive whole number"},
+		{2.5, "n must be a positive whole number"},
+		{math.NaN(), "n must be a positive whole number"},
+		{"-3", "n must be a positive whole number"},
+		{"99999999999999999999", "n must be a positive whole number"},
+		{float64(101), "n cannot exceed 100"},
+		{1e300, "n cannot exceed 100"},
+		{math.Inf(1), "n cannot exceed 100"},
+		{"101", "n cannot exceed 100"},
+	}
+	for _, tc := range invalid {
+		_, err := positiveInt(map[string]interface{}{"n": tc.value}, "n", 50, 100)
+		if err == nil || !strings.Contains(err.Error(), tc.errMsg) {
+			t.Errorf("Expected error containing %q for %v, got %v", tc.errMsg, tc.value, err)
+		}
+	}
+}
@@ -0,0 +1,385 @@
+package monitor
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Azure/aks-mcp/internal/azureclient"
+	"github.com/Azure/aks-mcp/internal/components/common"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/arm"
+)
+
+const (
+	// maxPrometheusRange is the longest time range a range query may cover
+	maxPrometheusRange = 7 * 24 * time.Hour
+	// maxPrometheusPoints is the most points a range query may return per series
+	maxPrometheusPoints = 1000
+	// defaultPrometheusPoints is the number of points the default step aims for
+	defaultPrometheusPoints = 120
+	// defaultPrometheusMaxSeries is the number of series returned when max_series is not set
+	defaultPrometheusMaxSeries = 50
+	// maxPrometheusMaxSeries is the most series max_series may ask for
+	maxPrometheusMaxSeries = 1000
+	// maxPrometheusQueryLength is the longest PromQL expression accepted
+	maxPrometheusQueryLength = 4096
+)
+
+// prometheusQuery is a typed PromQL query against an Azure Monitor workspace
+type prometheusQuery struct {
+	Expression    string
+	Range         bool
+	Time          time.Time         // instant queries
+	TimeRange     *common.TimeRange // range queries
+	Step          time.Duration
+	MaxSeries     int
+	IncludePoints bool
+	Workspace     string // optional Azure Monitor workspace resource ID to query
+}
+
+// PrometheusSeries is one series of a PromQL result, identified by its labels.
+// Instant vectors have a value; range results have a summary like the metrics operation.
+type PrometheusSeries struct {
+	Labels  map[string]string `json:"labels,omitempty"`
+	Value   *float64          `json:"value,omitempty"`
+	Summary *SeriesSummary    `json:"summary,omitempty"`
+	Points  []MetricPoint     `json:"points,omitempty"`
+}
+
+// PrometheusResult is the response of the prometheus operation
+type PrometheusResult struct {
+	Workspace   string             `json:"workspace"`
+	Query       string             `json:"query"`
+	QueryType   string             `json:"query_type"`
+	Time        string             `json:"time,omitempty"`
+	TimeRange   *common.TimeRange  `json:"time_range,omitempty"`
+	Step        string             `json:"step,omitempty"`
+	ResultType  string             `json:"result_type"`
+	TotalSeries int                `json:"total_series"`
+	Truncated   bool               `json:"truncated,omitempty"`
+	Series      []PrometheusSeries `json:"series"`
+}
+
+// prometheusData is the data field of a Prometheus query response
+type prometheusData struct {
+	ResultType string          `json:"resultType"`
+	Result     json.RawMessage `json:"result"`
+}
+
+// prometheusSample is a [unix time, "value"] pair
+type prometheusSample [2]interface{}
+
+// handlePrometheusOperation runs an instant or range PromQL query against the Azure Monitor workspace
+// that receives the cluster's managed Prometheus metrics
+func handlePrometheusOperation(params map[string]interface{}, azClient *azureclient.AzureClient) (string, error) {
+	if azClient == nil {
+		return "", fmt.Errorf("azure client is required but not provided")
+	}
+
+	subscriptionID, resourceGroup, clusterName, err := common.ExtractAKSParameters(params)
+	if err != nil {
+		return "", err
+	}
+
+	query, err := parsePrometheusQuery(params)
+	if err != nil {
+		return "", err
+	}
+
+	ctx, err := common.TenantContext(context.Background(), azClient, params)
+	if err != nil {
+		return "", err
+	}
+	workspaceID, endpoint, err := findPrometheusWorkspace(ctx, azClient, subscriptionID, resourceGroup, clusterName, query.Workspace)
+	if err != nil {
+		return "", err
+	}
+
+	result := PrometheusResult{
+		Workspace: workspaceID,
+		Query:     query.Expression,
+		QueryType: "instant",
+		Series:    []PrometheusSeries{},
+	}
+	values := url.Values{"query": {query.Expression}}
+	path := "/api/v1/query"
+	if query.Range {
+		path = "/api/v1/query_range"
+		result.QueryType = "range"
+		result.TimeRange = query.TimeRange
+		result.Step = query.Step.String()
+		values.Set("start", strconv.FormatInt(query.TimeRange.Start.Unix(), 10))
+		values.Set("end", strconv.FormatInt(query.TimeRange.End.Unix(), 10))
+		values.Set("step", strconv.FormatFloat(query.Step.Seconds(), 'f', -1, 64))
+	} else {
+		result.Time = query.Time.Format(time.RFC3339)
+		values.Set("time", strconv.FormatInt(query.Time.Unix(), 10))
+	}
+
+	data, err := azClient.QueryPrometheus(ctx, subscriptionID, endpoint, path, values)
+	if err != nil {
+		return "", err
+	}
+	if err := summarizePrometheus(query, data, &result); err != nil {
+		return "", err
+	}
+
+	resultJSON, err := json.MarshalIndent(result, "", "  ")
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal Prometheus result to JSON: %w", err)
+	}
+	return string(resultJSON), nil
+}
+
+// parsePrometheusQuery builds a PromQL query from the merged operation parameters.
+// query_type is instant (the default) or range; range queries are bounded by maxPrometheusRange and maxPrometheusPoints.
+func parsePrometheusQuery(params map[string]interface{}) (*prometheusQuery, error) {
+	query := &prometheusQuery{}
+
+	query.Expression, _ = params["query"].(string)
+	query.Expression = strings.TrimSpace(query.Expression)
+	if query.Expression == "" {
+		return nil, fmt.Errorf("missing query parameter, e.g. sum by (namespace) (rate(container_cpu_usage_seconds_total[5m]))")
+	}
+	if len(query.Expression) > maxPrometheusQueryLength {
+		return nil, fmt.Errorf("query cannot exceed %d characters", maxPrometheusQueryLength)
+	}
+
+	switch queryType, _ := params["query_type"].(string); queryType {
+	case "", "instant":
+		query.Time = monitorNow().UTC()
+		if at, _ := params["time"].(string); at != "" {
+			parsed, err := time.Parse(time.RFC3339, at)
+			if err != nil {
+				return nil, fmt.Errorf("invalid time format, expected RFC3339 (ISO 8601): %w", err)
+			}
+			query.Time = parsed.UTC()
+		}
+	case "range":
+		query.Range = true
+		timeRange, err := common.ParseTimeRange(params, monitorNow(), common.TimeRangeOptions{
+			Default:     defaultMetricsWindow,
+			MaxDuration: maxPrometheusRange,
+		})
+		if err != nil {
+			return nil, err
+		}
+		query.TimeRange = timeRange
+		window := timeRange.End.Sub(timeRange.Start)
+
+		if step, _ := params["step"].(string); step != "" {
+			query.Step, err = parsePrometheusStep(step)
+			if err != nil {
+				return nil, err
+			}
+		} else {
+			// Aim for defaultPrometheusPoints points, in whole minutes
+			query.Step = max(time.Minute, (window / defaultPrometheusPoints).Round(time.Minute))
+		}
+		if points := int(window/query.Step) + 1; points > maxPrometheusPoints {
+			return nil, fmt.Errorf("step %v gives %d points per series, which exceeds %d: use a larger step or a shorter time range", query.Step, points, maxPrometheusPoints)
+		}
+	default:
+		return nil, fmt.Errorf("invalid query_type: %s. Supported types: instant, range", queryType)
+	}
+
+	maxSeries, err := positiveInt(params, "max_series", defaultPrometheusMaxSeries, maxPrometheusMaxSeries)
+	if err != nil {
+		return nil, err
+	}
+	query.MaxSeries = maxSeries
+
+	switch include := params["include_points"].(type) {
+	case bool:
+		query.IncludePoints = include
+	case string:
+		query.IncludePoints = strings.EqualFold(include, "true")
+	}
+
+	query.Workspace, _ = params["azure_monitor_workspace"].(string)
+
+	return query, nil
+}
+
+// parsePrometheusStep parses a query resolution as an ISO 8601 duration (PT5M) or a Go duration (5m, 30s)
+func parsePrometheusStep(value string) (time.Duration, error) {
+	step, err := time.ParseDuration(value)
+	if err != nil {
+		step, err = common.ParseISODuration(value)
+		if err != nil {
+			return 0, fmt.Errorf("invalid step '%s', expected a duration such as PT5M, 5m or 30s", value)
+		}
+	}
+	if step < time.Second {
+		return 0, fmt.Errorf("invalid step '%s', the step must be at least one second", value)
+	}
+	return step.Truncate(time.Second), nil
+}
+
+// findPrometheusWorkspace follows the cluster's data collection rule associations to the Azure Monitor workspaces
+// that receive its managed Prometheus metrics and returns the workspace ID and Prometheus query endpoint.
+// When workspace is set, only that workspace is accepted.
+func findPrometheusWorkspace(ctx context.Context, azClient *azureclient.AzureClient, subscriptionID, resourceGroup, clusterName, workspace string) (string, string, error) {
+	clusterID := fmt.Sprintf("/subscriptions/%s/resourceGroups/%s/providers/Microsoft.ContainerService/managedClusters/%s",
+		subscriptionID, resourceGroup, clusterName)
+
+	associations, err := azClient.GetDataCollectionRuleAssociations(ctx, subscriptionID, clusterID)
+	if err != nil {
+		return "", "", err
+	}
+
+	var found []string
+	for _, association := range associations {
+		if association == nil || association.Properties == nil || association.Properties.DataCollectionRuleID == nil {
+			continue
+		}
+		ruleID, err := arm.ParseResourceID(*association.Properties.DataCollectionRuleID)
+		if err != nil {
+			continue
+		}
+		rule, err := azClient.GetDataCollectionRule(ctx, ruleID.SubscriptionID, ruleID.ResourceGroupName, ruleID.Name)
+		if err != nil {
+			return "", "", err
+		}
+		if rule.Properties == nil || rule.Properties.Destinations == nil {
+			continue
+		}
+
+		for _, destination := range rule.Properties.Destinations.MonitoringAccounts {
+			if destination == nil || destination.AccountResourceID == nil {
+				continue
+			}
+			accountID := *destination.AccountResourceID
+			found = append(found, accountID)
+			if workspace != "" && !strings.EqualFold(accountID, workspace) {
+				continue
+			}
+
+			parsed, err := arm.ParseResourceID(accountID)
+			if err != nil {
+				return "", "", fmt.Errorf("invalid Azure Monitor workspace ID '%s': %v", accountID, err)
+			}
+			account, err := azClient.GetAzureMonitorWorkspace(ctx, parsed.SubscriptionID, parsed.ResourceGroupName, parsed.Name)
+			if err != nil {
+				return "", "", err
+			}
+			if account.Properties == nil || account.Properties.Metrics == nil || account.Properties.Metrics.PrometheusQueryEndpoint == nil {
+				return "", "", fmt.Errorf("workspace %s has no Prometheus query endpoint", accountID)
+			}
+			return accountID, *account.Properties.Metrics.PrometheusQueryEndpoint, nil
+		}
+	}
+
+	if workspace != "" && len(found) > 0 {
+		return "", "", fmt.Errorf("workspace %s does not receive metrics from cluster %s. Linked workspaces: %s", workspace, clusterName, strings.Join(found, ", "))
+	}
+	return "", "", fmt.Errorf("no Azure Monitor workspace found for cluster %s: enable managed Prometheus (Azure Monitor metrics) on the cluster first", clusterName)
+}
+
+// summarizePrometheus converts the data of a Prometheus query response into the result series.
+// Range series are summarized like metrics, with the steps without a sample reported as gaps.
+func summarizePrometheus(query *prometheusQuery, raw json.RawMessage, result *PrometheusResult) error {
+	var data prometheusData
+	if err := json.Unmarshal(raw, &data); err != nil {
+		return fmt.Errorf("failed to parse Prometheus response: %w", err)
+	}
+	result.ResultType = data.ResultType
+
+	switch data.ResultType {
+	case "vector":
+		var vector []struct {
+			Metric map[string]string `json:"metric"`
+			Value  prometheusSample  `json:"value"`
+		}
+		if err := json.Unmarshal(data.Result, &vector); err != nil {
+			return fmt.Errorf("failed to parse Prometheus vector: %w", err)
+		}
+		for _, sample := range vector {
+			_, value := sample.Value.parse()
+			result.Series = append(result.Series, PrometheusSeries{Labels: sample.Metric, Value: value})
+		}
+		sort.SliceStable(result.Series, func(i, j int) bool {
+			return seriesRank(result.Series[i].Value) > seriesRank(result.Series[j].Value)
+		})
+
+	case "matrix":
+		var matrix []struct {
+			Metric map[string]string  `json:"metric"`
+			Values []prometheusSample `json:"values"`
+		}
+		if err := json.Unmarshal(data.Result, &matrix); err != nil {
+			return fmt.Errorf("failed to parse Prometheus matrix: %w", err)
+		}
+		for _, series := range matrix {
+			points := rangePoints(query, series.Values)
+			summary := summarizeSeries(points)
+			item := PrometheusSeries{Labels: series.Metric, Summary: &summary}
+			if query.IncludePoints {
+				item.Points = points
+			}
+			result.Series = append(result.Series, item)
+		}
+		sort.SliceStable(result.Series, func(i, j int) bool {
+			return seriesRank(result.Series[i].Summary.Avg) > seriesRank(result.Series[j].Summary.Avg)
+		})
+
+	case "scalar", "string":
+		var sample prometheusSample
+		if err := json.Unmarshal(data.Result, &sample); err != nil {
+			return fmt.Errorf("failed to parse Prometheus %s: %w", data.ResultType, err)
+		}
+		_, value := sample.parse()
+		result.Series = append(result.Series, PrometheusSeries{Value: value})
+
+	default:
+		return fmt.Errorf("unexpected Prometheus result type '%s'", data.ResultType)
+	}
+
+	// Series are ordered by value, largest first, so truncation keeps the top series
+	result.TotalSeries = len(result.Series)
+	if len(result.Series) > query.MaxSeries {
+		result.Series = result.Series[:query.MaxSeries]
+		result.Truncated = true
+	}
+	return nil
+}
+
+// rangePoints places the samples of a range series on the query steps; steps without a sample have no value
+func rangePoints(query *prometheusQuery, samples []prometheusSample) []MetricPoint {
+	values := make(map[int64]*float64, len(samples))
+	for _, sample := range samples {
+		timestamp, value := sample.parse()
+		values[timestamp] = value
+	}
+
+	var points []MetricPoint
+	for t := query.TimeRange.Start; !t.After(query.TimeRange.End); t = t.Add(query.Step) {
+		points = append(points, MetricPoint{Timestamp: t.UTC().Format(time.RFC3339), Value: values[t.Unix()]})
+	}
+	return points
+}
+
+// parse returns the Unix time and value of a sample. NaN and infinite values have no value.
+func (s prometheusSample) parse() (int64, *float64) {
+	timestamp, _ := s[0].(float64)
+	text, _ := s[1].(string)
+	value, err := strconv.ParseFloat(text, 64)
+	if err != nil || math.IsNaN(value) || math.IsInf(value, 0) {
+		return int64(timestamp), nil
+	}
+	return int64(timestamp), &value
+}
+
+// seriesRank orders series without a value last
+func seriesRank(value *float64) float64 {
+	if value == nil {
+		return math.Inf(-1)
+	}
+	return *value
+}
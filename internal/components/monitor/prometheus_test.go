@@ -0,0 +1,185 @@
+package monitor
+
+import (
+	"encoding/json"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Azure/aks-mcp/internal/azureclient"
+	"github.com/Azure/aks-mcp/internal/azureclient/fakearm"
+	"github.com/Azure/aks-mcp/internal/azureclient/fakearm/armtest"
+	"github.com/Azure/aks-mcp/internal/config"
+)
+
+const testMonitorWorkspaceID = "/subscriptions/" + fakearm.SubscriptionID + "/resourceGroups/test-rg/providers/Microsoft.Monitor/accounts/test-amw"
+
+// newPrometheusTestClient serves the cluster's data collection rules and an Azure Monitor workspace
+// whose Prometheus query endpoint is the fake server itself
+func newPrometheusTestClient(t *testing.T) (*fakearm.Server, *azureclient.AzureClient, *config.ConfigData) {
+	t.Helper()
+
+	srv, client, cfg := armtest.NewClient(t, filepath.Join("testdata", "prometheus.json"))
+	err := srv.SetResponse(testMonitorWorkspaceID, map[string]interface{}{
+		"id":       testMonitorWorkspaceID,
+		"name":     "test-amw",
+		"location": "eastus",
+		"properties": map[string]interface{}{
+			"metrics": map[string]interface{}{
+				"prometheusQueryEndpoint": srv.URL + "/prometheus",
+			},
+		},
+	})
+	if err != nil {
+		t.Fatalf("Failed to set workspace response: %v", err)
+	}
+	return srv, client, cfg
+}
+
+func TestGetAzMonitoringHandler_PrometheusRange(t *testing.T) {
+	srv, client, cfg := newPrometheusTestClient(t)
+
+	output, err := GetAzMonitoringHandler(client, cfg).Handle(map[string]interface{}{
+		"operation":       "prometheus",
+		"query_type":      "range",
+		"subscription_id": fakearm.SubscriptionID,
+		"resource_group":  fakearm.ResourceGroup,
+		"cluster_name":    fakearm.ClusterName,
+		"parameters":      `{"query": "sum by (namespace) (rate(container_cpu_usage_seconds_total[5m]))", "start_time": "2026-03-01T09:00:00Z", "end_time": "2026-03-01T12:00:00Z", "step": "PT30M"}`,
+	}, cfg)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	var result PrometheusResult
+	if err := json.Unmarshal([]byte(output), &result); err != nil {
+		t.Fatalf("Failed to parse result: %v\n%s", err, output)
+	}
+	if result.Workspace != testMonitorWorkspaceID || result.ResultType != "matrix" || result.Step != "30m0s" || result.TotalSeries != 2 {
+		t.Errorf("Unexpected query details: %+v", result)
+	}
+	if result.TimeRange == nil || result.TimeRange.Duration != "3h0m0s" {
+		t.Errorf("Unexpected query details: %+v", result)
+	}
+	if srv.RequestCount("/prometheus/api/v1/query_range") != 1 {
+		t.Errorf("Expected one range query, got requests %v", srv.Requests())
+	}
+
+	// Series are ordered by average, largest first
+	busiest := result.Series[0]
+	if busiest.Labels["namespace"] != "default" || busiest.Summary == nil {
+		t.Fatalf("Expected the default namespace first, got %+v", result.Series)
+	}
+	if busiest.Summary.Points != 7 || busiest.Summary.Missing != 1 || busiest.Summary.Gaps[0].Start != "2026-03-01T10:00:00Z" {
+		t.Errorf("Expected 7 steps with a gap at 10:00, got %+v", busiest.Summary)
+	}
+	if busiest.Summary.Trend != "rising" || *busiest.Summary.Max != 1.6 || busiest.Points != nil {
+		t.Errorf("Unexpected summary: %+v", busiest.Summary)
+	}
+
+	// NaN samples count as missing
+	if quiet := result.Series[1].Summary; quiet.Trend != "flat" || quiet.Missing != 1 {
+		t.Errorf("Unexpected summary for kube-system: %+v", quiet)
+	}
+}
+
+func TestGetAzMonitoringHandler_PrometheusInstant(t *testing.T) {
+	_, client, cfg := newPrometheusTestClient(t)
+
+	output, err := GetAzMonitoringHandler(client, cfg).Handle(map[string]interface{}{
+		"operation":       "prometheus",
+		"subscription_id": fakearm.SubscriptionID,
+		"resource_group":  fakearm.ResourceGroup,
+		"cluster_name":    fakearm.ClusterName,
+		"parameters":      `{"query": "sum by (namespace) (rate(container_cpu_usage_seconds_total[5m]))", "max_series": 2}`,
+	}, cfg)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	var result PrometheusResult
+	if err := json.Unmarshal([]byte(output), &result); err != nil {
+		t.Fatalf("Failed to parse result: %v", err)
+	}
+	if result.QueryType != "instant" || result.ResultType != "vector" || result.TotalSeries != 3 || !result.Truncated || len(result.Series) != 2 {
+		t.Fatalf("Unexpected result: %s", output)
+	}
+	if result.Series[0].Labels["namespace"] != "default" || *result.Series[0].Value != 1.6 || result.Series[1].Labels["namespace"] != "monitoring" {
+		t.Errorf("Expected the top 2 namespaces by value, got %+v", result.Series)
+	}
+}
+
+func TestGetAzMonitoringHandler_PrometheusWorkspaceNotFound(t *testing.T) {
+	srv, client, cfg := newPrometheusTestClient(t)
+	params := map[string]interface{}{
+		"operation":       "prometheus",
+		"subscription_id": fakearm.SubscriptionID,
+		"resource_group":  fakearm.ResourceGroup,
+		"cluster_name":    fakearm.ClusterName,
+		"parameters":      `{"query": "up", "azure_monitor_workspace": "/subscriptions/` + fakearm.SubscriptionID + `/resourceGroups/other-rg/providers/Microsoft.Monitor/accounts/other"}`,
+	}
+
+	_, err := GetAzMonitoringHandler(client, cfg).Handle(params, cfg)
+	if err == nil || !strings.Contains(err.Error(), "Linked workspaces: "+testMonitorWorkspaceID) {
+		t.Errorf("Expected error listing the linked workspace, got %v", err)
+	}
+
+	// Without managed Prometheus the cluster has no data collection rule sending to a workspace
+	if err := srv.SetResponse(fakearm.ClusterResourceID+"/providers/Microsoft.Insights/dataCollectionRuleAssociations", map[string]interface{}{"value": []interface{}{}}); err != nil {
+		t.Fatalf("Failed to set associations: %v", err)
+	}
+	// A new client has an empty cache
+	cfg = config.NewConfig()
+	client = armtest.NewClientFor(t, srv, cfg)
+	params["parameters"] = `{"query": "up"}`
+	_, err = GetAzMonitoringHandler(client, cfg).Handle(params, cfg)
+	if err == nil || !strings.Contains(err.Error(), "enable managed Prometheus") {
+		t.Errorf("Expected managed Prometheus error, got %v", err)
+	}
+}
+
+func TestParsePrometheusQuery(t *testing.T) {
+	defer func(original func() time.Time) { monitorNow = original }(monitorNow)
+	monitorNow = func() time.Time { return time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC) }
+
+	query, err := parsePrometheusQuery(map[string]interface{}{"query": "up", "query_type": "range", "timespan": "P1D"})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if !query.Range || query.Step != 12*time.Minute || query.TimeRange.Start != time.Date(2026, 2, 28, 12, 0, 0, 0, time.UTC) {
+		t.Errorf("Expected a default step of 12 minutes over one day, got %+v", query)
+	}
+
+	query, err = parsePrometheusQuery(map[string]interface{}{"query": "up", "time": "2026-03-01T08:00:00+01:00"})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if query.Range || query.Time != time.Date(2026, 3, 1, 7, 0, 0, 0, time.UTC) || query.MaxSeries != defaultPrometheusMaxSeries {
+		t.Errorf("Unexpected instant query: %+v", query)
+	}
+
+	errorCases := []struct {
+		name   string
+		params map[string]interface{}
+		errMsg string
+	}{
+		{"no query", map[string]interface{}{"query": "  "}, "missing query parameter"},
+		{"query too long", map[string]interface{}{"query": strings.Repeat("x", maxPrometheusQueryLength+1)}, "query cannot exceed"},
+		{"bad query type", map[string]interface{}{"query": "up", "query_type": "list"}, "invalid query_type"},
+		{"range too long", map[string]interface{}{"query": "up", "query_type": "range", "timespan": "P8D"}, "time range cannot exceed"},
+		{"too many points", map[string]interface{}{"query": "up", "query_type": "range", "timespan": "P1D", "step": "30s"}, "exceeds 1000"},
+		{"bad step", map[string]interface{}{"query": "up", "query_type": "range", "step": "often"}, "invalid step"},
+		{"bad time", map[string]interface{}{"query": "up", "time": "noon"}, "invalid time format"},
+		{"bad max_series", map[string]interface{}{"query": "up", "max_series": float64(0)}, "max_series must be"},
+		{"max_series too large", map[string]interface{}{"query": "up", "max_series": float64(1e6)}, "max_series cannot exceed 1000"},
+	}
+	for _, tc := range errorCases {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := parsePrometheusQuery(tc.params)
+			if err == nil || !strings.Contains(err.Error(), tc.errMsg) {
+				t.Errorf("Expected error containing %q, got %v", tc.errMsg, err)
+			}
+		})
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"slices"
 
+	"github.com/Azure/aks-mcp/internal/config"
 	"github.com/mark3labs/mcp-go/mcp"
 )
 
@@ -11,11 +12,20 @@ import (
 type MonitoringOperationType string
 
 const (
-	OpMetrics          MonitoringOperationType = "metrics"
-	OpResourceHealth   MonitoringOperationType = "resource_health"
-	OpAppInsights      MonitoringOperationType = "app_insights"
-	OpDiagnostics      MonitoringOperationType = "diagnostics"
-	OpControlPlaneLogs MonitoringOperationType = "control_plane_logs"
+	OpMetrics           MonitoringOperationType = "metrics"
+	OpResourceHealth    MonitoringOperationType = "resource_health"
+	OpAppInsights       MonitoringOperationType = "app_insights"
+	OpDiagnostics       MonitoringOperationType = "diagnostics"
+	OpControlPlaneLogs  MonitoringOperationType = "control_plane_logs"
+	OpContainerInsights MonitoringOperationType = "container_insights"
+	OpPrometheus        MonitoringOperationType = "prometheus"
+	OpActivityLog       MonitoringOperationType = "activity_log"
+	OpKubeAudit         MonitoringOperationType = "kube_audit"
+	OpTimeline          MonitoringOperationType = "control_plane_timeline"
+	OpAlertRules        MonitoringOperationType = "alert_rules"
+	OpAlerts            MonitoringOperationType = "alerts"
+
+	OpDiagnosticsConfigure MonitoringOperationType = "diagnostics_configure"
 )
 
 // RegisterAzMonitoring registers the monitoring tool
@@ -23,21 +33,49 @@ func RegisterAzMonitoring() mcp.Tool {
 	description := `Unified tool for Azure monitoring and diagnostics operations for AKS clusters.
 
 Supported operations:
-- metrics: Query metrics for Azure resources (list, list-definitions, list-namespaces)
+- metrics: Query metrics for Azure resources (list, list-definitions, list-namespaces). The list query returns a summary per time series (min, max, avg, p95, trend, gaps) instead of every data point
 - resource_health: Get resource health events for AKS clusters
+- activity_log: Show who changed what: administrative activity log events of the cluster, its node resource group and the network resources discovered from it, deduplicated and grouped into changes by correlation ID
+- alert_rules: List the metric alert, log search alert and Prometheus rule group rules scoped to the cluster, its resource group or subscription, or the Log Analytics workspaces it sends logs to, with their conditions
+- alerts: List the alerts Alerts Management raised for the cluster in a time range, fired or resolved, each joined to the definition of the rule that raised it
 - app_insights: Execute KQL queries against Application Insights data
 - diagnostics: Check AKS cluster diagnostic settings configuration
+- diagnostics_configure: Enable control plane log categories on a diagnostic setting of the cluster, sending them to a Log Analytics workspace in dedicated or AzureDiagnostics table mode (requires readwrite access)
 - control_plane_logs: Query AKS control plane logs with safety constraints
+- control_plane_timeline: Merge kube-apiserver errors, controller-manager, scheduler and cluster-autoscaler warnings, audited changes and resource health events of the cluster into one time-ordered timeline, each entry tagged with its source
+- kube_audit: Answer who did what in the cluster from its kube-audit or kube-audit-admin logs with a query template (user_actions, object_mutations, denied_requests, exec_sessions, secret_reads), in either diagnostic settings table mode
+- container_insights: Run a Container Insights query template (pod_restarts, oom_killed, container_logs, node_not_ready, top_consumers) against the workspace of the cluster's monitoring addon
+- prometheus: Run an instant or range PromQL query against the Azure Monitor workspace that receives the cluster's managed Prometheus metrics. Range results are summarized per series like metrics
 
 Examples:
-- List metrics: operation="metrics", query_type="list", parameters="{\"resource\":\"<aks-cluster-id>\"}"
+- Node CPU over the last 6 hours: operation="metrics", query_type="list", subscription_id="<subscription-id>", resource_group="<resource-group>", cluster_name="<cluster-name>", parameters="{\"metric_names\":\"node_cpu_usage_percentage\", \"aggregation\":\"Average\", \"interval\":\"PT5M\", \"timespan\":\"PT6H\", \"dimensions\":{\"node\":\"*\"}}"
+- List metrics parameters: metric_names (comma-separated, required), resource (defaults to the cluster), aggregation (Average, Minimum, Maximum, Total, Count), interval (PT1M, PT5M, PT15M, PT30M, PT1H, PT6H, PT12H, P1D), time_range (default last hour) or start_time/end_time, dimensions (dimension name to value, list of values or "*" to split by it) or filter, metric_namespace, top (at most 1000 series), include_points (add the raw data points)
 - List metrics definitions: operation="metrics", query_type="list-definitions", parameters="{\"resource\":\"<aks-cluster-id>\"}"
 - List metrics namespaces: operation="metrics", query_type="list-namespaces", parameters="{\"resource\":\"<aks-cluster-id>\"}"
-- Resource health: operation="resource_health", subscription_id="<subscription-id>", resource_group="<resource-group>", cluster_name="<cluster-name>", parameters="{\"start_time\":\"2025-01-01T00:00:00Z\"}"
+- Resource health: operation="resource_health", subscription_id="<subscription-id>", resource_group="<resource-group>", cluster_name="<cluster-name>", parameters="{\"time_range\":\"last 24h\"}"
+- Failed changes in the last week: operation="activity_log", subscription_id="<subscription-id>", resource_group="<resource-group>", cluster_name="<cluster-name>", parameters="{\"time_range\":\"last 7d\", \"status\":\"Failed\"}"
+- Activity log parameters: time_range or start_time/end_time (default last 24 hours, within the last 90 days), scope (comma-separated: cluster, node_resource_group, network; default all), operation_name (substring, e.g. managedClusters/write or securityRules), caller (substring, e.g. a user or app ID), status (final status of the change, e.g. Succeeded or Failed), max_changes (default 50, at most 500, most recent first kept)
+- Alert rules of the cluster: operation="alert_rules", subscription_id="<subscription-id>", resource_group="<resource-group>", cluster_name="<cluster-name>", parameters="{\"rule_type\":\"metric,prometheus\"}"
+- Alerts fired overnight: operation="alerts", subscription_id="<subscription-id>", resource_group="<resource-group>", cluster_name="<cluster-name>", parameters="{\"time_range\":\"2025-01-01T22:00:00Z/2025-01-02T08:00:00Z\", \"severity\":\"Sev0,Sev1,Sev2\"}"
+- Alert parameters: rule_type (comma-separated: metric, log_search, prometheus; default all, alert_rules only), time_range or start_time/end_time (alerts started in the range, default last 24 hours, within the last 30 days), monitor_condition (fired or resolved), state (comma-separated: new, acknowledged, closed), severity (comma-separated, Sev0 to Sev4), max_alerts (default 50, at most 500, most recent kept)
 - App Insights query: operation="app_insights", subscription_id="<subscription-id>", resource_group="<resource-group>", parameters="{\"app_insights_name\":\"...\", \"query\":\"...\"}"
 - Check diagnostics: operation="diagnostics", parameters="{\"subscription_id\":\"<subscription-id>\", \"resource_group\":\"<resource-group>\", \"cluster_name\":\"<cluster-name>\"}"
-- Query AKS control plane logs: operation="control_plane_logs", parameters="{\"log_category\":\"kube-apiserver\", \"start_time\":\"...\", \"end_time\":\"...\"}"
+- Enable audit logs: operation="diagnostics_configure", subscription_id="<subscription-id>", resource_group="<resource-group>", cluster_name="<cluster-name>", parameters="{\"categories\":\"kube-audit,kube-audit-admin\", \"dry_run\":true}"
+- Diagnostics configure parameters: categories (comma-separated, required), workspace_id (Log Analytics workspace resource ID, required when no diagnostic setting sends logs to a workspace), table_mode (dedicated or azure_diagnostics; new settings default to dedicated, existing settings keep their mode), setting_name (setting to update or create, default the one sending logs to the workspace or aks-mcp-diagnostics), dry_run (return the planned change without applying it)
+- Query AKS control plane logs: operation="control_plane_logs", parameters="{\"log_category\":\"kube-apiserver\", \"time_range\":\"around 2025-01-01T10:00:00Z ±15m\"}"
 - Query AKS control plane logs with filters: operation="control_plane_logs", parameters="{\"log_category\":\"kube-apiserver\", \"log_level\":\"error\", \"start_time\":\"...\", \"end_time\":\"...\", \"max_records\":\"50\"}"
+- Error patterns that increased: operation="control_plane_logs", parameters="{\"log_category\":\"kube-apiserver\", \"log_level\":\"error\", \"time_range\":\"last 1h\", \"summarize\":true, \"rank_by\":\"increase\"}"
+- Log summary parameters (control_plane_logs and the container_logs template of container_insights): summarize (return message patterns instead of records: numbers, UUIDs, IPs, times and pod names are masked, with count, first and last seen and an example per pattern; up to 1000 records are read unless max_records is set), rank_by (frequency or increase over the baseline window), baseline (time range expression, default the window of the same length just before), max_patterns (default 20, at most 100)
+- Incident timeline: operation="control_plane_timeline", subscription_id="<subscription-id>", resource_group="<resource-group>", cluster_name="<cluster-name>", parameters="{\"time_range\":\"around 2025-01-01T10:00:00Z ±30m\"}"
+- Timeline parameters: time_range or start_time/end_time (default last hour, at most 6 hours), sources (comma-separated: kube-apiserver, kube-controller-manager, kube-scheduler, cluster-autoscaler, audit, resource_health; default all), min_level (error, warning or info; default error for kube-apiserver and warning for the other components), max_records_per_source (default 50, at most 500, most recent kept), max_entries (default 200, at most 1000, most recent kept). Sources whose log category is not enabled are reported and skipped
+- Actions of a service account: operation="kube_audit", subscription_id="<subscription-id>", resource_group="<resource-group>", cluster_name="<cluster-name>", parameters="{\"template\":\"user_actions\", \"user\":\"system:serviceaccount:default:deployer\", \"time_range\":\"last 6h\"}"
+- Kube-audit parameters: template (required), time_range or start_time/end_time (required), user (required for user_actions), namespace or name (one required for object_mutations), resource (plural, e.g. deployments), log_category (kube-audit or kube-audit-admin, default kube-audit when enabled; secret_reads needs kube-audit), max_records
+- Pod restarts by reason: operation="container_insights", subscription_id="<subscription-id>", resource_group="<resource-group>", cluster_name="<cluster-name>", parameters="{\"template\":\"pod_restarts\", \"namespace\":\"default\", \"time_range\":\"last 6h\"}"
+- Container logs containing a string: operation="container_insights", parameters="{\"template\":\"container_logs\", \"namespace\":\"default\", \"pod_name\":\"...\", \"grep\":\"timeout\", \"start_time\":\"...\", \"end_time\":\"...\"}"
+- Namespace CPU over the last 3 hours: operation="prometheus", query_type="range", subscription_id="<subscription-id>", resource_group="<resource-group>", cluster_name="<cluster-name>", parameters="{\"query\":\"sum by (namespace) (rate(container_cpu_usage_seconds_total[5m]))\", \"time_range\":\"last 3h\"}"
+- Prometheus parameters: query (PromQL, required), query_type (instant or range), time (instant), time_range or start_time/end_time (range, default last hour, at most 7 days), step (e.g. PT5M or 5m), max_series (default 50, at most 1000, largest first), include_points, azure_monitor_workspace (workspace resource ID when several are linked)
+- Time ranges: metrics, resource_health, activity_log, alerts, app_insights, control_plane_logs, control_plane_timeline, kube_audit, container_insights and prometheus take time_range, resolved against server time: "last 2h", "last 30 minutes", an ISO 8601 duration such as PT30M, "since <RFC3339 time>", "around <RFC3339 time> ±15m" or "<start>/<end>". start_time may hold the same expressions; start_time/end_time in RFC3339 are still accepted. Responses include the resolved time_range
+- Container Insights parameters: template (required), time_range or start_time/end_time (required), namespace and pod_name (required for container_logs), container_name, node_name, grep (container_logs only), sort_by (cpu or memory, top_consumers only), max_records
 `
 
 	return mcp.NewTool("az_monitoring",
@@ -47,7 +85,7 @@ Examples:
 			mcp.Description("The monitoring operation to perform"),
 		),
 		mcp.WithString("query_type",
-			mcp.Description("Specific type of query for metrics operations (list, list-definitions, list-namespaces)"),
+			mcp.Description("Specific type of query: list, list-definitions or list-namespaces for metrics; instant or range for prometheus"),
 		),
 		mcp.WithString("parameters",
 			mcp.Required(),
@@ -62,6 +100,9 @@ Examples:
 		mcp.WithString("cluster_name",
 			mcp.Description("AKS cluster name (can be included in parameters)"),
 		),
+		mcp.WithString("tenant_id",
+			mcp.Description("Optional Entra tenant ID of the subscription (inferred from az account list if omitted). Not supported by resource_health, app_insights, control_plane_logs, container_insights, kube_audit and control_plane_timeline, which use the tenant of the Azure CLI login"),
+		),
 	)
 }
 
@@ -69,7 +110,9 @@ Examples:
 func ValidateMonitoringOperation(operation string) bool {
 	supportedOps := []string{
 		string(OpMetrics), string(OpResourceHealth), string(OpAppInsights),
-		string(OpDiagnostics), string(OpControlPlaneLogs),
+		string(OpDiagnostics), string(OpControlPlaneLogs), string(OpContainerInsights), string(OpPrometheus),
+		string(OpActivityLog), string(OpKubeAudit), string(OpDiagnosticsConfigure), string(OpTimeline),
+		string(OpAlertRules), string(OpAlerts),
 	}
 	return slices.Contains(supportedOps, operation)
 }
@@ -78,8 +121,26 @@ func ValidateMonitoringOperation(operation string) bool {
 func GetSupportedMonitoringOperations() []string {
 	return []string{
 		string(OpMetrics), string(OpResourceHealth), string(OpAppInsights),
-		string(OpDiagnostics), string(OpControlPlaneLogs),
+		string(OpDiagnostics), string(OpControlPlaneLogs), string(OpContainerInsights), string(OpPrometheus),
+		string(OpActivityLog), string(OpKubeAudit), string(OpDiagnosticsConfigure), string(OpTimeline),
+		string(OpAlertRules), string(OpAlerts),
+	}
+}
+
+// GetMonitoringOperationAccessLevel returns the required access level for a monitoring operation
+func GetMonitoringOperationAccessLevel(operation string) string {
+	if operation == string(OpDiagnosticsConfigure) {
+		return "readwrite"
+	}
+	return "readonly"
+}
+
+// ValidateMonitoringOperationAccess checks if the monitoring operation is allowed for the given access level
+func ValidateMonitoringOperationAccess(operation string, cfg *config.ConfigData) error {
+	if GetMonitoringOperationAccessLevel(operation) == "readwrite" && cfg.AccessLevel != "readwrite" && cfg.AccessLevel != "admin" {
+		return fmt.Errorf("operation '%s' requires readwrite or admin access level", operation)
 	}
+	return nil
 }
 
 // ValidateMetricsQueryType checks if the metrics query type is supported
@@ -1,7 +1,10 @@
 package monitor
 
 import (
+	"strings"
 	"testing"
+
+	"github.com/Azure/aks-mcp/internal/config"
 )
 
 func TestRegisterAzMonitoring_Tool(t *testing.T) {
@@ -22,7 +25,7 @@ func TestGetSupportedMonitoringOperations_ContainsExpectedOps(t *testing.T) {
 	operations := GetSupportedMonitoringOperations()
 
 	expectedOps := []string{
-		"metrics", "resource_health", "app_insights", "diagnostics", "control_plane_logs",
+		"metrics", "resource_health", "app_insights", "diagnostics", "control_plane_logs", "container_insights", "prometheus", "activity_log", "kube_audit", "diagnostics_configure", "control_plane_timeline", "alert_rules", "alerts",
 	}
 
 	for _, expectedOp := range expectedOps {
@@ -41,7 +44,7 @@ func TestGetSupportedMonitoringOperations_ContainsExpectedOps(t *testing.T) {
 
 func TestValidateMonitoringOperation_ChecksValidOperations(t *testing.T) {
 	// Test that validation works for supported operations
-	validOps := []string{"metrics", "resource_health", "app_insights", "diagnostics", "control_plane_logs"}
+	validOps := []string{"metrics", "resource_health", "app_insights", "diagnostics", "control_plane_logs", "container_insights", "prometheus", "activity_log", "kube_audit", "diagnostics_configure", "control_plane_timeline", "alert_rules", "alerts"}
 	for _, op := range validOps {
 		if !ValidateMonitoringOperation(op) {
 			t.Errorf("Expected operation '%s' to be valid", op)
@@ -74,3 +77,28 @@ func TestValidateMetricsQueryType_ChecksValidTypes(t *testing.T) {
 		}
 	}
 }
+
+func TestValidateMonitoringOperationAccess_ChecksAccessLevels(t *testing.T) {
+	testCases := []struct {
+		operation   string
+		accessLevel string
+		shouldError bool
+	}{
+		{"diagnostics", "readonly", false},
+		{"diagnostics_configure", "readonly", true},
+		{"diagnostics_configure", "readwrite", false},
+		{"diagnostics_configure", "admin", false},
+	}
+
+	for _, tc := range testCases {
+		cfg := config.NewConfig()
+		cfg.AccessLevel = tc.accessLevel
+		err := ValidateMonitoringOperationAccess(tc.operation, cfg)
+		if tc.shouldError && (err == nil || !strings.Contains(err.Error(), "requires readwrite or admin access level")) {
+			t.Errorf("Expected access error for %s with %s access, got %v", tc.operation, tc.accessLevel, err)
+		}
+		if !tc.shouldError && err != nil {
+			t.Errorf("Expected %s to be allowed with %s access, got %v", tc.operation, tc.accessLevel, err)
+		}
+	}
+}
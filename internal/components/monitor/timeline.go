@@ -0,0 +1,249 @@
+package monitor
+
+import (
+	"encoding/json"
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/Azure/aks-mcp/internal/azureclient"
+	"github.com/Azure/aks-mcp/internal/components/common"
+	"github.com/Azure/aks-mcp/internal/components/monitor/diagnostics"
+	"github.com/Azure/aks-mcp/internal/config"
+)
+
+const (
+	// defaultTimelineWindow is the time range of the timeline when no time parameter is set
+	defaultTimelineWindow = time.Hour
+	// maxTimelineWindow is the longest time range of the timeline
+	maxTimelineWindow = 6 * time.Hour
+	// defaultTimelineRecordsPerSource is the number of records read per source when max_records_per_source is not set
+	defaultTimelineRecordsPerSource = 50
+	// maxTimelineRecordsPerSource is the most records read per source
+	maxTimelineRecordsPerSource = 500
+	// defaultTimelineMaxEntries is the number of merged entries returned when max_entries is not set
+	defaultTimelineMaxEntries = 200
+	// maxTimelineMaxEntries is the most merged entries returned
+	maxTimelineMaxEntries = 1000
+	// timelineSourceResourceHealth is the source of resource health events
+	timelineSourceResourceHealth = "resource_health"
+)
+
+// timelineSources are the sources of the control_plane_timeline operation, in the order ties are broken
+var timelineSources = append(slices.Clone(diagnostics.TimelineLogSources), timelineSourceResourceHealth)
+
+// timelineQuery is a validated control plane timeline query
+type timelineQuery struct {
+	TimeRange           *common.TimeRange
+	Sources             []string
+	MinLevel            string // overrides the default level of each component when set
+	MaxRecordsPerSource int
+	MaxEntries          int
+}
+
+// TimelineResult is the response of the control_plane_timeline operation
+type TimelineResult struct {
+	Cluster      string                       `json:"cluster"`
+	TimeRange    *common.TimeRange            `json:"time_range"`
+	Sources      []diagnostics.TimelineSource `json:"sources"`
+	TotalEntries int                          `json:"total_entries"`
+	Truncated    bool                         `json:"truncated,omitempty"` // max_entries was reached
+	Entries      []diagnostics.TimelineEntry  `json:"entries"`
+}
+
+// handleTimelineOperation merges control plane logs of several components, audit events and resource health events
+// of the cluster into one time-ordered timeline
+func handleTimelineOperation(params map[string]interface{}, azClient *azureclient.AzureClient, cfg *config.ConfigData) (string, error) {
+	if azClient == nil {
+		return "", fmt.Errorf("azure client is required but not provided")
+	}
+
+	subscriptionID, resourceGroup, clusterName, err := common.ExtractAKSParameters(params)
+	if err != nil {
+		return "", err
+	}
+
+	query, err := parseTimelineQuery(params)
+	if err != nil {
+		return "", err
+	}
+
+	var logSources []string
+	for _, source := range query.Sources {
+		if source != timelineSourceResourceHealth {
+			logSources = append(logSources, source)
+		}
+	}
+	sources, entries := diagnostics.CollectControlPlaneTimeline(subscriptionID, resourceGroup, clusterName, logSources, query.MinLevel, query.MaxRecordsPerSource, query.TimeRange, azClient, cfg)
+
+	if slices.Contains(query.Sources, timelineSourceResourceHealth) {
+		source := diagnostics.TimelineSource{Name: timelineSourceResourceHealth}
+		healthEntries, err := collectResourceHealthTimeline(subscriptionID, resourceGroup, clusterName, query.TimeRange, cfg)
+		if err != nil {
+			source.Error = err.Error()
+		}
+		// Keep the most recent events
+		if len(healthEntries) > query.MaxRecordsPerSource {
+			sortTimelineEntries(healthEntries)
+			healthEntries = healthEntries[len(healthEntries)-query.MaxRecordsPerSource:]
+			source.Truncated = true
+		}
+		source.Records = len(healthEntries)
+		sources = append(sources, source)
+		entries = append(entries, healthEntries...)
+	}
+
+	// Fail only when no source could be read
+	failed := 0
+	for _, source := range sources {
+		if source.Error != "" {
+			failed++
+		}
+	}
+	if failed == len(sources) {
+		messages := make([]string, 0, len(sources))
+		for _, source := range sources {
+			messages = append(messages, fmt.Sprintf("%s: %s", source.Name, source.Error))
+		}
+		return "", fmt.Errorf("failed to read any timeline source of cluster %s: %s", clusterName, strings.Join(messages, "; "))
+	}
+
+	sortTimelineEntries(entries)
+	result := TimelineResult{
+		Cluster:      fmt.Sprintf("/subscriptions/%s/resourceGroups/%s/providers/Microsoft.ContainerService/managedClusters/%s", subscriptionID, resourceGroup, clusterName),
+		TimeRange:    query.TimeRange,
+		Sources:      sources,
+		TotalEntries: len(entries),
+		Entries:      []diagnostics.TimelineEntry{},
+	}
+	if len(entries) > query.MaxEntries {
+		// Keep the most recent entries
+		entries = entries[len(entries)-query.MaxEntries:]
+		result.Truncated = true
+	}
+	result.Entries = append(result.Entries, entries...)
+
+	resultJSON, err := json.MarshalIndent(result, "", "  ")
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal timeline result to JSON: %w", err)
+	}
+	return string(resultJSON), nil
+}
+
+// parseTimelineQuery builds a timeline query from the merged operation parameters
+func parseTimelineQuery(params map[string]interface{}) (*timelineQuery, error) {
+	timeRange, err := common.ParseTimeRange(params, monitorNow(), common.TimeRangeOptions{
+		Default:     defaultTimelineWindow,
+		MaxDuration: maxTimelineWindow,
+	})
+	if err != nil {
+		return nil, err
+	}
+	query := &timelineQuery{TimeRange: timeRange}
+
+	for _, source := range stringList(params["sources"]) {
+		source = strings.ToLower(source)
+		if !slices.Contains(timelineSources, source) {
+			return nil, fmt.Errorf("invalid source: %s. Supported sources: %s", source, strings.Join(timelineSources, ", "))
+		}
+		if !slices.Contains(query.Sources, source) {
+			query.Sources = append(query.Sources, source)
+		}
+	}
+	if len(query.Sources) == 0 {
+		query.Sources = timelineSources
+	}
+	// Merge ties in the same order whichever order the sources were given in
+	sort.SliceStable(query.Sources, func(i, j int) bool {
+		return slices.Index(timelineSources, query.Sources[i]) < slices.Index(timelineSources, query.Sources[j])
+	})
+
+	query.MinLevel, _ = params["min_level"].(string)
+	switch query.MinLevel = strings.ToLower(strings.TrimSpace(query.MinLevel)); query.MinLevel {
+	case "", "error", "warning", "info":
+	default:
+		return nil, fmt.Errorf("invalid min_level: %s. Valid levels: error, warning, info", query.MinLevel)
+	}
+
+	query.MaxRecordsPerSource, err = positiveInt(params, "max_records_per_source", defaultTimelineRecordsPerSource, maxTimelineRecordsPerSource)
+	if err != nil {
+		return nil, err
+	}
+
+	query.MaxEntries, err = positiveInt(params, "max_entries", defaultTimelineMaxEntries, maxTimelineMaxEntries)
+	if err != nil {
+		return nil, err
+	}
+
+	return query, nil
+}
+
+// collectResourceHealthTimeline converts the resource health events of the cluster in the time range into timeline entries
+func collectResourceHealthTimeline(subscriptionID, resourceGroup, clusterName string, timeRange *common.TimeRange, cfg *config.ConfigData) ([]diagnostics.TimelineEntry, error) {
+	output, err := HandleResourceHealthQuery(map[string]interface{}{
+		"subscription_id": subscriptionID,
+		"resource_group":  resourceGroup,
+		"cluster_name":    clusterName,
+		"start_time":      timeRange.Start.Format(time.RFC3339),
+		"end_time":        timeRange.End.Format(time.RFC3339),
+	}, cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	var response struct {
+		Result []struct {
+			EventTimestamp string `json:"eventTimestamp"`
+			Level          string `json:"level"`
+			Properties     struct {
+				Title                string `json:"title"`
+				CurrentHealthStatus  string `json:"currentHealthStatus"`
+				PreviousHealthStatus string `json:"previousHealthStatus"`
+				Cause                string `json:"cause"`
+			} `json:"properties"`
+		} `json:"result"`
+	}
+	if err := json.Unmarshal([]byte(output), &response); err != nil {
+		return nil, fmt.Errorf("failed to parse resource health events: %w", err)
+	}
+
+	entries := make([]diagnostics.TimelineEntry, 0, len(response.Result))
+	for _, event := range response.Result {
+		timestamp, err := time.Parse(time.RFC3339Nano, event.EventTimestamp)
+		if err != nil {
+			return nil, fmt.Errorf("invalid resource health event time '%s': %w", event.EventTimestamp, err)
+		}
+
+		properties := event.Properties
+		message := "health status " + properties.CurrentHealthStatus
+		if properties.PreviousHealthStatus != "" {
+			message = fmt.Sprintf("health status %s -> %s", properties.PreviousHealthStatus, properties.CurrentHealthStatus)
+		}
+		if properties.Title != "" && properties.Title != properties.CurrentHealthStatus {
+			message += ": " + properties.Title
+		}
+		if properties.Cause != "" {
+			message += fmt.Sprintf(" (%s)", properties.Cause)
+		}
+
+		entries = append(entries, diagnostics.TimelineEntry{
+			Time:    timestamp.UTC(),
+			Source:  timelineSourceResourceHealth,
+			Level:   diagnostics.NormalizeTimelineLevel(event.Level),
+			Message: message,
+		})
+	}
+	return entries, nil
+}
+
+// sortTimelineEntries orders entries by time, then by source
+func sortTimelineEntries(entries []diagnostics.TimelineEntry) {
+	sort.SliceStable(entries, func(i, j int) bool {
+		if !entries[i].Time.Equal(entries[j].Time) {
+			return entries[i].Time.Before(entries[j].Time)
+		}
+		return slices.Index(timelineSources, entries[i].Source) < slices.Index(timelineSources, entries[j].Source)
+	})
+}
@@ -0,0 +1,157 @@
+package monitor
+
+import (
+	"encoding/json"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Azure/aks-mcp/internal/azureclient/fakearm"
+	"github.com/Azure/aks-mcp/internal/azureclient/fakearm/armtest"
+	"github.com/Azure/aks-mcp/internal/command"
+)
+
+// runTimeline runs the control_plane_timeline operation over the two hours before noon on 2026-03-01. The default
+// cluster fixture sends kube-apiserver and kube-audit-admin logs, but no other component, to resource-specific tables.
+func runTimeline(t *testing.T, parameters string) (TimelineResult, error) {
+	t.Helper()
+
+	defer func(original func() time.Time) { monitorNow = original }(monitorNow)
+	monitorNow = func() time.Time { return time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC) }
+
+	stop, err := command.UseCassette(filepath.Join("testdata", "cassettes", "control_plane_timeline.json"))
+	if err != nil {
+		t.Fatalf("Failed to load cassette: %v", err)
+	}
+	defer func() {
+		if err := stop(); err != nil {
+			t.Errorf("Failed to save cassette: %v", err)
+		}
+	}()
+
+	_, client, cfg := armtest.NewClient(t)
+
+	var result TimelineResult
+	output, err := GetAzMonitoringHandler(client, cfg).Handle(map[string]interface{}{
+		"operation":       "control_plane_timeline",
+		"subscription_id": fakearm.SubscriptionID,
+		"resource_group":  fakearm.ResourceGroup,
+		"cluster_name":    fakearm.ClusterName,
+		"parameters":      parameters,
+	}, cfg)
+	if err == nil {
+		if err := json.Unmarshal([]byte(output), &result); err != nil {
+			t.Fatalf("Failed to parse result: %v\n%s", err, output)
+		}
+	}
+	return result, err
+}
+
+func TestGetAzMonitoringHandler_Timeline(t *testing.T) {
+	result, err := runTimeline(t, `{"time_range": "last 2h"}`)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if result.Cluster != fakearm.ClusterResourceID || result.TimeRange.Duration != "2h0m0s" {
+		t.Errorf("Unexpected query details: %+v", result)
+	}
+
+	// Components whose category is not enabled are reported and skipped
+	if len(result.Sources) != 6 {
+		t.Fatalf("Expected 6 sources, got %+v", result.Sources)
+	}
+	for _, source := range result.Sources {
+		switch source.Name {
+		case "kube-apiserver", "resource_health":
+			if source.Error != "" || source.Records == 0 {
+				t.Errorf("Expected records from %s, got %+v", source.Name, source)
+			}
+		case "audit":
+			if source.Category != "kube-audit-admin" || source.Records != 2 {
+				t.Errorf("Expected kube-audit-admin records, got %+v", source)
+			}
+		default:
+			if !strings.Contains(source.Error, "no diagnostic setting found") || source.MinLevel != "warning" {
+				t.Errorf("Expected %s to be skipped, got %+v", source.Name, source)
+			}
+		}
+	}
+
+	var got []string
+	for _, entry := range result.Entries {
+		got = append(got, entry.Time.Format("15:04:05")+" "+entry.Source+" "+entry.Level)
+	}
+	want := []string{
+		"10:30:00 kube-apiserver ERROR",
+		"11:00:00 audit INFO",
+		"11:05:00 kube-apiserver ERROR",
+		"11:05:00 audit WARNING",
+		"11:10:00 resource_health WARNING",
+	}
+	if strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Errorf("Expected entries:\n%s\ngot:\n%s", strings.Join(want, "\n"), strings.Join(got, "\n"))
+	}
+	if result.TotalEntries != 5 || result.Truncated {
+		t.Errorf("Expected 5 entries, got %d", result.TotalEntries)
+	}
+	if message := result.Entries[4].Message; message != "health status Available -> Degraded (PlatformInitiated)" {
+		t.Errorf("Unexpected resource health message: %s", message)
+	}
+}
+
+func TestGetAzMonitoringHandler_TimelineLimits(t *testing.T) {
+	t.Run("max entries", func(t *testing.T) {
+		result, err := runTimeline(t, `{"time_range": "last 2h", "sources": "resource_health,kube-apiserver", "max_entries": 2}`)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if len(result.Sources) != 2 || result.Sources[0].Name != "kube-apiserver" {
+			t.Errorf("Expected the sources in timeline order, got %+v", result.Sources)
+		}
+		if result.TotalEntries != 3 || !result.Truncated || len(result.Entries) != 2 || result.Entries[1].Source != "resource_health" {
+			t.Errorf("Expected the 2 most recent of 3 entries, got %+v", result)
+		}
+	})
+
+	t.Run("no readable source", func(t *testing.T) {
+		_, err := runTimeline(t, `{"time_range": "last 2h", "sources": ["kube-scheduler"]}`)
+		if err == nil || !strings.Contains(err.Error(), "kube-scheduler: no diagnostic setting found") {
+			t.Errorf("Expected the scheduler error, got %v", err)
+		}
+	})
+}
+
+func TestParseTimelineQuery(t *testing.T) {
+	defer func(original func() time.Time) { monitorNow = original }(monitorNow)
+	monitorNow = func() time.Time { return time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC) }
+
+	query, err := parseTimelineQuery(map[string]interface{}{})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if query.TimeRange.Duration != "1h0m0s" || len(query.Sources) != len(timelineSources) || query.MaxRecordsPerSource != defaultTimelineRecordsPerSource || query.MaxEntries != defaultTimelineMaxEntries {
+		t.Errorf("Unexpected defaults: %+v", query)
+	}
+
+	errorCases := []struct {
+		name   string
+		params map[string]interface{}
+		errMsg string
+	}{
+		{"window too long", map[string]interface{}{"time_range": "last 12h"}, "time range cannot exceed 6h0m0s"},
+		{"bad source", map[string]interface{}{"sources": "kube-apiserver,etcd"}, "invalid source: etcd"},
+		{"bad level", map[string]interface{}{"min_level": "debug"}, "invalid min_level: debug"},
+		{"too many records", map[string]interface{}{"max_records_per_source": "1000"}, "max_records_per_source cannot exceed 500"},
+		{"bad max entries", map[string]interface{}{"max_entries": 0.5}, "max_entries must be"},
+	}
+	for _, tc := range errorCases {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := parseTimelineQuery(tc.params)
+			if err == nil || !strings.Contains(err.Error(), tc.errMsg) {
+				t.Errorf("Expected error containing %q, got %v", tc.errMsg, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,315 @@
+// Package multicluster runs read-only kubectl commands against many AKS clusters at once.
+package multicluster
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/Azure/aks-mcp/internal/azureclient"
+	"github.com/Azure/aks-mcp/internal/command"
+	"github.com/Azure/aks-mcp/internal/config"
+	"github.com/Azure/aks-mcp/internal/k8s"
+	"github.com/Azure/aks-mcp/internal/security"
+	"github.com/Azure/aks-mcp/internal/tools"
+	k8ssecurity "github.com/Azure/mcp-kubernetes/pkg/security"
+	k8stools "github.com/Azure/mcp-kubernetes/pkg/tools"
+)
+
+const (
+	// defaultConcurrency is the number of clusters queried at once when max_concurrency is not set
+	defaultConcurrency = 5
+	// maxConcurrency bounds max_concurrency so a large fleet does not start hundreds of processes
+	maxConcurrency = 20
+	// maxClusters limits how many clusters one call may query
+	maxClusters = 100
+)
+
+// operationTools maps the read-only kubectl operations to the kubectl tool that validates them
+var operationTools = map[string]string{
+	"get":           "kubectl_resources",
+	"describe":      "kubectl_resources",
+	"logs":          "kubectl_diagnostics",
+	"events":        "kubectl_diagnostics",
+	"top":           "kubectl_diagnostics",
+	"cluster-info":  "kubectl_cluster",
+	"api-resources": "kubectl_cluster",
+	"api-versions":  "kubectl_cluster",
+	"explain":       "kubectl_cluster",
+	"auth":          "kubectl_config",
+}
+
+// readOnlyOperations returns the operations the tool accepts, sorted
+func readOnlyOperations() []string {
+	operations := make([]string, 0, len(operationTools))
+	for operation := range operationTools {
+		operations = append(operations, operation)
+	}
+	sort.Strings(operations)
+	return operations
+}
+
+// Cluster identifies an AKS cluster a command runs against
+type Cluster struct {
+	SubscriptionID string `json:"subscription_id"`
+	ResourceGroup  string `json:"resource_group"`
+	ClusterName    string `json:"cluster_name"`
+}
+
+// ClusterResult is the outcome of the command on one cluster
+type ClusterResult struct {
+	Cluster
+	Output string `json:"output,omitempty"`
+	Error  string `json:"error,omitempty"`
+}
+
+// Result is the response of the kubectl_multi_cluster tool
+type Result struct {
+	Command   string          `json:"command"`
+	Selection string          `json:"selection"`
+	Succeeded int             `json:"succeeded"`
+	Failed    int             `json:"failed"`
+	Clusters  []ClusterResult `json:"clusters"`
+}
+
+// GetMultiClusterKubectlHandler returns a handler for the kubectl_multi_cluster tool.
+// kubectl is the cluster-targeting kubectl tool executor, so each cluster gets its own credentials and transport.
+func GetMultiClusterKubectlHandler(kubectl k8stools.CommandExecutor, cfg *config.ConfigData) tools.ResourceHandler {
+	return tools.ResourceHandlerFunc(func(params map[string]interface{}, _ *config.ConfigData) (string, error) {
+		operation, _ := params["operation"].(string)
+		toolName, ok := operationTools[operation]
+		if !ok {
+			return "", fmt.Errorf("invalid operation '%s', expected a read-only operation: %s", operation, strings.Join(readOnlyOperations(), ", "))
+		}
+		resource, _ := params["resource"].(string)
+		args, _ := params["args"].(string)
+
+		subscriptionID, _ := params["subscription_id"].(string)
+		if subscriptionID == "" {
+			return "", fmt.Errorf("missing or invalid subscription_id parameter")
+		}
+
+		concurrency, err := parseConcurrency(params)
+		if err != nil {
+			return "", err
+		}
+
+		clusters, selection, err := selectClusters(subscriptionID, params, cfg)
+		if err != nil {
+			return "", err
+		}
+		if len(clusters) == 0 {
+			return "", fmt.Errorf("no AKS clusters found for %s", selection)
+		}
+		if len(clusters) > maxClusters {
+			return "", fmt.Errorf("%s selects %d clusters, more than the limit of %d", selection, len(clusters), maxClusters)
+		}
+
+		// Only read-only commands run across clusters, whatever the server access level;
+		// the allowed namespaces apply unchanged
+		k8sCfg := k8s.ConvertConfig(cfg)
+		k8sCfg.AccessLevel = "readonly"
+		k8sCfg.SecurityConfig.AccessLevel = k8ssecurity.AccessLevelReadOnly
+
+		results := make([]ClusterResult, len(clusters))
+		var wg sync.WaitGroup
+		sem := make(chan struct{}, concurrency)
+		for i, cluster := range clusters {
+			wg.Add(1)
+			go func(i int, cluster Cluster) {
+				defer wg.Done()
+				sem <- struct{}{}
+				defer func() { <-sem }()
+
+				result := ClusterResult{Cluster: cluster}
+				output, err := kubectl.Execute(map[string]interface{}{
+					"_tool_name":      toolName,
+					"operation":       operation,
+					"resource":        resource,
+					"args":            args,
+					"subscription_id": cluster.SubscriptionID,
+					"resource_group":  cluster.ResourceGroup,
+					"cluster_name":    cluster.ClusterName,
+				}, k8sCfg)
+				if err != nil {
+					result.Error = err.Error()
+				} else {
+					result.Output = output
+				}
+				results[i] = result
+			}(i, cluster)
+		}
+		wg.Wait()
+
+		response := Result{
+			Command:   strings.Join(strings.Fields(strings.Join([]string{"kubectl", operation, resource, args}, " ")), " "),
+			Selection: selection,
+			Clusters:  results,
+		}
+		for _, result := range results {
+			if result.Error != "" {
+				response.Failed++
+			} else {
+				response.Succeeded++
+			}
+		}
+
+		data, err := json.MarshalIndent(response, "", "  ")
+		if err != nil {
+			return "", fmt.Errorf("failed to marshal multi-cluster results: %v", err)
+		}
+		return string(data), nil
+	})
+}
+
+// parseConcurrency returns max_concurrency, defaulting to defaultConcurrency
+func parseConcurrency(params map[string]interface{}) (int, error) {
+	value, ok := params["max_concurrency"].(float64)
+	if !ok {
+		return defaultConcurrency, nil
+	}
+	if value < 1 || value > maxConcurrency || value != float64(int(value)) {
+		return 0, fmt.Errorf("max_concurrency must be a whole number between 1 and %d", maxConcurrency)
+	}
+	return int(value), nil
+}
+
+var (
+	// resourceGroupPattern follows the Azure resource group naming rules, which forbid a trailing period
+	resourceGroupPattern = regexp.MustCompile(`^[-\w.()]{0,89}[-\w()]$`)
+	// fleetNamePattern follows the Azure Kubernetes Fleet Manager naming rules
+	fleetNamePattern = regexp.MustCompile(`^[a-zA-Z0-9]([a-zA-Z0-9-]{0,61}[a-zA-Z0-9])?$`)
+)
+
+// selectClusters returns the clusters chosen by exactly one of clusters, resource_group or fleet_name,
+// and a description of the selection
+func selectClusters(subscriptionID string, params map[string]interface{}, cfg *config.ConfigData) ([]Cluster, string, error) {
+	list, _ := params["clusters"].(string)
+	resourceGroup, _ := params["resource_group"].(string)
+	fleetName, _ := params["fleet_name"].(string)
+	fleetResourceGroup, _ := params["fleet_resource_group"].(string)
+
+	selections := 0
+	for _, value := range []string{list, resourceGroup, fleetName} {
+		if strings.TrimSpace(value) != "" {
+			selections++
+		}
+	}
+	if selections != 1 {
+		return nil, "", fmt.Errorf("set exactly one of clusters, resource_group or fleet_name")
+	}
+
+	switch {
+	case strings.TrimSpace(list) != "":
+		clusters, err := parseClusterList(subscriptionID, list)
+		return clusters, "clusters", err
+	case resourceGroup != "":
+		clusters, err := listResourceGroupClusters(subscriptionID, resourceGroup, cfg)
+		return clusters, fmt.Sprintf("resource group %s", resourceGroup), err
+	default:
+		if fleetResourceGroup == "" {
+			return nil, "", fmt.Errorf("fleet_resource_group is required with fleet_name")
+		}
+		clusters, err := listFleetMembers(subscriptionID, fleetResourceGroup, fleetName, cfg)
+		return clusters, fmt.Sprintf("fleet %s", fleetName), err
+	}
+}
+
+// parseClusterList parses comma-separated resource_group/cluster_name pairs and AKS resource IDs
+func parseClusterList(subscriptionID, list string) ([]Cluster, error) {
+	var clusters []Cluster
+	seen := make(map[string]bool)
+	for _, entry := range strings.Split(list, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+
+		var cluster Cluster
+		if strings.HasPrefix(entry, "/") {
+			sub, rg, name, err := azureclient.ParseAKSResourceID(entry)
+			if err != nil {
+				return nil, fmt.Errorf("invalid cluster '%s': %v", entry, err)
+			}
+			cluster = Cluster{SubscriptionID: sub, ResourceGroup: rg, ClusterName: name}
+		} else {
+			parts := strings.Split(entry, "/")
+			if len(parts) != 2 || parts[0] == "" || parts[1] == "" {
+				return nil, fmt.Errorf("invalid cluster '%s', expected resource_group/cluster_name or an AKS resource ID", entry)
+			}
+			cluster = Cluster{SubscriptionID: subscriptionID, ResourceGroup: parts[0], ClusterName: parts[1]}
+		}
+
+		key := strings.ToLower(cluster.SubscriptionID + "/" + cluster.ResourceGroup + "/" + cluster.ClusterName)
+		if !seen[key] {
+			seen[key] = true
+			clusters = append(clusters, cluster)
+		}
+	}
+	return clusters, nil
+}
+
+// listResourceGroupClusters lists the AKS clusters in a resource group
+func listResourceGroupClusters(subscriptionID, resourceGroup string, cfg *config.ConfigData) ([]Cluster, error) {
+	if !resourceGroupPattern.MatchString(resourceGroup) {
+		return nil, fmt.Errorf("invalid resource_group '%s'", resourceGroup)
+	}
+	argv := []string{"az", "aks", "list",
+		"--subscription", subscriptionID,
+		"--resource-group", resourceGroup,
+		"--query", "[].id",
+		"--output", "json",
+	}
+	return listClusterIDs(argv, "clusters in resource group "+resourceGroup, cfg)
+}
+
+// listFleetMembers lists the member clusters of a fleet, which may be in other subscriptions
+func listFleetMembers(subscriptionID, resourceGroup, fleetName string, cfg *config.ConfigData) ([]Cluster, error) {
+	if !resourceGroupPattern.MatchString(resourceGroup) {
+		return nil, fmt.Errorf("invalid fleet_resource_group '%s'", resourceGroup)
+	}
+	if !fleetNamePattern.MatchString(fleetName) {
+		return nil, fmt.Errorf("invalid fleet_name '%s'", fleetName)
+	}
+	argv := []string{"az", "fleet", "member", "list",
+		"--subscription", subscriptionID,
+		"--resource-group", resourceGroup,
+		"--fleet-name", fleetName,
+		"--query", "[].clusterResourceId",
+		"--output", "json",
+	}
+	return listClusterIDs(argv, "members of fleet "+fleetName, cfg)
+}
+
+// listClusterIDs runs an az command that returns AKS resource IDs and parses them into clusters.
+// The arguments are passed as they are, so names are never split or unquoted.
+func listClusterIDs(argv []string, description string, cfg *config.ConfigData) ([]Cluster, error) {
+	validator := security.NewValidator(cfg.SecurityConfig)
+	if err := validator.ValidateCommand(strings.Join(argv, " "), security.CommandTypeAz); err != nil {
+		return nil, err
+	}
+
+	process := command.NewShellProcess(argv[0], cfg.Timeout)
+	output, err := process.RunArgs(argv[1:])
+	if err != nil {
+		return nil, fmt.Errorf("failed to list %s: %v", description, err)
+	}
+
+	var ids []string
+	if err := json.Unmarshal([]byte(output), &ids); err != nil {
+		return nil, fmt.Errorf("failed to list %s: %s", description, strings.TrimSpace(output))
+	}
+
+	clusters := make([]Cluster, 0, len(ids))
+	for _, id := range ids {
+		sub, rg, name, err := azureclient.ParseAKSResourceID(id)
+		if err != nil {
+			return nil, fmt.Errorf("unexpected cluster ID '%s' in %s: %v", id, description, err)
+		}
+		clusters = append(clusters, Cluster{SubscriptionID: sub, ResourceGroup: rg, ClusterName: name})
+	}
+	return clusters, nil
+}
@@ -0,0 +1,207 @@
+package multicluster
+
+import (
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/Azure/aks-mcp/internal/command"
+	"github.com/Azure/aks-mcp/internal/config"
+	k8sconfig "github.com/Azure/mcp-kubernetes/pkg/config"
+)
+
+const testSubscriptionID = "00000000-0000-0000-0000-000000000000"
+
+// stubKubectl returns per-cluster output and records the calls and the highest number running at once
+type stubKubectl struct {
+	mu        sync.Mutex
+	calls     []map[string]interface{}
+	running   int
+	maxActive int
+	failures  map[string]string
+}
+
+func (s *stubKubectl) Execute(params map[string]interface{}, cfg *k8sconfig.ConfigData) (string, error) {
+	s.mu.Lock()
+	s.calls = append(s.calls, params)
+	s.running++
+	if s.running > s.maxActive {
+		s.maxActive = s.running
+	}
+	s.mu.Unlock()
+
+	time.Sleep(10 * time.Millisecond)
+
+	s.mu.Lock()
+	s.running--
+	s.mu.Unlock()
+
+	if cfg.AccessLevel != "readonly" || cfg.SecurityConfig.AccessLevel != "readonly" {
+		return "", fmt.Errorf("expected a read-only configuration")
+	}
+	cluster, _ := params["cluster_name"].(string)
+	if message, ok := s.failures[cluster]; ok {
+		return "", fmt.Errorf("%s", message)
+	}
+	return fmt.Sprintf("payments   api-%s   0/1   CrashLoopBackOff", cluster), nil
+}
+
+func useCassette(t *testing.T) {
+	t.Helper()
+	stop, err := command.UseCassette(filepath.Join("testdata", "cassettes", "multi_cluster.json"))
+	if err != nil {
+		t.Fatalf("Failed to load cassette: %v", err)
+	}
+	t.Cleanup(func() {
+		if err := stop(); err != nil {
+			t.Errorf("Failed to save cassette: %v", err)
+		}
+	})
+}
+
+func runHandler(t *testing.T, kubectl *stubKubectl, params map[string]interface{}) (*Result, error) {
+	t.Helper()
+
+	cfg := config.NewConfig()
+	cfg.AccessLevel = "admin"
+	output, err := GetMultiClusterKubectlHandler(kubectl, cfg).Handle(params, cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	var result Result
+	if err := json.Unmarshal([]byte(output), &result); err != nil {
+		t.Fatalf("Expected a JSON result, got %q: %v", output, err)
+	}
+	return &result, nil
+}
+
+func TestMultiClusterKubectl_ClusterList(t *testing.T) {
+	kubectl := &stubKubectl{failures: map[string]string{"staging": "failed to get credentials for cluster"}}
+	result, err := runHandler(t, kubectl, map[string]interface{}{
+		"operation":       "get",
+		"resource":        "pods",
+		"args":            "--namespace payments --field-selector=status.phase!=Running",
+		"subscription_id": testSubscriptionID,
+		"clusters":        "prod-rg/prod-eastus, staging-rg/staging, /subscriptions/11111111-1111-1111-1111-111111111111/resourceGroups/eu-rg/providers/Microsoft.ContainerService/managedClusters/prod-westeurope, prod-rg/prod-eastus",
+		"max_concurrency": float64(2),
+	})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if result.Command != "kubectl get pods --namespace payments --field-selector=status.phase!=Running" || result.Succeeded != 2 || result.Failed != 1 {
+		t.Errorf("Unexpected result summary: %+v", result)
+	}
+
+	var got []string
+	for _, r := range result.Clusters {
+		got = append(got, fmt.Sprintf("%s/%s/%s output=%t error=%q", r.SubscriptionID, r.ResourceGroup, r.ClusterName, r.Output != "", r.Error))
+	}
+	want := []string{
+		testSubscriptionID + "/prod-rg/prod-eastus output=true error=\"\"",
+		testSubscriptionID + "/staging-rg/staging output=false error=\"failed to get credentials for cluster\"",
+		"11111111-1111-1111-1111-111111111111/eu-rg/prod-westeurope output=true error=\"\"",
+	}
+	if strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Errorf("Unexpected cluster results:\n%s\nwant:\n%s", strings.Join(got, "\n"), strings.Join(want, "\n"))
+	}
+
+	if kubectl.maxActive > 2 {
+		t.Errorf("Expected at most 2 clusters queried at once, got %d", kubectl.maxActive)
+	}
+	for _, call := range kubectl.calls {
+		if call["_tool_name"] != "kubectl_resources" || call["operation"] != "get" {
+			t.Errorf("Expected the kubectl_resources tool to validate get, got %+v", call)
+		}
+	}
+}
+
+func TestMultiClusterKubectl_ResourceGroupAndFleet(t *testing.T) {
+	useCassette(t)
+
+	tests := []struct {
+		name   string
+		params map[string]interface{}
+		want   []string
+	}{
+		{
+			name:   "resource group",
+			params: map[string]interface{}{"resource_group": "prod-rg"},
+			want:   []string{"prod-eastus", "prod-westus"},
+		},
+		{
+			name:   "fleet",
+			params: map[string]interface{}{"fleet_name": "prod-fleet", "fleet_resource_group": "fleet-rg"},
+			want:   []string{"prod-eastus", "prod-westeurope"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			params := map[string]interface{}{"operation": "events", "args": "--namespace payments", "subscription_id": testSubscriptionID}
+			for k, v := range tt.params {
+				params[k] = v
+			}
+
+			kubectl := &stubKubectl{}
+			result, err := runHandler(t, kubectl, params)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+
+			var got []string
+			for _, r := range result.Clusters {
+				got = append(got, r.ClusterName)
+			}
+			if strings.Join(got, ",") != strings.Join(tt.want, ",") || result.Succeeded != len(tt.want) {
+				t.Errorf("Expected clusters %v, got %+v", tt.want, result)
+			}
+			if kubectl.calls[0]["_tool_name"] != "kubectl_diagnostics" {
+				t.Errorf("Expected the kubectl_diagnostics tool to validate events, got %+v", kubectl.calls[0])
+			}
+		})
+	}
+}
+
+func TestMultiClusterKubectl_InvalidParams(t *testing.T) {
+	useCassette(t)
+
+	tests := []struct {
+		name   string
+		params map[string]interface{}
+		want   string
+	}{
+		{name: "write operation", params: map[string]interface{}{"operation": "delete", "clusters": "rg/aks"}, want: "expected a read-only operation"},
+		{name: "no selection", params: map[string]interface{}{"operation": "get"}, want: "set exactly one of clusters, resource_group or fleet_name"},
+		{name: "two selections", params: map[string]interface{}{"operation": "get", "clusters": "rg/aks", "resource_group": "rg"}, want: "set exactly one of"},
+		{name: "fleet resource group", params: map[string]interface{}{"operation": "get", "fleet_name": "prod-fleet"}, want: "fleet_resource_group is required"},
+		{name: "bad cluster", params: map[string]interface{}{"operation": "get", "clusters": "aks"}, want: "expected resource_group/cluster_name"},
+		{name: "concurrency", params: map[string]interface{}{"operation": "get", "clusters": "rg/aks", "max_concurrency": float64(50)}, want: "max_concurrency must be"},
+		{name: "resource group arguments", params: map[string]interface{}{"operation": "get", "resource_group": "prod-rg --subscription other"}, want: "invalid resource_group"},
+		{name: "fleet name arguments", params: map[string]interface{}{"operation": "get", "fleet_name": "prod-fleet;ls", "fleet_resource_group": "fleet-rg"}, want: "invalid fleet_name"},
+		{name: "empty resource group", params: map[string]interface{}{"operation": "get", "resource_group": "empty-rg"}, want: "no AKS clusters found for resource group empty-rg"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			params := map[string]interface{}{"subscription_id": testSubscriptionID}
+			for k, v := range tt.params {
+				params[k] = v
+			}
+
+			kubectl := &stubKubectl{}
+			_, err := runHandler(t, kubectl, params)
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("Expected error containing %q, got %v", tt.want, err)
+			}
+			if len(kubectl.calls) != 0 {
+				t.Errorf("Expected no kubectl calls, got %d", len(kubectl.calls))
+			}
+		})
+	}
+}
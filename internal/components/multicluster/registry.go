@@ -0,0 +1,48 @@
+package multicluster
+
+import (
+	"fmt"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// RegisterMultiClusterKubectlTool registers the kubectl_multi_cluster tool
+func RegisterMultiClusterKubectlTool() mcp.Tool {
+	return mcp.NewTool(
+		"kubectl_multi_cluster",
+		mcp.WithDescription("Run the same read-only kubectl command against many AKS clusters in parallel and return the output or error of each cluster. "+
+			"Select clusters with exactly one of clusters, resource_group, or fleet_name and fleet_resource_group. "+
+			"Only read-only operations run, whatever the server access level, and the allowed namespaces apply to every cluster. "+
+			"Example: operation='get', resource='pods', args='--namespace payments --field-selector=status.phase!=Running'"),
+		mcp.WithString("operation",
+			mcp.Description("The read-only kubectl operation to run"),
+			mcp.Enum(readOnlyOperations()...),
+			mcp.Required(),
+		),
+		mcp.WithString("resource",
+			mcp.Description("The resource type, e.g. pods or deployments. Use 'can-i' with the auth operation."),
+		),
+		mcp.WithString("args",
+			mcp.Description("Additional kubectl arguments, e.g. '--namespace payments --output wide'"),
+		),
+		mcp.WithString("subscription_id",
+			mcp.Description("Azure Subscription ID of the clusters, resource group or fleet"),
+			mcp.Required(),
+		),
+		mcp.WithString("clusters",
+			mcp.Description("Comma-separated clusters as resource_group/cluster_name or AKS resource IDs"),
+		),
+		mcp.WithString("resource_group",
+			mcp.Description("Run against every AKS cluster in this resource group"),
+		),
+		mcp.WithString("fleet_name",
+			mcp.Description("Run against every member cluster of this Azure Kubernetes Fleet Manager fleet"),
+		),
+		mcp.WithString("fleet_resource_group",
+			mcp.Description("Resource group of the fleet"),
+		),
+		mcp.WithNumber("max_concurrency",
+			mcp.Description(fmt.Sprintf("Maximum number of clusters queried at once (default %d, maximum %d)", defaultConcurrency, maxConcurrency)),
+		),
+	)
+}
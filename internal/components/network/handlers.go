@@ -28,7 +28,10 @@ func GetVNetInfoHandler(client *azureclient.AzureClient, cfg *config.ConfigData)
 		}
 
 		// Get the cluster details
-		ctx := context.Background()
+		ctx, err := common.TenantContext(context.Background(), client, params)
+		if err != nil {
+			return "", err
+		}
 		cluster, err := common.GetClusterDetails(ctx, client, subID, rg, clusterName)
 		if err != nil {
 			return "", fmt.Errorf("failed to get cluster details: %v", err)
@@ -71,7 +74,10 @@ func GetNSGInfoHandler(client *azureclient.AzureClient, cfg *config.ConfigData)
 		}
 
 		// Get the cluster details
-		ctx := context.Background()
+		ctx, err := common.TenantContext(context.Background(), client, params)
+		if err != nil {
+			return "", err
+		}
 		cluster, err := common.GetClusterDetails(ctx, client, subID, rg, clusterName)
 		if err != nil {
 			return "", fmt.Errorf("failed to get cluster details: %v", err)
@@ -114,7 +120,10 @@ func GetRouteTableInfoHandler(client *azureclient.AzureClient, cfg *config.Confi
 		}
 
 		// Get the cluster details
-		ctx := context.Background()
+		ctx, err := common.TenantContext(context.Background(), client, params)
+		if err != nil {
+			return "", err
+		}
 		cluster, err := common.GetClusterDetails(ctx, client, subID, rg, clusterName)
 		if err != nil {
 			return "", fmt.Errorf("failed to get cluster details: %v", err)
@@ -171,7 +180,10 @@ func GetSubnetInfoHandler(client *azureclient.AzureClient, cfg *config.ConfigDat
 		}
 
 		// Get the cluster details
-		ctx := context.Background()
+		ctx, err := common.TenantContext(context.Background(), client, params)
+		if err != nil {
+			return "", err
+		}
 		cluster, err := common.GetClusterDetails(ctx, client, subID, rg, clusterName)
 		if err != nil {
 			return "", fmt.Errorf("failed to get cluster details: %v", err)
@@ -214,7 +226,10 @@ func GetLoadBalancersInfoHandler(client *azureclient.AzureClient, cfg *config.Co
 		}
 
 		// Get the cluster details
-		ctx := context.Background()
+		ctx, err := common.TenantContext(context.Background(), client, params)
+		if err != nil {
+			return "", err
+		}
 		cluster, err := common.GetClusterDetails(ctx, client, subID, rg, clusterName)
 		if err != nil {
 			return "", fmt.Errorf("failed to get cluster details: %v", err)
@@ -289,14 +304,19 @@ func GetPrivateEndpointInfoHandler(client *azureclient.AzureClient, cfg *config.
 			return "", err
 		}
 
+		ctx, err := common.TenantContext(context.Background(), client, params)
+		if err != nil {
+			return "", err
+		}
+
 		// Get the cluster details to verify it exists and get node resource group
-		cluster, err := client.GetAKSCluster(context.Background(), subID, rg, clusterName)
+		cluster, err := client.GetAKSCluster(ctx, subID, rg, clusterName)
 		if err != nil {
 			return "", fmt.Errorf("failed to get AKS cluster: %v", err)
 		}
 
 		// Check if cluster is private and get private endpoint info
-		privateEndpointID, err := resourcehelpers.GetPrivateEndpointIDFromAKS(context.Background(), cluster, client)
+		privateEndpointID, err := resourcehelpers.GetPrivateEndpointIDFromAKS(ctx, cluster, client)
 		if err != nil {
 			return "", fmt.Errorf("failed to get private endpoint info: %v", err)
 		}
@@ -315,7 +335,7 @@ func GetPrivateEndpointInfoHandler(client *azureclient.AzureClient, cfg *config.
 		}
 
 		// Get the private endpoint details using the resource ID
-		privateEndpoint, err := client.GetPrivateEndpointByID(context.Background(), privateEndpointID)
+		privateEndpoint, err := client.GetPrivateEndpointByID(ctx, privateEndpointID)
 		if err != nil {
 			return "", fmt.Errorf("failed to get private endpoint details: %v", err)
 		}
@@ -333,56 +353,59 @@ func GetPrivateEndpointInfoHandler(client *azureclient.AzureClient, cfg *config.
 // GetAzNetworkResourcesHandler returns a handler for the az_network_resources command
 func GetAzNetworkResourcesHandler(client *azureclient.AzureClient, cfg *config.ConfigData) tools.ResourceHandler {
 	return tools.ResourceHandlerFunc(func(params map[string]interface{}, _ *config.ConfigData) (string, error) {
-		resourceType, subID, rg, clusterName, err := validateNetworkParams(params)
+		resourceType, err := validateNetworkParams(params)
 		if err != nil {
 			return "", err
 		}
 
-		// Handle resource type
-		return handleNetworkResourceType(client, resourceType, subID, rg, clusterName)
+		if _, err := common.TenantContext(context.Background(), client, params); err != nil {
+			return "", err
+		}
+
+		// Handle resource type; the resource handlers read the cluster and tenant from params
+		return handleNetworkResourceType(client, resourceType, params)
 	})
 }
 
-// validateNetworkParams validates network resource parameters
-func validateNetworkParams(params map[string]interface{}) (string, string, string, string, error) {
+// validateNetworkParams validates network resource parameters and returns the resource type
+func validateNetworkParams(params map[string]interface{}) (string, error) {
 	// Extract resource_type parameter
 	resourceType, ok := params["resource_type"].(string)
 	if !ok {
-		return "", "", "", "", fmt.Errorf("missing or invalid 'resource_type' parameter")
+		return "", fmt.Errorf("missing or invalid 'resource_type' parameter")
 	}
 
 	// Validate resource type
 	if !ValidateNetworkResourceType(resourceType) {
 		supportedTypes := GetSupportedNetworkResourceTypes()
-		return "", "", "", "", fmt.Errorf("unsupported resource type: %s. Supported types: %v", resourceType, supportedTypes)
+		return "", fmt.Errorf("unsupported resource type: %s. Supported types: %v", resourceType, supportedTypes)
 	}
 
-	// Extract common AKS parameters
-	subID, rg, clusterName, err := common.ExtractAKSParameters(params)
-	if err != nil {
-		return "", "", "", "", err
+	// Validate common AKS parameters
+	if _, _, _, err := common.ExtractAKSParameters(params); err != nil {
+		return "", err
 	}
 
-	return resourceType, subID, rg, clusterName, nil
+	return resourceType, nil
 }
 
 // handleNetworkResourceType routes to the appropriate resource handler based on type
-func handleNetworkResourceType(client *azureclient.AzureClient, resourceType, subID, rg, clusterName string) (string, error) {
+func handleNetworkResourceType(client *azureclient.AzureClient, resourceType string, params map[string]interface{}) (string, error) {
 	switch resourceType {
 	case string(ResourceTypeAll):
-		return handleAllNetworkResources(client, subID, rg, clusterName)
+		return handleAllNetworkResources(client, params)
 	case string(ResourceTypeVNet):
-		return handleVNetResource(client, subID, rg, clusterName)
+		return handleVNetResource(client, params)
 	case string(ResourceTypeNSG):
-		return handleNSGResource(client, subID, rg, clusterName)
+		return handleNSGResource(client, params)
 	case string(ResourceTypeRouteTable):
-		return handleRouteTableResource(client, subID, rg, clusterName)
+		return handleRouteTableResource(client, params)
 	case string(ResourceTypeSubnet):
-		return handleSubnetResource(client, subID, rg, clusterName)
+		return handleSubnetResource(client, params)
 	case string(ResourceTypeLoadBalancer):
-		return handleLoadBalancerResource(client, subID, rg, clusterName)
+		return handleLoadBalancerResource(client, params)
 	case string(ResourceTypePrivateEndpoint):
-		return handlePrivateEndpointResource(client, subID, rg, clusterName)
+		return handlePrivateEndpointResource(client, params)
 	default:
 		return "", fmt.Errorf("resource type '%s' not implemented", resourceType)
 	}
@@ -390,11 +413,11 @@ func handleNetworkResourceType(client *azureclient.AzureClient, resourceType, su
 
 // Helper functions for different resource types
 
-func handleAllNetworkResources(client *azureclient.AzureClient, subID, rg, clusterName string) (string, error) {
+func handleAllNetworkResources(client *azureclient.AzureClient, params map[string]interface{}) (string, error) {
 	result := make(map[string]interface{})
 
 	// Collect results and errors for each resource type
-	resourceHandlers := map[string]func(*azureclient.AzureClient, string, string, string) (string, error){
+	resourceHandlers := map[string]func(*azureclient.AzureClient, map[string]interface{}) (string, error){
 		"vnet":             handleVNetResource,
 		"nsg":              handleNSGResource,
 		"route_table":      handleRouteTableResource,
@@ -405,7 +428,7 @@ func handleAllNetworkResources(client *azureclient.AzureClient, subID, rg, clust
 
 	// Process each resource type and preserve error context
 	for resourceType, handler := range resourceHandlers {
-		resourceResult, err := handler(client, subID, rg, clusterName)
+		resourceResult, err := handler(client, params)
 		if err != nil {
 			// Preserve original error context and type for debugging
 			result[resourceType+"_error"] = map[string]interface{}{
@@ -425,68 +448,38 @@ func handleAllNetworkResources(client *azureclient.AzureClient, subID, rg, clust
 	return string(resultJSON), nil
 }
 
-func handleVNetResource(client *azureclient.AzureClient, subID, rg, clusterName string) (string, error) {
+func handleVNetResource(client *azureclient.AzureClient, params map[string]interface{}) (string, error) {
 	// Use the existing VNet handler logic
 	handler := GetVNetInfoHandler(client, nil)
-	params := map[string]interface{}{
-		"subscription_id": subID,
-		"resource_group":  rg,
-		"cluster_name":    clusterName,
-	}
 	return handler.Handle(params, nil)
 }
 
-func handleNSGResource(client *azureclient.AzureClient, subID, rg, clusterName string) (string, error) {
+func handleNSGResource(client *azureclient.AzureClient, params map[string]interface{}) (string, error) {
 	// Use the existing NSG handler logic
 	handler := GetNSGInfoHandler(client, nil)
-	params := map[string]interface{}{
-		"subscription_id": subID,
-		"resource_group":  rg,
-		"cluster_name":    clusterName,
-	}
 	return handler.Handle(params, nil)
 }
 
-func handleRouteTableResource(client *azureclient.AzureClient, subID, rg, clusterName string) (string, error) {
+func handleRouteTableResource(client *azureclient.AzureClient, params map[string]interface{}) (string, error) {
 	// Use the existing Route Table handler logic
 	handler := GetRouteTableInfoHandler(client, nil)
-	params := map[string]interface{}{
-		"subscription_id": subID,
-		"resource_group":  rg,
-		"cluster_name":    clusterName,
-	}
 	return handler.Handle(params, nil)
 }
 
-func handleSubnetResource(client *azureclient.AzureClient, subID, rg, clusterName string) (string, error) {
+func handleSubnetResource(client *azureclient.AzureClient, params map[string]interface{}) (string, error) {
 	// Use the existing Subnet handler logic
 	handler := GetSubnetInfoHandler(client, nil)
-	params := map[string]interface{}{
-		"subscription_id": subID,
-		"resource_group":  rg,
-		"cluster_name":    clusterName,
-	}
 	return handler.Handle(params, nil)
 }
 
-func handleLoadBalancerResource(client *azureclient.AzureClient, subID, rg, clusterName string) (string, error) {
+func handleLoadBalancerResource(client *azureclient.AzureClient, params map[string]interface{}) (string, error) {
 	// Use the existing Load Balancer handler logic
 	handler := GetLoadBalancersInfoHandler(client, nil)
-	params := map[string]interface{}{
-		"subscription_id": subID,
-		"resource_group":  rg,
-		"cluster_name":    clusterName,
-	}
 	return handler.Handle(params, nil)
 }
 
-func handlePrivateEndpointResource(client *azureclient.AzureClient, subID, rg, clusterName string) (string, error) {
+func handlePrivateEndpointResource(client *azureclient.AzureClient, params map[string]interface{}) (string, error) {
 	// Use the existing Private Endpoint handler logic
 	handler := GetPrivateEndpointInfoHandler(client, nil)
-	params := map[string]interface{}{
-		"subscription_id": subID,
-		"resource_group":  rg,
-		"cluster_name":    clusterName,
-	}
 	return handler.Handle(params, nil)
 }
@@ -1,7 +1,11 @@
 package network
 
 import (
+	"encoding/json"
 	"testing"
+
+	"github.com/Azure/aks-mcp/internal/azureclient/fakearm"
+	"github.com/Azure/aks-mcp/internal/azureclient/fakearm/armtest"
 )
 
 // TestGetLoadBalancersInfoHandler tests the load balancers info handler
@@ -219,3 +223,40 @@ func TestGetPrivateEndpointInfoHandlerValidation(t *testing.T) {
 	// Note: Testing with valid parameters and actual Azure client calls
 	// would require integration tests with mocked Azure services
 }
+
+// TestGetAzNetworkResourcesHandler_FakeARM runs the network tool end to end against a local fake ARM server
+func TestGetAzNetworkResourcesHandler_FakeARM(t *testing.T) {
+	_, client, _ := armtest.NewClient(t)
+
+	handler := GetAzNetworkResourcesHandler(client, nil)
+	tests := []struct {
+		resourceType string
+		wantName     string
+	}{
+		{"vnet", "aks-vnet-12345678"},
+		{"subnet", "aks-subnet"},
+		{"nsg", "aks-agentpool-12345678-nsg"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.resourceType, func(t *testing.T) {
+			result, err := handler.Handle(map[string]interface{}{
+				"resource_type":   tt.resourceType,
+				"subscription_id": fakearm.SubscriptionID,
+				"resource_group":  fakearm.ResourceGroup,
+				"cluster_name":    fakearm.ClusterName,
+			}, nil)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+
+			var resource map[string]interface{}
+			if err := json.Unmarshal([]byte(result), &resource); err != nil {
+				t.Fatalf("Failed to parse result: %v", err)
+			}
+			if resource["name"] != tt.wantName {
+				t.Errorf("Expected %s, got %v", tt.wantName, resource["name"])
+			}
+		})
+	}
+}
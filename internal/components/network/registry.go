@@ -55,6 +55,9 @@ Examples:
 			mcp.Description("Name of the AKS cluster"),
 			mcp.Required(),
 		),
+		mcp.WithString("tenant_id",
+			mcp.Description("Optional Entra tenant ID of the subscription (inferred from az account list if omitted)"),
+		),
 		mcp.WithString("filters",
 			mcp.Description("Optional filters for the query"),
 		),
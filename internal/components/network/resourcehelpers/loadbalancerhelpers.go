@@ -46,7 +46,7 @@ func findLoadBalancersInNodeResourceGroup(
 	nodeResourceGroup string,
 ) ([]string, error) {
 	// Get clients for the subscription
-	clients, err := client.GetOrCreateClientsForSubscription(subscriptionID)
+	clients, err := client.GetOrCreateClientsForContext(ctx, subscriptionID)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get clients for subscription %s: %v", subscriptionID, err)
 	}
@@ -53,7 +53,7 @@ func GetNSGIDFromAKS(
 	}
 
 	// Get subnet details to find attached NSG
-	clients, err := client.GetOrCreateClientsForSubscription(subscriptionID)
+	clients, err := client.GetOrCreateClientsForContext(ctx, subscriptionID)
 	if err != nil {
 		return "", fmt.Errorf("failed to get clients for subscription %s: %v", subscriptionID, err)
 	}
@@ -55,7 +55,7 @@ func findPrivateEndpointInNodeResourceGroup(
 	nodeResourceGroup string,
 ) (string, error) {
 	// Get clients for the subscription
-	clients, err := client.GetOrCreateClientsForSubscription(subscriptionID)
+	clients, err := client.GetOrCreateClientsForContext(ctx, subscriptionID)
 	if err != nil {
 		return "", fmt.Errorf("failed to get clients for subscription %s: %v", subscriptionID, err)
 	}
@@ -53,7 +53,7 @@ func GetRouteTableIDFromAKS(
 	}
 
 	// Get subnet details to find attached route table
-	clients, err := client.GetOrCreateClientsForSubscription(subscriptionID)
+	clients, err := client.GetOrCreateClientsForContext(ctx, subscriptionID)
 	if err != nil {
 		return "", fmt.Errorf("failed to get clients for subscription %s: %v", subscriptionID, err)
 	}
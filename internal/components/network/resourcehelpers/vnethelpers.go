@@ -64,7 +64,7 @@ func findVNetInNodeResourceGroup(
 	nodeResourceGroup := *cluster.Properties.NodeResourceGroup
 
 	// List virtual networks in the node resource group (now cached at client level)
-	clients, err := client.GetOrCreateClientsForSubscription(subscriptionID)
+	clients, err := client.GetOrCreateClientsForContext(ctx, subscriptionID)
 	if err != nil {
 		return "", fmt.Errorf("failed to get clients for subscription %s: %v", subscriptionID, err)
 	}
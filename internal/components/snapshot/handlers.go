@@ -0,0 +1,179 @@
+// Package snapshot exports the Azure-side configuration of an AKS cluster as a portable JSON document.
+package snapshot
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/Azure/aks-mcp/internal/azureclient"
+	"github.com/Azure/aks-mcp/internal/components/advisor"
+	"github.com/Azure/aks-mcp/internal/components/common"
+	"github.com/Azure/aks-mcp/internal/components/compute"
+	"github.com/Azure/aks-mcp/internal/components/monitor/diagnostics"
+	"github.com/Azure/aks-mcp/internal/components/network"
+	"github.com/Azure/aks-mcp/internal/config"
+	"github.com/Azure/aks-mcp/internal/tools"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/containerservice/armcontainerservice/v2"
+)
+
+// SchemaVersion is the version of the snapshot document format.
+// Bump it when sections are renamed or removed or their content changes shape.
+const SchemaVersion = "1.0"
+
+// now returns the capture time; tests replace it
+var now = time.Now
+
+// ClusterRef identifies the exported cluster
+type ClusterRef struct {
+	SubscriptionID string `json:"subscription_id"`
+	ResourceGroup  string `json:"resource_group"`
+	ClusterName    string `json:"cluster_name"`
+}
+
+// Network holds the network resources of the cluster
+type Network struct {
+	VNet            json.RawMessage `json:"vnet,omitempty"`
+	Subnet          json.RawMessage `json:"subnet,omitempty"`
+	NSG             json.RawMessage `json:"nsg,omitempty"`
+	RouteTable      json.RawMessage `json:"route_table,omitempty"`
+	LoadBalancers   json.RawMessage `json:"load_balancers,omitempty"`
+	PrivateEndpoint json.RawMessage `json:"private_endpoint,omitempty"`
+}
+
+// Document is the snapshot exported by the aks_snapshot_export tool.
+// Each section holds the resource as returned by Azure; sections that could not be read
+// are left out and their error is recorded under Errors, keyed by section name.
+type Document struct {
+	SchemaVersion          string                              `json:"schema_version"`
+	CapturedAt             string                              `json:"captured_at"`
+	Cluster                ClusterRef                          `json:"cluster"`
+	ManagedCluster         *armcontainerservice.ManagedCluster `json:"managed_cluster"`
+	NodePools              []*armcontainerservice.AgentPool    `json:"node_pools,omitempty"`
+	VMSS                   json.RawMessage                     `json:"vmss,omitempty"`
+	Network                Network                             `json:"network"`
+	DiagnosticSettings     json.RawMessage                     `json:"diagnostic_settings,omitempty"`
+	AdvisorRecommendations json.RawMessage                     `json:"advisor_recommendations,omitempty"`
+	Errors                 map[string]string                   `json:"errors,omitempty"`
+}
+
+// GetSnapshotExportHandler returns a handler for the aks_snapshot_export tool
+func GetSnapshotExportHandler(client *azureclient.AzureClient, cfg *config.ConfigData) tools.ResourceHandler {
+	return tools.ResourceHandlerFunc(func(params map[string]interface{}, _ *config.ConfigData) (string, error) {
+		subID, rg, clusterName, err := common.ExtractAKSParameters(params)
+		if err != nil {
+			return "", err
+		}
+
+		if _, err := common.TenantContext(context.Background(), client, params); err != nil {
+			return "", err
+		}
+		tenantID, _ := params["tenant_id"].(string)
+
+		doc, err := capture(client, cfg, ClusterRef{SubscriptionID: subID, ResourceGroup: rg, ClusterName: clusterName}, tenantID)
+		if err != nil {
+			return "", err
+		}
+
+		resultJSON, err := json.MarshalIndent(doc, "", "  ")
+		if err != nil {
+			return "", fmt.Errorf("failed to marshal snapshot to JSON: %v", err)
+		}
+		return string(resultJSON), nil
+	})
+}
+
+// capture reads every section of the snapshot in the given tenant, or the subscription's tenant when empty.
+// Only failing to get the cluster is an error.
+func capture(client *azureclient.AzureClient, cfg *config.ConfigData, ref ClusterRef, tenantID string) (*Document, error) {
+	ctx := azureclient.WithTenant(context.Background(), tenantID)
+	capturedAt := now().UTC()
+
+	// The cluster is read uncached, like the node pools, so it matches the capture time
+	cluster, err := client.GetAKSClusterStatus(ctx, ref.SubscriptionID, ref.ResourceGroup, ref.ClusterName)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get cluster details: %v", err)
+	}
+
+	doc := &Document{
+		SchemaVersion:  SchemaVersion,
+		CapturedAt:     capturedAt.Format(time.RFC3339),
+		Cluster:        ref,
+		ManagedCluster: cluster,
+		Errors:         make(map[string]string),
+	}
+
+	params := map[string]interface{}{
+		"subscription_id": ref.SubscriptionID,
+		"resource_group":  ref.ResourceGroup,
+		"cluster_name":    ref.ClusterName,
+	}
+	if tenantID != "" {
+		params["tenant_id"] = tenantID
+	}
+	section := func(name string, handler func() (string, error)) json.RawMessage {
+		output, err := handler()
+		if err != nil {
+			doc.Errors[name] = err.Error()
+			return nil
+		}
+		if !json.Valid([]byte(output)) {
+			doc.Errors[name] = fmt.Sprintf("unexpected output: %s", output)
+			return nil
+		}
+		return json.RawMessage(output)
+	}
+	handle := func(handler tools.ResourceHandler) func() (string, error) {
+		return func() (string, error) {
+			return handler.Handle(params, cfg)
+		}
+	}
+
+	doc.NodePools = captureNodePools(ctx, client, cluster, ref, doc.Errors)
+	doc.VMSS = section("vmss", handle(compute.GetAKSVMSSInfoHandler(client, cfg)))
+	doc.Network = Network{
+		VNet:            section("network.vnet", handle(network.GetVNetInfoHandler(client, cfg))),
+		Subnet:          section("network.subnet", handle(network.GetSubnetInfoHandler(client, cfg))),
+		NSG:             section("network.nsg", handle(network.GetNSGInfoHandler(client, cfg))),
+		RouteTable:      section("network.route_table", handle(network.GetRouteTableInfoHandler(client, cfg))),
+		LoadBalancers:   section("network.load_balancers", handle(network.GetLoadBalancersInfoHandler(client, cfg))),
+		PrivateEndpoint: section("network.private_endpoint", handle(network.GetPrivateEndpointInfoHandler(client, cfg))),
+	}
+	doc.DiagnosticSettings = section("diagnostic_settings", func() (string, error) {
+		return diagnostics.HandleControlPlaneDiagnosticSettings(params, client, cfg)
+	})
+	doc.AdvisorRecommendations = section("advisor_recommendations", func() (string, error) {
+		return advisor.HandleAdvisorRecommendation(map[string]interface{}{
+			"operation":       "list",
+			"subscription_id": ref.SubscriptionID,
+			"resource_group":  ref.ResourceGroup,
+			"cluster_names":   ref.ClusterName,
+		}, cfg)
+	})
+
+	return doc, nil
+}
+
+// captureNodePools gets the full agent pool resource of every node pool in the cluster
+func captureNodePools(ctx context.Context, client *azureclient.AzureClient, cluster *armcontainerservice.ManagedCluster, ref ClusterRef, errs map[string]string) []*armcontainerservice.AgentPool {
+	profiles, err := compute.GetNodePoolsFromAKS(ctx, cluster, client)
+	if err != nil {
+		errs["node_pools"] = err.Error()
+		return nil
+	}
+
+	var pools []*armcontainerservice.AgentPool
+	for _, profile := range profiles {
+		if profile.Name == nil {
+			continue
+		}
+		pool, err := client.GetAgentPool(ctx, ref.SubscriptionID, ref.ResourceGroup, ref.ClusterName, *profile.Name)
+		if err != nil {
+			errs["node_pools."+*profile.Name] = err.Error()
+			continue
+		}
+		pools = append(pools, pool)
+	}
+	return pools
+}
@@ -0,0 +1,191 @@
+package snapshot
+
+import (
+	"context"
+	"encoding/json"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Azure/aks-mcp/internal/azureclient/fakearm"
+	"github.com/Azure/aks-mcp/internal/azureclient/fakearm/armtest"
+	"github.com/Azure/aks-mcp/internal/command"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/to"
+)
+
+func useCassette(t *testing.T) {
+	t.Helper()
+
+	stop, err := command.UseCassette(filepath.Join("testdata", "cassettes", "snapshot_export.json"))
+	if err != nil {
+		t.Fatalf("Failed to load cassette: %v", err)
+	}
+	t.Cleanup(func() {
+		if err := stop(); err != nil {
+			t.Errorf("Failed to save cassette: %v", err)
+		}
+	})
+}
+
+func TestGetSnapshotExportHandler(t *testing.T) {
+	useCassette(t)
+	_, client, cfg := armtest.NewClient(t, filepath.Join("testdata", "fixtures.json"))
+
+	defer func(original func() time.Time) { now = original }(now)
+	now = func() time.Time { return time.Date(2026, 3, 1, 12, 0, 0, 0, time.FixedZone("CET", 3600)) }
+
+	output, err := GetSnapshotExportHandler(client, cfg).Handle(map[string]interface{}{
+		"subscription_id": fakearm.SubscriptionID,
+		"resource_group":  fakearm.ResourceGroup,
+		"cluster_name":    fakearm.ClusterName,
+	}, cfg)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	var doc map[string]json.RawMessage
+	if err := json.Unmarshal([]byte(output), &doc); err != nil {
+		t.Fatalf("Failed to parse snapshot: %v", err)
+	}
+
+	if string(doc["schema_version"]) != `"`+SchemaVersion+`"` {
+		t.Errorf("Expected schema version %s, got %s", SchemaVersion, doc["schema_version"])
+	}
+	if string(doc["captured_at"]) != `"2026-03-01T11:00:00Z"` {
+		t.Errorf("Expected the capture time in UTC, got %s", doc["captured_at"])
+	}
+	if _, ok := doc["errors"]; ok {
+		t.Errorf("Unexpected errors: %s", doc["errors"])
+	}
+
+	// Each section holds the resource as Azure returns it
+	sections := map[string]string{
+		"managed_cluster":         `"kubernetesVersion":"1.30.4"`,
+		"node_pools":              `"vmSize":"Standard_DS2_v2"`,
+		"vmss":                    `"aks-nodepool1-12345678-vmss"`,
+		"diagnostic_settings":     `[`,
+		"advisor_recommendations": `"category":"Cost"`,
+	}
+	for section, expected := range sections {
+		if !strings.Contains(compact(t, doc[section]), expected) {
+			t.Errorf("Expected section %s to contain %s, got %s", section, expected, doc[section])
+		}
+	}
+
+	var network map[string]json.RawMessage
+	if err := json.Unmarshal(doc["network"], &network); err != nil {
+		t.Fatalf("Failed to parse network section: %v", err)
+	}
+	networkSections := map[string]string{
+		"vnet":             `"aks-vnet-12345678"`,
+		"subnet":           `"addressPrefix":"10.224.0.0/16"`,
+		"nsg":              `"allow-https"`,
+		"route_table":      `No route table attached`,
+		"load_balancers":   `"name":"kubernetes"`,
+		"private_endpoint": `"private_cluster":false`,
+	}
+	for section, expected := range networkSections {
+		if !strings.Contains(compact(t, network[section]), expected) {
+			t.Errorf("Expected network section %s to contain %s, got %s", section, expected, network[section])
+		}
+	}
+}
+
+func TestGetSnapshotExportHandlerPartialFailure(t *testing.T) {
+	useCassette(t)
+	srv, client, cfg := armtest.NewClient(t)
+
+	// Without the load balancer fixtures and the NSG, those sections fail and the others are still exported
+	srv.RemoveResponse("/subscriptions/" + fakearm.SubscriptionID + "/resourceGroups/" + fakearm.NodeResourceGroup +
+		"/providers/Microsoft.Network/networkSecurityGroups/aks-agentpool-12345678-nsg")
+
+	output, err := GetSnapshotExportHandler(client, cfg).Handle(map[string]interface{}{
+		"subscription_id": fakearm.SubscriptionID,
+		"resource_group":  fakearm.ResourceGroup,
+		"cluster_name":    fakearm.ClusterName,
+	}, cfg)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	var doc Document
+	if err := json.Unmarshal([]byte(output), &doc); err != nil {
+		t.Fatalf("Failed to parse snapshot: %v", err)
+	}
+	for _, section := range []string{"network.load_balancers", "network.nsg"} {
+		if doc.Errors[section] == "" {
+			t.Errorf("Expected an error for %s, got %v", section, doc.Errors)
+		}
+	}
+	if len(doc.Errors) != 2 {
+		t.Errorf("Expected 2 errors, got %v", doc.Errors)
+	}
+	if doc.ManagedCluster == nil || len(doc.NodePools) != 1 || doc.Network.VNet == nil || doc.Network.NSG != nil {
+		t.Errorf("Unexpected sections in partial snapshot: %s", output)
+	}
+}
+
+func TestGetSnapshotExportHandlerReadsCurrentCluster(t *testing.T) {
+	useCassette(t)
+	srv, client, cfg := armtest.NewClient(t)
+
+	// The cluster is cached before it is upgraded, and the snapshot must still show the upgraded cluster
+	cached, err := client.GetAKSCluster(context.Background(), fakearm.SubscriptionID, fakearm.ResourceGroup, fakearm.ClusterName)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	upgraded := *cached
+	properties := *cached.Properties
+	properties.KubernetesVersion = to.Ptr("1.99.0")
+	upgraded.Properties = &properties
+	if err := srv.SetResponse(fakearm.ClusterResourceID, upgraded); err != nil {
+		t.Fatalf("Failed to set cluster: %v", err)
+	}
+
+	output, err := GetSnapshotExportHandler(client, cfg).Handle(map[string]interface{}{
+		"subscription_id": fakearm.SubscriptionID,
+		"resource_group":  fakearm.ResourceGroup,
+		"cluster_name":    fakearm.ClusterName,
+	}, cfg)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	var doc Document
+	if err := json.Unmarshal([]byte(output), &doc); err != nil {
+		t.Fatalf("Failed to parse snapshot: %v", err)
+	}
+	if doc.ManagedCluster == nil || doc.ManagedCluster.Properties == nil || doc.ManagedCluster.Properties.KubernetesVersion == nil ||
+		*doc.ManagedCluster.Properties.KubernetesVersion != "1.99.0" {
+		t.Errorf("Expected the snapshot to read the current cluster, got %s", output)
+	}
+}
+
+func TestGetSnapshotExportHandlerClusterNotFound(t *testing.T) {
+	_, client, cfg := armtest.NewClient(t)
+
+	_, err := GetSnapshotExportHandler(client, cfg).Handle(map[string]interface{}{
+		"subscription_id": fakearm.SubscriptionID,
+		"resource_group":  fakearm.ResourceGroup,
+		"cluster_name":    "missing-cluster",
+	}, cfg)
+	if err == nil || !strings.Contains(err.Error(), "failed to get cluster details") {
+		t.Errorf("Expected cluster lookup error, got %v", err)
+	}
+}
+
+// compact removes insignificant whitespace from a JSON section
+func compact(t *testing.T, section json.RawMessage) string {
+	t.Helper()
+
+	var value interface{}
+	if err := json.Unmarshal(section, &value); err != nil {
+		t.Fatalf("Failed to parse section: %v", err)
+	}
+	data, err := json.Marshal(value)
+	if err != nil {
+		t.Fatalf("Failed to marshal section: %v", err)
+	}
+	return string(data)
+}
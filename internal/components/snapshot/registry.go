@@ -0,0 +1,31 @@
+package snapshot
+
+import (
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// RegisterSnapshotExportTool registers the aks_snapshot_export tool
+func RegisterSnapshotExportTool() mcp.Tool {
+	return mcp.NewTool(
+		"aks_snapshot_export",
+		mcp.WithDescription("Export the Azure-side configuration of an AKS cluster as a single versioned JSON document for audits and incident handoffs. "+
+			"The document contains the managed cluster, node pools and their VMSS models, the VNet, subnet, NSG, route table, load balancers and private endpoint, "+
+			"diagnostic settings and Azure Advisor recommendations, with a schema version and the capture time. "+
+			"Sections that cannot be read are listed under errors instead of failing the export. This tool only reads resources."),
+		mcp.WithString("subscription_id",
+			mcp.Description("Azure Subscription ID"),
+			mcp.Required(),
+		),
+		mcp.WithString("resource_group",
+			mcp.Description("Azure Resource Group containing the AKS cluster"),
+			mcp.Required(),
+		),
+		mcp.WithString("cluster_name",
+			mcp.Description("Name of the AKS cluster"),
+			mcp.Required(),
+		),
+		mcp.WithString("tenant_id",
+			mcp.Description("Optional Entra tenant ID of the subscription (inferred from az account list if omitted)"),
+		),
+	)
+}
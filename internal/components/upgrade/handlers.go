@@ -0,0 +1,331 @@
+// Package upgrade provides the aks_upgrade_plan tool, which checks whether an AKS cluster is ready
+// to be upgraded and orders the upgrade steps without performing them.
+package upgrade
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/Azure/aks-mcp/internal/azureclient"
+	"github.com/Azure/aks-mcp/internal/command"
+	"github.com/Azure/aks-mcp/internal/components/common"
+	"github.com/Azure/aks-mcp/internal/config"
+	"github.com/Azure/aks-mcp/internal/kubeconfig"
+	"github.com/Azure/aks-mcp/internal/security"
+	"github.com/Azure/aks-mcp/internal/tools"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/containerservice/armcontainerservice/v2"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/network/armnetwork/v2"
+)
+
+// versionPattern matches Kubernetes versions accepted as target_version
+var versionPattern = regexp.MustCompile(`^\d+\.\d+\.\d+$`)
+
+// metricLabelPattern matches the labels of a Prometheus metric line
+var metricLabelPattern = regexp.MustCompile(`(\w+)="([^"]*)"`)
+
+// GetUpgradePlanHandler returns a handler for the aks_upgrade_plan tool.
+// kubectl runs the read-only Kubernetes checks against the requested cluster, which it is given as the cluster target parameters.
+func GetUpgradePlanHandler(client *azureclient.AzureClient, kubectl tools.CommandExecutor, cfg *config.ConfigData) tools.ResourceHandler {
+	return tools.ResourceHandlerFunc(func(params map[string]interface{}, _ *config.ConfigData) (string, error) {
+		subID, rg, clusterName, err := common.ExtractAKSParameters(params)
+		if err != nil {
+			return "", err
+		}
+		target := kubeconfig.Target{SubscriptionID: subID, ResourceGroup: rg, ClusterName: clusterName}
+		if err := target.Validate(); err != nil {
+			return "", err
+		}
+		// The Azure CLI and Kubernetes checks run in the tenant of the az login, so the plan cannot honor tenant_id
+		if tenantID, _ := params["tenant_id"].(string); tenantID != "" {
+			return "", fmt.Errorf("tenant_id is not supported by aks_upgrade_plan, which runs in the tenant of the Azure CLI login; use az login --tenant to switch tenants")
+		}
+
+		targetVersion, _ := params["target_version"].(string)
+		if targetVersion != "" && !versionPattern.MatchString(targetVersion) {
+			return "", fmt.Errorf("invalid target_version '%s', expected a full version such as 1.30.4", targetVersion)
+		}
+
+		ctx := context.Background()
+		// The plan is made against the current cluster, not a cached copy
+		cluster, err := client.GetAKSClusterStatus(ctx, subID, rg, clusterName)
+		if err != nil {
+			return "", fmt.Errorf("failed to get cluster details: %v", err)
+		}
+
+		plan := newPlan(subID, rg, clusterName)
+
+		profile, err := getUpgradeProfile(subID, rg, clusterName, cfg)
+		plan.checkVersions(cluster, targetVersion, profile, err)
+		plan.checkSkew(cluster)
+
+		pdbs, err := listPodDisruptionBudgets(kubectl, target, cfg)
+		plan.checkPodDisruptionBudgets(pdbs, err)
+
+		usages, err := listDeprecatedAPIUsage(kubectl, target, cfg)
+		plan.checkRemovedAPIs(usages, err)
+
+		subnets, managedPools := requiredSubnetIPs(cluster)
+		subnetErrs := loadSubnetCapacity(ctx, client, subnets)
+		plan.checkSubnetCapacity(subnets, managedPools, subnetErrs)
+
+		quotas, err := surgeQuotaUsage(cluster, subID, cfg)
+		plan.checkQuota(quotas, err)
+
+		plan.buildSteps(cluster)
+
+		result, err := json.MarshalIndent(plan, "", "  ")
+		if err != nil {
+			return "", fmt.Errorf("failed to marshal upgrade plan: %v", err)
+		}
+		return string(result), nil
+	})
+}
+
+// runAzJSON runs a read-only az command and decodes its JSON output.
+// The arguments are passed as they are, so names are never split or unquoted.
+func runAzJSON(args []string, cfg *config.ConfigData, v interface{}) error {
+	validator := security.NewValidator(cfg.SecurityConfig)
+	if err := validator.ValidateCommand("az "+strings.Join(args, " "), security.CommandTypeAz); err != nil {
+		return err
+	}
+
+	output, err := command.NewShellProcess("az", cfg.Timeout).RunArgs(args)
+	if err != nil {
+		return err
+	}
+	if err := json.Unmarshal([]byte(output), v); err != nil {
+		return fmt.Errorf("unexpected output: %s", strings.TrimSpace(output))
+	}
+	return nil
+}
+
+// getUpgradeProfile lists the versions the control plane can be upgraded to
+func getUpgradeProfile(subscriptionID, resourceGroup, clusterName string, cfg *config.ConfigData) (*upgradeProfile, error) {
+	var profile upgradeProfile
+	err := runAzJSON([]string{
+		"aks", "get-upgrades",
+		"--name", clusterName,
+		"--resource-group", resourceGroup,
+		"--subscription", subscriptionID,
+		"--output", "json",
+	}, cfg, &profile)
+	if err != nil {
+		return nil, err
+	}
+	return &profile, nil
+}
+
+// listPodDisruptionBudgets lists the PodDisruptionBudgets of all namespaces
+func listPodDisruptionBudgets(kubectl tools.CommandExecutor, target kubeconfig.Target, cfg *config.ConfigData) ([]podDisruptionBudget, error) {
+	output, err := kubectl.Execute(target.Params(map[string]interface{}{
+		"command": "get poddisruptionbudgets --all-namespaces --output json",
+	}), cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	var list struct {
+		Items []podDisruptionBudget `json:"items"`
+	}
+	if err := json.Unmarshal([]byte(output), &list); err != nil {
+		return nil, fmt.Errorf("unexpected kubectl output: %s", strings.TrimSpace(output))
+	}
+	return list.Items, nil
+}
+
+// listDeprecatedAPIUsage reads the deprecated APIs requested since the API server started from its metrics
+func listDeprecatedAPIUsage(kubectl tools.CommandExecutor, target kubeconfig.Target, cfg *config.ConfigData) ([]deprecatedAPIUsage, error) {
+	output, err := kubectl.Execute(target.Params(map[string]interface{}{
+		"command": "get --raw /metrics",
+	}), cfg)
+	if err != nil {
+		return nil, err
+	}
+	return parseDeprecatedAPIMetrics(output)
+}
+
+// parseDeprecatedAPIMetrics extracts apiserver_requested_deprecated_apis series from Prometheus text output
+func parseDeprecatedAPIMetrics(metrics string) ([]deprecatedAPIUsage, error) {
+	if !strings.Contains(metrics, "# TYPE") {
+		return nil, fmt.Errorf("unexpected kubectl output: %s", strings.TrimSpace(metrics))
+	}
+
+	seen := make(map[string]bool)
+	var usages []deprecatedAPIUsage
+	for _, line := range strings.Split(metrics, "\n") {
+		if !strings.HasPrefix(line, "apiserver_requested_deprecated_apis{") {
+			continue
+		}
+
+		// The gauge is set to 1 for APIs requested since the API server started
+		fields := strings.Fields(line)
+		if value, err := strconv.ParseFloat(fields[len(fields)-1], 64); err != nil || value == 0 {
+			continue
+		}
+
+		labels := make(map[string]string)
+		for _, match := range metricLabelPattern.FindAllStringSubmatch(line, -1) {
+			labels[match[1]] = match[2]
+		}
+
+		usage := deprecatedAPIUsage{
+			Group:          labels["group"],
+			Version:        labels["version"],
+			Resource:       labels["resource"],
+			RemovedRelease: labels["removed_release"],
+		}
+		key := usage.Group + "/" + usage.Version + "/" + usage.Resource
+		if !seen[key] {
+			seen[key] = true
+			usages = append(usages, usage)
+		}
+	}
+
+	sort.Slice(usages, func(i, j int) bool {
+		return usages[i].Group+"/"+usages[i].Version+"/"+usages[i].Resource < usages[j].Group+"/"+usages[j].Version+"/"+usages[j].Resource
+	})
+	return usages, nil
+}
+
+// loadSubnetCapacity fills in the free IPs of each subnet and returns the subnets that could not be read
+func loadSubnetCapacity(ctx context.Context, client *azureclient.AzureClient, subnets map[string]*subnetCapacity) map[string]error {
+	errs := make(map[string]error)
+	for key, capacity := range subnets {
+		resource, err := client.GetResourceByID(ctx, capacity.ID)
+		if err != nil {
+			errs[key] = err
+			continue
+		}
+		subnet, ok := resource.(*armnetwork.Subnet)
+		if !ok || subnet.Properties == nil {
+			errs[key] = fmt.Errorf("unexpected resource type %T", resource)
+			continue
+		}
+
+		prefix := stringValue(subnet.Properties.AddressPrefix)
+		if prefix == "" && len(subnet.Properties.AddressPrefixes) > 0 {
+			prefix = stringValue(subnet.Properties.AddressPrefixes[0])
+		}
+		available, err := availableIPs(prefix, len(subnet.Properties.IPConfigurations))
+		if err != nil {
+			errs[key] = err
+			continue
+		}
+		capacity.Available = available
+	}
+	return errs
+}
+
+// vmSKU holds the fields of az vm list-skus output used to size quota requirements
+type vmSKU struct {
+	Name         string `json:"name"`
+	Family       string `json:"family"`
+	Capabilities []struct {
+		Name  string `json:"name"`
+		Value string `json:"value"`
+	} `json:"capabilities"`
+}
+
+// vmUsage holds the fields of az vm list-usage output
+type vmUsage struct {
+	CurrentValue int `json:"currentValue"`
+	Limit        int `json:"limit"`
+	Name         struct {
+		Value          string `json:"value"`
+		LocalizedValue string `json:"localizedValue"`
+	} `json:"name"`
+}
+
+// surgeQuotaUsage returns the regional vCPU quotas used by surge nodes, with the vCPUs each needs
+func surgeQuotaUsage(cluster *armcontainerservice.ManagedCluster, subscriptionID string, cfg *config.ConfigData) ([]quotaUsage, error) {
+	location := stringValue(cluster.Location)
+	if location == "" {
+		return nil, fmt.Errorf("cluster location is unknown")
+	}
+
+	// Sum the surge vCPUs per VM family and in total
+	required := make(map[string]int)
+	total := 0
+	skus := make(map[string]*vmSKU)
+	for _, pool := range agentPools(cluster) {
+		size := stringValue(pool.VMSize)
+		if size == "" {
+			continue
+		}
+
+		sku, exists := skus[strings.ToLower(size)]
+		if !exists {
+			var err error
+			sku, err = getVMSKU(location, size, subscriptionID, cfg)
+			if err != nil {
+				return nil, err
+			}
+			skus[strings.ToLower(size)] = sku
+		}
+
+		vcpus := 0
+		for _, capability := range sku.Capabilities {
+			if capability.Name == "vCPUs" {
+				vcpus, _ = strconv.Atoi(capability.Value)
+			}
+		}
+		required[strings.ToLower(sku.Family)] += surgeNodes(pool) * vcpus
+		total += surgeNodes(pool) * vcpus
+	}
+	required["cores"] = total
+
+	var usage []vmUsage
+	err := runAzJSON([]string{
+		"vm", "list-usage",
+		"--location", location,
+		"--subscription", subscriptionID,
+		"--output", "json",
+	}, cfg, &usage)
+	if err != nil {
+		return nil, err
+	}
+
+	var quotas []quotaUsage
+	for _, u := range usage {
+		if vcpus, exists := required[strings.ToLower(u.Name.Value)]; exists {
+			name := u.Name.LocalizedValue
+			if name == "" {
+				name = u.Name.Value
+			}
+			quotas = append(quotas, quotaUsage{Name: name, Current: u.CurrentValue, Limit: u.Limit, Required: vcpus})
+		}
+	}
+	sort.Slice(quotas, func(i, j int) bool {
+		return quotas[i].Name < quotas[j].Name
+	})
+	return quotas, nil
+}
+
+// getVMSKU looks up the family and capabilities of a VM size in a region
+func getVMSKU(location, size, subscriptionID string, cfg *config.ConfigData) (*vmSKU, error) {
+	var skus []vmSKU
+	err := runAzJSON([]string{
+		"vm", "list-skus",
+		"--location", location,
+		"--size", size,
+		"--resource-type", "virtualMachines",
+		"--subscription", subscriptionID,
+		"--output", "json",
+	}, cfg, &skus)
+	if err != nil {
+		return nil, err
+	}
+
+	// --size matches partial names, so pick the exact size
+	for i := range skus {
+		if strings.EqualFold(skus[i].Name, size) {
+			return &skus[i], nil
+		}
+	}
+	return nil, fmt.Errorf("VM size %s is not available in %s", size, location)
+}
@@ -0,0 +1,188 @@
+package upgrade
+
+import (
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/Azure/aks-mcp/internal/azureclient/fakearm"
+	"github.com/Azure/aks-mcp/internal/azureclient/fakearm/armtest"
+	"github.com/Azure/aks-mcp/internal/command"
+	"github.com/Azure/aks-mcp/internal/config"
+)
+
+// stubKubectl returns fixed output for kubectl commands that target the fake cluster
+type stubKubectl struct {
+	outputs map[string]string
+}
+
+func (s *stubKubectl) Execute(params map[string]interface{}, _ *config.ConfigData) (string, error) {
+	if params["subscription_id"] != fakearm.SubscriptionID || params["resource_group"] != fakearm.ResourceGroup || params["cluster_name"] != fakearm.ClusterName {
+		return "", fmt.Errorf("expected kubectl to target %s, got %v", fakearm.ClusterResourceID, params)
+	}
+	cmd, _ := params["command"].(string)
+	if output, ok := s.outputs[cmd]; ok {
+		return output, nil
+	}
+	return "error: the server doesn't have a resource type", nil
+}
+
+const testPDBs = `{
+  "items": [
+    {
+      "metadata": {"name": "payments", "namespace": "prod"},
+      "status": {"disruptionsAllowed": 0, "currentHealthy": 2, "desiredHealthy": 2, "expectedPods": 2}
+    },
+    {
+      "metadata": {"name": "web", "namespace": "prod"},
+      "status": {"disruptionsAllowed": 1, "currentHealthy": 3, "desiredHealthy": 2, "expectedPods": 3}
+    }
+  ]
+}`
+
+const testMetrics = `# HELP apiserver_requested_deprecated_apis [STABLE] Gauge of deprecated APIs that have been requested
+# TYPE apiserver_requested_deprecated_apis gauge
+apiserver_requested_deprecated_apis{group="flowcontrol.apiserver.k8s.io",removed_release="1.29",resource="flowschemas",subresource="",version="v1beta2"} 1
+apiserver_requested_deprecated_apis{group="flowcontrol.apiserver.k8s.io",removed_release="1.29",resource="flowschemas",subresource="status",version="v1beta2"} 1
+apiserver_requested_deprecated_apis{group="flowcontrol.apiserver.k8s.io",removed_release="1.32",resource="prioritylevelconfigurations",subresource="",version="v1beta3"} 1
+# HELP apiserver_request_total Counter of apiserver requests
+# TYPE apiserver_request_total counter
+apiserver_request_total{code="200",verb="GET"} 42
+`
+
+func TestGetUpgradePlanHandler(t *testing.T) {
+	stop, err := command.UseCassette(filepath.Join("testdata", "cassettes", "upgrade_plan.json"))
+	if err != nil {
+		t.Fatalf("Failed to load cassette: %v", err)
+	}
+	defer func() {
+		if err := stop(); err != nil {
+			t.Errorf("Failed to save cassette: %v", err)
+		}
+	}()
+
+	_, client, cfg := armtest.NewClient(t, filepath.Join("testdata", "fixtures.json"))
+
+	kubectl := &stubKubectl{outputs: map[string]string{
+		"get poddisruptionbudgets --all-namespaces --output json": testPDBs,
+		"get --raw /metrics": testMetrics,
+	}}
+
+	handler := GetUpgradePlanHandler(client, kubectl, cfg)
+	output, err := handler.Handle(map[string]interface{}{
+		"subscription_id": fakearm.SubscriptionID,
+		"resource_group":  fakearm.ResourceGroup,
+		"cluster_name":    fakearm.ClusterName,
+	}, cfg)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	var plan Plan
+	if err := json.Unmarshal([]byte(output), &plan); err != nil {
+		t.Fatalf("Expected a plan, got %q: %v", output, err)
+	}
+
+	// The latest non-preview upgrade is chosen by default
+	if plan.CurrentVersion != "1.30.4" || plan.TargetVersion != "1.31.1" {
+		t.Errorf("Expected upgrade from 1.30.4 to 1.31.1, got %s to %s", plan.CurrentVersion, plan.TargetVersion)
+	}
+
+	statuses := make(map[string]string)
+	for _, check := range plan.Checks {
+		statuses[check.Name] = check.Status
+	}
+	want := map[string]string{
+		"versions":               StatusPass,
+		"version_skew":           StatusWarn,
+		"pod_disruption_budgets": StatusFail,
+		"removed_apis":           StatusFail,
+		"surge_subnet_capacity":  StatusFail,
+		"surge_quota":            StatusFail,
+	}
+	for name, status := range want {
+		if statuses[name] != status {
+			t.Errorf("Expected check %s to be %s, got %s", name, status, statuses[name])
+		}
+	}
+
+	if plan.Ready || len(plan.BlockingIssues) != 4 {
+		t.Errorf("Expected 4 blocking issues, got %+v", plan.BlockingIssues)
+	}
+	var messages []string
+	for _, issue := range plan.BlockingIssues {
+		messages = append(messages, issue.Message)
+	}
+	joined := strings.Join(messages, "\n")
+	for _, fragment := range []string{"prod/payments", "flowcontrol.apiserver.k8s.io/v1beta2 flowschemas", "need 155 IPs", "only 4 of 64 are free"} {
+		if !strings.Contains(joined, fragment) {
+			t.Errorf("Expected a blocking issue mentioning %q, got:\n%s", fragment, joined)
+		}
+	}
+
+	// Blocking issues come first, then the control plane, then system before user node pools
+	var operations []string
+	for _, step := range plan.Steps {
+		if step.Operation != nil {
+			operations = append(operations, step.Operation["operation"].(string))
+		}
+	}
+	if got := strings.Join(operations, ","); got != "upgrade,operation_status,nodepool-upgrade,operation_status,nodepool-upgrade,operation_status" {
+		t.Errorf("Unexpected step order: %s", got)
+	}
+	if plan.Steps[0].Operation != nil || plan.Steps[3].Operation["nodepool_name"] != "system" || plan.Steps[3].Operation["subscription"] != fakearm.SubscriptionID {
+		t.Errorf("Unexpected steps: %+v", plan.Steps)
+	}
+}
+
+func TestGetUpgradePlanHandler_InvalidTargetVersion(t *testing.T) {
+	handler := GetUpgradePlanHandler(nil, &stubKubectl{}, config.NewConfig())
+	_, err := handler.Handle(map[string]interface{}{
+		"subscription_id": fakearm.SubscriptionID,
+		"resource_group":  fakearm.ResourceGroup,
+		"cluster_name":    fakearm.ClusterName,
+		"target_version":  "1.31; rm -rf /",
+	}, config.NewConfig())
+	if err == nil || !strings.Contains(err.Error(), "invalid target_version") {
+		t.Errorf("Expected invalid target_version error, got %v", err)
+	}
+}
+
+func TestGetUpgradePlanHandler_InvalidParams(t *testing.T) {
+	tests := []struct {
+		name    string
+		params  map[string]interface{}
+		wantErr string
+	}{
+		{
+			name:    "flags in cluster name",
+			params:  map[string]interface{}{"cluster_name": "x --subscription other"},
+			wantErr: "invalid cluster_name",
+		},
+		{
+			name:    "tenant_id",
+			params:  map[string]interface{}{"tenant_id": "11111111-1111-1111-1111-111111111111"},
+			wantErr: "tenant_id is not supported",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			params := map[string]interface{}{
+				"subscription_id": fakearm.SubscriptionID,
+				"resource_group":  fakearm.ResourceGroup,
+				"cluster_name":    fakearm.ClusterName,
+			}
+			for key, value := range tt.params {
+				params[key] = value
+			}
+
+			_, err := GetUpgradePlanHandler(nil, &stubKubectl{}, config.NewConfig()).Handle(params, config.NewConfig())
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("Expected error containing %q, got %v", tt.wantErr, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,618 @@
+package upgrade
+
+import (
+	"fmt"
+	"math"
+	"net"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/containerservice/armcontainerservice/v2"
+)
+
+// Check statuses
+const (
+	StatusPass    = "pass"
+	StatusWarn    = "warn"
+	StatusFail    = "fail"
+	StatusSkipped = "skipped"
+)
+
+// maxKubeletSkew is how many minor versions a node pool may lag behind the control plane
+const maxKubeletSkew = 3
+
+// defaultMaxSurge is the surge used by AKS for node pools without an explicit max surge
+const defaultMaxSurge = "1"
+
+// Plan is the result of the aks_upgrade_plan tool
+type Plan struct {
+	SubscriptionID    string   `json:"subscription_id"`
+	ClusterName       string   `json:"cluster_name"`
+	ResourceGroup     string   `json:"resource_group"`
+	CurrentVersion    string   `json:"current_version"`
+	TargetVersion     string   `json:"target_version,omitempty"`
+	AvailableUpgrades []string `json:"available_upgrades,omitempty"`
+	// Ready is true when no check found a blocking issue
+	Ready          bool    `json:"ready"`
+	BlockingIssues []Issue `json:"blocking_issues"`
+	Warnings       []Issue `json:"warnings"`
+	Checks         []Check `json:"checks"`
+	Steps          []Step  `json:"steps"`
+	Note           string  `json:"note"`
+}
+
+// Check is the outcome of a single readiness check
+type Check struct {
+	Name    string      `json:"name"`
+	Status  string      `json:"status"`
+	Message string      `json:"message"`
+	Details interface{} `json:"details,omitempty"`
+}
+
+// Issue is a problem found by a check
+type Issue struct {
+	Check       string `json:"check"`
+	Message     string `json:"message"`
+	Remediation string `json:"remediation,omitempty"`
+}
+
+// Step is an ordered action of the upgrade plan. Operation holds the az_aks_operations
+// parameters that perform the step, when it maps to one.
+type Step struct {
+	Order       int                    `json:"order"`
+	Description string                 `json:"description"`
+	Operation   map[string]interface{} `json:"operation,omitempty"`
+}
+
+// upgradeProfile is the output of az aks get-upgrades
+type upgradeProfile struct {
+	ControlPlaneProfile struct {
+		KubernetesVersion string `json:"kubernetesVersion"`
+		Upgrades          []struct {
+			KubernetesVersion string `json:"kubernetesVersion"`
+			IsPreview         *bool  `json:"isPreview"`
+		} `json:"upgrades"`
+	} `json:"controlPlaneProfile"`
+}
+
+// podDisruptionBudget holds the fields of a PodDisruptionBudget used to predict blocked drains
+type podDisruptionBudget struct {
+	Metadata struct {
+		Name      string `json:"name"`
+		Namespace string `json:"namespace"`
+	} `json:"metadata"`
+	Status struct {
+		DisruptionsAllowed int `json:"disruptionsAllowed"`
+		CurrentHealthy     int `json:"currentHealthy"`
+		DesiredHealthy     int `json:"desiredHealthy"`
+		ExpectedPods       int `json:"expectedPods"`
+	} `json:"status"`
+}
+
+// deprecatedAPIUsage is a deprecated API requested from the API server, as reported by
+// the apiserver_requested_deprecated_apis metric
+type deprecatedAPIUsage struct {
+	Group          string `json:"group"`
+	Version        string `json:"version"`
+	Resource       string `json:"resource"`
+	RemovedRelease string `json:"removed_release,omitempty"`
+}
+
+// subnetCapacity is the IP space of a subnet used by node pools
+type subnetCapacity struct {
+	ID        string `json:"id"`
+	Available int    `json:"available_ips"`
+	Required  int    `json:"required_ips"`
+	// Pools are the node pools whose surge nodes take IPs from the subnet
+	Pools []string `json:"node_pools"`
+}
+
+// quotaUsage is the regional vCPU usage and limit of a quota, e.g. a VM family
+type quotaUsage struct {
+	Name     string `json:"name"`
+	Current  int    `json:"current"`
+	Limit    int    `json:"limit"`
+	Required int    `json:"required"`
+}
+
+// newPlan creates an empty plan for a cluster
+func newPlan(subscriptionID, resourceGroup, clusterName string) *Plan {
+	return &Plan{
+		SubscriptionID: subscriptionID,
+		ClusterName:    clusterName,
+		ResourceGroup:  resourceGroup,
+		BlockingIssues: []Issue{},
+		Warnings:       []Issue{},
+		Checks:         []Check{},
+		Steps:          []Step{},
+		Note:           "This plan is read-only; no upgrade was started. Run the steps with az_aks_operations once the blocking issues are resolved.",
+	}
+}
+
+// addCheck records a check result
+func (p *Plan) addCheck(name, status, message string, details interface{}) {
+	p.Checks = append(p.Checks, Check{Name: name, Status: status, Message: message, Details: details})
+}
+
+// block records a blocking issue
+func (p *Plan) block(check, message, remediation string) {
+	p.BlockingIssues = append(p.BlockingIssues, Issue{Check: check, Message: message, Remediation: remediation})
+}
+
+// warn records a non-blocking issue
+func (p *Plan) warn(check, message, remediation string) {
+	p.Warnings = append(p.Warnings, Issue{Check: check, Message: message, Remediation: remediation})
+}
+
+// checkVersions picks the target version and validates it against the available upgrades
+func (p *Plan) checkVersions(cluster *armcontainerservice.ManagedCluster, requested string, profile *upgradeProfile, profileErr error) {
+	const check = "versions"
+
+	p.CurrentVersion = clusterVersion(cluster)
+	p.TargetVersion = requested
+
+	if profileErr != nil {
+		p.addCheck(check, StatusSkipped, fmt.Sprintf("Available upgrades could not be listed: %v", profileErr), nil)
+		if requested == "" {
+			p.block(check, "No target version was given and the available upgrades could not be listed",
+				"Pass target_version, or check access to az aks get-upgrades")
+		}
+		return
+	}
+
+	var latest string
+	for _, u := range profile.ControlPlaneProfile.Upgrades {
+		p.AvailableUpgrades = append(p.AvailableUpgrades, u.KubernetesVersion)
+		if (u.IsPreview == nil || !*u.IsPreview) && compareVersions(u.KubernetesVersion, latest) > 0 {
+			latest = u.KubernetesVersion
+		}
+	}
+	sort.Slice(p.AvailableUpgrades, func(i, j int) bool {
+		return compareVersions(p.AvailableUpgrades[i], p.AvailableUpgrades[j]) < 0
+	})
+
+	if requested == "" {
+		if latest == "" {
+			p.TargetVersion = p.CurrentVersion
+			p.addCheck(check, StatusPass, fmt.Sprintf("No upgrades are available; the control plane is on %s", p.CurrentVersion), nil)
+			return
+		}
+		p.TargetVersion = latest
+	}
+
+	switch {
+	case p.TargetVersion == p.CurrentVersion:
+		p.addCheck(check, StatusPass, fmt.Sprintf("The control plane is already on %s", p.CurrentVersion), nil)
+	case slices.Contains(p.AvailableUpgrades, p.TargetVersion):
+		p.addCheck(check, StatusPass, fmt.Sprintf("%s is an available upgrade from %s", p.TargetVersion, p.CurrentVersion), nil)
+	default:
+		p.addCheck(check, StatusFail, fmt.Sprintf("%s is not an available upgrade from %s", p.TargetVersion, p.CurrentVersion), p.AvailableUpgrades)
+		remediation := "Choose one of the available upgrades"
+		if minorGap(p.CurrentVersion, p.TargetVersion) > 1 {
+			remediation = "AKS upgrades one minor version at a time; upgrade through the intermediate minor versions first"
+		}
+		p.block(check, fmt.Sprintf("Target version %s is not an available upgrade from %s", p.TargetVersion, p.CurrentVersion), remediation)
+	}
+}
+
+// checkSkew verifies every node pool stays within the supported skew of the target control plane version
+func (p *Plan) checkSkew(cluster *armcontainerservice.ManagedCluster) {
+	const check = "version_skew"
+
+	if p.TargetVersion == "" {
+		p.addCheck(check, StatusSkipped, "No target version", nil)
+		return
+	}
+
+	skew := make(map[string]string)
+	status := StatusPass
+	for _, pool := range agentPools(cluster) {
+		name := stringValue(pool.Name)
+		version := poolVersion(pool)
+		skew[name] = version
+
+		gap := minorGap(version, p.TargetVersion)
+		switch {
+		case gap > maxKubeletSkew:
+			status = StatusFail
+			p.block(check, fmt.Sprintf("Node pool %s on %s would be %d minor versions behind a %s control plane; at most %d are supported",
+				name, version, gap, p.TargetVersion, maxKubeletSkew),
+				fmt.Sprintf("Upgrade node pool %s to %s before upgrading the control plane", name, p.CurrentVersion))
+		case gap < 0:
+			status = StatusFail
+			p.block(check, fmt.Sprintf("Node pool %s on %s is newer than the target version %s", name, version, p.TargetVersion),
+				"Choose a target version at or above every node pool version")
+		case minorGap(version, p.CurrentVersion) > 0:
+			if status == StatusPass {
+				status = StatusWarn
+			}
+			p.warn(check, fmt.Sprintf("Node pool %s on %s is behind the control plane on %s", name, version, p.CurrentVersion),
+				"The plan upgrades it together with the other node pools")
+		}
+	}
+
+	p.addCheck(check, status, fmt.Sprintf("Compared %d node pool versions with target %s", len(skew), p.TargetVersion), skew)
+}
+
+// kubernetesCheckRemediation is the remediation for Kubernetes checks that could not run against the cluster
+const kubernetesCheckRemediation = "Make sure aks-mcp can fetch user credentials for the cluster and reach its API server, then plan again"
+
+// checkPodDisruptionBudgets finds PodDisruptionBudgets that currently allow no disruptions and would block node drains
+func (p *Plan) checkPodDisruptionBudgets(pdbs []podDisruptionBudget, pdbErr error) {
+	const check = "pod_disruption_budgets"
+
+	if pdbErr != nil {
+		p.addCheck(check, StatusFail, fmt.Sprintf("PodDisruptionBudgets could not be listed: %v", pdbErr), nil)
+		p.block(check, "PodDisruptionBudgets of the cluster could not be checked", kubernetesCheckRemediation)
+		return
+	}
+
+	var blocking []string
+	for _, pdb := range pdbs {
+		if pdb.Status.ExpectedPods > 0 && pdb.Status.DisruptionsAllowed == 0 {
+			name := pdb.Metadata.Namespace + "/" + pdb.Metadata.Name
+			blocking = append(blocking, name)
+			p.block(check, fmt.Sprintf("PodDisruptionBudget %s allows no disruptions (%d of %d pods healthy, %d required), so draining its nodes will stall",
+				name, pdb.Status.CurrentHealthy, pdb.Status.ExpectedPods, pdb.Status.DesiredHealthy),
+				"Scale up the workload or relax minAvailable/maxUnavailable for the duration of the upgrade")
+		}
+	}
+
+	if len(blocking) > 0 {
+		p.addCheck(check, StatusFail, fmt.Sprintf("%d of %d PodDisruptionBudgets would block node drains", len(blocking), len(pdbs)), blocking)
+		return
+	}
+	p.addCheck(check, StatusPass, fmt.Sprintf("None of the %d PodDisruptionBudgets would block node drains", len(pdbs)), nil)
+}
+
+// checkRemovedAPIs finds deprecated APIs still requested from the cluster that are removed at or before the target version
+func (p *Plan) checkRemovedAPIs(usages []deprecatedAPIUsage, usageErr error) {
+	const check = "removed_apis"
+
+	if usageErr != nil {
+		p.addCheck(check, StatusFail, fmt.Sprintf("API server metrics could not be read: %v", usageErr), nil)
+		p.block(check, "Removed API usage in the cluster could not be checked", kubernetesCheckRemediation)
+		return
+	}
+
+	var removed []deprecatedAPIUsage
+	for _, usage := range usages {
+		api := fmt.Sprintf("%s/%s %s", usage.Group, usage.Version, usage.Resource)
+		if usage.Group == "" {
+			api = fmt.Sprintf("%s %s", usage.Version, usage.Resource)
+		}
+
+		if usage.RemovedRelease != "" && p.TargetVersion != "" && minorGap(usage.RemovedRelease, p.TargetVersion) >= 0 {
+			removed = append(removed, usage)
+			p.block(check, fmt.Sprintf("%s is still requested and is removed in Kubernetes %s", api, usage.RemovedRelease),
+				"Migrate manifests, Helm charts and clients to the replacement API version before upgrading")
+			continue
+		}
+		p.warn(check, fmt.Sprintf("%s is deprecated and still requested", api), "Plan a migration to the replacement API version")
+	}
+
+	message := "No API removed by the target version has been requested since the API server started"
+	status := StatusPass
+	if len(removed) > 0 {
+		message = fmt.Sprintf("%d removed APIs are still requested", len(removed))
+		status = StatusFail
+	}
+	p.addCheck(check, status, message, usages)
+}
+
+// surgeNodes returns how many extra nodes a node pool adds during an upgrade
+func surgeNodes(pool *armcontainerservice.ManagedClusterAgentPoolProfile) int {
+	maxSurge := defaultMaxSurge
+	if pool.UpgradeSettings != nil && pool.UpgradeSettings.MaxSurge != nil && *pool.UpgradeSettings.MaxSurge != "" {
+		maxSurge = *pool.UpgradeSettings.MaxSurge
+	}
+
+	count := 0
+	if pool.Count != nil {
+		count = int(*pool.Count)
+	}
+
+	var surge int
+	if percent, isPercent := strings.CutSuffix(maxSurge, "%"); isPercent {
+		pct, err := strconv.Atoi(percent)
+		if err != nil {
+			return 1
+		}
+		surge = int(math.Ceil(float64(count) * float64(pct) / 100))
+	} else {
+		n, err := strconv.Atoi(maxSurge)
+		if err != nil {
+			return 1
+		}
+		surge = n
+	}
+
+	if surge < 1 {
+		surge = 1
+	}
+	return surge
+}
+
+// nodeIPs returns the node subnet IPs and pod subnet IPs that each node of a pool takes
+func nodeIPs(cluster *armcontainerservice.ManagedCluster, pool *armcontainerservice.ManagedClusterAgentPoolProfile) (nodeSubnet, podSubnet int) {
+	maxPods := 30
+	if pool.MaxPods != nil {
+		maxPods = int(*pool.MaxPods)
+	}
+
+	if pool.PodSubnetID != nil && *pool.PodSubnetID != "" {
+		return 1, maxPods
+	}
+
+	// Azure CNI without overlay assigns pod IPs from the node subnet; overlay clusters have a pod CIDR
+	if props := cluster.Properties; props != nil && props.NetworkProfile != nil {
+		network := props.NetworkProfile
+		if network.NetworkPlugin != nil && *network.NetworkPlugin == armcontainerservice.NetworkPluginAzure &&
+			stringValue(network.PodCidr) == "" {
+			return 1 + maxPods, 0
+		}
+	}
+	return 1, 0
+}
+
+// requiredSubnetIPs sums the IPs needed by surge nodes per subnet ID
+func requiredSubnetIPs(cluster *armcontainerservice.ManagedCluster) (map[string]*subnetCapacity, []string) {
+	subnets := make(map[string]*subnetCapacity)
+	var managed []string
+
+	add := func(subnetID, pool string, ips int) {
+		key := strings.ToLower(subnetID)
+		if subnets[key] == nil {
+			subnets[key] = &subnetCapacity{ID: subnetID}
+		}
+		subnets[key].Required += ips
+		subnets[key].Pools = append(subnets[key].Pools, pool)
+	}
+
+	for _, pool := range agentPools(cluster) {
+		name := stringValue(pool.Name)
+		surge := surgeNodes(pool)
+		nodeSubnet, podSubnet := nodeIPs(cluster, pool)
+
+		if pool.VnetSubnetID == nil || *pool.VnetSubnetID == "" {
+			managed = append(managed, name)
+			continue
+		}
+		add(*pool.VnetSubnetID, name, surge*nodeSubnet)
+		if podSubnet > 0 {
+			add(*pool.PodSubnetID, name, surge*podSubnet)
+		}
+	}
+	return subnets, managed
+}
+
+// checkSubnetCapacity compares the IPs needed by surge nodes with the free IPs of each subnet
+func (p *Plan) checkSubnetCapacity(subnets map[string]*subnetCapacity, managedPools []string, subnetErrs map[string]error) {
+	const check = "surge_subnet_capacity"
+
+	ids := make([]string, 0, len(subnets))
+	for id := range subnets {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+
+	status := StatusPass
+	var details []*subnetCapacity
+	for _, id := range ids {
+		subnet := subnets[id]
+		if err := subnetErrs[id]; err != nil {
+			if status == StatusPass {
+				status = StatusWarn
+			}
+			p.warn(check, fmt.Sprintf("Subnet %s could not be read: %v", subnet.ID, err), "Check the free IPs of the subnet manually")
+			continue
+		}
+		details = append(details, subnet)
+		if subnet.Required > subnet.Available {
+			status = StatusFail
+			p.block(check, fmt.Sprintf("Surge nodes of node pools %s need %d IPs in subnet %s, but only %d are free",
+				strings.Join(subnet.Pools, ", "), subnet.Required, subnet.ID, subnet.Available),
+				"Lower max_surge on the node pools or free up or add address space to the subnet")
+		}
+	}
+
+	message := fmt.Sprintf("Checked surge IP requirements against %d subnets", len(details))
+	if len(managedPools) > 0 {
+		message += fmt.Sprintf("; node pools %s use the AKS managed virtual network and were not checked", strings.Join(managedPools, ", "))
+	}
+	p.addCheck(check, status, message, details)
+}
+
+// availableIPs returns the free IPs of a subnet from its address prefix and used IP configurations.
+// Azure reserves five addresses in every subnet.
+func availableIPs(addressPrefix string, usedIPs int) (int, error) {
+	_, network, err := net.ParseCIDR(addressPrefix)
+	if err != nil {
+		return 0, fmt.Errorf("invalid address prefix %s: %v", addressPrefix, err)
+	}
+	ones, bits := network.Mask.Size()
+	if bits-ones > 30 {
+		return math.MaxInt32, nil
+	}
+
+	available := (1 << (bits - ones)) - 5 - usedIPs
+	if available < 0 {
+		available = 0
+	}
+	return available, nil
+}
+
+// checkQuota compares the vCPUs needed by surge nodes with the regional quotas
+func (p *Plan) checkQuota(quotas []quotaUsage, quotaErr error) {
+	const check = "surge_quota"
+
+	if quotaErr != nil {
+		p.addCheck(check, StatusSkipped, fmt.Sprintf("Regional quota could not be read: %v", quotaErr), nil)
+		p.warn(check, "vCPU quota for surge nodes was not checked", "Check az vm list-usage for the cluster region")
+		return
+	}
+
+	status := StatusPass
+	for _, quota := range quotas {
+		if quota.Current+quota.Required > quota.Limit {
+			status = StatusFail
+			p.block(check, fmt.Sprintf("Surge nodes need %d vCPUs of quota %s, but only %d of %d are free",
+				quota.Required, quota.Name, quota.Limit-quota.Current, quota.Limit),
+				"Request a quota increase or lower max_surge on the node pools")
+		}
+	}
+	p.addCheck(check, status, fmt.Sprintf("Checked surge vCPUs against %d regional quotas", len(quotas)), quotas)
+}
+
+// buildSteps orders the upgrade: resolve blocking issues, upgrade the control plane, then
+// system node pools before user node pools, checking progress after each step
+func (p *Plan) buildSteps(cluster *armcontainerservice.ManagedCluster) {
+	addStep := func(description string, operation map[string]interface{}) {
+		p.Steps = append(p.Steps, Step{Order: len(p.Steps) + 1, Description: description, Operation: operation})
+	}
+
+	p.Ready = len(p.BlockingIssues) == 0
+	if !p.Ready {
+		addStep(fmt.Sprintf("Resolve the %d blocking issues and run aks_upgrade_plan again", len(p.BlockingIssues)), nil)
+	}
+	if p.TargetVersion == "" {
+		return
+	}
+
+	if p.TargetVersion != p.CurrentVersion {
+		addStep(fmt.Sprintf("Upgrade the control plane from %s to %s", p.CurrentVersion, p.TargetVersion), map[string]interface{}{
+			"operation":          "upgrade",
+			"cluster_name":       p.ClusterName,
+			"resource_group":     p.ResourceGroup,
+			"subscription":       p.SubscriptionID,
+			"kubernetes_version": p.TargetVersion,
+			"control_plane_only": true,
+			"yes":                true,
+			"no_wait":            true,
+		})
+		addStep("Wait until the control plane upgrade succeeds", map[string]interface{}{
+			"operation":      "operation_status",
+			"cluster_name":   p.ClusterName,
+			"resource_group": p.ResourceGroup,
+			"subscription":   p.SubscriptionID,
+		})
+	}
+
+	pools := agentPools(cluster)
+	sort.SliceStable(pools, func(i, j int) bool {
+		iSystem := pools[i].Mode != nil && *pools[i].Mode == armcontainerservice.AgentPoolModeSystem
+		jSystem := pools[j].Mode != nil && *pools[j].Mode == armcontainerservice.AgentPoolModeSystem
+		if iSystem != jSystem {
+			return iSystem
+		}
+		return stringValue(pools[i].Name) < stringValue(pools[j].Name)
+	})
+
+	for _, pool := range pools {
+		name := stringValue(pool.Name)
+		version := poolVersion(pool)
+		if compareVersions(version, p.TargetVersion) >= 0 {
+			continue
+		}
+		addStep(fmt.Sprintf("Upgrade node pool %s from %s to %s, surging %d nodes at a time", name, version, p.TargetVersion, surgeNodes(pool)),
+			map[string]interface{}{
+				"operation":          "nodepool-upgrade",
+				"cluster_name":       p.ClusterName,
+				"resource_group":     p.ResourceGroup,
+				"subscription":       p.SubscriptionID,
+				"nodepool_name":      name,
+				"kubernetes_version": p.TargetVersion,
+				"yes":                true,
+				"no_wait":            true,
+			})
+		addStep(fmt.Sprintf("Wait until the node pool %s upgrade succeeds", name), map[string]interface{}{
+			"operation":      "operation_status",
+			"cluster_name":   p.ClusterName,
+			"resource_group": p.ResourceGroup,
+			"subscription":   p.SubscriptionID,
+			"nodepool_name":  name,
+		})
+	}
+}
+
+// agentPools returns the agent pool profiles of a cluster
+func agentPools(cluster *armcontainerservice.ManagedCluster) []*armcontainerservice.ManagedClusterAgentPoolProfile {
+	if cluster == nil || cluster.Properties == nil {
+		return nil
+	}
+	var pools []*armcontainerservice.ManagedClusterAgentPoolProfile
+	for _, pool := range cluster.Properties.AgentPoolProfiles {
+		if pool != nil {
+			pools = append(pools, pool)
+		}
+	}
+	return pools
+}
+
+// clusterVersion returns the current control plane version of a cluster
+func clusterVersion(cluster *armcontainerservice.ManagedCluster) string {
+	if cluster == nil || cluster.Properties == nil {
+		return ""
+	}
+	if v := stringValue(cluster.Properties.CurrentKubernetesVersion); v != "" {
+		return v
+	}
+	return stringValue(cluster.Properties.KubernetesVersion)
+}
+
+// poolVersion returns the current version of a node pool
+func poolVersion(pool *armcontainerservice.ManagedClusterAgentPoolProfile) string {
+	if v := stringValue(pool.CurrentOrchestratorVersion); v != "" {
+		return v
+	}
+	return stringValue(pool.OrchestratorVersion)
+}
+
+// parseVersion splits a Kubernetes version such as 1.30.4 or v1.30 into numbers
+func parseVersion(version string) []int {
+	var parts []int
+	for _, part := range strings.Split(strings.TrimPrefix(version, "v"), ".") {
+		n, err := strconv.Atoi(part)
+		if err != nil {
+			break
+		}
+		parts = append(parts, n)
+	}
+	for len(parts) < 3 {
+		parts = append(parts, 0)
+	}
+	return parts
+}
+
+// compareVersions compares two Kubernetes versions; an empty version sorts first
+func compareVersions(a, b string) int {
+	if a == "" || b == "" {
+		return strings.Compare(a, b)
+	}
+	pa, pb := parseVersion(a), parseVersion(b)
+	for i := 0; i < 3; i++ {
+		if pa[i] != pb[i] {
+			if pa[i] < pb[i] {
+				return -1
+			}
+			return 1
+		}
+	}
+	return 0
+}
+
+// minorGap returns how many minor versions to is ahead of from
+func minorGap(from, to string) int {
+	pf, pt := parseVersion(from), parseVersion(to)
+	return (pt[0]-pf[0])*100 + pt[1] - pf[1]
+}
+
+// stringValue dereferences an optional string from the Azure SDK
+func stringValue(value *string) string {
+	if value == nil {
+		return ""
+	}
+	return *value
+}
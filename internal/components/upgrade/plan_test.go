@@ -0,0 +1,120 @@
+package upgrade
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/to"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/containerservice/armcontainerservice/v2"
+)
+
+func TestSurgeNodes(t *testing.T) {
+	tests := []struct {
+		count    int32
+		maxSurge string
+		want     int
+	}{
+		{count: 3, maxSurge: "", want: 1},
+		{count: 3, maxSurge: "2", want: 2},
+		{count: 10, maxSurge: "33%", want: 4},
+		{count: 0, maxSurge: "50%", want: 1},
+	}
+
+	for _, tt := range tests {
+		pool := &armcontainerservice.ManagedClusterAgentPoolProfile{Count: to.Ptr(tt.count)}
+		if tt.maxSurge != "" {
+			pool.UpgradeSettings = &armcontainerservice.AgentPoolUpgradeSettings{MaxSurge: to.Ptr(tt.maxSurge)}
+		}
+		if got := surgeNodes(pool); got != tt.want {
+			t.Errorf("surgeNodes(count=%d, maxSurge=%q) = %d, want %d", tt.count, tt.maxSurge, got, tt.want)
+		}
+	}
+}
+
+func TestAvailableIPs(t *testing.T) {
+	available, err := availableIPs("10.0.0.0/24", 10)
+	if err != nil || available != 241 {
+		t.Errorf("Expected 241 free IPs, got %d, %v", available, err)
+	}
+	if available, _ := availableIPs("10.0.0.0/29", 10); available != 0 {
+		t.Errorf("Expected no free IPs in an exhausted subnet, got %d", available)
+	}
+	if _, err := availableIPs("not-a-cidr", 0); err == nil {
+		t.Error("Expected error for an invalid prefix")
+	}
+}
+
+func TestCompareVersions(t *testing.T) {
+	if compareVersions("1.30.10", "1.30.9") <= 0 || compareVersions("1.29.9", "1.30.0") >= 0 || compareVersions("1.30.4", "1.30.4") != 0 {
+		t.Error("Expected versions to compare numerically")
+	}
+	if gap := minorGap("1.27.3", "1.31.1"); gap != 4 {
+		t.Errorf("Expected a minor gap of 4, got %d", gap)
+	}
+}
+
+func TestPlanChecks(t *testing.T) {
+	cluster := &armcontainerservice.ManagedCluster{
+		Properties: &armcontainerservice.ManagedClusterProperties{
+			CurrentKubernetesVersion: to.Ptr("1.30.4"),
+			AgentPoolProfiles: []*armcontainerservice.ManagedClusterAgentPoolProfile{
+				{Name: to.Ptr("old"), CurrentOrchestratorVersion: to.Ptr("1.27.9")},
+			},
+		},
+	}
+
+	// A version that skips a minor version is not an available upgrade
+	profile := &upgradeProfile{}
+	profile.ControlPlaneProfile.Upgrades = append(profile.ControlPlaneProfile.Upgrades, struct {
+		KubernetesVersion string `json:"kubernetesVersion"`
+		IsPreview         *bool  `json:"isPreview"`
+	}{KubernetesVersion: "1.31.1"})
+
+	plan := newPlan("sub", "rg", "cluster")
+	plan.checkVersions(cluster, "1.32.0", profile, nil)
+	if len(plan.BlockingIssues) != 1 || !strings.Contains(plan.BlockingIssues[0].Remediation, "one minor version at a time") {
+		t.Errorf("Expected a blocking issue for skipping a minor version, got %+v", plan.BlockingIssues)
+	}
+
+	// A node pool more than three minor versions behind the target blocks the control plane upgrade
+	plan = newPlan("sub", "rg", "cluster")
+	plan.checkVersions(cluster, "1.31.1", profile, nil)
+	plan.checkSkew(cluster)
+	if len(plan.BlockingIssues) != 1 || !strings.Contains(plan.BlockingIssues[0].Message, "4 minor versions behind") {
+		t.Errorf("Expected a version skew blocking issue, got %+v", plan.BlockingIssues)
+	}
+
+	// Kubernetes checks that could not run against the cluster fail the plan rather than passing unchecked
+	plan = newPlan("sub", "rg", "cluster")
+	plan.checkPodDisruptionBudgets(nil, errors.New("failed to get credentials"))
+	plan.checkRemovedAPIs(nil, errors.New("failed to get credentials"))
+	plan.buildSteps(cluster)
+	if plan.Ready || len(plan.BlockingIssues) != 2 || plan.Checks[0].Status != StatusFail || plan.Checks[1].Status != StatusFail {
+		t.Errorf("Expected failed Kubernetes checks to block the plan, got %+v", plan)
+	}
+}
+
+func TestBuildSteps_Subscription(t *testing.T) {
+	cluster := &armcontainerservice.ManagedCluster{
+		Properties: &armcontainerservice.ManagedClusterProperties{
+			CurrentKubernetesVersion: to.Ptr("1.30.4"),
+			AgentPoolProfiles: []*armcontainerservice.ManagedClusterAgentPoolProfile{
+				{Name: to.Ptr("system"), CurrentOrchestratorVersion: to.Ptr("1.30.4")},
+			},
+		},
+	}
+
+	// Every step targets the subscription the plan was computed for, not the current az CLI subscription
+	plan := newPlan("sub", "rg", "cluster")
+	plan.CurrentVersion, plan.TargetVersion = "1.30.4", "1.31.1"
+	plan.buildSteps(cluster)
+	if len(plan.Steps) != 4 {
+		t.Fatalf("Expected 4 steps, got %+v", plan.Steps)
+	}
+	for _, step := range plan.Steps {
+		if step.Operation["subscription"] != "sub" {
+			t.Errorf("Expected step %d to target subscription sub, got %+v", step.Order, step.Operation)
+		}
+	}
+}
@@ -0,0 +1,32 @@
+package upgrade
+
+import (
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// RegisterUpgradePlanTool registers the aks_upgrade_plan tool
+func RegisterUpgradePlanTool() mcp.Tool {
+	return mcp.NewTool(
+		"aks_upgrade_plan",
+		mcp.WithDescription("Check whether an AKS cluster is ready to be upgraded and return an ordered upgrade plan with blocking issues. "+
+			"Checks available versions, control plane and node pool version skew, PodDisruptionBudgets that would block drains, "+
+			"removed Kubernetes APIs still in use, and surge capacity against subnet IPs and regional vCPU quota. "+
+			"Kubernetes checks run against the named cluster with credentials aks-mcp fetches for it, and fail the plan when they cannot run. "+
+			"This tool does not start the upgrade."),
+		mcp.WithString("subscription_id",
+			mcp.Description("Azure Subscription ID"),
+			mcp.Required(),
+		),
+		mcp.WithString("resource_group",
+			mcp.Description("Azure Resource Group containing the AKS cluster"),
+			mcp.Required(),
+		),
+		mcp.WithString("cluster_name",
+			mcp.Description("Name of the AKS cluster"),
+			mcp.Required(),
+		),
+		mcp.WithString("target_version",
+			mcp.Description("Kubernetes version to upgrade to, e.g. 1.31.1. Defaults to the latest available non-preview upgrade."),
+		),
+	)
+}
@@ -14,6 +14,16 @@ type ConfigData struct {
 	Timeout int
 	// Cache timeout for Azure resources
 	CacheTimeout time.Duration
+	// Enable the encrypted on-disk cache for read-only Azure metadata
+	PersistentCache bool
+	// Directory for the on-disk cache (empty means the user cache directory)
+	PersistentCacheDir string
+	// Encryption key file for the on-disk cache, outside its directory (empty means the user config directory)
+	PersistentCacheKeyFile string
+	// Time to live for entries in the on-disk cache
+	PersistentCacheTimeout time.Duration
+	// Comma-separated list of Entra tenant IDs that credentials may be used with (empty allows any)
+	AllowedTenants string
 	// Security configuration
 	SecurityConfig *security.SecurityConfig
 
@@ -28,19 +38,25 @@ type ConfigData struct {
 	AdditionalTools map[string]bool
 	// Comma-separated list of allowed Kubernetes namespaces
 	AllowNamespaces string
+	// Directory for the kubeconfig files aks-mcp fetches per cluster (empty means the user cache directory)
+	KubeconfigDir string
+	// kubelogin login mode for clusters that use Entra ID authentication
+	KubeloginMode string
 }
 
 // NewConfig creates and returns a new configuration instance
 func NewConfig() *ConfigData {
 	return &ConfigData{
-		Timeout:         60,
-		CacheTimeout:    1 * time.Minute,
-		SecurityConfig:  security.NewSecurityConfig(),
-		Transport:       "stdio",
-		Port:            8000,
-		AccessLevel:     "readonly",
-		AdditionalTools: make(map[string]bool),
-		AllowNamespaces: "",
+		Timeout:                60,
+		CacheTimeout:           1 * time.Minute,
+		PersistentCacheTimeout: 30 * time.Minute,
+		SecurityConfig:         security.NewSecurityConfig(),
+		Transport:              "stdio",
+		Port:                   8000,
+		AccessLevel:            "readonly",
+		AdditionalTools:        make(map[string]bool),
+		AllowNamespaces:        "",
+		KubeloginMode:          "azurecli",
 	}
 }
 
@@ -51,6 +67,15 @@ func (cfg *ConfigData) ParseFlags() {
 	flag.StringVar(&cfg.Host, "host", "127.0.0.1", "Host to listen for the server (only used with transport sse or streamable-http)")
 	flag.IntVar(&cfg.Port, "port", 8000, "Port to listen for the server (only used with transport sse or streamable-http)")
 	flag.IntVar(&cfg.Timeout, "timeout", 600, "Timeout for command execution in seconds, default is 600s")
+	// Cache settings
+	flag.BoolVar(&cfg.PersistentCache, "persistent-cache", false, "Enable the encrypted on-disk cache for read-only Azure metadata")
+	flag.StringVar(&cfg.PersistentCacheDir, "persistent-cache-dir", "", "Directory for the on-disk cache (default is the user cache directory)")
+	flag.StringVar(&cfg.PersistentCacheKeyFile, "persistent-cache-key-file", "",
+		"Encryption key file for the on-disk cache, outside the cache directory (default is in the user config directory)")
+	flag.DurationVar(&cfg.PersistentCacheTimeout, "persistent-cache-ttl", 30*time.Minute, "Time to live for entries in the on-disk cache")
+	// Azure settings
+	flag.StringVar(&cfg.AllowedTenants, "allowed-tenants", "",
+		"Comma-separated list of Entra tenant IDs that Azure credentials may be used with (empty allows any tenant)")
 	// Security settings
 	flag.StringVar(&cfg.AccessLevel, "access-level", "readonly", "Access level (readonly, readwrite, admin)")
 
@@ -59,6 +84,10 @@ func (cfg *ConfigData) ParseFlags() {
 		"Comma-separated list of additional Kubernetes tools to support (kubectl is always enabled). Available: helm,cilium,inspektor-gadget")
 	flag.StringVar(&cfg.AllowNamespaces, "allow-namespaces", "",
 		"Comma-separated list of allowed Kubernetes namespaces (empty means all namespaces)")
+	flag.StringVar(&cfg.KubeconfigDir, "kubeconfig-dir", "",
+		"Directory for the kubeconfig files fetched for cluster-targeted Kubernetes tools (default is the user cache directory)")
+	flag.StringVar(&cfg.KubeloginMode, "kubelogin-mode", "azurecli",
+		"kubelogin login mode for Entra ID clusters in fetched kubeconfig files (azurecli, msi, spn, workloadidentity)")
 
 	flag.Parse()
 
@@ -2,7 +2,8 @@ package config
 
 import (
 	"fmt"
-	"os/exec"
+
+	"github.com/Azure/aks-mcp/internal/command"
 )
 
 // Validator handles all validation logic for MCP Kubernetes
@@ -23,7 +24,7 @@ func NewValidator(cfg *ConfigData) *Validator {
 
 // isCliInstalled checks if a CLI tool is installed and available in the system PATH
 func (v *Validator) isCliInstalled(cliName string) bool {
-	_, err := exec.LookPath(cliName)
+	_, err := command.LookPath(cliName)
 	return err == nil
 }
 
@@ -0,0 +1,299 @@
+package k8s
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/Azure/aks-mcp/internal/command"
+	"github.com/Azure/aks-mcp/internal/kubeconfig"
+	"github.com/Azure/mcp-kubernetes/pkg/cilium"
+	k8sconfig "github.com/Azure/mcp-kubernetes/pkg/config"
+	"github.com/Azure/mcp-kubernetes/pkg/helm"
+	"github.com/Azure/mcp-kubernetes/pkg/kubectl"
+	k8ssecurity "github.com/Azure/mcp-kubernetes/pkg/security"
+	k8stools "github.com/Azure/mcp-kubernetes/pkg/tools"
+	"github.com/google/shlex"
+)
+
+// invokeUnsupportedOperations need a local terminal or file system, which az aks command invoke does not provide
+var invokeUnsupportedOperations = map[string]bool{
+	"cp": true,
+}
+
+// shellMetacharacters chain, substitute or redirect commands in the shell az aks command invoke runs the command line in.
+// The mcp-kubernetes validators only check the operation and namespace flags, as the direct executors run without a shell.
+const shellMetacharacters = ";&|$`<>()\n"
+
+// shellSafePattern matches words that need no quoting in a shell
+var shellSafePattern = regexp.MustCompile(`^[\w@%+=:,./-]+$`)
+
+// routedExecutor runs commands against the current kubeconfig context or the cluster targeted by the
+// call's subscription_id, resource_group and cluster_name, directly or through az aks command invoke
+type routedExecutor struct {
+	binary   string
+	direct   k8stools.CommandExecutor
+	selector *TransportSelector
+	store    *kubeconfig.Store
+	// command validates params like the direct executor does and returns the command line to run
+	command func(params map[string]interface{}, cfg *k8sconfig.ConfigData) (string, error)
+	// invokable reports whether the binary is available to az aks command invoke
+	invokable bool
+}
+
+// NewRoutedKubectlToolExecutor returns an executor for the grouped kubectl tools that runs commands
+// against a targeted cluster and proxies them through az aks command invoke when the selector picks it
+func NewRoutedKubectlToolExecutor(selector *TransportSelector, store *kubeconfig.Store) k8stools.CommandExecutor {
+	return &routedExecutor{
+		binary:    "kubectl",
+		direct:    kubectl.NewKubectlToolExecutor(),
+		selector:  selector,
+		store:     store,
+		command:   kubectlToolCommand,
+		invokable: true,
+	}
+}
+
+// NewRoutedKubectlExecutor returns an executor for kubectl command lines that runs them
+// against a targeted cluster and proxies them through az aks command invoke when the selector picks it
+func NewRoutedKubectlExecutor(selector *TransportSelector, store *kubeconfig.Store) k8stools.CommandExecutor {
+	return &routedExecutor{
+		binary:    "kubectl",
+		direct:    kubectl.NewExecutor(),
+		selector:  selector,
+		store:     store,
+		command:   kubectlCommand,
+		invokable: true,
+	}
+}
+
+// NewRoutedHelmExecutor returns an executor for the helm tool that runs commands
+// against a targeted cluster and proxies them through az aks command invoke when the selector picks it
+func NewRoutedHelmExecutor(selector *TransportSelector, store *kubeconfig.Store) k8stools.CommandExecutor {
+	return &routedExecutor{
+		binary:    "helm",
+		direct:    helm.NewExecutor(),
+		selector:  selector,
+		store:     store,
+		command:   helmCommand,
+		invokable: true,
+	}
+}
+
+// NewRoutedCiliumExecutor returns an executor for the cilium tool that runs commands against a targeted cluster.
+// The cilium CLI is not available to az aks command invoke, so private clusters must be reachable.
+func NewRoutedCiliumExecutor(store *kubeconfig.Store) k8stools.CommandExecutor {
+	return &routedExecutor{
+		binary:  "cilium",
+		direct:  cilium.NewExecutor(),
+		store:   store,
+		command: ciliumCommand,
+	}
+}
+
+// Execute runs the command with the transport chosen for its cluster
+func (e *routedExecutor) Execute(params map[string]interface{}, cfg *k8sconfig.ConfigData) (string, error) {
+	target, err := kubeconfig.TargetFromParams(params)
+	if err != nil {
+		return "", err
+	}
+	if target != nil {
+		return e.executeForTarget(*target, params, cfg)
+	}
+
+	if !e.invokable {
+		return e.direct.Execute(params, cfg)
+	}
+	route := e.selector.Route()
+	if route.Transport != TransportCommandInvoke {
+		return e.direct.Execute(params, cfg)
+	}
+
+	commandLine, err := e.command(params, cfg)
+	if err != nil {
+		return "", err
+	}
+	return invokeCommand(route, params, commandLine, cfg.Timeout)
+}
+
+// executeForTarget runs the command with the kubeconfig file the store fetched for target
+func (e *routedExecutor) executeForTarget(target kubeconfig.Target, params map[string]interface{}, cfg *k8sconfig.ConfigData) (string, error) {
+	// Validate before fetching credentials so rejected commands do not touch the cluster
+	commandLine, err := e.command(params, cfg)
+	if err != nil {
+		return "", err
+	}
+	if e.store == nil {
+		return "", fmt.Errorf("cluster targets are not supported by the %s tool", e.binary)
+	}
+
+	kubeconfigPath, err := e.store.Path(target, cfg.Timeout)
+	if err != nil {
+		return "", err
+	}
+
+	if e.invokable {
+		if route := e.selector.RouteFor(target, kubeconfigPath); route.Transport == TransportCommandInvoke {
+			return invokeCommand(route, params, commandLine, cfg.Timeout)
+		}
+	}
+
+	process := command.NewShellProcess(e.binary, cfg.Timeout)
+	return process.Run(kubeconfig.WithFlag(commandLine, e.binary, kubeconfigPath))
+}
+
+// kubectlToolCommand builds the command line like the mcp-kubernetes kubectl tool executor and validates it with the mcp-kubernetes security validator
+func kubectlToolCommand(params map[string]interface{}, cfg *k8sconfig.ConfigData) (string, error) {
+	operation, ok := params["operation"].(string)
+	if !ok {
+		return "", fmt.Errorf("operation parameter is required and must be a string")
+	}
+	resource, ok := params["resource"].(string)
+	if !ok {
+		return "", fmt.Errorf("resource parameter is required and must be a string")
+	}
+	args, ok := params["args"].(string)
+	if !ok {
+		return "", fmt.Errorf("args parameter is required and must be a string")
+	}
+	toolName, _ := params["_tool_name"].(string)
+	if !slices.Contains(kubectl.GetKubectlToolNames(), toolName) {
+		return "", fmt.Errorf("unknown tool: %s", toolName)
+	}
+
+	// The mcp-kubernetes security validator enforces the access level and namespace scope
+	fullCommand := kubectl.NewKubectlToolExecutor().GetCommandForValidation(operation, resource, args, toolName)
+	validator := k8ssecurity.NewValidator(cfg.SecurityConfig)
+	if err := validator.ValidateCommand(fullCommand, k8ssecurity.CommandTypeKubectl); err != nil {
+		return "", err
+	}
+	return "kubectl " + fullCommand, nil
+}
+
+// kubectlCommand applies the checks of the mcp-kubernetes kubectl executor and returns the kubectl command line
+func kubectlCommand(params map[string]interface{}, cfg *k8sconfig.ConfigData) (string, error) {
+	kubectlCmd, ok := params["command"].(string)
+	if !ok {
+		return "", fmt.Errorf("invalid command parameter")
+	}
+
+	validator := k8ssecurity.NewValidator(cfg.SecurityConfig)
+	if err := validator.ValidateCommand(kubectlCmd, k8ssecurity.CommandTypeKubectl); err != nil {
+		return "", err
+	}
+
+	if !strings.HasPrefix(kubectlCmd, "kubectl ") {
+		kubectlCmd = "kubectl " + kubectlCmd
+	}
+	return kubectlCmd, nil
+}
+
+// helmCommand applies the checks of the mcp-kubernetes helm executor and returns the helm command line
+func helmCommand(params map[string]interface{}, cfg *k8sconfig.ConfigData) (string, error) {
+	helmCmd, ok := params["command"].(string)
+	if !ok {
+		return "", fmt.Errorf("invalid command parameter")
+	}
+
+	validator := k8ssecurity.NewValidator(cfg.SecurityConfig)
+	if err := validator.ValidateCommand(helmCmd, k8ssecurity.CommandTypeHelm); err != nil {
+		return "", err
+	}
+
+	if !strings.HasPrefix(helmCmd, "helm ") {
+		helmCmd = "helm " + helmCmd
+	}
+	return helmCmd, nil
+}
+
+// ciliumCommand applies the checks of the mcp-kubernetes cilium executor and returns the cilium command line
+func ciliumCommand(params map[string]interface{}, cfg *k8sconfig.ConfigData) (string, error) {
+	ciliumCmd, ok := params["command"].(string)
+	if !ok {
+		return "", fmt.Errorf("invalid command parameter")
+	}
+
+	validator := k8ssecurity.NewValidator(cfg.SecurityConfig)
+	if err := validator.ValidateCommand(ciliumCmd, k8ssecurity.CommandTypeCilium); err != nil {
+		return "", err
+	}
+
+	if !strings.HasPrefix(ciliumCmd, "cilium ") {
+		ciliumCmd = "cilium " + ciliumCmd
+	}
+	return ciliumCmd, nil
+}
+
+// invokeResult is the output of az aks command invoke
+type invokeResult struct {
+	ExitCode          int    `json:"exitCode"`
+	Logs              string `json:"logs"`
+	ProvisioningState string `json:"provisioningState"`
+	Reason            string `json:"reason"`
+}
+
+// invokeCommand runs commandLine in the cluster with az aks command invoke and returns its output.
+// Like the direct executors, the output of a command that fails in the cluster is returned without an error.
+func invokeCommand(route Route, params map[string]interface{}, commandLine string, timeout int) (string, error) {
+	if operation, _ := params["operation"].(string); invokeUnsupportedOperations[operation] {
+		return "", fmt.Errorf("operation '%s' is not supported for private clusters reached through az aks command invoke", operation)
+	}
+
+	shellCommand, err := shellCommandLine(commandLine)
+	if err != nil {
+		return "", err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Duration(timeout)*time.Second)
+	defer cancel()
+
+	argv := []string{
+		"az", "aks", "command", "invoke",
+		"--resource-group", route.ResourceGroup,
+		"--name", route.ClusterName,
+		"--subscription", route.SubscriptionID,
+		"--command", shellCommand,
+		"--output", "json",
+	}
+	result, err := command.DefaultRunner().Run(ctx, argv)
+	if ctx.Err() == context.DeadlineExceeded {
+		return "", ctx.Err()
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to run az aks command invoke: %v", err)
+	}
+	if result.ExitCode != 0 {
+		return "", fmt.Errorf("az aks command invoke failed: %s", strings.TrimSpace(result.Stderr))
+	}
+
+	var invoked invokeResult
+	if err := json.Unmarshal([]byte(result.Stdout), &invoked); err != nil {
+		return "", fmt.Errorf("failed to parse az aks command invoke output: %v", err)
+	}
+	if invoked.ProvisioningState != "" && invoked.ProvisioningState != "Succeeded" && invoked.Logs == "" {
+		return "", fmt.Errorf("az aks command invoke %s: %s", strings.ToLower(invoked.ProvisioningState), invoked.Reason)
+	}
+	return invoked.Logs, nil
+}
+
+// shellCommandLine splits a validated command line like the direct executors do and rebuilds it with each word
+// quoted for the shell of az aks command invoke. Command lines with shell metacharacters are rejected.
+func shellCommandLine(commandLine string) (string, error) {
+	if strings.ContainsAny(commandLine, shellMetacharacters) {
+		return "", fmt.Errorf("shell metacharacters (; & | $ ` < > ( ) and newlines) are not allowed in commands for private clusters reached through az aks command invoke")
+	}
+	words, err := shlex.Split(commandLine)
+	if err != nil {
+		return "", fmt.Errorf("failed to parse command: %v", err)
+	}
+	for i, word := range words {
+		if !shellSafePattern.MatchString(word) {
+			words[i] = "'" + strings.ReplaceAll(word, "'", `'\''`) + "'"
+		}
+	}
+	return strings.Join(words, " "), nil
+}
@@ -0,0 +1,215 @@
+package k8s
+
+import (
+	"context"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/Azure/aks-mcp/internal/command"
+	"github.com/Azure/aks-mcp/internal/config"
+	"github.com/Azure/aks-mcp/internal/kubeconfig"
+)
+
+// targetRunner serves az aks get-credentials by writing a kubeconfig and records the other runs
+type targetRunner struct {
+	runs [][]string
+}
+
+func (r *targetRunner) Run(_ context.Context, argv []string) (*command.Result, error) {
+	r.runs = append(r.runs, argv)
+	for i, arg := range argv {
+		if argv[0] == "az" && arg == "--file" {
+			if err := os.WriteFile(argv[i+1], []byte("apiVersion: v1\nkind: Config\n"), 0o600); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return &command.Result{Stdout: "pod/web-6d4cf56db6-9xk2p\n"}, nil
+}
+
+func (r *targetRunner) LookPath(file string) (string, error) {
+	return file, nil
+}
+
+func TestRoutedKubectlToolExecutor_CommandInvoke(t *testing.T) {
+	selector, _ := newTestSelector(t, false)
+	executor := NewRoutedKubectlToolExecutor(selector, nil)
+
+	cfg := config.NewConfig()
+	cfg.AllowNamespaces = "shop"
+	output, err := executor.Execute(map[string]interface{}{
+		"_tool_name": "kubectl_resources",
+		"operation":  "get",
+		"resource":   "pods",
+		"args":       "--namespace shop",
+	}, ConvertConfig(cfg))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if !strings.Contains(output, "web-6d4cf56db6-9xk2p") {
+		t.Errorf("Expected the command invoke logs, got %q", output)
+	}
+}
+
+func TestRoutedKubectlToolExecutor_CommandInvokeChecks(t *testing.T) {
+	selector, _ := newTestSelector(t, false)
+	executor := NewRoutedKubectlToolExecutor(selector, nil)
+
+	tests := []struct {
+		name        string
+		accessLevel string
+		params      map[string]interface{}
+		want        string
+	}{
+		{
+			name:        "access level",
+			accessLevel: "readonly",
+			params:      map[string]interface{}{"_tool_name": "kubectl_resources", "operation": "delete", "resource": "pod", "args": "web --namespace shop"},
+			want:        "Cannot execute write or admin operations in read-only mode",
+		},
+		{
+			name:        "namespace",
+			accessLevel: "readonly",
+			params:      map[string]interface{}{"_tool_name": "kubectl_resources", "operation": "get", "resource": "pods", "args": "--namespace kube-system"},
+			want:        "Access to namespace 'kube-system' is denied",
+		},
+		{
+			name:        "all namespaces",
+			accessLevel: "readonly",
+			params:      map[string]interface{}{"_tool_name": "kubectl_resources", "operation": "get", "resource": "pods", "args": "--all-namespaces"},
+			want:        "Access to all namespaces is restricted",
+		},
+		{
+			name:        "admin operation",
+			accessLevel: "readwrite",
+			params:      map[string]interface{}{"_tool_name": "kubectl_resources", "operation": "drain", "resource": "node", "args": "aks-nodepool1-0"},
+			want:        "Cannot execute admin operations in read-write mode",
+		},
+		{
+			name:        "unknown tool",
+			accessLevel: "admin",
+			params:      map[string]interface{}{"_tool_name": "kubectl_unknown", "operation": "get", "resource": "pods", "args": "--namespace shop"},
+			want:        "unknown tool: kubectl_unknown",
+		},
+		{
+			name:        "chained command",
+			accessLevel: "readonly",
+			params:      map[string]interface{}{"_tool_name": "kubectl_resources", "operation": "get", "resource": "pods", "args": "--namespace shop ; kubectl delete namespace prod"},
+			want:        "shell metacharacters",
+		},
+		{
+			name:        "command substitution",
+			accessLevel: "readonly",
+			params:      map[string]interface{}{"_tool_name": "kubectl_resources", "operation": "get", "resource": "pods", "args": "--namespace shop $(kubectl delete namespace prod)"},
+			want:        "shell metacharacters",
+		},
+		{
+			name:        "copy",
+			accessLevel: "admin",
+			params:      map[string]interface{}{"_tool_name": "kubectl_diagnostics", "operation": "cp", "resource": "", "args": "--namespace shop web:/tmp/a ./a"},
+			want:        "not supported for private clusters",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := config.NewConfig()
+			cfg.AccessLevel = tt.accessLevel
+			cfg.AllowNamespaces = "shop"
+
+			_, err := executor.Execute(tt.params, ConvertConfig(cfg))
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("Expected error containing %q, got %v", tt.want, err)
+			}
+		})
+	}
+}
+
+func TestRoutedHelmExecutor_CommandInvoke(t *testing.T) {
+	selector, _ := newTestSelector(t, false)
+	executor := WrapK8sExecutor(NewRoutedHelmExecutor(selector, nil))
+
+	cfg := config.NewConfig()
+	output, err := executor.Execute(map[string]interface{}{"command": "list --namespace shop"}, cfg)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if !strings.Contains(output, "web-1.2.0") {
+		t.Errorf("Expected the command invoke logs, got %q", output)
+	}
+
+	if _, err := executor.Execute(map[string]interface{}{"command": "uninstall web --namespace shop"}, cfg); err == nil {
+		t.Errorf("Expected helm uninstall to be rejected in read-only mode")
+	}
+}
+
+func TestRoutedKubectlToolExecutor_ClusterTarget(t *testing.T) {
+	runner := &targetRunner{}
+	defer command.SetDefaultRunner(runner)()
+
+	store := kubeconfig.NewStore(t.TempDir(), "")
+	executor := NewRoutedKubectlToolExecutor(NewTransportSelector(nil), store)
+
+	cfg := config.NewConfig()
+	cfg.AllowNamespaces = "shop"
+	params := map[string]interface{}{
+		"_tool_name":      "kubectl_diagnostics",
+		"operation":       "exec",
+		"resource":        "",
+		"args":            "web --namespace shop -- ls /tmp",
+		"subscription_id": "00000000-0000-0000-0000-000000000000",
+		"resource_group":  "test-rg",
+		"cluster_name":    "test-cluster",
+	}
+
+	// Checks apply before credentials are fetched
+	if _, err := executor.Execute(params, ConvertConfig(cfg)); err == nil || len(runner.runs) != 0 {
+		t.Fatalf("Expected exec to be rejected in read-only mode without fetching credentials, got %v after %d runs", err, len(runner.runs))
+	}
+
+	cfg.AccessLevel = "admin"
+	output, err := executor.Execute(params, ConvertConfig(cfg))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if output != "pod/web-6d4cf56db6-9xk2p\n" || len(runner.runs) != 2 {
+		t.Fatalf("Expected credentials to be fetched and the command run, got %q after %v", output, runner.runs)
+	}
+
+	kubeconfigPath, err := store.Path(kubeconfig.Target{SubscriptionID: "00000000-0000-0000-0000-000000000000", ResourceGroup: "test-rg", ClusterName: "test-cluster"}, 60)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	want := "kubectl --kubeconfig=" + kubeconfigPath + " exec web --namespace shop -- ls /tmp"
+	if got := strings.Join(runner.runs[1], " "); got != want {
+		t.Errorf("Expected %q, got %q", want, got)
+	}
+}
+
+func TestRoutedKubectlExecutor_ClusterTarget(t *testing.T) {
+	runner := &targetRunner{}
+	defer command.SetDefaultRunner(runner)()
+
+	store := kubeconfig.NewStore(t.TempDir(), "")
+	executor := WrapK8sExecutor(NewRoutedKubectlExecutor(NewTransportSelector(nil), store))
+
+	target := kubeconfig.Target{SubscriptionID: "00000000-0000-0000-0000-000000000000", ResourceGroup: "test-rg", ClusterName: "test-cluster"}
+	cfg := config.NewConfig()
+	if _, err := executor.Execute(target.Params(map[string]interface{}{"command": "delete pod web --namespace shop"}), cfg); err == nil || len(runner.runs) != 0 {
+		t.Fatalf("Expected delete to be rejected in read-only mode without fetching credentials, got %v after %d runs", err, len(runner.runs))
+	}
+
+	if _, err := executor.Execute(target.Params(map[string]interface{}{"command": "get poddisruptionbudgets --all-namespaces --output json"}), cfg); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	kubeconfigPath, err := store.Path(target, 60)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	want := "kubectl --kubeconfig=" + kubeconfigPath + " get poddisruptionbudgets --all-namespaces --output json"
+	if len(runner.runs) != 2 || strings.Join(runner.runs[1], " ") != want {
+		t.Errorf("Expected %q, got %v", want, runner.runs)
+	}
+}
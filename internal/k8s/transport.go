@@ -0,0 +1,372 @@
+package k8s
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Azure/aks-mcp/internal/azureclient"
+	"github.com/Azure/aks-mcp/internal/command"
+	"github.com/Azure/aks-mcp/internal/kubeconfig"
+)
+
+// Transport is how kubectl and helm commands reach a cluster's API server
+type Transport string
+
+const (
+	// TransportDirect runs kubectl and helm locally against the API server
+	TransportDirect Transport = "direct"
+	// TransportCommandInvoke proxies commands through az aks command invoke
+	TransportCommandInvoke Transport = "command-invoke"
+)
+
+const (
+	// routeCacheTTL is how long the transport chosen for an API server is reused
+	routeCacheTTL = 5 * time.Minute
+	// dialTimeout limits how long the API server reachability check waits
+	dialTimeout = 5 * time.Second
+	// lookupTimeout limits the kubeconfig and cluster lookups made to choose a transport
+	lookupTimeout = 60 * time.Second
+)
+
+// aksDomainSuffix is the domain of AKS API server FQDNs, including private cluster FQDNs
+const aksDomainSuffix = ".azmk8s.io"
+
+// Route is the transport chosen for the cluster a command runs against.
+// The cluster fields are only set for TransportCommandInvoke.
+type Route struct {
+	Transport      Transport
+	SubscriptionID string
+	ResourceGroup  string
+	ClusterName    string
+}
+
+type cachedRoute struct {
+	route   Route
+	expires time.Time
+}
+
+// cachedServer is the API server read from a kubeconfig, valid while the kubeconfig files are unchanged
+type cachedServer struct {
+	server *url.URL
+	stamp  string
+}
+
+// TransportSelector chooses the transport for the current kubeconfig context or a targeted cluster.
+// Commands go through az aks command invoke only when the API server cannot be reached
+// and it belongs to an AKS cluster with EnablePrivateCluster set.
+type TransportSelector struct {
+	azClient *azureclient.AzureClient
+	dial     func(ctx context.Context, network, address string) (net.Conn, error)
+	now      func() time.Time
+
+	mu      sync.Mutex
+	routes  map[string]cachedRoute
+	servers map[string]cachedServer
+}
+
+// NewTransportSelector creates a transport selector that looks clusters up with azClient.
+// A nil client disables command invoke, so every command runs directly.
+func NewTransportSelector(azClient *azureclient.AzureClient) *TransportSelector {
+	dialer := &net.Dialer{Timeout: dialTimeout}
+	return &TransportSelector{
+		azClient: azClient,
+		dial:     dialer.DialContext,
+		now:      time.Now,
+		routes:   make(map[string]cachedRoute),
+		servers:  make(map[string]cachedServer),
+	}
+}
+
+// Route returns the transport for the current kubeconfig context.
+// Failures to choose fall back to the direct transport so kubectl reports its own error.
+func (s *TransportSelector) Route() Route {
+	direct := Route{Transport: TransportDirect}
+	if s == nil || s.azClient == nil {
+		return direct
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), lookupTimeout)
+	defer cancel()
+
+	server, err := s.kubeconfigServer(ctx, "")
+	if err != nil {
+		log.Printf("Using direct kubectl transport: %v", err)
+		return direct
+	}
+
+	return s.cachedRoute(server, func() (Route, error) {
+		return s.selectRoute(ctx, server)
+	})
+}
+
+// RouteFor returns the transport for target, whose credentials are in the kubeconfig file at kubeconfigPath
+func (s *TransportSelector) RouteFor(target kubeconfig.Target, kubeconfigPath string) Route {
+	direct := Route{Transport: TransportDirect}
+	if s == nil || s.azClient == nil {
+		return direct
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), lookupTimeout)
+	defer cancel()
+
+	server, err := s.kubeconfigServer(ctx, kubeconfigPath)
+	if err != nil {
+		log.Printf("Using direct kubectl transport for %s: %v", target, err)
+		return direct
+	}
+
+	return s.cachedRoute(server, func() (Route, error) {
+		return s.routeForCluster(ctx, server, target.SubscriptionID, target.ResourceGroup, target.ClusterName)
+	})
+}
+
+// cachedRoute returns the cached route for the API server or chooses one with selectRoute
+func (s *TransportSelector) cachedRoute(server *url.URL, selectRoute func() (Route, error)) Route {
+	direct := Route{Transport: TransportDirect}
+
+	s.mu.Lock()
+	cached, ok := s.routes[server.Host]
+	s.mu.Unlock()
+	if ok && s.now().Before(cached.expires) {
+		return cached.route
+	}
+
+	route, err := selectRoute()
+	if err != nil {
+		// Retry on the next command rather than caching a lookup failure
+		log.Printf("Using direct kubectl transport for %s: %v", server.Host, err)
+		return direct
+	}
+
+	s.mu.Lock()
+	s.routes[server.Host] = cachedRoute{route: route, expires: s.now().Add(routeCacheTTL)}
+	s.mu.Unlock()
+	return route
+}
+
+// selectRoute checks whether the API server is reachable and, if not, whether it belongs to a private AKS cluster
+func (s *TransportSelector) selectRoute(ctx context.Context, server *url.URL) (Route, error) {
+	direct := Route{Transport: TransportDirect}
+
+	host := strings.ToLower(server.Hostname())
+	if !strings.HasSuffix(host, aksDomainSuffix) {
+		return direct, nil
+	}
+
+	if s.reachable(ctx, server) {
+		return direct, nil
+	}
+
+	clusterID, err := findClusterByFQDN(ctx, host)
+	if err != nil {
+		return direct, err
+	}
+	if clusterID == "" {
+		return direct, nil
+	}
+
+	subscriptionID, resourceGroup, clusterName, err := azureclient.ParseAKSResourceID(clusterID)
+	if err != nil {
+		return direct, err
+	}
+	return s.privateClusterRoute(ctx, server, subscriptionID, resourceGroup, clusterName)
+}
+
+// routeForCluster checks whether the API server of a known cluster is reachable and, if not, whether the cluster is private
+func (s *TransportSelector) routeForCluster(ctx context.Context, server *url.URL, subscriptionID, resourceGroup, clusterName string) (Route, error) {
+	if s.reachable(ctx, server) {
+		return Route{Transport: TransportDirect}, nil
+	}
+	return s.privateClusterRoute(ctx, server, subscriptionID, resourceGroup, clusterName)
+}
+
+// reachable reports whether a TCP connection to the API server can be opened
+func (s *TransportSelector) reachable(ctx context.Context, server *url.URL) bool {
+	port := server.Port()
+	if port == "" {
+		port = "443"
+	}
+	conn, err := s.dial(ctx, "tcp", net.JoinHostPort(server.Hostname(), port))
+	if err != nil {
+		return false
+	}
+	_ = conn.Close()
+	return true
+}
+
+// privateClusterRoute returns the command invoke route when the cluster has EnablePrivateCluster set.
+// The cluster is read uncached, so a cluster made private since it was cached is still routed through command invoke.
+func (s *TransportSelector) privateClusterRoute(ctx context.Context, server *url.URL, subscriptionID, resourceGroup, clusterName string) (Route, error) {
+	direct := Route{Transport: TransportDirect}
+
+	cluster, err := s.azClient.GetAKSClusterStatus(ctx, subscriptionID, resourceGroup, clusterName)
+	if err != nil {
+		return direct, fmt.Errorf("failed to get cluster details: %v", err)
+	}
+
+	props := cluster.Properties
+	if props == nil || props.APIServerAccessProfile == nil || props.APIServerAccessProfile.EnablePrivateCluster == nil ||
+		!*props.APIServerAccessProfile.EnablePrivateCluster {
+		return direct, nil
+	}
+
+	log.Printf("API server %s of private cluster %s is not reachable, running commands through az aks command invoke", server.Hostname(), clusterName)
+	return Route{
+		Transport:      TransportCommandInvoke,
+		SubscriptionID: subscriptionID,
+		ResourceGroup:  resourceGroup,
+		ClusterName:    clusterName,
+	}, nil
+}
+
+// kubeconfigServer returns the API server URL of the current context of a kubeconfig file,
+// or of the default kubeconfig when kubeconfigPath is empty. The URL is read again only
+// after one of the kubeconfig files changes, such as when the current context is switched.
+func (s *TransportSelector) kubeconfigServer(ctx context.Context, kubeconfigPath string) (*url.URL, error) {
+	stamp := kubeconfigStamp(kubeconfigPath)
+
+	s.mu.Lock()
+	cached, ok := s.servers[kubeconfigPath]
+	s.mu.Unlock()
+	if ok && cached.stamp == stamp {
+		return cached.server, nil
+	}
+
+	server, err := readKubeconfigServer(ctx, kubeconfigPath)
+	if err != nil {
+		return nil, err
+	}
+
+	s.mu.Lock()
+	s.servers[kubeconfigPath] = cachedServer{server: server, stamp: stamp}
+	s.mu.Unlock()
+	return server, nil
+}
+
+// kubeconfigStamp returns the size and modification time of the kubeconfig files kubectl reads,
+// following the KUBECONFIG environment variable when kubeconfigPath is empty
+func kubeconfigStamp(kubeconfigPath string) string {
+	var paths []string
+	switch {
+	case kubeconfigPath != "":
+		paths = []string{kubeconfigPath}
+	case os.Getenv("KUBECONFIG") != "":
+		paths = filepath.SplitList(os.Getenv("KUBECONFIG"))
+	default:
+		if home, err := os.UserHomeDir(); err == nil {
+			paths = []string{filepath.Join(home, ".kube", "config")}
+		}
+	}
+
+	var stamp strings.Builder
+	for _, path := range paths {
+		info, err := os.Stat(path)
+		if err != nil {
+			fmt.Fprintf(&stamp, "%s:missing;", path)
+			continue
+		}
+		fmt.Fprintf(&stamp, "%s:%d:%d;", path, info.Size(), info.ModTime().UnixNano())
+	}
+	return stamp.String()
+}
+
+// readKubeconfigServer runs kubectl config view to read the API server URL of the current context
+func readKubeconfigServer(ctx context.Context, kubeconfigPath string) (*url.URL, error) {
+	argv := []string{"kubectl", "config", "view", "--minify", "--output", "json"}
+	if kubeconfigPath != "" {
+		argv = append(argv, "--kubeconfig", kubeconfigPath)
+	}
+	result, err := command.DefaultRunner().Run(ctx, argv)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read kubeconfig: %v", err)
+	}
+	if result.ExitCode != 0 {
+		return nil, fmt.Errorf("failed to read kubeconfig: %s", strings.TrimSpace(result.Stderr))
+	}
+
+	var kubeconfig struct {
+		Clusters []struct {
+			Cluster struct {
+				Server string `json:"server"`
+			} `json:"cluster"`
+		} `json:"clusters"`
+	}
+	if err := json.Unmarshal([]byte(result.Stdout), &kubeconfig); err != nil {
+		return nil, fmt.Errorf("failed to parse kubeconfig: %v", err)
+	}
+	if len(kubeconfig.Clusters) == 0 || kubeconfig.Clusters[0].Cluster.Server == "" {
+		return nil, fmt.Errorf("the current kubeconfig context has no cluster")
+	}
+
+	server, err := url.Parse(kubeconfig.Clusters[0].Cluster.Server)
+	if err != nil {
+		return nil, fmt.Errorf("invalid API server URL: %v", err)
+	}
+	return server, nil
+}
+
+// findClusterByFQDN returns the resource ID of the AKS cluster, in any subscription az can access,
+// whose public or private API server FQDN is host. It returns an empty ID when no cluster matches.
+func findClusterByFQDN(ctx context.Context, host string) (string, error) {
+	subscriptions, err := listSubscriptionIDs(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	for _, subscriptionID := range subscriptions {
+		result, err := command.DefaultRunner().Run(ctx, []string{
+			"az", "aks", "list", "--subscription", subscriptionID,
+			"--query", "[].{id:id,fqdn:fqdn,privateFqdn:privateFqdn}", "--output", "json",
+		})
+		if err != nil {
+			return "", fmt.Errorf("failed to list AKS clusters in subscription %s: %v", subscriptionID, err)
+		}
+		if result.ExitCode != 0 {
+			return "", fmt.Errorf("failed to list AKS clusters in subscription %s: %s", subscriptionID, strings.TrimSpace(result.Stderr))
+		}
+
+		var clusters []struct {
+			ID          string `json:"id"`
+			FQDN        string `json:"fqdn"`
+			PrivateFQDN string `json:"privateFqdn"`
+		}
+		if err := json.Unmarshal([]byte(result.Stdout), &clusters); err != nil {
+			return "", fmt.Errorf("failed to parse AKS cluster list: %v", err)
+		}
+
+		for _, cluster := range clusters {
+			if strings.EqualFold(cluster.PrivateFQDN, host) || strings.EqualFold(cluster.FQDN, host) {
+				return cluster.ID, nil
+			}
+		}
+	}
+	return "", nil
+}
+
+// listSubscriptionIDs returns the enabled subscriptions of the signed-in az account
+func listSubscriptionIDs(ctx context.Context) ([]string, error) {
+	result, err := command.DefaultRunner().Run(ctx, []string{
+		"az", "account", "list", "--query", "[?state=='Enabled'].id", "--output", "json",
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list subscriptions: %v", err)
+	}
+	if result.ExitCode != 0 {
+		return nil, fmt.Errorf("failed to list subscriptions: %s", strings.TrimSpace(result.Stderr))
+	}
+
+	var subscriptions []string
+	if err := json.Unmarshal([]byte(result.Stdout), &subscriptions); err != nil {
+		return nil, fmt.Errorf("failed to parse subscription list: %v", err)
+	}
+	return subscriptions, nil
+}
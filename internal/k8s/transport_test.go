@@ -0,0 +1,127 @@
+package k8s
+
+import (
+	"context"
+	"errors"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Azure/aks-mcp/internal/azureclient/fakearm"
+	"github.com/Azure/aks-mcp/internal/azureclient/fakearm/armtest"
+	"github.com/Azure/aks-mcp/internal/command"
+)
+
+// newTestSelector returns a selector backed by the fake ARM server whose API server dials fail when reachable is false
+func newTestSelector(t *testing.T, reachable bool) (*TransportSelector, *int) {
+	t.Helper()
+
+	stop, err := command.UseCassette(filepath.Join("testdata", "cassettes", "command_invoke.json"))
+	if err != nil {
+		t.Fatalf("Failed to load cassette: %v", err)
+	}
+	t.Cleanup(func() {
+		if err := stop(); err != nil {
+			t.Errorf("Failed to save cassette: %v", err)
+		}
+	})
+
+	_, client, _ := armtest.NewClient(t, filepath.Join("testdata", "private_cluster.json"))
+
+	dials := 0
+	selector := NewTransportSelector(client)
+	selector.dial = func(_ context.Context, _, _ string) (net.Conn, error) {
+		dials++
+		if reachable {
+			client, server := net.Pipe()
+			_ = server.Close()
+			return client, nil
+		}
+		return nil, errors.New("dial tcp: lookup failed: no such host")
+	}
+	return selector, &dials
+}
+
+func TestTransportSelector_PrivateCluster(t *testing.T) {
+	selector, dials := newTestSelector(t, false)
+
+	route := selector.Route()
+	want := Route{
+		Transport:      TransportCommandInvoke,
+		SubscriptionID: fakearm.SubscriptionID,
+		ResourceGroup:  fakearm.ResourceGroup,
+		ClusterName:    "private-cluster",
+	}
+	if route != want {
+		t.Fatalf("Expected %+v, got %+v", want, route)
+	}
+
+	// The choice is cached for the API server until it expires
+	if route := selector.Route(); route != want || *dials != 1 {
+		t.Errorf("Expected cached route after one dial, got %+v after %d dials", route, *dials)
+	}
+	selector.now = func() time.Time { return time.Now().Add(routeCacheTTL + time.Minute) }
+	if selector.Route(); *dials != 2 {
+		t.Errorf("Expected the route to be chosen again once expired, got %d dials", *dials)
+	}
+}
+
+func TestTransportSelector_ReachableServer(t *testing.T) {
+	selector, _ := newTestSelector(t, true)
+
+	if route := selector.Route(); route.Transport != TransportDirect || route.ClusterName != "" {
+		t.Errorf("Expected the direct transport for a reachable API server, got %+v", route)
+	}
+}
+
+func TestTransportSelector_NoAzureClient(t *testing.T) {
+	if route := NewTransportSelector(nil).Route(); route.Transport != TransportDirect {
+		t.Errorf("Expected the direct transport without an Azure client, got %+v", route)
+	}
+}
+
+// countingRunner counts the runs of each command before passing them on
+type countingRunner struct {
+	runner command.Runner
+	counts map[string]int
+}
+
+func (r *countingRunner) Run(ctx context.Context, argv []string) (*command.Result, error) {
+	r.counts[strings.Join(argv, " ")]++
+	return r.runner.Run(ctx, argv)
+}
+
+func (r *countingRunner) LookPath(file string) (string, error) {
+	return r.runner.LookPath(file)
+}
+
+func TestTransportSelector_KubeconfigCache(t *testing.T) {
+	kubeconfigPath := filepath.Join(t.TempDir(), "config")
+	if err := os.WriteFile(kubeconfigPath, []byte("apiVersion: v1\nkind: Config\n"), 0o600); err != nil {
+		t.Fatalf("Failed to write kubeconfig: %v", err)
+	}
+	t.Setenv("KUBECONFIG", kubeconfigPath)
+
+	selector, _ := newTestSelector(t, true)
+	runner := &countingRunner{runner: command.DefaultRunner(), counts: make(map[string]int)}
+	defer command.SetDefaultRunner(runner)()
+
+	const view = "kubectl config view --minify --output json"
+	selector.Route()
+	selector.Route()
+	if runner.counts[view] != 1 {
+		t.Fatalf("Expected the kubeconfig to be read once, got %d reads", runner.counts[view])
+	}
+
+	// Switching contexts rewrites the kubeconfig, so the API server is read again
+	if err := os.WriteFile(kubeconfigPath, []byte("apiVersion: v1\nkind: Config\ncurrent-context: other\n"), 0o600); err != nil {
+		t.Fatalf("Failed to write kubeconfig: %v", err)
+	}
+	selector.Route()
+	if runner.counts[view] != 2 {
+		t.Errorf("Expected the kubeconfig to be read again after it changed, got %d reads", runner.counts[view])
+	}
+}
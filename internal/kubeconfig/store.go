@@ -0,0 +1,194 @@
+package kubeconfig
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Azure/aks-mcp/internal/command"
+)
+
+// credentialTTL is how long fetched credentials are used before they are fetched again,
+// so rotated cluster certificates and changed access are picked up
+const credentialTTL = time.Hour
+
+// DefaultDir returns the default directory for fetched kubeconfig files
+func DefaultDir() string {
+	if dir, err := os.UserCacheDir(); err == nil {
+		return filepath.Join(dir, "aks-mcp", "kubeconfig")
+	}
+	return filepath.Join(os.TempDir(), "aks-mcp-kubeconfig")
+}
+
+// storeEntry is the kubeconfig file of one cluster. Its lock is held while credentials are fetched,
+// so concurrent calls for the cluster wait for one fetch while calls for other clusters proceed.
+type storeEntry struct {
+	mu      sync.Mutex
+	path    string
+	fetched time.Time
+}
+
+// Store fetches user credentials for AKS clusters into private kubeconfig files, one per cluster.
+// Clusters that use Entra ID authentication are converted to authenticate with kubelogin.
+type Store struct {
+	dir       string
+	loginMode string
+	now       func() time.Time
+
+	mu      sync.Mutex
+	entries map[Target]*storeEntry
+}
+
+// NewStore creates a kubeconfig store in dir; an empty dir uses DefaultDir.
+// loginMode is the kubelogin login mode for Entra ID clusters, for example azurecli.
+func NewStore(dir, loginMode string) *Store {
+	if dir == "" {
+		dir = DefaultDir()
+	}
+	if loginMode == "" {
+		loginMode = "azurecli"
+	}
+	return &Store{
+		dir:       dir,
+		loginMode: loginMode,
+		now:       time.Now,
+		entries:   make(map[Target]*storeEntry),
+	}
+}
+
+// filePath returns the kubeconfig file for a target key. Names are hashed because resource group and
+// cluster names may contain the characters a readable file name would use to separate them.
+func (s *Store) filePath(key Target) string {
+	sum := sha256.Sum256([]byte(key.String()))
+	return filepath.Join(s.dir, hex.EncodeToString(sum[:])+".kubeconfig")
+}
+
+// Path returns the kubeconfig file for target, fetching credentials when there are none or they are stale
+func (s *Store) Path(target Target, timeout int) (string, error) {
+	path, _, err := s.credentials(target, timeout)
+	return path, err
+}
+
+// Resolve returns the kubeconfig file for the cluster a tool call targets, or an empty path for the current context
+func (s *Store) Resolve(params map[string]interface{}, timeout int) (string, error) {
+	path, _, err := s.ResolveFetched(params, timeout)
+	return path, err
+}
+
+// ResolveFetched is Resolve that also returns when the credentials in the file were fetched,
+// so callers that load the file once can tell when it has been refreshed
+func (s *Store) ResolveFetched(params map[string]interface{}, timeout int) (string, time.Time, error) {
+	target, err := TargetFromParams(params)
+	if err != nil || target == nil {
+		return "", time.Time{}, err
+	}
+	return s.credentials(*target, timeout)
+}
+
+// credentials returns the kubeconfig file for target and when its credentials were fetched,
+// fetching them when there are none or they are stale
+func (s *Store) credentials(target Target, timeout int) (string, time.Time, error) {
+	if err := target.Validate(); err != nil {
+		return "", time.Time{}, err
+	}
+	key := target.key()
+
+	s.mu.Lock()
+	entry, ok := s.entries[key]
+	if !ok {
+		entry = &storeEntry{path: s.filePath(key)}
+		s.entries[key] = entry
+	}
+	s.mu.Unlock()
+
+	entry.mu.Lock()
+	defer entry.mu.Unlock()
+
+	if !entry.fetched.IsZero() && s.now().Sub(entry.fetched) < credentialTTL {
+		if _, err := os.Stat(entry.path); err == nil {
+			return entry.path, entry.fetched, nil
+		}
+	}
+
+	if err := s.fetch(target, entry.path, timeout); err != nil {
+		return "", time.Time{}, err
+	}
+
+	entry.fetched = s.now()
+	return entry.path, entry.fetched, nil
+}
+
+// fetch writes user credentials for target to path and converts them for kubelogin when needed
+func (s *Store) fetch(target Target, path string, timeout int) error {
+	if err := os.MkdirAll(s.dir, 0o700); err != nil {
+		return fmt.Errorf("failed to create kubeconfig directory: %v", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Duration(timeout)*time.Second)
+	defer cancel()
+
+	log.Printf("Fetching credentials for cluster %s", target)
+	err := run(ctx, []string{
+		"az", "aks", "get-credentials",
+		"--subscription", target.SubscriptionID,
+		"--resource-group", target.ResourceGroup,
+		"--name", target.ClusterName,
+		"--file", path,
+		"--overwrite-existing",
+		"--output", "none",
+	})
+	if err != nil {
+		return fmt.Errorf("failed to get credentials for cluster %s: %v", target, err)
+	}
+	if err := os.Chmod(path, 0o600); err != nil {
+		return fmt.Errorf("failed to restrict kubeconfig permissions: %v", err)
+	}
+
+	// Entra ID clusters get an exec credential plugin that runs kubelogin, by default with device code login,
+	// which cannot complete in a non-interactive server
+	data, err := os.ReadFile(path) // #nosec G304 -- path is in the store directory, named by a hash of the target
+	if err != nil {
+		return fmt.Errorf("failed to read kubeconfig: %v", err)
+	}
+	if !strings.Contains(string(data), "kubelogin") {
+		return nil
+	}
+
+	err = run(ctx, []string{"kubelogin", "convert-kubeconfig", "--login", s.loginMode, "--kubeconfig", path})
+	if err != nil {
+		return fmt.Errorf("failed to convert kubeconfig for cluster %s with kubelogin: %v", target, err)
+	}
+	return nil
+}
+
+// run runs argv with the default runner and reports a non-zero exit code as an error
+func run(ctx context.Context, argv []string) error {
+	result, err := command.DefaultRunner().Run(ctx, argv)
+	if err != nil {
+		return err
+	}
+	if result.ExitCode != 0 {
+		return fmt.Errorf("%s", strings.TrimSpace(result.Stderr))
+	}
+	return nil
+}
+
+// Flag returns the --kubeconfig flag for path, quoted for shell-style argument splitting.
+// Single quotes in path are escaped, so a cache directory containing them cannot end the quoted word.
+func Flag(path string) string {
+	return "--kubeconfig='" + strings.ReplaceAll(path, "'", `'\''`) + "'"
+}
+
+// WithFlag inserts the --kubeconfig flag for path after the binary name of commandLine.
+// The flag goes before the subcommand so it is not passed to a command after "--", as in kubectl exec.
+func WithFlag(commandLine, binary, path string) string {
+	rest := strings.TrimPrefix(strings.TrimPrefix(commandLine, binary), " ")
+	return binary + " " + Flag(path) + " " + rest
+}
@@ -0,0 +1,270 @@
+package kubeconfig
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Azure/aks-mcp/internal/command"
+	"github.com/google/shlex"
+)
+
+// fakeRunner serves az aks get-credentials by writing a kubeconfig and records every run
+type fakeRunner struct {
+	kubeconfig string
+	exitCode   int
+	runs       []string
+}
+
+func (r *fakeRunner) Run(_ context.Context, argv []string) (*command.Result, error) {
+	r.runs = append(r.runs, strings.Join(argv, " "))
+	if r.exitCode != 0 {
+		return &command.Result{Stderr: "ERROR: (ResourceNotFound) The Resource was not found.", ExitCode: r.exitCode}, nil
+	}
+	if argv[0] == "az" {
+		for i, arg := range argv {
+			if arg == "--file" {
+				if err := os.WriteFile(argv[i+1], []byte(r.kubeconfig), 0o644); err != nil {
+					return nil, err
+				}
+			}
+		}
+	}
+	return &command.Result{}, nil
+}
+
+func (r *fakeRunner) LookPath(file string) (string, error) {
+	return file, nil
+}
+
+const testSubscriptionID = "00000000-0000-0000-0000-000000000000"
+
+const entraKubeconfig = `apiVersion: v1
+kind: Config
+users:
+- name: clusterUser_test-rg_test-cluster
+  user:
+    exec:
+      apiVersion: client.authentication.k8s.io/v1beta1
+      command: kubelogin
+      args: [get-token, --login, devicecode]
+`
+
+func TestStore_Path(t *testing.T) {
+	runner := &fakeRunner{kubeconfig: entraKubeconfig}
+	defer command.SetDefaultRunner(runner)()
+
+	dir := filepath.Join(t.TempDir(), "kubeconfig")
+	store := NewStore(dir, "")
+	target := Target{SubscriptionID: "00000000-0000-0000-0000-000000000000", ResourceGroup: "Test-RG", ClusterName: "test-cluster"}
+
+	path, err := store.Path(target, 60)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if filepath.Dir(path) != dir || !strings.HasSuffix(path, ".kubeconfig") || strings.Contains(path, "test-cluster") {
+		t.Errorf("Expected a kubeconfig named by a hash in %s, got %s", dir, path)
+	}
+	if info, err := os.Stat(path); err != nil || info.Mode().Perm() != 0o600 {
+		t.Errorf("Expected a private kubeconfig file, got %v, %v", info, err)
+	}
+
+	want := []string{
+		"az aks get-credentials --subscription 00000000-0000-0000-0000-000000000000 --resource-group Test-RG --name test-cluster --file " + path + " --overwrite-existing --output none",
+		"kubelogin convert-kubeconfig --login azurecli --kubeconfig " + path,
+	}
+	if strings.Join(runner.runs, "\n") != strings.Join(want, "\n") {
+		t.Errorf("Unexpected runs:\n%s\nwant:\n%s", strings.Join(runner.runs, "\n"), strings.Join(want, "\n"))
+	}
+
+	// Names are case-insensitive, and fetched credentials are reused until they are stale
+	if _, err := store.Path(Target{SubscriptionID: target.SubscriptionID, ResourceGroup: "test-rg", ClusterName: "Test-Cluster"}, 60); err != nil || len(runner.runs) != 2 {
+		t.Errorf("Expected cached credentials, got %d runs, %v", len(runner.runs), err)
+	}
+	store.now = func() time.Time { return time.Now().Add(credentialTTL + time.Minute) }
+	if _, err := store.Path(target, 60); err != nil || len(runner.runs) != 4 {
+		t.Errorf("Expected stale credentials to be fetched again, got %d runs, %v", len(runner.runs), err)
+	}
+}
+
+func TestStore_Path_LocalAccounts(t *testing.T) {
+	runner := &fakeRunner{kubeconfig: "apiVersion: v1\nkind: Config\nusers:\n- name: clusterUser\n  user:\n    client-certificate-data: Y2VydA==\n"}
+	defer command.SetDefaultRunner(runner)()
+
+	if _, err := NewStore(t.TempDir(), "azurecli").Path(Target{SubscriptionID: testSubscriptionID, ResourceGroup: "rg", ClusterName: "aks"}, 60); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(runner.runs) != 1 {
+		t.Errorf("Expected kubelogin not to run for a cluster with local accounts, got %v", runner.runs)
+	}
+}
+
+func TestStore_Path_Error(t *testing.T) {
+	defer command.SetDefaultRunner(&fakeRunner{exitCode: 3})()
+
+	_, err := NewStore(t.TempDir(), "").Path(Target{SubscriptionID: testSubscriptionID, ResourceGroup: "rg", ClusterName: "missing"}, 60)
+	if err == nil || !strings.Contains(err.Error(), "failed to get credentials for cluster "+testSubscriptionID+"/rg/missing") || !strings.Contains(err.Error(), "ResourceNotFound") {
+		t.Errorf("Expected the az error, got %v", err)
+	}
+}
+
+func TestStore_Path_AmbiguousNames(t *testing.T) {
+	runner := &fakeRunner{kubeconfig: "apiVersion: v1\nkind: Config\n"}
+	defer command.SetDefaultRunner(runner)()
+
+	// Both targets would join to a_b_c, since resource group and cluster names may contain underscores
+	store := NewStore(t.TempDir(), "")
+	first, err := store.Path(Target{SubscriptionID: testSubscriptionID, ResourceGroup: "a_b", ClusterName: "c"}, 60)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	second, err := store.Path(Target{SubscriptionID: testSubscriptionID, ResourceGroup: "a", ClusterName: "b_c"}, 60)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if first == second {
+		t.Errorf("Expected separate kubeconfig files for different clusters, both got %s", first)
+	}
+	if len(runner.runs) != 2 {
+		t.Errorf("Expected credentials to be fetched for each cluster, got %v", runner.runs)
+	}
+}
+
+func TestStore_ResolveFetched(t *testing.T) {
+	defer command.SetDefaultRunner(&fakeRunner{kubeconfig: "apiVersion: v1\nkind: Config\n"})()
+
+	store := NewStore(t.TempDir(), "")
+	now := time.Now()
+	store.now = func() time.Time { return now }
+	params := map[string]interface{}{"subscription_id": testSubscriptionID, "resource_group": "rg", "cluster_name": "aks"}
+
+	_, fetched, err := store.ResolveFetched(params, 60)
+	if err != nil || !fetched.Equal(now) {
+		t.Fatalf("Expected credentials fetched at %v, got %v, %v", now, fetched, err)
+	}
+
+	// Cached credentials keep their fetch time until they are refreshed
+	store.now = func() time.Time { return now.Add(time.Minute) }
+	if _, cached, err := store.ResolveFetched(params, 60); err != nil || !cached.Equal(now) {
+		t.Errorf("Expected the cached fetch time %v, got %v, %v", now, cached, err)
+	}
+	store.now = func() time.Time { return now.Add(credentialTTL + time.Minute) }
+	if _, refreshed, err := store.ResolveFetched(params, 60); err != nil || !refreshed.Equal(now.Add(credentialTTL+time.Minute)) {
+		t.Errorf("Expected refreshed credentials to get a new fetch time, got %v, %v", refreshed, err)
+	}
+
+	if path, fetched, err := store.ResolveFetched(map[string]interface{}{}, 60); err != nil || path != "" || !fetched.IsZero() {
+		t.Errorf("Expected no kubeconfig for the current context, got %q, %v, %v", path, fetched, err)
+	}
+}
+
+func TestStore_Path_InvalidTarget(t *testing.T) {
+	runner := &fakeRunner{}
+	defer command.SetDefaultRunner(runner)()
+
+	store := NewStore(t.TempDir(), "")
+	targets := []Target{
+		{SubscriptionID: "sub", ResourceGroup: "rg", ClusterName: "aks"},
+		{SubscriptionID: testSubscriptionID, ResourceGroup: "..", ClusterName: "aks"},
+		{SubscriptionID: testSubscriptionID, ResourceGroup: "rg/../../etc", ClusterName: "aks"},
+		{SubscriptionID: testSubscriptionID, ResourceGroup: "rg", ClusterName: "../aks"},
+	}
+	for _, target := range targets {
+		if _, err := store.Path(target, 60); err == nil {
+			t.Errorf("Expected target %s to be rejected", target)
+		}
+	}
+	if len(runner.runs) != 0 {
+		t.Errorf("Expected no credentials to be fetched for invalid targets, got %v", runner.runs)
+	}
+}
+
+// blockingRunner writes a kubeconfig for az aks get-credentials, reports the cluster and waits for release
+type blockingRunner struct {
+	started chan string
+	release chan struct{}
+}
+
+func (r *blockingRunner) Run(_ context.Context, argv []string) (*command.Result, error) {
+	var cluster string
+	for i, arg := range argv {
+		switch arg {
+		case "--name":
+			cluster = argv[i+1]
+		case "--file":
+			if err := os.WriteFile(argv[i+1], []byte("apiVersion: v1\nkind: Config\n"), 0o600); err != nil {
+				return nil, err
+			}
+		}
+	}
+	r.started <- cluster
+	<-r.release
+	return &command.Result{}, nil
+}
+
+func (r *blockingRunner) LookPath(file string) (string, error) {
+	return file, nil
+}
+
+func TestStore_Path_ConcurrentClusters(t *testing.T) {
+	runner := &blockingRunner{started: make(chan string, 2), release: make(chan struct{})}
+	defer command.SetDefaultRunner(runner)()
+
+	store := NewStore(t.TempDir(), "")
+	errs := make(chan error, 2)
+	for _, cluster := range []string{"aks-a", "aks-b"} {
+		go func(cluster string) {
+			_, err := store.Path(Target{SubscriptionID: testSubscriptionID, ResourceGroup: "rg", ClusterName: cluster}, 60)
+			errs <- err
+		}(cluster)
+	}
+
+	// Both fetches start before either finishes, since fetching one cluster does not block the other
+	for i := 0; i < 2; i++ {
+		select {
+		case <-runner.started:
+		case <-time.After(5 * time.Second):
+			close(runner.release)
+			t.Fatalf("Expected credentials for both clusters to be fetched concurrently")
+		}
+	}
+	close(runner.release)
+
+	for i := 0; i < 2; i++ {
+		if err := <-errs; err != nil {
+			t.Errorf("Unexpected error: %v", err)
+		}
+	}
+}
+
+func TestWithFlag(t *testing.T) {
+	tests := []struct {
+		commandLine string
+		want        string
+	}{
+		{"kubectl get pods", "kubectl --kubeconfig='/tmp/a b/c' get pods"},
+		{"kubectl exec web -- ls -l", "kubectl --kubeconfig='/tmp/a b/c' exec web -- ls -l"},
+		{"list --namespace shop", "helm --kubeconfig='/tmp/a b/c' list --namespace shop"},
+	}
+	for _, tt := range tests {
+		binary := strings.Fields(tt.want)[0]
+		if got := WithFlag(tt.commandLine, binary, "/tmp/a b/c"); got != tt.want {
+			t.Errorf("WithFlag(%q) = %q, want %q", tt.commandLine, got, tt.want)
+		}
+	}
+}
+
+func TestFlag_QuotesInPath(t *testing.T) {
+	path := "/tmp/it's' --as=admin '/c"
+	words, err := shlex.Split("kubectl " + Flag(path) + " get pods")
+	if err != nil {
+		t.Fatalf("Failed to split command line: %v", err)
+	}
+	want := []string{"kubectl", "--kubeconfig=" + path, "get", "pods"}
+	if strings.Join(words, "\n") != strings.Join(want, "\n") {
+		t.Errorf("Expected %q, got %q", want, words)
+	}
+}
@@ -0,0 +1,120 @@
+// Package kubeconfig manages the kubeconfig files aks-mcp fetches for the clusters that
+// Kubernetes tools target, so tools do not depend on the current context of ~/.kube/config.
+package kubeconfig
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// Target identifies the AKS cluster a Kubernetes tool call runs against
+type Target struct {
+	SubscriptionID string
+	ResourceGroup  string
+	ClusterName    string
+}
+
+// String returns the target as subscription/resource group/cluster
+func (t Target) String() string {
+	return fmt.Sprintf("%s/%s/%s", t.SubscriptionID, t.ResourceGroup, t.ClusterName)
+}
+
+var (
+	subscriptionIDPattern = regexp.MustCompile(`^[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}$`)
+	// resourceGroupPattern follows the Azure naming rules, which also forbid a trailing period
+	resourceGroupPattern = regexp.MustCompile(`^[-\w.()]{0,89}[-\w()]$`)
+	clusterNamePattern   = regexp.MustCompile(`^[a-zA-Z0-9][a-zA-Z0-9_-]{0,62}$`)
+)
+
+// Validate checks the target against the Azure naming rules, which also keeps
+// the names safe to use in kubeconfig file names
+func (t Target) Validate() error {
+	if !subscriptionIDPattern.MatchString(t.SubscriptionID) {
+		return fmt.Errorf("invalid subscription_id %q: must be a subscription ID", t.SubscriptionID)
+	}
+	if !resourceGroupPattern.MatchString(t.ResourceGroup) {
+		return fmt.Errorf("invalid resource_group %q", t.ResourceGroup)
+	}
+	if !clusterNamePattern.MatchString(t.ClusterName) {
+		return fmt.Errorf("invalid cluster_name %q", t.ClusterName)
+	}
+	return nil
+}
+
+// Params returns a copy of params with the cluster target parameters set to the target
+func (t Target) Params(params map[string]interface{}) map[string]interface{} {
+	targeted := make(map[string]interface{}, len(params)+len(targetParams))
+	for name, value := range params {
+		targeted[name] = value
+	}
+	targeted["subscription_id"] = t.SubscriptionID
+	targeted["resource_group"] = t.ResourceGroup
+	targeted["cluster_name"] = t.ClusterName
+	return targeted
+}
+
+// key returns a case-insensitive identity for the target, since Azure resource names are case-insensitive
+func (t Target) key() Target {
+	return Target{
+		SubscriptionID: strings.ToLower(t.SubscriptionID),
+		ResourceGroup:  strings.ToLower(t.ResourceGroup),
+		ClusterName:    strings.ToLower(t.ClusterName),
+	}
+}
+
+// targetParams are the optional tool parameters that select a cluster
+var targetParams = []struct {
+	name        string
+	description string
+}{
+	{"subscription_id", "Azure Subscription ID of the target AKS cluster. Set with resource_group and cluster_name to run against that cluster instead of the current kubeconfig context"},
+	{"resource_group", "Resource group of the target AKS cluster"},
+	{"cluster_name", "Name of the target AKS cluster"},
+}
+
+// WithClusterTarget adds the optional cluster target parameters to a Kubernetes tool
+func WithClusterTarget(tool mcp.Tool) mcp.Tool {
+	if tool.InputSchema.Properties == nil {
+		tool.InputSchema.Properties = make(map[string]any)
+	}
+	for _, param := range targetParams {
+		tool.InputSchema.Properties[param.name] = map[string]any{
+			"type":        "string",
+			"description": param.description,
+		}
+	}
+	return tool
+}
+
+// TargetFromParams returns the cluster a tool call targets, or nil when it uses the current kubeconfig context
+func TargetFromParams(params map[string]interface{}) (*Target, error) {
+	values := make(map[string]string, len(targetParams))
+	var missing []string
+	for _, param := range targetParams {
+		value, _ := params[param.name].(string)
+		values[param.name] = strings.TrimSpace(value)
+		if values[param.name] == "" {
+			missing = append(missing, param.name)
+		}
+	}
+
+	switch len(missing) {
+	case 0:
+		target := &Target{
+			SubscriptionID: values["subscription_id"],
+			ResourceGroup:  values["resource_group"],
+			ClusterName:    values["cluster_name"],
+		}
+		if err := target.Validate(); err != nil {
+			return nil, err
+		}
+		return target, nil
+	case len(targetParams):
+		return nil, nil
+	default:
+		return nil, fmt.Errorf("a cluster target requires subscription_id, resource_group and cluster_name; missing %s", strings.Join(missing, ", "))
+	}
+}
@@ -0,0 +1,43 @@
+package kubeconfig
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+func TestTargetFromParams(t *testing.T) {
+	target, err := TargetFromParams(map[string]interface{}{"command": "get pods"})
+	if target != nil || err != nil {
+		t.Errorf("Expected no target without cluster parameters, got %+v, %v", target, err)
+	}
+
+	target, err = TargetFromParams(map[string]interface{}{"subscription_id": testSubscriptionID, "resource_group": "rg", "cluster_name": "aks"})
+	if err != nil || target == nil || *target != (Target{SubscriptionID: testSubscriptionID, ResourceGroup: "rg", ClusterName: "aks"}) {
+		t.Errorf("Unexpected target %+v, %v", target, err)
+	}
+
+	_, err = TargetFromParams(map[string]interface{}{"subscription_id": testSubscriptionID, "resource_group": "../rg", "cluster_name": "aks"})
+	if err == nil || !strings.Contains(err.Error(), "invalid resource_group") {
+		t.Errorf("Expected an invalid resource group error, got %v", err)
+	}
+
+	_, err = TargetFromParams(map[string]interface{}{"cluster_name": "aks"})
+	if err == nil || !strings.Contains(err.Error(), "missing subscription_id, resource_group") {
+		t.Errorf("Expected an incomplete target error, got %v", err)
+	}
+}
+
+func TestWithClusterTarget(t *testing.T) {
+	tool := WithClusterTarget(mcp.NewTool("helm", mcp.WithString("command", mcp.Required())))
+
+	for _, name := range []string{"command", "subscription_id", "resource_group", "cluster_name"} {
+		if _, ok := tool.InputSchema.Properties[name]; !ok {
+			t.Errorf("Expected parameter %s", name)
+		}
+	}
+	if len(tool.InputSchema.Required) != 1 {
+		t.Errorf("Expected cluster target parameters to be optional, got required %v", tool.InputSchema.Required)
+	}
+}
@@ -29,6 +29,11 @@ var (
 		"az aks nodepool list",
 		"az aks nodepool show",
 		"az aks nodepool get-upgrades",
+		"az aks nodepool snapshot list",
+
+		// Maintenance configuration commands
+		"az aks maintenanceconfiguration list",
+		"az aks maintenanceconfiguration show",
 
 		// Operation and snapshot commands
 		"az aks operation",
@@ -59,6 +64,10 @@ var (
 		"az monitor activity-log list",
 		"az monitor app-insights query",
 
+		// VM quota and SKU commands (read-only)
+		"az vm list-usage",
+		"az vm list-skus",
+
 		// Azure Fleet commands (read-only)
 		"az fleet list",
 		"az fleet show",
@@ -8,14 +8,20 @@ import (
 	"github.com/Azure/aks-mcp/internal/azureclient"
 	"github.com/Azure/aks-mcp/internal/components/advisor"
 	"github.com/Azure/aks-mcp/internal/components/azaks"
+	"github.com/Azure/aks-mcp/internal/components/clusterdiff"
 	"github.com/Azure/aks-mcp/internal/components/compute"
+	"github.com/Azure/aks-mcp/internal/components/deprecation"
 	"github.com/Azure/aks-mcp/internal/components/detectors"
 	"github.com/Azure/aks-mcp/internal/components/fleet"
 	"github.com/Azure/aks-mcp/internal/components/inspektorgadget"
 	"github.com/Azure/aks-mcp/internal/components/monitor"
+	"github.com/Azure/aks-mcp/internal/components/multicluster"
 	"github.com/Azure/aks-mcp/internal/components/network"
+	"github.com/Azure/aks-mcp/internal/components/snapshot"
+	"github.com/Azure/aks-mcp/internal/components/upgrade"
 	"github.com/Azure/aks-mcp/internal/config"
 	"github.com/Azure/aks-mcp/internal/k8s"
+	"github.com/Azure/aks-mcp/internal/kubeconfig"
 	"github.com/Azure/aks-mcp/internal/tools"
 	"github.com/Azure/aks-mcp/internal/version"
 	"github.com/Azure/mcp-kubernetes/pkg/cilium"
@@ -30,6 +36,10 @@ type Service struct {
 	cfg       *config.ConfigData
 	mcpServer *server.MCPServer
 	azClient  *azureclient.AzureClient
+	// transportSelector chooses how Kubernetes commands reach each cluster
+	transportSelector *k8s.TransportSelector
+	// kubeconfigStore holds the credentials of clusters that tool calls target
+	kubeconfigStore *kubeconfig.Store
 }
 
 // NewService creates a new MCP Kubernetes service
@@ -51,6 +61,12 @@ func (s *Service) Initialize() error {
 	s.azClient = azClient
 	log.Println("Azure client initialized successfully")
 
+	// Commands for private clusters that cannot be reached directly go through az aks command invoke
+	s.transportSelector = k8s.NewTransportSelector(azClient)
+
+	// Credentials for tool calls that target a cluster are kept in aks-mcp's own kubeconfig files
+	s.kubeconfigStore = kubeconfig.NewStore(s.cfg.KubeconfigDir, s.cfg.KubeloginMode)
+
 	// Create MCP server
 	s.mcpServer = server.NewMCPServer(
 		"AKS MCP",
@@ -105,7 +121,7 @@ func (s *Service) registerAzCommands() {
 	// Register AKS operations tool
 	log.Println("Registering tool: az_aks_operations")
 	aksOperationsTool := azaks.RegisterAzAksOperations(s.cfg)
-	s.mcpServer.AddTool(aksOperationsTool, tools.CreateToolHandler(azaks.NewAksOperationsExecutor(), s.cfg))
+	s.mcpServer.AddTool(aksOperationsTool, tools.CreateToolHandler(azaks.NewAksOperationsExecutor(s.azClient), s.cfg))
 
 	// Register monitoring tool
 	log.Println("Registering tool: az_monitoring")
@@ -128,6 +144,15 @@ func (s *Service) registerAzureResourceTools() {
 	// Register Compute-related tools
 	s.registerComputeTools(s.azClient)
 
+	// Register upgrade planning tools
+	s.registerUpgradeTools(s.azClient)
+
+	// Register cluster configuration drift tool
+	s.registerClusterDiffTools(s.azClient)
+
+	// Register cluster snapshot export tool
+	s.registerSnapshotTools(s.azClient)
+
 	// TODO: Add other resource categories in the future:
 }
 
@@ -202,6 +227,33 @@ func (s *Service) registerComputeTools(azClient *azureclient.AzureClient) {
 	}
 }
 
+// registerUpgradeTools registers the AKS upgrade planning and deprecated API tools
+func (s *Service) registerUpgradeTools(azClient *azureclient.AzureClient) {
+	log.Println("Registering upgrade tool: aks_upgrade_plan")
+	upgradePlanTool := upgrade.RegisterUpgradePlanTool()
+	// Kubernetes checks run against the cluster each call names, with credentials from the kubeconfig store
+	kubectlExecutor := k8s.WrapK8sExecutor(k8s.NewRoutedKubectlExecutor(s.transportSelector, s.kubeconfigStore))
+	s.mcpServer.AddTool(upgradePlanTool, tools.CreateResourceHandler(upgrade.GetUpgradePlanHandler(azClient, kubectlExecutor, s.cfg), s.cfg))
+
+	log.Println("Registering upgrade tool: aks_deprecated_apis")
+	deprecatedAPIsTool := deprecation.RegisterDeprecatedAPIsTool()
+	s.mcpServer.AddTool(deprecatedAPIsTool, tools.CreateResourceHandler(deprecation.GetDeprecatedAPIsHandler(azClient, kubectlExecutor, s.cfg), s.cfg))
+}
+
+// registerClusterDiffTools registers the tool that compares the configuration of two AKS clusters
+func (s *Service) registerClusterDiffTools(azClient *azureclient.AzureClient) {
+	log.Println("Registering tool: aks_cluster_diff")
+	clusterDiffTool := clusterdiff.RegisterClusterDiffTool()
+	s.mcpServer.AddTool(clusterDiffTool, tools.CreateResourceHandler(clusterdiff.GetClusterDiffHandler(azClient, s.cfg), s.cfg))
+}
+
+// registerSnapshotTools registers the tool that exports a cluster's Azure-side configuration (available at all access levels)
+func (s *Service) registerSnapshotTools(azClient *azureclient.AzureClient) {
+	log.Println("Registering tool: aks_snapshot_export")
+	snapshotTool := snapshot.RegisterSnapshotExportTool()
+	s.mcpServer.AddTool(snapshotTool, tools.CreateResourceHandler(snapshot.GetSnapshotExportHandler(azClient, s.cfg), s.cfg))
+}
+
 // registerAdvisorTools registers all Azure Advisor-related tools
 func (s *Service) registerAdvisorTools() {
 	log.Println("Registering Advisor tools...")
@@ -216,39 +268,42 @@ func (s *Service) registerAdvisorTools() {
 func (s *Service) registerKubernetesTools() {
 	log.Println("Registering Kubernetes tools...")
 
+	transportSelector := s.transportSelector
+	kubeconfigStore := s.kubeconfigStore
+
 	// Register kubectl commands based on access level
-	s.registerKubectlCommands()
+	s.registerKubectlCommands(transportSelector, kubeconfigStore)
 
 	// Register helm if enabled
 	if s.cfg.AdditionalTools["helm"] {
 		log.Println("Registering Kubernetes tool: helm")
-		helmTool := helm.RegisterHelm()
-		helmExecutor := k8s.WrapK8sExecutor(helm.NewExecutor())
+		helmTool := kubeconfig.WithClusterTarget(helm.RegisterHelm())
+		helmExecutor := k8s.WrapK8sExecutor(k8s.NewRoutedHelmExecutor(transportSelector, kubeconfigStore))
 		s.mcpServer.AddTool(helmTool, tools.CreateToolHandler(helmExecutor, s.cfg))
 	}
 
 	// Register cilium if enabled
 	if s.cfg.AdditionalTools["cilium"] {
 		log.Println("Registering Kubernetes tool: cilium")
-		ciliumTool := cilium.RegisterCilium()
-		ciliumExecutor := k8s.WrapK8sExecutor(cilium.NewExecutor())
+		ciliumTool := kubeconfig.WithClusterTarget(cilium.RegisterCilium())
+		ciliumExecutor := k8s.WrapK8sExecutor(k8s.NewRoutedCiliumExecutor(kubeconfigStore))
 		s.mcpServer.AddTool(ciliumTool, tools.CreateToolHandler(ciliumExecutor, s.cfg))
 	}
 
 	// Register Inspektor Gadget tools for observability
 	if s.cfg.AdditionalTools["inspektor-gadget"] {
 		log.Println("Registering Kubernetes tool: inspektor-gadget")
-		s.registerInspektorGadgetTools()
+		s.registerInspektorGadgetTools(kubeconfigStore)
 	}
 }
 
 // registerKubectlCommands registers kubectl commands based on access level
-func (s *Service) registerKubectlCommands() {
+func (s *Service) registerKubectlCommands(transportSelector *k8s.TransportSelector, kubeconfigStore *kubeconfig.Store) {
 	// Get kubectl tools filtered by access level
 	kubectlTools := kubectl.RegisterKubectlTools(s.cfg.AccessLevel)
 
-	// Create a kubectl executor
-	kubectlExecutor := kubectl.NewKubectlToolExecutor()
+	// Create a kubectl executor that routes commands by the current cluster's transport
+	kubectlExecutor := k8s.NewRoutedKubectlToolExecutor(transportSelector, kubeconfigStore)
 
 	// Convert aks-mcp config to k8s config
 	k8sCfg := k8s.ConvertConfig(s.cfg)
@@ -258,19 +313,25 @@ func (s *Service) registerKubectlCommands() {
 		log.Printf("Registering kubectl tool: %s", tool.Name)
 		// Create a handler that injects the tool name into params
 		handler := k8stools.CreateToolHandlerWithName(kubectlExecutor, k8sCfg, tool.Name)
-		s.mcpServer.AddTool(tool, handler)
+		s.mcpServer.AddTool(kubeconfig.WithClusterTarget(tool), handler)
 	}
+
+	// Register the read-only fan-out across clusters, which reuses the cluster-targeting executor
+	log.Println("Registering kubectl tool: kubectl_multi_cluster")
+	multiClusterTool := multicluster.RegisterMultiClusterKubectlTool()
+	s.mcpServer.AddTool(multiClusterTool, tools.CreateResourceHandler(multicluster.GetMultiClusterKubectlHandler(kubectlExecutor, s.cfg), s.cfg))
 }
 
 // registerInspektorGadgetTools registers all Inspektor Gadget tools for observability
-func (s *Service) registerInspektorGadgetTools() {
+func (s *Service) registerInspektorGadgetTools(kubeconfigStore *kubeconfig.Store) {
+	// Without a usable current context, gadgets can still run in clusters that calls target
 	gadgetMgr, err := inspektorgadget.NewGadgetManager()
 	if err != nil {
-		log.Printf("Warning: Failed to create gadget manager: %v", err)
-		return
+		log.Printf("Warning: Failed to create gadget manager for the current kubeconfig context: %v", err)
 	}
 
 	// Register Inspektor Gadget tool
-	inspektorGadget := inspektorgadget.RegisterInspektorGadgetTool()
-	s.mcpServer.AddTool(inspektorGadget, tools.CreateResourceHandler(inspektorgadget.InspektorGadgetHandler(gadgetMgr, s.cfg), s.cfg))
+	inspektorGadget := kubeconfig.WithClusterTarget(inspektorgadget.RegisterInspektorGadgetTool())
+	resolver := inspektorgadget.NewManagerResolver(gadgetMgr, kubeconfigStore, s.cfg)
+	s.mcpServer.AddTool(inspektorGadget, tools.CreateResourceHandler(inspektorgadget.InspektorGadgetHandlerWithResolver(resolver, s.cfg), s.cfg))
 }